@@ -8,6 +8,30 @@ type (
 const (
 	// BearerPrefix is the prefix for the bearer token
 	BearerPrefix = "Bearer"
+
+	// AuthorizationHeaderKey is the key for the authorization header
+	AuthorizationHeaderKey = "Authorization"
+
+	// WWWAuthenticateHeaderKey is the key for the authentication challenge header
+	WWWAuthenticateHeaderKey = "WWW-Authenticate"
+
+	// WebSocketProtocolHeaderKey is the key for the WebSocket subprotocols header
+	WebSocketProtocolHeaderKey = "Sec-WebSocket-Protocol"
+
+	// DefaultWebSocketProtocolPrefix is the default prefix of the WebSocket subprotocol that carries the token
+	DefaultWebSocketProtocolPrefix = "bearer."
+
+	// DefaultRealm is the default realm used in the authentication challenges
+	DefaultRealm = "api"
+
+	// InvalidRequestErrorCode is the RFC 6750 error code for a malformed request
+	InvalidRequestErrorCode = "invalid_request"
+
+	// InvalidTokenErrorCode is the RFC 6750 error code for an expired, revoked, malformed or otherwise invalid token
+	InvalidTokenErrorCode = "invalid_token"
+
+	// InsufficientScopeErrorCode is the RFC 6750 error code for a token without the privileges required by the request
+	InsufficientScopeErrorCode = "insufficient_scope"
 )
 
 var (
@@ -25,4 +49,13 @@ var (
 
 	// SubjectClaim is the claim for the subject
 	SubjectClaim = "sub"
+
+	// ScopeClaim is the claim for the space-delimited scopes
+	ScopeClaim = "scope"
+
+	// PermissionsClaim is the claim for the permissions
+	PermissionsClaim = "permissions"
+
+	// RolesClaim is the claim for the roles
+	RolesClaim = "roles"
 )
@@ -13,4 +13,9 @@ var (
 	ErrMissingTokenClaimsID               = errors.New("missing token claims id")
 	ErrMissingTokenClaimsSubject          = errors.New("missing token claims subject")
 	ErrEmptyToken                         = errors.New("empty token")
+	ErrMissingAuthorizationHeader         = errors.New("missing authorization header")
+	ErrInvalidAuthorizationHeader         = errors.New("invalid authorization header")
+	ErrMissingToken                       = errors.New("missing token")
+	ErrInvalidTokenPrefix                 = errors.New("invalid token prefix")
+	ErrMismatchedTokenType                = errors.New("mismatched token type")
 )
@@ -0,0 +1,295 @@
+package gojwt
+
+import (
+	"errors"
+	"strings"
+)
+
+type (
+	// Carrier provides the request values a token can be extracted from, such as an HTTP request or gRPC metadata
+	Carrier interface {
+		Header(key string) []string
+		Cookie(name string) (string, bool)
+		Query(key string) (string, bool)
+	}
+
+	// TokenExtractor extracts the raw token from a request
+	TokenExtractor interface {
+		ExtractToken(carrier Carrier) (string, error)
+	}
+
+	// AuthorizationHeaderExtractor extracts the bearer token from the authorization header
+	AuthorizationHeaderExtractor struct{}
+
+	// HeaderExtractor extracts the raw token from a custom header
+	HeaderExtractor struct {
+		// Key is the header key
+		Key string
+
+		// Prefix is the prefix removed from the header value (optional)
+		Prefix string
+	}
+
+	// CookieExtractor extracts the raw token from a cookie. The middlewares only accept the tokens it finds when the
+	// request also passes the CSRF check of their cookie configuration
+	CookieExtractor struct {
+		// Name is the cookie name
+		Name string
+	}
+
+	// QueryExtractor extracts the raw token from a query parameter, which should be limited to endpoints that cannot
+	// send headers, since URLs are usually logged
+	QueryExtractor struct {
+		// Key is the query parameter key
+		Key string
+	}
+
+	// MetadataExtractor extracts the raw token from a gRPC metadata key
+	MetadataExtractor struct {
+		// Key is the metadata key
+		Key string
+
+		// Prefix is the prefix removed from the metadata value (optional)
+		Prefix string
+	}
+
+	// WebSocketProtocolExtractor extracts the raw token from the Sec-WebSocket-Protocol header, where browsers can
+	// send it as a subprotocol with the given prefix. The server must still select one of the other subprotocols
+	WebSocketProtocolExtractor struct {
+		// Prefix is the prefix of the subprotocol that carries the token (optional, DefaultWebSocketProtocolPrefix is
+		// used if empty)
+		Prefix string
+	}
+
+	// ChainExtractor tries each extractor in order until one of them finds a token
+	ChainExtractor []TokenExtractor
+)
+
+// ExtractToken extracts the bearer token from the authorization header
+//
+// Parameters:
+//
+//   - carrier: The request carrier
+//
+// Returns:
+//
+//   - string: The raw token
+//   - error: ErrMissingToken if there is no authorization header, or an error if it is not a bearer authorization
+func (AuthorizationHeaderExtractor) ExtractToken(carrier Carrier) (string, error) {
+	values := carrier.Header(AuthorizationHeaderKey)
+	if len(values) == 0 {
+		return "", ErrMissingToken
+	}
+
+	rawToken, err := ParseBearerToken(values[0])
+	if errors.Is(err, ErrMissingAuthorizationHeader) {
+		return "", ErrMissingToken
+	}
+	return rawToken, err
+}
+
+// ExtractToken extracts the raw token from the custom header
+//
+// Parameters:
+//
+//   - carrier: The request carrier
+//
+// Returns:
+//
+//   - string: The raw token
+//   - error: ErrMissingToken if the header is missing or empty
+func (h HeaderExtractor) ExtractToken(carrier Carrier) (string, error) {
+	return extractPrefixedValue(carrier.Header(h.Key), h.Prefix)
+}
+
+// ExtractToken extracts the raw token from the cookie
+//
+// Parameters:
+//
+//   - carrier: The request carrier
+//
+// Returns:
+//
+//   - string: The raw token
+//   - error: ErrMissingToken if the cookie is missing or empty
+func (c CookieExtractor) ExtractToken(carrier Carrier) (string, error) {
+	rawToken, found := carrier.Cookie(c.Name)
+	if !found || rawToken == "" {
+		return "", ErrMissingToken
+	}
+	return rawToken, nil
+}
+
+// ExtractToken extracts the raw token from the query parameter
+//
+// Parameters:
+//
+//   - carrier: The request carrier
+//
+// Returns:
+//
+//   - string: The raw token
+//   - error: ErrMissingToken if the query parameter is missing or empty
+func (q QueryExtractor) ExtractToken(carrier Carrier) (string, error) {
+	rawToken, found := carrier.Query(q.Key)
+	if !found || rawToken == "" {
+		return "", ErrMissingToken
+	}
+	return rawToken, nil
+}
+
+// ExtractToken extracts the raw token from the gRPC metadata key, which is case-insensitive
+//
+// Parameters:
+//
+//   - carrier: The request carrier
+//
+// Returns:
+//
+//   - string: The raw token
+//   - error: ErrMissingToken if the metadata key is missing or empty
+func (m MetadataExtractor) ExtractToken(carrier Carrier) (string, error) {
+	return extractPrefixedValue(
+		carrier.Header(strings.ToLower(m.Key)),
+		m.Prefix,
+	)
+}
+
+// ExtractToken extracts the raw token from the Sec-WebSocket-Protocol header
+//
+// Parameters:
+//
+//   - carrier: The request carrier
+//
+// Returns:
+//
+//   - string: The raw token
+//   - error: ErrMissingToken if no subprotocol carries the token
+func (w WebSocketProtocolExtractor) ExtractToken(carrier Carrier) (string, error) {
+	prefix := w.Prefix
+	if prefix == "" {
+		prefix = DefaultWebSocketProtocolPrefix
+	}
+
+	// Each header value is a comma-separated list of subprotocols
+	for _, value := range carrier.Header(WebSocketProtocolHeaderKey) {
+		for _, protocol := range strings.Split(value, ",") {
+			rawToken, found := strings.CutPrefix(
+				strings.TrimSpace(protocol),
+				prefix,
+			)
+			if found && rawToken != "" {
+				return rawToken, nil
+			}
+		}
+	}
+	return "", ErrMissingToken
+}
+
+// ExtractToken returns the token of the first extractor that finds one. Errors other than ErrMissingToken stop the
+// chain, so a malformed credential is never ignored in favor of another one
+//
+// Parameters:
+//
+//   - carrier: The request carrier
+//
+// Returns:
+//
+//   - string: The raw token
+//   - error: ErrMissingToken if no extractor finds a token, or the error of the extractor that failed
+func (c ChainExtractor) ExtractToken(carrier Carrier) (string, error) {
+	rawToken, _, err := ExtractTokenWithSource(c, carrier)
+	return rawToken, err
+}
+
+// ExtractTokenWithSource extracts the raw token with the given extractor, and returns the extractor that found it,
+// looking into the chains. The source lets the middlewares apply the checks a source needs, such as the CSRF check
+// of the cookies
+//
+// Parameters:
+//
+//   - extractor: The token extractor
+//   - carrier: The request carrier
+//
+// Returns:
+//
+//   - string: The raw token
+//   - TokenExtractor: The extractor that found the token, or nil if the token is missing
+//   - error: ErrMissingToken if no extractor finds a token, or the error of the extractor that failed
+func ExtractTokenWithSource(
+	extractor TokenExtractor,
+	carrier Carrier,
+) (string, TokenExtractor, error) {
+	// Check if the extractor is a chain
+	chain, ok := extractor.(ChainExtractor)
+	if !ok {
+		rawToken, err := extractor.ExtractToken(carrier)
+		if err != nil {
+			return "", nil, err
+		}
+		return rawToken, extractor, nil
+	}
+
+	for _, chained := range chain {
+		if chained == nil {
+			continue
+		}
+
+		rawToken, source, err := ExtractTokenWithSource(chained, carrier)
+		if errors.Is(err, ErrMissingToken) {
+			continue
+		}
+		return rawToken, source, err
+	}
+	return "", nil, ErrMissingToken
+}
+
+// IsCookieExtractor checks if the given extractor reads the token from a cookie. Browsers send the cookies on
+// cross-site requests, so the tokens found by these extractors must pass a CSRF check
+//
+// Parameters:
+//
+//   - extractor: The token extractor
+//
+// Returns:
+//
+//   - bool: True if the extractor is a CookieExtractor
+func IsCookieExtractor(extractor TokenExtractor) bool {
+	switch extractor.(type) {
+	case CookieExtractor, *CookieExtractor:
+		return true
+	default:
+		return false
+	}
+}
+
+// extractPrefixedValue extracts the raw token from the first value, removing the given prefix
+//
+// Parameters:
+//
+//   - values: The header or metadata values
+//   - prefix: The prefix to remove (optional)
+//
+// Returns:
+//
+//   - string: The raw token
+//   - error: ErrMissingToken if there is no value, or ErrInvalidTokenPrefix if the value does not have the prefix
+func extractPrefixedValue(values []string, prefix string) (string, error) {
+	if len(values) == 0 {
+		return "", ErrMissingToken
+	}
+	value := strings.TrimSpace(values[0])
+	if value == "" {
+		return "", ErrMissingToken
+	}
+	if prefix == "" {
+		return value, nil
+	}
+
+	rawToken, found := strings.CutPrefix(value, prefix)
+	rawToken = strings.TrimSpace(rawToken)
+	if !found || rawToken == "" {
+		return "", ErrInvalidTokenPrefix
+	}
+	return rawToken, nil
+}
@@ -0,0 +1,264 @@
+package context
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	gojwt "github.com/ralvarezdev/go-jwt"
+	gojwtauthz "github.com/ralvarezdev/go-jwt/token/authz"
+)
+
+// RequireClaim returns a middleware that requires the token claims set by the authentication middleware to contain
+// the given claim. If values are given, the claim must be equal to one of them, or contain one of them if the claim
+// is a list. Numbers are compared by their value, so RequireClaim("level", 3) matches a level claim of 3
+//
+// Parameters:
+//
+//   - claim: The required claim
+//   - values: The accepted values (optional)
+//
+// Returns:
+//
+//   - gin.HandlerFunc: The middleware
+func (a *Authenticator) RequireClaim(claim string, values ...any) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		claims, ok := a.getClaims(ctx)
+		if !ok {
+			return
+		}
+
+		// Check if the claim is present
+		value, found := claims[claim]
+		if !found {
+			ctx.AbortWithStatus(http.StatusForbidden)
+			return
+		}
+
+		// Check if the claim has one of the accepted values
+		if len(values) > 0 && !claimMatches(value, values) {
+			ctx.AbortWithStatus(http.StatusForbidden)
+			return
+		}
+		ctx.Next()
+	}
+}
+
+// RequireScope returns a middleware that requires the token claims set by the authentication middleware to grant
+// all the given scopes
+//
+// Parameters:
+//
+//   - scopes: The required scopes
+//
+// Returns:
+//
+//   - gin.HandlerFunc: The middleware
+func (a *Authenticator) RequireScope(scopes ...string) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		claims, ok := a.getClaims(ctx)
+		if !ok {
+			return
+		}
+
+		// Check if every scope is granted
+		granted := gojwt.GetClaimsScopes(claims)
+		for _, scope := range scopes {
+			if !slices.Contains(granted, scope) {
+				a.AbortWithChallenge(
+					ctx,
+					http.StatusForbidden,
+					gojwt.InsufficientScopeErrorCode,
+					fmt.Sprintf(
+						"the token requires the scopes: %s",
+						strings.Join(scopes, " "),
+					),
+				)
+				return
+			}
+		}
+		ctx.Next()
+	}
+}
+
+// RequireSubjectParam returns a middleware that requires the subject of the token claims set by the authentication
+// middleware to be equal to the given route parameter, such as the user ID in /users/:id
+//
+// Parameters:
+//
+//   - param: The route parameter name
+//
+// Returns:
+//
+//   - gin.HandlerFunc: The middleware
+func (a *Authenticator) RequireSubjectParam(param string) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		claims, ok := a.getClaims(ctx)
+		if !ok {
+			return
+		}
+
+		// Check if the subject matches the route parameter
+		subject, err := claims.GetSubject()
+		if err != nil || subject == "" || subject != ctx.Param(param) {
+			ctx.AbortWithStatus(http.StatusForbidden)
+			return
+		}
+		ctx.Next()
+	}
+}
+
+// RequirePolicy returns a middleware that requires the token claims set by the authentication middleware to satisfy
+// the given authorization policy
+//
+// Parameters:
+//
+//   - authorizer: The authorizer (optional, the roles grant no permissions if nil)
+//   - policy: The authorization policy
+//
+// Returns:
+//
+//   - gin.HandlerFunc: The middleware
+func (a *Authenticator) RequirePolicy(
+	authorizer *gojwtauthz.Authorizer,
+	policy gojwtauthz.Policy,
+) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		claims, ok := a.getClaims(ctx)
+		if !ok {
+			return
+		}
+
+		// Check the policy
+		if err := authorizer.Authorize(claims, policy); err != nil {
+			if errors.Is(err, gojwtauthz.ErrInsufficientScope) {
+				a.AbortWithChallenge(
+					ctx,
+					http.StatusForbidden,
+					gojwt.InsufficientScopeErrorCode,
+					err.Error(),
+				)
+				return
+			}
+			ctx.AbortWithStatus(http.StatusForbidden)
+			return
+		}
+		ctx.Next()
+	}
+}
+
+// getClaims gets the token claims from the context, aborting the request if they are missing
+//
+// Parameters:
+//
+//   - ctx: The gin context
+//
+// Returns:
+//
+//   - jwt.MapClaims: The token claims
+//   - bool: Whether the token claims were found
+func (a *Authenticator) getClaims(ctx *gin.Context) (jwt.MapClaims, bool) {
+	claims, err := GetCtxTokenClaims(ctx)
+	if err != nil {
+		a.AbortWithChallenge(ctx, http.StatusUnauthorized, "", "")
+		return nil, false
+	}
+	return claims, true
+}
+
+// claimMatches checks if the claim value is equal to, or contains if it is a list, one of the accepted values
+//
+// Parameters:
+//
+//   - value: The claim value
+//   - values: The accepted values
+//
+// Returns:
+//
+//   - bool: Whether the claim value matches
+func claimMatches(value any, values []any) bool {
+	if items, ok := value.([]any); ok {
+		for _, item := range items {
+			if claimContains(values, item) {
+				return true
+			}
+		}
+		return false
+	}
+	return claimContains(values, value)
+}
+
+// claimContains checks if one of the accepted values is equal to the claim value. JSON numbers are decoded as float64,
+// so numbers are compared by their value whatever their Go type
+//
+// Parameters:
+//
+//   - values: The accepted values
+//   - value: The claim value
+//
+// Returns:
+//
+//   - bool: Whether one of the accepted values is equal to the claim value
+func claimContains(values []any, value any) bool {
+	value = normalizeClaimValue(value)
+
+	// Check if the claim value can be compared, lists and objects never match
+	if value == nil || !reflect.TypeOf(value).Comparable() {
+		return false
+	}
+	return slices.ContainsFunc(
+		values, func(accepted any) bool {
+			accepted = normalizeClaimValue(accepted)
+			return accepted != nil && reflect.TypeOf(accepted).Comparable() && accepted == value
+		},
+	)
+}
+
+// normalizeClaimValue converts numbers to float64, as they are decoded from the JSON claims
+//
+// Parameters:
+//
+//   - value: The value
+//
+// Returns:
+//
+//   - any: The normalized value
+func normalizeClaimValue(value any) any {
+	switch v := value.(type) {
+	case int:
+		return float64(v)
+	case int8:
+		return float64(v)
+	case int16:
+		return float64(v)
+	case int32:
+		return float64(v)
+	case int64:
+		return float64(v)
+	case uint:
+		return float64(v)
+	case uint8:
+		return float64(v)
+	case uint16:
+		return float64(v)
+	case uint32:
+		return float64(v)
+	case uint64:
+		return float64(v)
+	case float32:
+		return float64(v)
+	case json.Number:
+		if f, err := v.Float64(); err == nil {
+			return f
+		}
+		return v.String()
+	default:
+		return value
+	}
+}
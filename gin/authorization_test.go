@@ -0,0 +1,163 @@
+package context
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	gojwt "github.com/ralvarezdev/go-jwt"
+	gojwttoken "github.com/ralvarezdev/go-jwt/token"
+)
+
+func TestAuthenticator_RequireClaim(t *testing.T) {
+	service := newTestTokenService(t)
+	authenticator := newTestAuthenticator(t, service, nil)
+
+	// The numeric claims are decoded from the token as float64
+	pair, err := service.IssueTokens(
+		context.Background(),
+		testSubject,
+		map[string]any{
+			"level":  3,
+			"groups": []string{"staff", "admins"},
+		},
+	)
+	if err != nil {
+		t.Fatalf("IssueTokens() error = %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		claim      string
+		values     []any
+		wantStatus int
+	}{
+		{name: "present claim", claim: "level", wantStatus: http.StatusOK},
+		{name: "missing claim", claim: "department", wantStatus: http.StatusForbidden},
+		{name: "int value", claim: "level", values: []any{3}, wantStatus: http.StatusOK},
+		{name: "int64 value", claim: "level", values: []any{int64(3)}, wantStatus: http.StatusOK},
+		{name: "uint8 value", claim: "level", values: []any{uint8(3)}, wantStatus: http.StatusOK},
+		{name: "float64 value", claim: "level", values: []any{3.0}, wantStatus: http.StatusOK},
+		{name: "one of the values", claim: "level", values: []any{1, 2, 3}, wantStatus: http.StatusOK},
+		{name: "different number", claim: "level", values: []any{4}, wantStatus: http.StatusForbidden},
+		{name: "number as a string", claim: "level", values: []any{"3"}, wantStatus: http.StatusForbidden},
+		{name: "contained value", claim: "groups", values: []any{"admins"}, wantStatus: http.StatusOK},
+		{name: "not contained value", claim: "groups", values: []any{"guests"}, wantStatus: http.StatusForbidden},
+	}
+	for _, test := range tests {
+		t.Run(
+			test.name, func(t *testing.T) {
+				router := newTestRouter(
+					"/",
+					authenticator.Authenticate(gojwttoken.AccessToken),
+					authenticator.RequireClaim(test.claim, test.values...),
+				)
+				if recorder := serve(router, "/", pair.AccessToken); recorder.Code != test.wantStatus {
+					t.Errorf("status = %d, want %d", recorder.Code, test.wantStatus)
+				}
+			},
+		)
+	}
+}
+
+func TestAuthenticator_RequireScope(t *testing.T) {
+	service := newTestTokenService(t)
+	authenticator := newTestAuthenticator(t, service, nil)
+
+	pair, err := service.IssueTokens(
+		context.Background(),
+		testSubject,
+		map[string]any{gojwt.ScopeClaim: "orders:read orders:write"},
+	)
+	if err != nil {
+		t.Fatalf("IssueTokens() error = %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		scopes     []string
+		wantStatus int
+	}{
+		{name: "granted scope", scopes: []string{"orders:read"}, wantStatus: http.StatusOK},
+		{name: "granted scopes", scopes: []string{"orders:read", "orders:write"}, wantStatus: http.StatusOK},
+		{name: "missing scope", scopes: []string{"orders:read", "users:read"}, wantStatus: http.StatusForbidden},
+	}
+	for _, test := range tests {
+		t.Run(
+			test.name, func(t *testing.T) {
+				router := newTestRouter(
+					"/",
+					authenticator.Authenticate(gojwttoken.AccessToken),
+					authenticator.RequireScope(test.scopes...),
+				)
+				recorder := serve(router, "/", pair.AccessToken)
+				if recorder.Code != test.wantStatus {
+					t.Fatalf("status = %d, want %d", recorder.Code, test.wantStatus)
+				}
+
+				// The missing scopes are reported in the challenge
+				if test.wantStatus == http.StatusForbidden {
+					challenge := recorder.Header().Get(gojwt.WWWAuthenticateHeaderKey)
+					if !strings.Contains(challenge, `error="`+gojwt.InsufficientScopeErrorCode+`"`) {
+						t.Errorf("challenge = %q, want the %q error code", challenge, gojwt.InsufficientScopeErrorCode)
+					}
+				}
+			},
+		)
+	}
+}
+
+func TestAuthenticator_RequireSubjectParam(t *testing.T) {
+	service := newTestTokenService(t)
+	authenticator := newTestAuthenticator(t, service, nil)
+
+	pair, err := service.IssueTokens(context.Background(), testSubject, nil)
+	if err != nil {
+		t.Fatalf("IssueTokens() error = %v", err)
+	}
+	router := newTestRouter(
+		"/users/:id",
+		authenticator.Authenticate(gojwttoken.AccessToken),
+		authenticator.RequireSubjectParam("id"),
+	)
+
+	tests := []struct {
+		name       string
+		target     string
+		wantStatus int
+	}{
+		{name: "own subject", target: "/users/" + testSubject, wantStatus: http.StatusOK},
+		{name: "another subject", target: "/users/other", wantStatus: http.StatusForbidden},
+	}
+	for _, test := range tests {
+		t.Run(
+			test.name, func(t *testing.T) {
+				if recorder := serve(router, test.target, pair.AccessToken); recorder.Code != test.wantStatus {
+					t.Errorf("status = %d, want %d", recorder.Code, test.wantStatus)
+				}
+			},
+		)
+	}
+}
+
+func TestAuthenticator_Require_WithoutClaims(t *testing.T) {
+	authenticator := newTestAuthenticator(t, newTestTokenService(t), nil)
+
+	// The authorization middlewares reject the requests that were not authenticated
+	for name, middleware := range map[string]gin.HandlerFunc{
+		"RequireClaim":        authenticator.RequireClaim("level"),
+		"RequireScope":        authenticator.RequireScope("orders:read"),
+		"RequireSubjectParam": authenticator.RequireSubjectParam("id"),
+	} {
+		t.Run(
+			name, func(t *testing.T) {
+				router := newTestRouter("/users/:id", middleware)
+				if recorder := serve(router, "/users/"+testSubject, ""); recorder.Code != http.StatusUnauthorized {
+					t.Errorf("status = %d, want %d", recorder.Code, http.StatusUnauthorized)
+				}
+			},
+		)
+	}
+}
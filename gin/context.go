@@ -28,8 +28,8 @@ func SetCtxTokenClaims(ctx *gin.Context, claims jwt.MapClaims) {
 //   - error: An error if the token claims are not found or of an unexpected type
 func GetCtxTokenClaims(ctx *gin.Context) (jwt.MapClaims, error) {
 	// Get the token claims from the context
-	value := ctx.Value(gojwt.CtxTokenClaimsKey)
-	if value == nil {
+	value, found := ctx.Get(gojwt.CtxTokenClaimsKey)
+	if !found || value == nil {
 		return nil, gojwt.ErrMissingTokenClaimsInContext
 	}
 
@@ -64,8 +64,8 @@ func SetCtxToken(ctx *gin.Context, token string) {
 //   - error: An error if the token is not found or of an unexpected type
 func GetCtxToken(ctx *gin.Context) (string, error) {
 	// Get the token from the context
-	value := ctx.Value(gojwt.CtxTokenKey)
-	if value == nil {
+	value, found := ctx.Get(gojwt.CtxTokenKey)
+	if !found || value == nil {
 		return "", gojwt.ErrMissingTokenInContext
 	}
 
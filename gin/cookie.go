@@ -0,0 +1,76 @@
+package context
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	gojwtnethttp "github.com/ralvarezdev/go-jwt/net/http"
+)
+
+// SetTokenCookies writes the access token, the refresh token and a new CSRF token into their cookies
+//
+// Parameters:
+//
+//   - ctx: The gin context
+//   - config: The cookie configuration
+//   - accessToken: The raw access token
+//   - accessTokenExpiresAt: The access token expiration time
+//   - refreshToken: The raw refresh token (optional, the refresh token cookie is not written if empty)
+//   - refreshTokenExpiresAt: The refresh token expiration time
+//
+// Returns:
+//
+//   - error: An error if the config is nil or if the CSRF token could not be generated
+func SetTokenCookies(
+	ctx *gin.Context,
+	config *gojwtnethttp.CookieConfig,
+	accessToken string,
+	accessTokenExpiresAt time.Time,
+	refreshToken string,
+	refreshTokenExpiresAt time.Time,
+) error {
+	return config.SetTokenCookies(
+		ctx.Writer,
+		accessToken,
+		accessTokenExpiresAt,
+		refreshToken,
+		refreshTokenExpiresAt,
+	)
+}
+
+// ClearTokenCookies deletes the token and CSRF cookies
+//
+// Parameters:
+//
+//   - ctx: The gin context
+//   - config: The cookie configuration
+//
+// Returns:
+//
+//   - error: An error if the config is nil
+func ClearTokenCookies(
+	ctx *gin.Context,
+	config *gojwtnethttp.CookieConfig,
+) error {
+	return config.ClearTokenCookies(ctx.Writer)
+}
+
+// CSRFProtect returns a middleware that rejects the state-changing requests without a valid double-submit CSRF token
+//
+// Parameters:
+//
+//   - config: The cookie configuration
+//
+// Returns:
+//
+//   - gin.HandlerFunc: The middleware
+func CSRFProtect(config *gojwtnethttp.CookieConfig) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		if err := config.CheckCSRF(ctx.Request); err != nil {
+			ctx.AbortWithStatus(http.StatusForbidden)
+			return
+		}
+		ctx.Next()
+	}
+}
@@ -0,0 +1,106 @@
+package context
+
+import (
+	"log/slog"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	gojwtnethttp "github.com/ralvarezdev/go-jwt/net/http"
+	gojwtservice "github.com/ralvarezdev/go-jwt/token/service"
+)
+
+type (
+	// IssueHook authenticates the request, such as by checking the user credentials, and returns the authenticated
+	// subject and the application claims of the tokens
+	IssueHook func(ctx *gin.Context) (
+		subject string,
+		claims map[string]any,
+		err error,
+	)
+
+	// TokenHandlers are the mountable token endpoints built on top of a token service, which delegate to the net/http
+	// token handlers
+	TokenHandlers struct {
+		handlers *gojwtnethttp.TokenHandlers
+	}
+)
+
+// NewTokenHandlers creates new token handlers
+//
+// Parameters:
+//
+//   - service: The token service
+//   - logger: The logger (optional, can be nil)
+//
+// Returns:
+//
+//   - *TokenHandlers: The token handlers
+//   - error: An error if the token service is nil
+func NewTokenHandlers(
+	service *gojwtservice.Service,
+	logger *slog.Logger,
+) (*TokenHandlers, error) {
+	handlers, err := gojwtnethttp.NewTokenHandlers(service, logger)
+	if err != nil {
+		return nil, err
+	}
+
+	return &TokenHandlers{
+		handlers: handlers,
+	}, nil
+}
+
+// IssueHandler returns the handler that issues a new pair of tokens for the subject authenticated by the given hook
+//
+// Parameters:
+//
+//   - hook: The hook that authenticates the request
+//
+// Returns:
+//
+//   - gin.HandlerFunc: The handler
+func (t *TokenHandlers) IssueHandler(hook IssueHook) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		t.handlers.IssueHandler(
+			func(r *http.Request) (string, map[string]any, error) {
+				return hook(ctx)
+			},
+		).ServeHTTP(ctx.Writer, ctx.Request)
+	}
+}
+
+// RefreshHandler returns the handler that exchanges the refresh_token parameter for a new pair of tokens
+//
+// Returns:
+//
+//   - gin.HandlerFunc: The handler
+func (t *TokenHandlers) RefreshHandler() gin.HandlerFunc {
+	return gin.WrapH(t.handlers.RefreshHandler())
+}
+
+// RevokeHandler returns the RFC 7009 handler that revokes the token parameter. It must be preceded by the
+// authentication middleware, since only the tokens owned by the authenticated subject are revoked
+//
+// Returns:
+//
+//   - gin.HandlerFunc: The handler
+func (t *TokenHandlers) RevokeHandler() gin.HandlerFunc {
+	handler := t.handlers.RevokeHandler()
+	return func(ctx *gin.Context) {
+		// Pass the token claims set by the authentication middleware to the net/http handler
+		r := ctx.Request
+		if claims, err := GetCtxTokenClaims(ctx); err == nil {
+			r = gojwtnethttp.SetCtxTokenClaims(r, claims)
+		}
+		handler.ServeHTTP(ctx.Writer, r)
+	}
+}
+
+// IntrospectHandler returns the RFC 7662 handler that introspects the token parameter
+//
+// Returns:
+//
+//   - gin.HandlerFunc: The handler
+func (t *TokenHandlers) IntrospectHandler() gin.HandlerFunc {
+	return gin.WrapH(t.handlers.IntrospectHandler())
+}
@@ -0,0 +1,326 @@
+package context
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	gojwt "github.com/ralvarezdev/go-jwt"
+	gojwtnethttp "github.com/ralvarezdev/go-jwt/net/http"
+	gojwttoken "github.com/ralvarezdev/go-jwt/token"
+	gojwtvalidator "github.com/ralvarezdev/go-jwt/token/validator"
+)
+
+type (
+	// Authenticator is the gin authentication middleware that validates bearer tokens
+	Authenticator struct {
+		validator    gojwtvalidator.Validator
+		realm        string
+		skipPaths    map[string]struct{}
+		extractor    gojwt.TokenExtractor
+		cookieConfig *gojwtnethttp.CookieConfig
+		logger       *slog.Logger
+	}
+)
+
+// NewAuthenticator creates a new authenticator
+//
+// Parameters:
+//
+//   - validator: The token validator
+//   - options: The options (optional, can be nil)
+//   - logger: The logger (optional, can be nil)
+//
+// Returns:
+//
+//   - *Authenticator: The authenticator
+//   - error: An error if the validator is nil
+func NewAuthenticator(
+	validator gojwtvalidator.Validator,
+	options *Options,
+	logger *slog.Logger,
+) (*Authenticator, error) {
+	// Check if the validator is nil
+	if validator == nil {
+		return nil, gojwtvalidator.ErrNilValidator
+	}
+
+	// Set the options
+	if options == nil {
+		options = &Options{}
+	}
+	realm := options.Realm
+	if realm == "" {
+		realm = gojwt.DefaultRealm
+	}
+	extractor := options.Extractor
+	if extractor == nil {
+		extractor = gojwt.AuthorizationHeaderExtractor{}
+	}
+	skipPaths := make(map[string]struct{}, len(options.SkipPaths))
+	for _, path := range options.SkipPaths {
+		skipPaths[path] = struct{}{}
+	}
+
+	if logger != nil {
+		logger = logger.With(slog.String("component", "gin_authenticator"))
+	}
+
+	return &Authenticator{
+		validator:    validator,
+		realm:        realm,
+		skipPaths:    skipPaths,
+		extractor:    extractor,
+		cookieConfig: options.CookieConfig,
+		logger:       logger,
+	}, nil
+}
+
+// Authenticate returns a middleware that requires a valid bearer token of the given token type. It can be used per
+// route group to choose between access and refresh tokens
+//
+// Parameters:
+//
+//   - token: The expected token type
+//
+// Returns:
+//
+//   - gin.HandlerFunc: The middleware
+func (a *Authenticator) Authenticate(token gojwttoken.Token) gin.HandlerFunc {
+	return a.authenticate(a.extractor, token, false)
+}
+
+// AuthenticateOptional returns a middleware that validates the token of the given token type only if the request has
+// one. Requests without a token go through without claims in the context, while invalid tokens are still rejected
+//
+// Parameters:
+//
+//   - token: The expected token type
+//
+// Returns:
+//
+//   - gin.HandlerFunc: The middleware
+func (a *Authenticator) AuthenticateOptional(token gojwttoken.Token) gin.HandlerFunc {
+	return a.authenticate(a.extractor, token, true)
+}
+
+// AuthenticateWith returns a middleware that requires a valid token of the given token type found by the given
+// extractor, such as a query parameter or a WebSocket subprotocol for endpoints that cannot send the authorization
+// header. The tokens found by a gojwt.CookieExtractor are only accepted if the request passes the CSRF check of the
+// cookie configuration of the options
+//
+// Parameters:
+//
+//   - extractor: The token extractor (optional, the authenticator extractor is used if nil)
+//   - token: The expected token type
+//
+// Returns:
+//
+//   - gin.HandlerFunc: The middleware
+func (a *Authenticator) AuthenticateWith(
+	extractor gojwt.TokenExtractor,
+	token gojwttoken.Token,
+) gin.HandlerFunc {
+	if extractor == nil {
+		extractor = a.extractor
+	}
+	return a.authenticate(extractor, token, false)
+}
+
+// authenticate returns the authentication middleware
+//
+// Parameters:
+//
+//   - extractor: The token extractor
+//   - token: The expected token type
+//   - optional: Whether requests without a token go through
+//
+// Returns:
+//
+//   - gin.HandlerFunc: The middleware
+func (a *Authenticator) authenticate(
+	extractor gojwt.TokenExtractor,
+	token gojwttoken.Token,
+	optional bool,
+) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		// Check if the path is skipped
+		if a.isSkipped(ctx) {
+			ctx.Next()
+			return
+		}
+
+		// Extract the token from the request
+		rawToken, err := gojwtnethttp.ExtractToken(
+			ctx.Request,
+			extractor,
+			a.cookieConfig,
+		)
+		if err != nil {
+			// Requests without credentials must not receive an error code
+			if errors.Is(err, gojwt.ErrMissingToken) {
+				if optional {
+					ctx.Next()
+					return
+				}
+				a.AbortWithChallenge(ctx, http.StatusUnauthorized, "", "")
+				return
+			}
+
+			// Check if the token cookie failed the CSRF check
+			if errors.Is(err, gojwtnethttp.ErrFailedCSRFCheck) {
+				ctx.AbortWithStatus(http.StatusForbidden)
+				return
+			}
+			a.AbortWithChallenge(
+				ctx,
+				http.StatusBadRequest,
+				gojwt.InvalidRequestErrorCode,
+				err.Error(),
+			)
+			return
+		}
+
+		a.nextWithToken(ctx, rawToken, token)
+	}
+}
+
+// AuthenticateCookie returns a middleware that validates the token of the given token type carried by its cookie,
+// for browser clients. State-changing requests must also carry a valid double-submit CSRF token
+//
+// Parameters:
+//
+//   - config: The cookie configuration
+//   - token: The expected token type
+//
+// Returns:
+//
+//   - gin.HandlerFunc: The middleware
+func (a *Authenticator) AuthenticateCookie(
+	config *gojwtnethttp.CookieConfig,
+	token gojwttoken.Token,
+) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		// Check if the path is skipped
+		if a.isSkipped(ctx) {
+			ctx.Next()
+			return
+		}
+
+		// Get the token from its cookie
+		rawToken, err := config.GetTokenCookie(ctx.Request, token)
+		if err != nil {
+			a.AbortWithChallenge(ctx, http.StatusUnauthorized, "", "")
+			return
+		}
+
+		// Check the CSRF token
+		if err = config.CheckCSRF(ctx.Request); err != nil {
+			ctx.AbortWithStatus(http.StatusForbidden)
+			return
+		}
+
+		a.nextWithToken(ctx, rawToken, token)
+	}
+}
+
+// nextWithToken validates the raw token and calls the next handlers with the raw token and its claims set in the
+// context
+//
+// Parameters:
+//
+//   - ctx: The gin context
+//   - rawToken: The raw token
+//   - token: The expected token type
+func (a *Authenticator) nextWithToken(
+	ctx *gin.Context,
+	rawToken string,
+	token gojwttoken.Token,
+) {
+	// Validate the token
+	claims, err := a.validator.ValidateClaims(
+		ctx.Request.Context(),
+		rawToken,
+		token,
+	)
+	if err != nil {
+		if a.logger != nil {
+			a.logger.Debug(
+				"Token validation failed",
+				slog.String("token", token.String()),
+				slog.String("path", ctx.Request.URL.Path),
+				slog.String("error", err.Error()),
+			)
+		}
+		a.AbortWithChallenge(
+			ctx,
+			http.StatusUnauthorized,
+			gojwt.InvalidTokenErrorCode,
+			gojwt.InvalidTokenDescription(err),
+		)
+		return
+	}
+
+	// Check if the claims belong to the expected token type
+	if err = gojwt.CheckClaimsTokenType(claims, token); err != nil {
+		a.AbortWithChallenge(
+			ctx,
+			http.StatusUnauthorized,
+			gojwt.InvalidTokenErrorCode,
+			gojwt.InvalidTokenDescription(err),
+		)
+		return
+	}
+
+	// Set the raw token and its claims in the context
+	SetCtxToken(ctx, rawToken)
+	SetCtxTokenClaims(ctx, claims)
+	ctx.Next()
+}
+
+// isSkipped checks if the request path is skipped
+//
+// Parameters:
+//
+//   - ctx: The gin context
+//
+// Returns:
+//
+//   - bool: Whether the request path is skipped
+func (a *Authenticator) isSkipped(ctx *gin.Context) bool {
+	if len(a.skipPaths) == 0 {
+		return false
+	}
+	if _, ok := a.skipPaths[ctx.FullPath()]; ok {
+		return true
+	}
+	_, ok := a.skipPaths[ctx.Request.URL.Path]
+	return ok
+}
+
+// AbortWithChallenge aborts the request with an RFC 6750 error response and its WWW-Authenticate challenge
+//
+// Parameters:
+//
+//   - ctx: The gin context
+//   - status: The HTTP status code
+//   - errorCode: The RFC 6750 error code (optional, omitted if empty)
+//   - errorDescription: The human-readable error description (optional, omitted if empty)
+func (a *Authenticator) AbortWithChallenge(
+	ctx *gin.Context,
+	status int,
+	errorCode string,
+	errorDescription string,
+) {
+	realm := gojwt.DefaultRealm
+	if a != nil {
+		realm = a.realm
+	}
+
+	ctx.Header(
+		gojwt.WWWAuthenticateHeaderKey,
+		gojwt.BearerChallenge(realm, errorCode, errorDescription),
+	)
+	ctx.AbortWithStatus(status)
+}
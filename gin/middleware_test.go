@@ -0,0 +1,290 @@
+package context
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	goflagmode "github.com/ralvarezdev/go-flags/mode"
+	gojwt "github.com/ralvarezdev/go-jwt"
+	gojwttoken "github.com/ralvarezdev/go-jwt/token"
+	gojwttokenclaims "github.com/ralvarezdev/go-jwt/token/claims"
+	gojwttokenclaimscache "github.com/ralvarezdev/go-jwt/token/claims/cache"
+	gojwtissuer "github.com/ralvarezdev/go-jwt/token/issuer"
+	gojwttokenservice "github.com/ralvarezdev/go-jwt/token/service"
+	gojwtvalidator "github.com/ralvarezdev/go-jwt/token/validator"
+)
+
+const (
+	// testSubject is the subject of the tokens issued by the tests
+	testSubject = "subject"
+)
+
+// newTestTokenService creates a token service with a new ED25519 key pair and an in-memory token validator
+//
+// Parameters:
+//
+//   - t: The test
+//
+// Returns:
+//
+//   - *gojwttokenservice.Service: The token service
+func newTestTokenService(t *testing.T) *gojwttokenservice.Service {
+	t.Helper()
+
+	// Generate the key pair
+	publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate the key pair: %v", err)
+	}
+	privateKeyDER, err := x509.MarshalPKCS8PrivateKey(privateKey)
+	if err != nil {
+		t.Fatalf("failed to marshal the private key: %v", err)
+	}
+	publicKeyDER, err := x509.MarshalPKIXPublicKey(publicKey)
+	if err != nil {
+		t.Fatalf("failed to marshal the public key: %v", err)
+	}
+
+	tokenValidator := gojwttokenclaimscache.NewTokenValidator(nil)
+	issuer, err := gojwtissuer.NewEd25519Issuer(
+		pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: privateKeyDER}),
+	)
+	if err != nil {
+		t.Fatalf("failed to create the issuer: %v", err)
+	}
+	claimsValidator, err := gojwttokenclaims.NewDefaultClaimsValidator(tokenValidator)
+	if err != nil {
+		t.Fatalf("failed to create the claims validator: %v", err)
+	}
+	validator, err := gojwtvalidator.NewEd25519Validator(
+		pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicKeyDER}),
+		claimsValidator,
+		goflagmode.NewFlag(goflagmode.Prod, goflagmode.AllowedModes),
+	)
+	if err != nil {
+		t.Fatalf("failed to create the validator: %v", err)
+	}
+
+	service, err := gojwttokenservice.NewService(issuer, validator, tokenValidator, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to create the token service: %v", err)
+	}
+	return service
+}
+
+// newTestAuthenticator creates an authenticator backed by the validator of the given token service
+//
+// Parameters:
+//
+//   - t: The test
+//   - service: The token service
+//   - options: The authenticator options (optional, can be nil)
+//
+// Returns:
+//
+//   - *Authenticator: The authenticator
+func newTestAuthenticator(
+	t *testing.T,
+	service *gojwttokenservice.Service,
+	options *Options,
+) *Authenticator {
+	t.Helper()
+
+	authenticator, err := NewAuthenticator(service.Validator(), options, nil)
+	if err != nil {
+		t.Fatalf("NewAuthenticator() error = %v", err)
+	}
+	return authenticator
+}
+
+// newTestRouter creates a router that serves the given route behind the given middlewares, answering with the
+// subject of the token claims in the context, if any
+//
+// Parameters:
+//
+//   - route: The route pattern
+//   - middlewares: The middlewares of the route
+//
+// Returns:
+//
+//   - *gin.Engine: The router
+func newTestRouter(route string, middlewares ...gin.HandlerFunc) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET(
+		route, append(
+			middlewares, func(ctx *gin.Context) {
+				claims, err := GetCtxTokenClaims(ctx)
+				if err != nil {
+					ctx.String(http.StatusOK, "")
+					return
+				}
+				subject, _ := claims.GetSubject()
+				ctx.String(http.StatusOK, subject)
+			},
+		)...,
+	)
+	return router
+}
+
+// serve sends a GET request with the given bearer token to the router
+//
+// Parameters:
+//
+//   - router: The router
+//   - target: The request target
+//   - rawToken: The raw token (optional, no token is sent if empty)
+//
+// Returns:
+//
+//   - *httptest.ResponseRecorder: The recorded response
+func serve(router *gin.Engine, target, rawToken string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, target, nil)
+	if rawToken != "" {
+		req.Header.Set(gojwt.AuthorizationHeaderKey, gojwt.BearerPrefix+" "+rawToken)
+	}
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+	return recorder
+}
+
+func TestAuthenticator_Authenticate(t *testing.T) {
+	service := newTestTokenService(t)
+	ctx := context.Background()
+
+	pair, err := service.IssueTokens(ctx, testSubject, nil)
+	if err != nil {
+		t.Fatalf("IssueTokens() error = %v", err)
+	}
+	revokedPair, err := service.IssueTokens(ctx, testSubject, nil)
+	if err != nil {
+		t.Fatalf("IssueTokens() error = %v", err)
+	}
+	if err = service.Revoke(ctx, revokedPair.AccessToken, ""); err != nil {
+		t.Fatalf("Revoke() error = %v", err)
+	}
+
+	tests := []struct {
+		name          string
+		token         gojwttoken.Token
+		optional      bool
+		rawToken      string
+		wantStatus    int
+		wantErrorCode string
+	}{
+		{
+			name:       "access token",
+			token:      gojwttoken.AccessToken,
+			rawToken:   pair.AccessToken,
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "refresh token on a refresh token route",
+			token:      gojwttoken.RefreshToken,
+			rawToken:   pair.RefreshToken,
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:          "refresh token on an access token route",
+			token:         gojwttoken.AccessToken,
+			rawToken:      pair.RefreshToken,
+			wantStatus:    http.StatusUnauthorized,
+			wantErrorCode: gojwt.InvalidTokenErrorCode,
+		},
+		{
+			name:          "access token on a refresh token route",
+			token:         gojwttoken.RefreshToken,
+			rawToken:      pair.AccessToken,
+			wantStatus:    http.StatusUnauthorized,
+			wantErrorCode: gojwt.InvalidTokenErrorCode,
+		},
+		{
+			name:          "revoked token",
+			token:         gojwttoken.AccessToken,
+			rawToken:      revokedPair.AccessToken,
+			wantStatus:    http.StatusUnauthorized,
+			wantErrorCode: gojwt.InvalidTokenErrorCode,
+		},
+		{
+			name:          "malformed token",
+			token:         gojwttoken.AccessToken,
+			rawToken:      "not-a-token",
+			wantStatus:    http.StatusUnauthorized,
+			wantErrorCode: gojwt.InvalidTokenErrorCode,
+		},
+		{
+			name:       "missing token",
+			token:      gojwttoken.AccessToken,
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name:       "missing optional token",
+			token:      gojwttoken.AccessToken,
+			optional:   true,
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:          "invalid optional token",
+			token:         gojwttoken.AccessToken,
+			optional:      true,
+			rawToken:      pair.RefreshToken,
+			wantStatus:    http.StatusUnauthorized,
+			wantErrorCode: gojwt.InvalidTokenErrorCode,
+		},
+	}
+	for _, test := range tests {
+		t.Run(
+			test.name, func(t *testing.T) {
+				authenticator := newTestAuthenticator(t, service, nil)
+				middleware := authenticator.Authenticate(test.token)
+				if test.optional {
+					middleware = authenticator.AuthenticateOptional(test.token)
+				}
+
+				recorder := serve(newTestRouter("/", middleware), "/", test.rawToken)
+				if recorder.Code != test.wantStatus {
+					t.Fatalf("status = %d, want %d", recorder.Code, test.wantStatus)
+				}
+
+				// Check the subject set in the context of the authenticated requests
+				if recorder.Code == http.StatusOK {
+					if test.rawToken != "" && recorder.Body.String() != testSubject {
+						t.Errorf("subject = %q, want %q", recorder.Body.String(), testSubject)
+					}
+					return
+				}
+
+				// Requests without credentials must not receive an error code
+				challenge := recorder.Header().Get(gojwt.WWWAuthenticateHeaderKey)
+				if !strings.HasPrefix(challenge, gojwt.BearerPrefix) {
+					t.Errorf("challenge = %q, want a bearer challenge", challenge)
+				}
+				hasErrorCode := strings.Contains(challenge, `error="`)
+				if test.wantErrorCode == "" && hasErrorCode {
+					t.Errorf("challenge = %q, want no error code", challenge)
+				}
+				if test.wantErrorCode != "" && !strings.Contains(challenge, `error="`+test.wantErrorCode+`"`) {
+					t.Errorf("challenge = %q, want the %q error code", challenge, test.wantErrorCode)
+				}
+			},
+		)
+	}
+}
+
+func TestAuthenticator_Authenticate_SkipPaths(t *testing.T) {
+	service := newTestTokenService(t)
+	authenticator := newTestAuthenticator(t, service, &Options{SkipPaths: []string{"/health"}})
+
+	router := newTestRouter("/health", authenticator.Authenticate(gojwttoken.AccessToken))
+	if recorder := serve(router, "/health", ""); recorder.Code != http.StatusOK {
+		t.Errorf("status = %d, want %d", recorder.Code, http.StatusOK)
+	}
+}
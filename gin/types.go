@@ -0,0 +1,26 @@
+package context
+
+import (
+	gojwt "github.com/ralvarezdev/go-jwt"
+	gojwtnethttp "github.com/ralvarezdev/go-jwt/net/http"
+)
+
+type (
+	// Options are the options for the gin authenticator
+	Options struct {
+		// Realm is the protection realm sent in the authentication challenges (optional, gojwt.DefaultRealm is used if
+		// empty)
+		Realm string
+
+		// SkipPaths are the paths that are not authenticated, matched against both the route pattern and the request
+		// path
+		SkipPaths []string
+
+		// Extractor is the token extractor (optional, the authorization header is used if nil)
+		Extractor gojwt.TokenExtractor
+
+		// CookieConfig is the cookie configuration whose CSRF check is run for the tokens found by a
+		// gojwt.CookieExtractor (optional, the tokens found in cookies are rejected if nil)
+		CookieConfig *gojwtnethttp.CookieConfig
+	}
+)
@@ -0,0 +1,70 @@
+package grpc
+
+import (
+	"strings"
+
+	"google.golang.org/grpc/metadata"
+)
+
+type (
+	// MetadataCarrier is the token carrier of the gRPC metadata. Cookies and query parameters are not available
+	MetadataCarrier struct {
+		md metadata.MD
+	}
+)
+
+// NewMetadataCarrier creates a new metadata carrier
+//
+// Parameters:
+//
+//   - md: The gRPC metadata (optional, can be nil)
+//
+// Returns:
+//
+//   - *MetadataCarrier: The metadata carrier
+func NewMetadataCarrier(md metadata.MD) *MetadataCarrier {
+	return &MetadataCarrier{
+		md: md,
+	}
+}
+
+// Header returns the values of the given metadata key, which is case-insensitive
+//
+// Parameters:
+//
+//   - key: The metadata key
+//
+// Returns:
+//
+//   - []string: The metadata values
+func (m *MetadataCarrier) Header(key string) []string {
+	return m.md.Get(strings.ToLower(key))
+}
+
+// Cookie is not supported by the gRPC metadata
+//
+// Parameters:
+//
+//   - name: The cookie name
+//
+// Returns:
+//
+//   - string: An empty string
+//   - bool: Always false
+func (m *MetadataCarrier) Cookie(name string) (string, bool) {
+	return "", false
+}
+
+// Query is not supported by the gRPC metadata
+//
+// Parameters:
+//
+//   - key: The query parameter key
+//
+// Returns:
+//
+//   - string: An empty string
+//   - bool: Always false
+func (m *MetadataCarrier) Query(key string) (string, bool) {
+	return "", false
+}
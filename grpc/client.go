@@ -0,0 +1,157 @@
+package grpc
+
+import (
+	"context"
+
+	gojwt "github.com/ralvarezdev/go-jwt"
+	gojwttokensource "github.com/ralvarezdev/go-jwt/token/source"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+type (
+	// ClientAuthenticator is the gRPC client interceptor that attaches the bearer token of a token source, refreshing
+	// it and retrying once when the server answers with codes.Unauthenticated
+	ClientAuthenticator struct {
+		source gojwttokensource.Source
+	}
+)
+
+// NewClientAuthenticator creates a new client authenticator. It must not be combined with PerRPCCredentials over the
+// same source, since the token would be attached twice
+//
+// Parameters:
+//
+//   - source: The token source
+//
+// Returns:
+//
+//   - *ClientAuthenticator: The client authenticator
+//   - error: An error if the source is nil
+func NewClientAuthenticator(source gojwttokensource.Source) (
+	*ClientAuthenticator,
+	error,
+) {
+	// Check if the source is nil
+	if source == nil {
+		return nil, gojwttokensource.ErrNilSource
+	}
+
+	return &ClientAuthenticator{
+		source: source,
+	}, nil
+}
+
+// withToken returns the outgoing context with the given bearer token
+//
+// Parameters:
+//
+//   - ctx: The outgoing context
+//   - rawToken: The raw token
+//
+// Returns:
+//
+//   - context.Context: The outgoing context with the authorization metadata
+func withToken(ctx context.Context, rawToken string) context.Context {
+	return metadata.AppendToOutgoingContext(
+		ctx,
+		AuthorizationMetadataKey,
+		gojwt.BearerPrefix+" "+rawToken,
+	)
+}
+
+// retryToken refreshes the token if the error has the codes.Unauthenticated status code
+//
+// Parameters:
+//
+//   - ctx: The context
+//   - err: The error returned by the first attempt
+//
+// Returns:
+//
+//   - string: The refreshed raw token
+//   - bool: Whether the call must be retried with the refreshed token
+func (c *ClientAuthenticator) retryToken(
+	ctx context.Context,
+	err error,
+) (string, bool) {
+	if status.Code(err) != codes.Unauthenticated {
+		return "", false
+	}
+
+	// Refresh the token, sources without refresh support are not retried
+	rawToken, refreshErr := c.source.Refresh(ctx)
+	if refreshErr != nil {
+		return "", false
+	}
+	return rawToken, true
+}
+
+// UnaryClientInterceptor returns the unary client interceptor that attaches the bearer token
+//
+// Returns:
+//
+//   - grpc.UnaryClientInterceptor: The unary client interceptor
+func (c *ClientAuthenticator) UnaryClientInterceptor() grpc.UnaryClientInterceptor {
+	return func(
+		ctx context.Context,
+		method string,
+		req, reply any,
+		cc *grpc.ClientConn,
+		invoker grpc.UnaryInvoker,
+		opts ...grpc.CallOption,
+	) error {
+		// Get the token
+		rawToken, err := c.source.Token(ctx)
+		if err != nil {
+			return err
+		}
+
+		// Invoke the method, retrying once with a refreshed token
+		err = invoker(withToken(ctx, rawToken), method, req, reply, cc, opts...)
+		if rawToken, retry := c.retryToken(ctx, err); retry {
+			return invoker(
+				withToken(ctx, rawToken),
+				method,
+				req,
+				reply,
+				cc,
+				opts...,
+			)
+		}
+		return err
+	}
+}
+
+// StreamClientInterceptor returns the stream client interceptor that attaches the bearer token. The stream is only
+// retried if its establishment fails, since the messages already exchanged cannot be replayed. The server-streaming
+// calls usually report a rejected token with the first received message instead, so those are not retried
+//
+// Returns:
+//
+//   - grpc.StreamClientInterceptor: The stream client interceptor
+func (c *ClientAuthenticator) StreamClientInterceptor() grpc.StreamClientInterceptor {
+	return func(
+		ctx context.Context,
+		desc *grpc.StreamDesc,
+		cc *grpc.ClientConn,
+		method string,
+		streamer grpc.Streamer,
+		opts ...grpc.CallOption,
+	) (grpc.ClientStream, error) {
+		// Get the token
+		rawToken, err := c.source.Token(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		// Open the stream, retrying once with a refreshed token
+		stream, err := streamer(withToken(ctx, rawToken), desc, cc, method, opts...)
+		if rawToken, retry := c.retryToken(ctx, err); retry {
+			return streamer(withToken(ctx, rawToken), desc, cc, method, opts...)
+		}
+		return stream, err
+	}
+}
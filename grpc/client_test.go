@@ -0,0 +1,198 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+)
+
+type (
+	// fakeSource is a token source that returns a fixed token and a fixed refreshed token
+	fakeSource struct {
+		rawToken          string
+		refreshedRawToken string
+		refreshErr        error
+		refreshes         atomic.Int32
+	}
+)
+
+// Token returns the fixed token
+//
+// Parameters:
+//
+//   - ctx: The context
+//
+// Returns:
+//
+//   - string: The raw token
+//   - error: Always nil
+func (s *fakeSource) Token(ctx context.Context) (string, error) {
+	return s.rawToken, nil
+}
+
+// Refresh counts the refresh and returns the fixed refreshed token
+//
+// Parameters:
+//
+//   - ctx: The context
+//
+// Returns:
+//
+//   - string: The refreshed raw token
+//   - error: The fixed refresh error
+func (s *fakeSource) Refresh(ctx context.Context) (string, error) {
+	s.refreshes.Add(1)
+	return s.refreshedRawToken, s.refreshErr
+}
+
+func TestClientAuthenticator_UnaryClientInterceptor(t *testing.T) {
+	service := newTestTokenService(t)
+	ctx := context.Background()
+
+	pair, err := service.IssueTokens(ctx, testSubject, nil)
+	if err != nil {
+		t.Fatalf("IssueTokens() error = %v", err)
+	}
+	revokedPair, err := service.IssueTokens(ctx, testSubject, nil)
+	if err != nil {
+		t.Fatalf("IssueTokens() error = %v", err)
+	}
+	if err = service.Revoke(ctx, revokedPair.AccessToken, ""); err != nil {
+		t.Fatalf("Revoke() error = %v", err)
+	}
+
+	tests := []struct {
+		name          string
+		source        *fakeSource
+		wantCode      codes.Code
+		wantRefreshes int32
+	}{
+		{
+			name:     "valid token",
+			source:   &fakeSource{rawToken: pair.AccessToken},
+			wantCode: codes.OK,
+		},
+		{
+			name: "rejected token refreshed",
+			source: &fakeSource{
+				rawToken:          revokedPair.AccessToken,
+				refreshedRawToken: pair.AccessToken,
+			},
+			wantCode:      codes.OK,
+			wantRefreshes: 1,
+		},
+		{
+			name: "rejected refreshed token",
+			source: &fakeSource{
+				rawToken:          revokedPair.AccessToken,
+				refreshedRawToken: revokedPair.AccessToken,
+			},
+			wantCode:      codes.Unauthenticated,
+			wantRefreshes: 1,
+		},
+		{
+			name: "failed refresh",
+			source: &fakeSource{
+				rawToken:   revokedPair.AccessToken,
+				refreshErr: errors.New("refresh failed"),
+			},
+			wantCode:      codes.Unauthenticated,
+			wantRefreshes: 1,
+		},
+	}
+	for _, test := range tests {
+		t.Run(
+			test.name, func(t *testing.T) {
+				clientAuthenticator, err := NewClientAuthenticator(test.source)
+				if err != nil {
+					t.Fatalf("NewClientAuthenticator() error = %v", err)
+				}
+				client := newTestHealthClient(
+					t,
+					service,
+					&Options{},
+					grpc.WithUnaryInterceptor(clientAuthenticator.UnaryClientInterceptor()),
+				)
+
+				_, err = client.Check(ctx, &grpc_health_v1.HealthCheckRequest{})
+				if code := status.Code(err); code != test.wantCode {
+					t.Errorf("Check() code = %v, want %v (error = %v)", code, test.wantCode, err)
+				}
+				if refreshes := test.source.refreshes.Load(); refreshes != test.wantRefreshes {
+					t.Errorf("Refresh() calls = %d, want %d", refreshes, test.wantRefreshes)
+				}
+			},
+		)
+	}
+}
+
+func TestClientAuthenticator_StreamClientInterceptor(t *testing.T) {
+	service := newTestTokenService(t)
+	ctx := context.Background()
+
+	pair, err := service.IssueTokens(ctx, testSubject, nil)
+	if err != nil {
+		t.Fatalf("IssueTokens() error = %v", err)
+	}
+	revokedPair, err := service.IssueTokens(ctx, testSubject, nil)
+	if err != nil {
+		t.Fatalf("IssueTokens() error = %v", err)
+	}
+	if err = service.Revoke(ctx, revokedPair.AccessToken, ""); err != nil {
+		t.Fatalf("Revoke() error = %v", err)
+	}
+
+	// The server-streaming calls report the rejected token with the first received message, so they are not retried
+	tests := []struct {
+		name     string
+		source   *fakeSource
+		wantCode codes.Code
+	}{
+		{
+			name:     "valid token",
+			source:   &fakeSource{rawToken: pair.AccessToken},
+			wantCode: codes.OK,
+		},
+		{
+			name: "rejected token",
+			source: &fakeSource{
+				rawToken:          revokedPair.AccessToken,
+				refreshedRawToken: pair.AccessToken,
+			},
+			wantCode: codes.Unauthenticated,
+		},
+	}
+	for _, test := range tests {
+		t.Run(
+			test.name, func(t *testing.T) {
+				clientAuthenticator, err := NewClientAuthenticator(test.source)
+				if err != nil {
+					t.Fatalf("NewClientAuthenticator() error = %v", err)
+				}
+				client := newTestHealthClient(
+					t,
+					service,
+					&Options{},
+					grpc.WithStreamInterceptor(clientAuthenticator.StreamClientInterceptor()),
+				)
+
+				stream, err := client.Watch(ctx, &grpc_health_v1.HealthCheckRequest{})
+				if err == nil {
+					_, err = stream.Recv()
+				}
+				if code := status.Code(err); code != test.wantCode {
+					t.Errorf("Watch() code = %v, want %v (error = %v)", code, test.wantCode, err)
+				}
+				if refreshes := test.source.refreshes.Load(); refreshes != 0 {
+					t.Errorf("Refresh() calls = %d, want 0", refreshes)
+				}
+			},
+		)
+	}
+}
@@ -0,0 +1,6 @@
+package grpc
+
+const (
+	// AuthorizationMetadataKey is the metadata key for the authorization
+	AuthorizationMetadataKey = "authorization"
+)
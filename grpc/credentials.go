@@ -0,0 +1,75 @@
+package grpc
+
+import (
+	"context"
+
+	gojwt "github.com/ralvarezdev/go-jwt"
+	gojwttokensource "github.com/ralvarezdev/go-jwt/token/source"
+)
+
+type (
+	// PerRPCCredentials attaches the bearer token of a token source to every RPC
+	PerRPCCredentials struct {
+		source                   gojwttokensource.Source
+		requireTransportSecurity bool
+	}
+)
+
+// NewPerRPCCredentials creates new per-RPC credentials
+//
+// Parameters:
+//
+//   - source: The token source
+//   - requireTransportSecurity: Whether the credentials require a secure transport
+//
+// Returns:
+//
+//   - *PerRPCCredentials: The per-RPC credentials
+//   - error: An error if the source is nil
+func NewPerRPCCredentials(
+	source gojwttokensource.Source,
+	requireTransportSecurity bool,
+) (*PerRPCCredentials, error) {
+	// Check if the source is nil
+	if source == nil {
+		return nil, gojwttokensource.ErrNilSource
+	}
+
+	return &PerRPCCredentials{
+		source:                   source,
+		requireTransportSecurity: requireTransportSecurity,
+	}, nil
+}
+
+// GetRequestMetadata returns the authorization metadata with the current bearer token
+//
+// Parameters:
+//
+//   - ctx: The context
+//   - uri: The URI of the request entry point
+//
+// Returns:
+//
+//   - map[string]string: The request metadata
+//   - error: An error if the token could not be obtained
+func (p *PerRPCCredentials) GetRequestMetadata(
+	ctx context.Context,
+	uri ...string,
+) (map[string]string, error) {
+	rawToken, err := p.source.Token(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]string{
+		AuthorizationMetadataKey: gojwt.BearerPrefix + " " + rawToken,
+	}, nil
+}
+
+// RequireTransportSecurity returns whether the credentials require a secure transport
+//
+// Returns:
+//
+//   - bool: Whether the credentials require a secure transport
+func (p *PerRPCCredentials) RequireTransportSecurity() bool {
+	return p.requireTransportSecurity
+}
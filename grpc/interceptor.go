@@ -0,0 +1,225 @@
+package grpc
+
+import (
+	"context"
+	"log/slog"
+	"maps"
+
+	gojwt "github.com/ralvarezdev/go-jwt"
+	gojwttoken "github.com/ralvarezdev/go-jwt/token"
+	gojwtauthz "github.com/ralvarezdev/go-jwt/token/authz"
+	gojwtvalidator "github.com/ralvarezdev/go-jwt/token/validator"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+type (
+	// Authenticator is the gRPC server authentication interceptor that validates bearer tokens
+	Authenticator struct {
+		validator     gojwtvalidator.Validator
+		policies      map[string]MethodPolicy
+		defaultPolicy MethodPolicy
+		extractor     gojwt.TokenExtractor
+		authorizer    *gojwtauthz.Authorizer
+		authzPolicies map[string]gojwtauthz.Policy
+		logger        *slog.Logger
+	}
+
+	// authenticatedServerStream is a server stream with the authenticated context
+	authenticatedServerStream struct {
+		grpc.ServerStream
+		ctx context.Context
+	}
+)
+
+// NewAuthenticator creates a new authenticator
+//
+// Parameters:
+//
+//   - validator: The token validator
+//   - options: The options (optional, can be nil)
+//   - logger: The logger (optional, can be nil)
+//
+// Returns:
+//
+//   - *Authenticator: The authenticator
+//   - error: An error if the validator is nil
+func NewAuthenticator(
+	validator gojwtvalidator.Validator,
+	options *Options,
+	logger *slog.Logger,
+) (*Authenticator, error) {
+	// Check if the validator is nil
+	if validator == nil {
+		return nil, gojwtvalidator.ErrNilValidator
+	}
+
+	// Set the options
+	if options == nil {
+		options = &Options{}
+	}
+
+	extractor := options.Extractor
+	if extractor == nil {
+		extractor = gojwt.AuthorizationHeaderExtractor{}
+	}
+
+	if logger != nil {
+		logger = logger.With(slog.String("component", "grpc_authenticator"))
+	}
+
+	return &Authenticator{
+		validator:     validator,
+		policies:      maps.Clone(options.Policies),
+		defaultPolicy: options.DefaultPolicy,
+		extractor:     extractor,
+		authorizer:    options.Authorizer,
+		authzPolicies: maps.Clone(options.AuthorizationPolicies),
+		logger:        logger,
+	}, nil
+}
+
+// Context returns the method context
+//
+// Returns:
+//
+//   - context.Context: The authenticated context
+func (a *authenticatedServerStream) Context() context.Context {
+	return a.ctx
+}
+
+// policy returns the authentication policy of the given method
+//
+// Parameters:
+//
+//   - fullMethod: The full method name
+//
+// Returns:
+//
+//   - MethodPolicy: The authentication policy
+func (a *Authenticator) policy(fullMethod string) MethodPolicy {
+	if policy, ok := a.policies[fullMethod]; ok {
+		return policy
+	}
+	return a.defaultPolicy
+}
+
+// authenticate validates the bearer token of the incoming metadata based on the method policy
+//
+// Parameters:
+//
+//   - ctx: The incoming context
+//   - fullMethod: The full method name
+//
+// Returns:
+//
+//   - context.Context: The context with the raw token and its claims set, or the incoming context for public methods
+//   - error: A codes.Unauthenticated status error if the token is missing or invalid, or a codes.PermissionDenied
+//     status error if the token belongs to another token type or its claims do not satisfy the authorization policy
+func (a *Authenticator) authenticate(
+	ctx context.Context,
+	fullMethod string,
+) (context.Context, error) {
+	// Get the expected token type
+	var token gojwttoken.Token
+	switch a.policy(fullMethod) {
+	case PublicMethod:
+		return ctx, nil
+	case RefreshTokenMethod:
+		token = gojwttoken.RefreshToken
+	default:
+		token = gojwttoken.AccessToken
+	}
+
+	// Extract the token from the metadata
+	md, _ := metadata.FromIncomingContext(ctx)
+	rawToken, err := a.extractor.ExtractToken(NewMetadataCarrier(md))
+	if err != nil {
+		return nil, status.Error(codes.Unauthenticated, err.Error())
+	}
+
+	// Validate the token
+	claims, err := a.validator.ValidateClaims(ctx, rawToken, token)
+	if err != nil {
+		if a.logger != nil {
+			a.logger.Debug(
+				"Token validation failed",
+				slog.String("token", token.String()),
+				slog.String("method", fullMethod),
+				slog.String("error", err.Error()),
+			)
+		}
+
+		// Check if the token is authentic but of another token type
+		if parsedClaims, parseErr := a.validator.GetClaims(rawToken); parseErr == nil {
+			if typeErr := gojwt.CheckClaimsTokenType(
+				parsedClaims,
+				token,
+			); typeErr != nil {
+				return nil, status.Error(codes.PermissionDenied, typeErr.Error())
+			}
+		}
+		return nil, status.Error(
+			codes.Unauthenticated,
+			gojwt.InvalidTokenDescription(err),
+		)
+	}
+	if err = gojwt.CheckClaimsTokenType(claims, token); err != nil {
+		return nil, status.Error(codes.PermissionDenied, err.Error())
+	}
+
+	// Check the authorization policy
+	if policy, ok := a.authzPolicies[fullMethod]; ok {
+		if err = a.authorizer.Authorize(claims, policy); err != nil {
+			return nil, status.Error(codes.PermissionDenied, err.Error())
+		}
+	}
+
+	// Set the raw token and its claims in the context
+	return SetCtxTokenClaims(SetCtxToken(ctx, rawToken), claims), nil
+}
+
+// UnaryServerInterceptor returns the unary server interceptor that authenticates the requests
+//
+// Returns:
+//
+//   - grpc.UnaryServerInterceptor: The unary server interceptor
+func (a *Authenticator) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(
+		ctx context.Context,
+		req any,
+		info *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler,
+	) (any, error) {
+		ctx, err := a.authenticate(ctx, info.FullMethod)
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// StreamServerInterceptor returns the stream server interceptor that authenticates the streams
+//
+// Returns:
+//
+//   - grpc.StreamServerInterceptor: The stream server interceptor
+func (a *Authenticator) StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(
+		srv any,
+		ss grpc.ServerStream,
+		info *grpc.StreamServerInfo,
+		handler grpc.StreamHandler,
+	) error {
+		ctx, err := a.authenticate(ss.Context(), info.FullMethod)
+		if err != nil {
+			return err
+		}
+		return handler(
+			srv,
+			&authenticatedServerStream{ServerStream: ss, ctx: ctx},
+		)
+	}
+}
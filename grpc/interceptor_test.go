@@ -0,0 +1,427 @@
+package grpc
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"net"
+	"testing"
+
+	goflagmode "github.com/ralvarezdev/go-flags/mode"
+	gojwt "github.com/ralvarezdev/go-jwt"
+	gojwtauthz "github.com/ralvarezdev/go-jwt/token/authz"
+	gojwttokenclaims "github.com/ralvarezdev/go-jwt/token/claims"
+	gojwttokenclaimscache "github.com/ralvarezdev/go-jwt/token/claims/cache"
+	gojwtissuer "github.com/ralvarezdev/go-jwt/token/issuer"
+	gojwttokenservice "github.com/ralvarezdev/go-jwt/token/service"
+	gojwtvalidator "github.com/ralvarezdev/go-jwt/token/validator"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+const (
+	// testSubject is the subject of the tokens issued by the tests
+	testSubject = "subject"
+
+	// checkMethod is the full method name of the unary method used by the tests
+	checkMethod = "/grpc.health.v1.Health/Check"
+
+	// watchMethod is the full method name of the stream method used by the tests
+	watchMethod = "/grpc.health.v1.Health/Watch"
+)
+
+type (
+	// subjectHealthServer is a health server that fails the calls whose context misses the claims of the test subject
+	subjectHealthServer struct {
+		*health.Server
+	}
+)
+
+// Check checks the subject of the context before answering
+//
+// Parameters:
+//
+//   - ctx: The context
+//   - req: The request
+//
+// Returns:
+//
+//   - *grpc_health_v1.HealthCheckResponse: The response
+//   - error: A codes.Internal status error if the context misses the claims of the test subject
+func (s subjectHealthServer) Check(
+	ctx context.Context,
+	req *grpc_health_v1.HealthCheckRequest,
+) (*grpc_health_v1.HealthCheckResponse, error) {
+	if subject, err := GetCtxTokenClaimsSubject(ctx); err != nil || subject != testSubject {
+		return nil, status.Error(codes.Internal, "missing the claims of the test subject")
+	}
+	return s.Server.Check(ctx, req)
+}
+
+// Watch checks the subject of the stream context before answering
+//
+// Parameters:
+//
+//   - req: The request
+//   - stream: The server stream
+//
+// Returns:
+//
+//   - error: A codes.Internal status error if the context misses the claims of the test subject
+func (s subjectHealthServer) Watch(
+	req *grpc_health_v1.HealthCheckRequest,
+	stream grpc_health_v1.Health_WatchServer,
+) error {
+	if subject, err := GetCtxTokenClaimsSubject(stream.Context()); err != nil || subject != testSubject {
+		return status.Error(codes.Internal, "missing the claims of the test subject")
+	}
+	return s.Server.Watch(req, stream)
+}
+
+// newTestTokenService creates a token service with a new ED25519 key pair and an in-memory token validator
+//
+// Parameters:
+//
+//   - t: The test
+//
+// Returns:
+//
+//   - *gojwttokenservice.Service: The token service
+func newTestTokenService(t *testing.T) *gojwttokenservice.Service {
+	t.Helper()
+
+	// Generate the key pair
+	publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate the key pair: %v", err)
+	}
+	privateKeyDER, err := x509.MarshalPKCS8PrivateKey(privateKey)
+	if err != nil {
+		t.Fatalf("failed to marshal the private key: %v", err)
+	}
+	publicKeyDER, err := x509.MarshalPKIXPublicKey(publicKey)
+	if err != nil {
+		t.Fatalf("failed to marshal the public key: %v", err)
+	}
+
+	tokenValidator := gojwttokenclaimscache.NewTokenValidator(nil)
+	issuer, err := gojwtissuer.NewEd25519Issuer(
+		pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: privateKeyDER}),
+	)
+	if err != nil {
+		t.Fatalf("failed to create the issuer: %v", err)
+	}
+	claimsValidator, err := gojwttokenclaims.NewDefaultClaimsValidator(tokenValidator)
+	if err != nil {
+		t.Fatalf("failed to create the claims validator: %v", err)
+	}
+	validator, err := gojwtvalidator.NewEd25519Validator(
+		pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicKeyDER}),
+		claimsValidator,
+		goflagmode.NewFlag(goflagmode.Prod, goflagmode.AllowedModes),
+	)
+	if err != nil {
+		t.Fatalf("failed to create the validator: %v", err)
+	}
+
+	service, err := gojwttokenservice.NewService(issuer, validator, tokenValidator, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to create the token service: %v", err)
+	}
+	return service
+}
+
+// newTestClientConn serves the services registered by the given function behind the authenticator of the given
+// options over an in-memory connection, and returns the client connection
+//
+// Parameters:
+//
+//   - t: The test
+//   - service: The token service whose validator authenticates the requests
+//   - options: The authenticator options
+//   - register: The function that registers the services
+//   - dialOptions: The additional dial options
+//
+// Returns:
+//
+//   - *grpc.ClientConn: The client connection
+func newTestClientConn(
+	t *testing.T,
+	service *gojwttokenservice.Service,
+	options *Options,
+	register func(server *grpc.Server),
+	dialOptions ...grpc.DialOption,
+) *grpc.ClientConn {
+	t.Helper()
+
+	authenticator, err := NewAuthenticator(service.Validator(), options, nil)
+	if err != nil {
+		t.Fatalf("NewAuthenticator() error = %v", err)
+	}
+
+	// Serve the services
+	listener := bufconn.Listen(1 << 20)
+	server := grpc.NewServer(
+		grpc.UnaryInterceptor(authenticator.UnaryServerInterceptor()),
+		grpc.StreamInterceptor(authenticator.StreamServerInterceptor()),
+	)
+	register(server)
+	go func() {
+		_ = server.Serve(listener)
+	}()
+	t.Cleanup(server.Stop)
+
+	// Connect to the server
+	conn, err := grpc.NewClient(
+		"passthrough:///bufnet",
+		append(
+			[]grpc.DialOption{
+				grpc.WithContextDialer(
+					func(ctx context.Context, _ string) (net.Conn, error) {
+						return listener.DialContext(ctx)
+					},
+				),
+				grpc.WithTransportCredentials(insecure.NewCredentials()),
+			},
+			dialOptions...,
+		)...,
+	)
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	t.Cleanup(
+		func() {
+			_ = conn.Close()
+		},
+	)
+	return conn
+}
+
+// newTestHealthClient serves the health service behind the authenticator of the given options over an in-memory
+// connection, and returns its client
+//
+// Parameters:
+//
+//   - t: The test
+//   - service: The token service whose validator authenticates the requests
+//   - options: The authenticator options
+//   - dialOptions: The additional dial options
+//
+// Returns:
+//
+//   - grpc_health_v1.HealthClient: The health client
+func newTestHealthClient(
+	t *testing.T,
+	service *gojwttokenservice.Service,
+	options *Options,
+	dialOptions ...grpc.DialOption,
+) grpc_health_v1.HealthClient {
+	t.Helper()
+
+	conn := newTestClientConn(
+		t,
+		service,
+		options,
+		func(server *grpc.Server) {
+			grpc_health_v1.RegisterHealthServer(server, subjectHealthServer{health.NewServer()})
+		},
+		dialOptions...,
+	)
+	return grpc_health_v1.NewHealthClient(conn)
+}
+
+// withBearerToken returns the outgoing context with the given bearer token, if any
+//
+// Parameters:
+//
+//   - rawToken: The raw token (optional, no token is attached if empty)
+//
+// Returns:
+//
+//   - context.Context: The outgoing context
+func withBearerToken(rawToken string) context.Context {
+	if rawToken == "" {
+		return context.Background()
+	}
+	return metadata.AppendToOutgoingContext(
+		context.Background(),
+		AuthorizationMetadataKey,
+		gojwt.BearerPrefix+" "+rawToken,
+	)
+}
+
+func TestAuthenticator_UnaryServerInterceptor(t *testing.T) {
+	service := newTestTokenService(t)
+	ctx := context.Background()
+
+	// Issue the tokens
+	pair, err := service.IssueTokens(ctx, testSubject, nil)
+	if err != nil {
+		t.Fatalf("IssueTokens() error = %v", err)
+	}
+	scopedPair, err := service.IssueTokens(ctx, testSubject, map[string]any{gojwt.ScopeClaim: "health:read"})
+	if err != nil {
+		t.Fatalf("IssueTokens() error = %v", err)
+	}
+	revokedPair, err := service.IssueTokens(ctx, testSubject, nil)
+	if err != nil {
+		t.Fatalf("IssueTokens() error = %v", err)
+	}
+	if err = service.Revoke(ctx, revokedPair.AccessToken, ""); err != nil {
+		t.Fatalf("Revoke() error = %v", err)
+	}
+
+	authorizer, err := gojwtauthz.NewAuthorizer(nil)
+	if err != nil {
+		t.Fatalf("NewAuthorizer() error = %v", err)
+	}
+	scopePolicy := map[string]gojwtauthz.Policy{checkMethod: {Scopes: []string{"health:read"}}}
+
+	tests := []struct {
+		name     string
+		options  *Options
+		rawToken string
+		wantCode codes.Code
+	}{
+		{
+			name:     "access token",
+			options:  &Options{},
+			rawToken: pair.AccessToken,
+			wantCode: codes.OK,
+		},
+		{
+			name:     "missing token",
+			options:  &Options{},
+			wantCode: codes.Unauthenticated,
+		},
+		{
+			name:     "malformed token",
+			options:  &Options{},
+			rawToken: "not-a-token",
+			wantCode: codes.Unauthenticated,
+		},
+		{
+			name:     "revoked token",
+			options:  &Options{},
+			rawToken: revokedPair.AccessToken,
+			wantCode: codes.Unauthenticated,
+		},
+		{
+			name:     "refresh token on an access token method",
+			options:  &Options{},
+			rawToken: pair.RefreshToken,
+			wantCode: codes.PermissionDenied,
+		},
+		{
+			name:     "refresh token on a refresh token method",
+			options:  &Options{Policies: map[string]MethodPolicy{checkMethod: RefreshTokenMethod}},
+			rawToken: pair.RefreshToken,
+			wantCode: codes.OK,
+		},
+		{
+			name:     "access token on a refresh token method",
+			options:  &Options{Policies: map[string]MethodPolicy{checkMethod: RefreshTokenMethod}},
+			rawToken: pair.AccessToken,
+			wantCode: codes.PermissionDenied,
+		},
+		{
+			name:     "public method without token",
+			options:  &Options{DefaultPolicy: PublicMethod},
+			wantCode: codes.Internal,
+		},
+		{
+			name: "missing scope",
+			options: &Options{
+				Authorizer:            authorizer,
+				AuthorizationPolicies: scopePolicy,
+			},
+			rawToken: pair.AccessToken,
+			wantCode: codes.PermissionDenied,
+		},
+		{
+			name: "granted scope",
+			options: &Options{
+				Authorizer:            authorizer,
+				AuthorizationPolicies: scopePolicy,
+			},
+			rawToken: scopedPair.AccessToken,
+			wantCode: codes.OK,
+		},
+	}
+	for _, test := range tests {
+		t.Run(
+			test.name, func(t *testing.T) {
+				client := newTestHealthClient(t, service, test.options)
+
+				// The public methods reach the handler without claims, which answers with codes.Internal
+				_, err := client.Check(withBearerToken(test.rawToken), &grpc_health_v1.HealthCheckRequest{})
+				if code := status.Code(err); code != test.wantCode {
+					t.Errorf("Check() code = %v, want %v (error = %v)", code, test.wantCode, err)
+				}
+			},
+		)
+	}
+}
+
+func TestAuthenticator_StreamServerInterceptor(t *testing.T) {
+	service := newTestTokenService(t)
+	pair, err := service.IssueTokens(context.Background(), testSubject, nil)
+	if err != nil {
+		t.Fatalf("IssueTokens() error = %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		options  *Options
+		rawToken string
+		wantCode codes.Code
+	}{
+		{
+			name:     "access token",
+			options:  &Options{},
+			rawToken: pair.AccessToken,
+			wantCode: codes.OK,
+		},
+		{
+			name:     "missing token",
+			options:  &Options{},
+			wantCode: codes.Unauthenticated,
+		},
+		{
+			name:     "refresh token on an access token method",
+			options:  &Options{},
+			rawToken: pair.RefreshToken,
+			wantCode: codes.PermissionDenied,
+		},
+		{
+			name:     "refresh token on a refresh token method",
+			options:  &Options{Policies: map[string]MethodPolicy{watchMethod: RefreshTokenMethod}},
+			rawToken: pair.RefreshToken,
+			wantCode: codes.OK,
+		},
+	}
+	for _, test := range tests {
+		t.Run(
+			test.name, func(t *testing.T) {
+				client := newTestHealthClient(t, service, test.options)
+
+				// The stream errors are received with the first message
+				stream, err := client.Watch(withBearerToken(test.rawToken), &grpc_health_v1.HealthCheckRequest{})
+				if err == nil {
+					_, err = stream.Recv()
+				}
+				if code := status.Code(err); code != test.wantCode {
+					t.Errorf("Watch() code = %v, want %v (error = %v)", code, test.wantCode, err)
+				}
+			},
+		)
+	}
+}
@@ -0,0 +1,328 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+
+	"github.com/ralvarezdev/go-jwt/grpc/tokenpb"
+	gojwttokenservice "github.com/ralvarezdev/go-jwt/token/service"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+type (
+	// IssueHook authenticates the credentials of an Issue request and returns the authenticated subject and the
+	// application claims of the tokens
+	IssueHook func(ctx context.Context, credentials map[string]string) (
+		subject string,
+		claims map[string]any,
+		err error,
+	)
+
+	// TokenServiceServer is the gRPC token service backed by a token service
+	TokenServiceServer struct {
+		tokenpb.UnimplementedTokenServiceServer
+		service       *gojwttokenservice.Service
+		issueHook     IssueHook
+		sessionLister gojwttokenservice.SessionLister
+		logger        *slog.Logger
+	}
+)
+
+// NewTokenServiceServer creates a new gRPC token service server
+//
+// Parameters:
+//
+//   - service: The token service
+//   - issueHook: The hook that authenticates the Issue requests (optional, Issue is unimplemented if nil)
+//   - sessionLister: The session lister (optional, ListSessions is unimplemented if nil)
+//   - logger: The logger (optional, can be nil)
+//
+// Returns:
+//
+//   - *TokenServiceServer: The gRPC token service server
+//   - error: An error if the token service is nil
+func NewTokenServiceServer(
+	service *gojwttokenservice.Service,
+	issueHook IssueHook,
+	sessionLister gojwttokenservice.SessionLister,
+	logger *slog.Logger,
+) (*TokenServiceServer, error) {
+	// Check if the token service is nil
+	if service == nil {
+		return nil, gojwttokenservice.ErrNilService
+	}
+
+	if logger != nil {
+		logger = logger.With(slog.String("component", "grpc_token_service"))
+	}
+
+	return &TokenServiceServer{
+		service:       service,
+		issueHook:     issueHook,
+		sessionLister: sessionLister,
+		logger:        logger,
+	}, nil
+}
+
+// TokenServicePolicies returns the authentication policies of the token service methods. Issue and Refresh are
+// public, since their callers prove their identity in the request, while the other methods require an access token
+//
+// Returns:
+//
+//   - map[string]MethodPolicy: The authentication policies by full method name
+func TokenServicePolicies() map[string]MethodPolicy {
+	return map[string]MethodPolicy{
+		tokenpb.TokenService_Issue_FullMethodName:        PublicMethod,
+		tokenpb.TokenService_Refresh_FullMethodName:      PublicMethod,
+		tokenpb.TokenService_Revoke_FullMethodName:       AccessTokenMethod,
+		tokenpb.TokenService_Introspect_FullMethodName:   AccessTokenMethod,
+		tokenpb.TokenService_ListSessions_FullMethodName: AccessTokenMethod,
+	}
+}
+
+// Issue issues a new pair of tokens for the subject authenticated by the issue hook
+//
+// Parameters:
+//
+//   - ctx: The context
+//   - req: The request
+//
+// Returns:
+//
+//   - *tokenpb.IssueResponse: The issued tokens
+//   - error: A status error if the credentials are rejected or if the tokens could not be issued
+func (t *TokenServiceServer) Issue(
+	ctx context.Context,
+	req *tokenpb.IssueRequest,
+) (*tokenpb.IssueResponse, error) {
+	if t.issueHook == nil {
+		return t.UnimplementedTokenServiceServer.Issue(ctx, req)
+	}
+
+	// Authenticate the credentials
+	subject, claims, err := t.issueHook(ctx, req.GetCredentials())
+	if err != nil {
+		// The hook error may describe the credentials, so it is only logged
+		if t.logger != nil {
+			t.logger.Debug(
+				"Issue request rejected",
+				slog.String("error", err.Error()),
+			)
+		}
+		return nil, status.Error(
+			codes.Unauthenticated,
+			gojwttokenservice.ErrInvalidGrant.Error(),
+		)
+	}
+
+	// Issue the tokens
+	pair, err := t.service.IssueTokens(ctx, subject, claims)
+	if err != nil {
+		return nil, t.statusError(err)
+	}
+	return &tokenpb.IssueResponse{TokenPair: newTokenPair(pair)}, nil
+}
+
+// Refresh exchanges a refresh token for a new pair of tokens
+//
+// Parameters:
+//
+//   - ctx: The context
+//   - req: The request
+//
+// Returns:
+//
+//   - *tokenpb.RefreshResponse: The issued tokens
+//   - error: A status error if the refresh token is not valid or if the tokens could not be issued
+func (t *TokenServiceServer) Refresh(
+	ctx context.Context,
+	req *tokenpb.RefreshRequest,
+) (*tokenpb.RefreshResponse, error) {
+	if req.GetRefreshToken() == "" {
+		return nil, status.Error(codes.InvalidArgument, "missing refresh token")
+	}
+
+	pair, err := t.service.Refresh(ctx, req.GetRefreshToken())
+	if err != nil {
+		return nil, t.statusError(err)
+	}
+	return &tokenpb.RefreshResponse{TokenPair: newTokenPair(pair)}, nil
+}
+
+// Revoke revokes a token as described in RFC 7009. Only the tokens owned by the subject authenticated by the access
+// token set in the context by the authentication interceptors are revoked, the others are ignored
+//
+// Parameters:
+//
+//   - ctx: The context
+//   - req: The request
+//
+// Returns:
+//
+//   - *tokenpb.RevokeResponse: The response
+//   - error: A status error if the caller is not authenticated or if the token could not be revoked
+func (t *TokenServiceServer) Revoke(
+	ctx context.Context,
+	req *tokenpb.RevokeRequest,
+) (*tokenpb.RevokeResponse, error) {
+	if req.GetToken() == "" {
+		return nil, status.Error(codes.InvalidArgument, "missing token")
+	}
+
+	// Get the authenticated subject
+	subject, err := GetCtxTokenClaimsSubject(ctx)
+	if err != nil {
+		return nil, status.Error(codes.Unauthenticated, err.Error())
+	}
+
+	if err = t.service.RevokeOwned(
+		ctx,
+		subject,
+		req.GetToken(),
+		req.GetTokenTypeHint(),
+	); err != nil {
+		return nil, t.statusError(err)
+	}
+	return &tokenpb.RevokeResponse{}, nil
+}
+
+// Introspect introspects a token as described in RFC 7662
+//
+// Parameters:
+//
+//   - ctx: The context
+//   - req: The request
+//
+// Returns:
+//
+//   - *tokenpb.IntrospectResponse: The introspection response
+//   - error: A status error if the token type hint is not supported
+func (t *TokenServiceServer) Introspect(
+	ctx context.Context,
+	req *tokenpb.IntrospectRequest,
+) (*tokenpb.IntrospectResponse, error) {
+	if req.GetToken() == "" {
+		return nil, status.Error(codes.InvalidArgument, "missing token")
+	}
+
+	introspection, err := t.service.Introspect(
+		ctx,
+		req.GetToken(),
+		req.GetTokenTypeHint(),
+	)
+	if err != nil {
+		return nil, t.statusError(err)
+	}
+	return &tokenpb.IntrospectResponse{
+		Active:    introspection.Active,
+		Scope:     introspection.Scope,
+		TokenType: introspection.TokenType,
+		Exp:       introspection.ExpiresAt,
+		Iat:       introspection.IssuedAt,
+		Nbf:       introspection.NotBefore,
+		Sub:       introspection.Subject,
+		Aud:       introspection.Audience,
+		Iss:       introspection.Issuer,
+		Jti:       introspection.ID,
+	}, nil
+}
+
+// ListSessions lists the sessions of the subject authenticated by the access token set in the context by the
+// authentication interceptors
+//
+// Parameters:
+//
+//   - ctx: The context
+//   - req: The request
+//
+// Returns:
+//
+//   - *tokenpb.ListSessionsResponse: The sessions
+//   - error: A status error if the caller is not authenticated or if the sessions could not be listed
+func (t *TokenServiceServer) ListSessions(
+	ctx context.Context,
+	req *tokenpb.ListSessionsRequest,
+) (*tokenpb.ListSessionsResponse, error) {
+	if t.sessionLister == nil {
+		return t.UnimplementedTokenServiceServer.ListSessions(ctx, req)
+	}
+
+	// Get the authenticated subject
+	subject, err := GetCtxTokenClaimsSubject(ctx)
+	if err != nil {
+		return nil, status.Error(codes.Unauthenticated, err.Error())
+	}
+
+	// List the sessions
+	sessions, err := t.sessionLister.ListSessions(ctx, subject)
+	if err != nil {
+		return nil, t.statusError(err)
+	}
+	res := &tokenpb.ListSessionsResponse{
+		Sessions: make([]*tokenpb.Session, 0, len(sessions)),
+	}
+	for _, session := range sessions {
+		res.Sessions = append(
+			res.Sessions, &tokenpb.Session{
+				Id:        session.ID,
+				IssuedAt:  timestamppb.New(session.IssuedAt),
+				ExpiresAt: timestamppb.New(session.ExpiresAt),
+			},
+		)
+	}
+	return res, nil
+}
+
+// statusError converts a token service error into a status error, without exposing the internal errors
+//
+// Parameters:
+//
+//   - err: The token service error
+//
+// Returns:
+//
+//   - error: The status error
+func (t *TokenServiceServer) statusError(err error) error {
+	switch {
+	case errors.Is(err, gojwttokenservice.ErrInvalidGrant):
+		return status.Error(
+			codes.Unauthenticated,
+			gojwttokenservice.ErrInvalidGrant.Error(),
+		)
+	case errors.Is(err, gojwttokenservice.ErrUnsupportedTokenTypeHint),
+		errors.Is(err, gojwttokenservice.ErrEmptySubject),
+		errors.Is(err, gojwttokenservice.ErrReservedClaim):
+		return status.Error(codes.InvalidArgument, err.Error())
+	default:
+		if t.logger != nil {
+			t.logger.Error(
+				"Token service failed",
+				slog.String("error", err.Error()),
+			)
+		}
+		return status.Error(codes.Internal, "token service failed")
+	}
+}
+
+// newTokenPair converts a token pair into its protobuf message
+//
+// Parameters:
+//
+//   - pair: The token pair
+//
+// Returns:
+//
+//   - *tokenpb.TokenPair: The protobuf token pair
+func newTokenPair(pair *gojwttokenservice.TokenPair) *tokenpb.TokenPair {
+	return &tokenpb.TokenPair{
+		AccessToken:           pair.AccessToken,
+		AccessTokenExpiresAt:  timestamppb.New(pair.AccessTokenExpiresAt),
+		RefreshToken:          pair.RefreshToken,
+		RefreshTokenExpiresAt: timestamppb.New(pair.RefreshTokenExpiresAt),
+		TokenType:             pair.TokenType,
+		ExpiresIn:             pair.ExpiresIn,
+	}
+}
@@ -0,0 +1,405 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/ralvarezdev/go-jwt/grpc/tokenpb"
+	gojwttokenservice "github.com/ralvarezdev/go-jwt/token/service"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var (
+	// errTestUnknownUser is the error returned by the test issue hook for unknown users
+	errTestUnknownUser = errors.New("unknown user alice")
+)
+
+// testIssueHook authenticates the credentials whose password is "secret" as the test subject
+//
+// Parameters:
+//
+//   - ctx: The context
+//   - credentials: The credentials
+//
+// Returns:
+//
+//   - string: The test subject
+//   - map[string]any: The application claims
+//   - error: errTestUnknownUser if the password is not "secret"
+func testIssueHook(_ context.Context, credentials map[string]string) (
+	string,
+	map[string]any,
+	error,
+) {
+	if credentials["password"] != "secret" {
+		return "", nil, errTestUnknownUser
+	}
+	return testSubject, map[string]any{"role": "admin"}, nil
+}
+
+// newTestTokenServiceClient serves the token service behind the authenticator configured with its policies over an
+// in-memory connection, and returns its client
+//
+// Parameters:
+//
+//   - t: The test
+//   - service: The token service
+//   - issueHook: The issue hook (optional, Issue is unimplemented if nil)
+//
+// Returns:
+//
+//   - tokenpb.TokenServiceClient: The token service client
+func newTestTokenServiceClient(
+	t *testing.T,
+	service *gojwttokenservice.Service,
+	issueHook IssueHook,
+) tokenpb.TokenServiceClient {
+	t.Helper()
+
+	tokenServiceServer, err := NewTokenServiceServer(service, issueHook, nil, nil)
+	if err != nil {
+		t.Fatalf("NewTokenServiceServer() error = %v", err)
+	}
+	conn := newTestClientConn(
+		t,
+		service,
+		&Options{Policies: TokenServicePolicies()},
+		func(server *grpc.Server) {
+			tokenpb.RegisterTokenServiceServer(server, tokenServiceServer)
+		},
+	)
+	return tokenpb.NewTokenServiceClient(conn)
+}
+
+// introspect introspects the given token on behalf of the given access token
+//
+// Parameters:
+//
+//   - t: The test
+//   - client: The token service client
+//   - accessToken: The access token of the caller
+//   - rawToken: The raw token to introspect
+//
+// Returns:
+//
+//   - *tokenpb.IntrospectResponse: The introspection response
+func introspect(
+	t *testing.T,
+	client tokenpb.TokenServiceClient,
+	accessToken string,
+	rawToken string,
+) *tokenpb.IntrospectResponse {
+	t.Helper()
+
+	res, err := client.Introspect(
+		withBearerToken(accessToken),
+		&tokenpb.IntrospectRequest{Token: rawToken},
+	)
+	if err != nil {
+		t.Fatalf("Introspect() error = %v", err)
+	}
+	return res
+}
+
+func TestTokenServiceServer_Issue(t *testing.T) {
+	service := newTestTokenService(t)
+	client := newTestTokenServiceClient(t, service, testIssueHook)
+
+	tests := []struct {
+		name        string
+		credentials map[string]string
+		wantCode    codes.Code
+	}{
+		{
+			name:        "valid credentials",
+			credentials: map[string]string{"password": "secret"},
+			wantCode:    codes.OK,
+		},
+		{
+			name:        "invalid credentials",
+			credentials: map[string]string{"password": "wrong"},
+			wantCode:    codes.Unauthenticated,
+		},
+	}
+	for _, test := range tests {
+		t.Run(
+			test.name, func(t *testing.T) {
+				res, err := client.Issue(
+					context.Background(),
+					&tokenpb.IssueRequest{Credentials: test.credentials},
+				)
+				if code := status.Code(err); code != test.wantCode {
+					t.Fatalf("Issue() code = %v, want %v (error = %v)", code, test.wantCode, err)
+				}
+				if err != nil {
+					// The hook error must not be exposed to the caller
+					if message := status.Convert(err).Message(); message != gojwttokenservice.ErrInvalidGrant.Error() {
+						t.Errorf(
+							"Issue() message = %q, want %q",
+							message,
+							gojwttokenservice.ErrInvalidGrant.Error(),
+						)
+					}
+					return
+				}
+
+				// Check the issued access token
+				pair := res.GetTokenPair()
+				if pair.GetTokenType() != gojwttokenservice.BearerTokenType {
+					t.Errorf("Issue() token type = %q, want %q", pair.GetTokenType(), gojwttokenservice.BearerTokenType)
+				}
+				if introspection := introspect(
+					t,
+					client,
+					pair.GetAccessToken(),
+					pair.GetAccessToken(),
+				); !introspection.GetActive() || introspection.GetSub() != testSubject {
+					t.Errorf(
+						"Introspect() active = %v, sub = %q, want true, %q",
+						introspection.GetActive(),
+						introspection.GetSub(),
+						testSubject,
+					)
+				}
+			},
+		)
+	}
+}
+
+func TestTokenServiceServer_Issue_WithoutHook(t *testing.T) {
+	client := newTestTokenServiceClient(t, newTestTokenService(t), nil)
+
+	_, err := client.Issue(context.Background(), &tokenpb.IssueRequest{})
+	if code := status.Code(err); code != codes.Unimplemented {
+		t.Errorf("Issue() code = %v, want %v (error = %v)", code, codes.Unimplemented, err)
+	}
+}
+
+func TestTokenServiceServer_Refresh(t *testing.T) {
+	service := newTestTokenService(t)
+	client := newTestTokenServiceClient(t, service, nil)
+
+	// Issue the tokens, the first refresh token is consumed by the first test
+	pair, err := service.IssueTokens(context.Background(), testSubject, nil)
+	if err != nil {
+		t.Fatalf("IssueTokens() error = %v", err)
+	}
+
+	tests := []struct {
+		name         string
+		refreshToken string
+		wantCode     codes.Code
+	}{
+		{
+			name:         "refresh token",
+			refreshToken: pair.RefreshToken,
+			wantCode:     codes.OK,
+		},
+		{
+			name:         "already used refresh token",
+			refreshToken: pair.RefreshToken,
+			wantCode:     codes.Unauthenticated,
+		},
+		{
+			name:         "access token",
+			refreshToken: pair.AccessToken,
+			wantCode:     codes.Unauthenticated,
+		},
+		{
+			name:     "missing refresh token",
+			wantCode: codes.InvalidArgument,
+		},
+	}
+	for _, test := range tests {
+		t.Run(
+			test.name, func(t *testing.T) {
+				res, err := client.Refresh(
+					context.Background(),
+					&tokenpb.RefreshRequest{RefreshToken: test.refreshToken},
+				)
+				if code := status.Code(err); code != test.wantCode {
+					t.Fatalf("Refresh() code = %v, want %v (error = %v)", code, test.wantCode, err)
+				}
+				if err != nil {
+					return
+				}
+
+				// The access token of the consumed refresh token is revoked
+				newAccessToken := res.GetTokenPair().GetAccessToken()
+				if introspection := introspect(t, client, newAccessToken, pair.AccessToken); introspection.GetActive() {
+					t.Error("Introspect() active = true for the access token of the consumed refresh token")
+				}
+				if introspection := introspect(t, client, newAccessToken, newAccessToken); !introspection.GetActive() {
+					t.Error("Introspect() active = false for the new access token")
+				}
+			},
+		)
+	}
+}
+
+func TestTokenServiceServer_Revoke(t *testing.T) {
+	service := newTestTokenService(t)
+	client := newTestTokenServiceClient(t, service, nil)
+	ctx := context.Background()
+
+	// Issue the tokens of the caller and of another subject
+	pair, err := service.IssueTokens(ctx, testSubject, nil)
+	if err != nil {
+		t.Fatalf("IssueTokens() error = %v", err)
+	}
+	ownedPair, err := service.IssueTokens(ctx, testSubject, nil)
+	if err != nil {
+		t.Fatalf("IssueTokens() error = %v", err)
+	}
+	foreignPair, err := service.IssueTokens(ctx, "other", nil)
+	if err != nil {
+		t.Fatalf("IssueTokens() error = %v", err)
+	}
+
+	tests := []struct {
+		name        string
+		accessToken string
+		token       string
+		wantCode    codes.Code
+		wantActive  bool
+	}{
+		{
+			name:        "owned token",
+			accessToken: pair.AccessToken,
+			token:       ownedPair.RefreshToken,
+			wantCode:    codes.OK,
+			wantActive:  false,
+		},
+		{
+			name:        "foreign token",
+			accessToken: pair.AccessToken,
+			token:       foreignPair.RefreshToken,
+			wantCode:    codes.OK,
+			wantActive:  true,
+		},
+		{
+			name:       "missing access token",
+			token:      foreignPair.RefreshToken,
+			wantCode:   codes.Unauthenticated,
+			wantActive: true,
+		},
+		{
+			name:        "missing token",
+			accessToken: pair.AccessToken,
+			wantCode:    codes.InvalidArgument,
+		},
+	}
+	for _, test := range tests {
+		t.Run(
+			test.name, func(t *testing.T) {
+				_, err := client.Revoke(
+					withBearerToken(test.accessToken),
+					&tokenpb.RevokeRequest{Token: test.token},
+				)
+				if code := status.Code(err); code != test.wantCode {
+					t.Fatalf("Revoke() code = %v, want %v (error = %v)", code, test.wantCode, err)
+				}
+				if test.token == "" {
+					return
+				}
+
+				// Only the owned tokens are revoked, the others are silently ignored
+				if introspection := introspect(
+					t,
+					client,
+					pair.AccessToken,
+					test.token,
+				); introspection.GetActive() != test.wantActive {
+					t.Errorf("Introspect() active = %v, want %v", introspection.GetActive(), test.wantActive)
+				}
+			},
+		)
+	}
+}
+
+func TestTokenServiceServer_Introspect(t *testing.T) {
+	service := newTestTokenService(t)
+	client := newTestTokenServiceClient(t, service, nil)
+
+	pair, err := service.IssueTokens(context.Background(), testSubject, nil)
+	if err != nil {
+		t.Fatalf("IssueTokens() error = %v", err)
+	}
+
+	tests := []struct {
+		name          string
+		accessToken   string
+		token         string
+		tokenTypeHint string
+		wantCode      codes.Code
+		wantActive    bool
+		wantTokenType string
+	}{
+		{
+			name:          "access token",
+			accessToken:   pair.AccessToken,
+			token:         pair.AccessToken,
+			wantCode:      codes.OK,
+			wantActive:    true,
+			wantTokenType: gojwttokenservice.AccessTokenTypeHint,
+		},
+		{
+			name:          "refresh token",
+			accessToken:   pair.AccessToken,
+			token:         pair.RefreshToken,
+			tokenTypeHint: gojwttokenservice.RefreshTokenTypeHint,
+			wantCode:      codes.OK,
+			wantActive:    true,
+			wantTokenType: gojwttokenservice.RefreshTokenTypeHint,
+		},
+		{
+			name:        "malformed token",
+			accessToken: pair.AccessToken,
+			token:       "not-a-token",
+			wantCode:    codes.OK,
+		},
+		{
+			name:          "unsupported token type hint",
+			accessToken:   pair.AccessToken,
+			token:         pair.AccessToken,
+			tokenTypeHint: "id_token",
+			wantCode:      codes.InvalidArgument,
+		},
+		{
+			name:     "missing access token",
+			token:    pair.AccessToken,
+			wantCode: codes.Unauthenticated,
+		},
+	}
+	for _, test := range tests {
+		t.Run(
+			test.name, func(t *testing.T) {
+				res, err := client.Introspect(
+					withBearerToken(test.accessToken),
+					&tokenpb.IntrospectRequest{
+						Token:         test.token,
+						TokenTypeHint: test.tokenTypeHint,
+					},
+				)
+				if code := status.Code(err); code != test.wantCode {
+					t.Fatalf("Introspect() code = %v, want %v (error = %v)", code, test.wantCode, err)
+				}
+				if err != nil {
+					return
+				}
+				if res.GetActive() != test.wantActive {
+					t.Errorf("Introspect() active = %v, want %v", res.GetActive(), test.wantActive)
+				}
+				if res.GetTokenType() != test.wantTokenType {
+					t.Errorf("Introspect() token type = %q, want %q", res.GetTokenType(), test.wantTokenType)
+				}
+				if test.wantActive && res.GetSub() != testSubject {
+					t.Errorf("Introspect() sub = %q, want %q", res.GetSub(), testSubject)
+				}
+			},
+		)
+	}
+}
@@ -0,0 +1,59 @@
+package grpc
+
+import (
+	gojwt "github.com/ralvarezdev/go-jwt"
+	gojwtauthz "github.com/ralvarezdev/go-jwt/token/authz"
+)
+
+type (
+	// MethodPolicy is the authentication policy of a gRPC method
+	MethodPolicy int
+
+	// Options are the options for the gRPC authenticator
+	Options struct {
+		// Policies are the authentication policies by full method name, such as /package.Service/Method
+		Policies map[string]MethodPolicy
+
+		// DefaultPolicy is the policy of the methods missing from the policies
+		DefaultPolicy MethodPolicy
+
+		// Extractor is the token extractor (optional, the authorization metadata is used if nil)
+		Extractor gojwt.TokenExtractor
+
+		// Authorizer is the authorizer of the authorization policies (optional, the roles grant no permissions if nil)
+		Authorizer *gojwtauthz.Authorizer
+
+		// AuthorizationPolicies are the authorization policies checked against the token claims by full method name,
+		// the methods missing from them only require a valid token
+		AuthorizationPolicies map[string]gojwtauthz.Policy
+	}
+)
+
+const (
+	// AccessTokenMethod requires a valid access token
+	AccessTokenMethod MethodPolicy = iota
+
+	// RefreshTokenMethod requires a valid refresh token
+	RefreshTokenMethod
+
+	// PublicMethod does not require a token
+	PublicMethod
+)
+
+// String returns the string representation of the method policy
+//
+// Returns:
+//
+//   - string: The string representation of the method policy
+func (m MethodPolicy) String() string {
+	switch m {
+	case AccessTokenMethod:
+		return "access_token"
+	case RefreshTokenMethod:
+		return "refresh_token"
+	case PublicMethod:
+		return "public"
+	default:
+		return "unknown"
+	}
+}
@@ -0,0 +1,116 @@
+package context
+
+import (
+	"fmt"
+	"net/http"
+
+	gojwt "github.com/ralvarezdev/go-jwt"
+)
+
+type (
+	// RequestCarrier is the token carrier of an HTTP request
+	RequestCarrier struct {
+		request *http.Request
+	}
+)
+
+// NewRequestCarrier creates a new request carrier
+//
+// Parameters:
+//
+//   - r: The HTTP request
+//
+// Returns:
+//
+//   - *RequestCarrier: The request carrier
+func NewRequestCarrier(r *http.Request) *RequestCarrier {
+	return &RequestCarrier{
+		request: r,
+	}
+}
+
+// Header returns the values of the given header
+//
+// Parameters:
+//
+//   - key: The header key
+//
+// Returns:
+//
+//   - []string: The header values
+func (r *RequestCarrier) Header(key string) []string {
+	return r.request.Header.Values(key)
+}
+
+// Cookie returns the value of the given cookie
+//
+// Parameters:
+//
+//   - name: The cookie name
+//
+// Returns:
+//
+//   - string: The cookie value
+//   - bool: Whether the cookie was found
+func (r *RequestCarrier) Cookie(name string) (string, bool) {
+	cookie, err := r.request.Cookie(name)
+	if err != nil {
+		return "", false
+	}
+	return cookie.Value, true
+}
+
+// Query returns the value of the given query parameter
+//
+// Parameters:
+//
+//   - key: The query parameter key
+//
+// Returns:
+//
+//   - string: The query parameter value
+//   - bool: Whether the query parameter was found
+func (r *RequestCarrier) Query(key string) (string, bool) {
+	query := r.request.URL.Query()
+	if !query.Has(key) {
+		return "", false
+	}
+	return query.Get(key), true
+}
+
+// ExtractToken extracts the raw token from the HTTP request. If the token is found by a gojwt.CookieExtractor, the
+// request must also pass the CSRF check of the cookie configuration, since browsers send the cookies on cross-site
+// requests
+//
+// Parameters:
+//
+//   - r: The HTTP request
+//   - extractor: The token extractor
+//   - config: The cookie configuration (optional, the tokens found in cookies are rejected if nil)
+//
+// Returns:
+//
+//   - string: The raw token
+//   - error: An error if the token could not be extracted, or an error wrapping ErrFailedCSRFCheck if it was found in
+//     a cookie and the request failed the CSRF check
+func ExtractToken(
+	r *http.Request,
+	extractor gojwt.TokenExtractor,
+	config *CookieConfig,
+) (string, error) {
+	rawToken, source, err := gojwt.ExtractTokenWithSource(
+		extractor,
+		NewRequestCarrier(r),
+	)
+	if err != nil {
+		return "", err
+	}
+
+	// Check the CSRF token of the tokens found in cookies
+	if gojwt.IsCookieExtractor(source) {
+		if err = config.CheckCSRF(r); err != nil {
+			return "", fmt.Errorf("%w: %w", ErrFailedCSRFCheck, err)
+		}
+	}
+	return rawToken, nil
+}
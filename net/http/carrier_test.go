@@ -0,0 +1,122 @@
+package context
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	gojwt "github.com/ralvarezdev/go-jwt"
+)
+
+func TestExtractToken(t *testing.T) {
+	config, err := NewCookieConfig("/refresh")
+	if err != nil {
+		t.Fatalf("NewCookieConfig() error = %v", err)
+	}
+	extractor := gojwt.ChainExtractor{
+		gojwt.AuthorizationHeaderExtractor{},
+		gojwt.CookieExtractor{Name: config.AccessTokenName},
+	}
+
+	tests := []struct {
+		name          string
+		method        string
+		authorization string
+		tokenCookie   bool
+		csrfCookie    string
+		csrfHeader    string
+		config        *CookieConfig
+		want          string
+		wantErr       error
+	}{
+		{
+			name:          "authorization header",
+			method:        http.MethodPost,
+			authorization: "Bearer header-token",
+			config:        config,
+			want:          "header-token",
+		},
+		{
+			name:          "authorization header without cookie config",
+			method:        http.MethodPost,
+			authorization: "Bearer header-token",
+			want:          "header-token",
+		},
+		{
+			name:        "cookie on a safe method",
+			method:      http.MethodGet,
+			tokenCookie: true,
+			config:      config,
+			want:        "cookie-token",
+		},
+		{
+			name:        "cookie without csrf token",
+			method:      http.MethodPost,
+			tokenCookie: true,
+			config:      config,
+			wantErr:     ErrMissingCSRFToken,
+		},
+		{
+			name:        "cookie with mismatched csrf token",
+			method:      http.MethodPost,
+			tokenCookie: true,
+			csrfCookie:  "csrf",
+			csrfHeader:  "other",
+			config:      config,
+			wantErr:     ErrInvalidCSRFToken,
+		},
+		{
+			name:        "cookie with csrf token",
+			method:      http.MethodPost,
+			tokenCookie: true,
+			csrfCookie:  "csrf",
+			csrfHeader:  "csrf",
+			config:      config,
+			want:        "cookie-token",
+		},
+		{
+			name:        "cookie without cookie config",
+			method:      http.MethodGet,
+			tokenCookie: true,
+			wantErr:     ErrNilCookieConfig,
+		},
+		{
+			name:    "missing token",
+			method:  http.MethodPost,
+			config:  config,
+			wantErr: gojwt.ErrMissingToken,
+		},
+	}
+	for _, test := range tests {
+		t.Run(
+			test.name, func(t *testing.T) {
+				r := httptest.NewRequest(test.method, "/", nil)
+				if test.authorization != "" {
+					r.Header.Set(gojwt.AuthorizationHeaderKey, test.authorization)
+				}
+				if test.tokenCookie {
+					r.AddCookie(&http.Cookie{Name: config.AccessTokenName, Value: "cookie-token"})
+				}
+				if test.csrfCookie != "" {
+					r.AddCookie(&http.Cookie{Name: config.CSRFName, Value: test.csrfCookie})
+				}
+				if test.csrfHeader != "" {
+					r.Header.Set(config.CSRFHeaderKey, test.csrfHeader)
+				}
+
+				rawToken, err := ExtractToken(r, extractor, test.config)
+				if !errors.Is(err, test.wantErr) {
+					t.Fatalf("ExtractToken() error = %v, want %v", err, test.wantErr)
+				}
+				if test.wantErr != nil && !errors.Is(err, gojwt.ErrMissingToken) &&
+					!errors.Is(err, ErrFailedCSRFCheck) {
+					t.Errorf("ExtractToken() error = %v, want it to wrap %v", err, ErrFailedCSRFCheck)
+				}
+				if rawToken != test.want {
+					t.Errorf("ExtractToken() = %q, want %q", rawToken, test.want)
+				}
+			},
+		)
+	}
+}
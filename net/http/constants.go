@@ -0,0 +1,52 @@
+package context
+
+import (
+	"net/http"
+)
+
+const (
+	// DefaultAccessTokenCookieName is the default name of the access token cookie
+	DefaultAccessTokenCookieName = "access_token"
+
+	// DefaultRefreshTokenCookieName is the default name of the refresh token cookie
+	DefaultRefreshTokenCookieName = "refresh_token"
+
+	// DefaultCSRFCookieName is the default name of the CSRF token cookie
+	DefaultCSRFCookieName = "csrf_token"
+
+	// DefaultCSRFHeaderKey is the default key of the header that echoes the CSRF token
+	DefaultCSRFHeaderKey = "X-CSRF-Token"
+
+	// DefaultAccessTokenCookiePath is the default path of the access token cookie
+	DefaultAccessTokenCookiePath = "/"
+
+	// DefaultCookieSameSite is the default SameSite mode of the cookies
+	DefaultCookieSameSite = http.SameSiteStrictMode
+
+	// TokenParam is the token request parameter of the revoke and introspect endpoints
+	TokenParam = "token"
+
+	// TokenTypeHintParam is the token type hint request parameter of the revoke and introspect endpoints
+	TokenTypeHintParam = "token_type_hint"
+
+	// RefreshTokenParam is the refresh token request parameter of the refresh endpoint
+	RefreshTokenParam = "refresh_token"
+
+	// MaxTokenRequestBodySize is the maximum size of the token requests body
+	MaxTokenRequestBodySize = 1 << 20
+
+	// InvalidGrantErrorCode is the OAuth 2.0 error code for an invalid, expired or revoked grant
+	InvalidGrantErrorCode = "invalid_grant"
+
+	// InvalidClientErrorCode is the OAuth 2.0 error code for a request whose caller is not authenticated
+	InvalidClientErrorCode = "invalid_client"
+
+	// UnsupportedTokenTypeErrorCode is the RFC 7009 error code for an unsupported token type hint
+	UnsupportedTokenTypeErrorCode = "unsupported_token_type"
+
+	// ServerErrorCode is the OAuth 2.0 error code for an unexpected server error
+	ServerErrorCode = "server_error"
+
+	// CSRFTokenLength is the number of random bytes of the CSRF tokens
+	CSRFTokenLength = 32
+)
@@ -52,6 +52,31 @@ func GetCtxTokenClaims(r *http.Request) (jwt.MapClaims, error) {
 	return claims, nil
 }
 
+// GetCtxTokenClaimsSubject gets the token claims subject from the context
+//
+// Parameters:
+//
+//   - r: The HTTP request
+//
+// Returns:
+//
+//   - string: The token claims subject
+//   - error: An error if the token claims subject is not found or is of an unexpected type
+func GetCtxTokenClaimsSubject(r *http.Request) (string, error) {
+	// Get the claims from the context
+	claims, err := GetCtxTokenClaims(r)
+	if err != nil {
+		return "", err
+	}
+
+	// Get the subject from the claims
+	subject, ok := claims[gojwt.SubjectClaim].(string)
+	if !ok || subject == "" {
+		return "", gojwt.ErrMissingTokenClaimsSubject
+	}
+	return subject, nil
+}
+
 // SetCtxToken sets the raw token in the context
 //
 // Parameters:
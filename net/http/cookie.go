@@ -0,0 +1,338 @@
+package context
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"net/http"
+	"time"
+
+	gojwttoken "github.com/ralvarezdev/go-jwt/token"
+)
+
+type (
+	// CookieConfig is the configuration of the cookies used to carry the tokens of browser clients
+	CookieConfig struct {
+		// AccessTokenName is the name of the access token cookie
+		AccessTokenName string
+
+		// RefreshTokenName is the name of the refresh token cookie
+		RefreshTokenName string
+
+		// CSRFName is the name of the CSRF token cookie, which is readable by the client scripts
+		CSRFName string
+
+		// CSRFHeaderKey is the key of the header the client uses to echo the CSRF token
+		CSRFHeaderKey string
+
+		// Domain is the domain of the cookies (optional, the cookies are host-only if empty)
+		Domain string
+
+		// AccessTokenPath is the path of the access token cookie
+		AccessTokenPath string
+
+		// RefreshTokenPath is the path of the refresh token cookie, so it is only sent to the refresh endpoint
+		RefreshTokenPath string
+
+		// SameSite is the SameSite mode of the cookies
+		SameSite http.SameSite
+
+		// Insecure disables the Secure attribute of the cookies, which must only be used for local development
+		Insecure bool
+	}
+)
+
+// NewCookieConfig creates a new cookie configuration with the default values
+//
+// Parameters:
+//
+//   - refreshTokenPath: The path of the refresh token endpoint
+//
+// Returns:
+//
+//   - *CookieConfig: The cookie configuration
+//   - error: An error if the refresh token path is empty
+func NewCookieConfig(refreshTokenPath string) (*CookieConfig, error) {
+	// Check if the refresh token path is empty
+	if refreshTokenPath == "" {
+		return nil, ErrEmptyRefreshTokenCookiePath
+	}
+
+	return &CookieConfig{
+		AccessTokenName:  DefaultAccessTokenCookieName,
+		RefreshTokenName: DefaultRefreshTokenCookieName,
+		CSRFName:         DefaultCSRFCookieName,
+		CSRFHeaderKey:    DefaultCSRFHeaderKey,
+		AccessTokenPath:  DefaultAccessTokenCookiePath,
+		RefreshTokenPath: refreshTokenPath,
+		SameSite:         DefaultCookieSameSite,
+	}, nil
+}
+
+// cookie creates a cookie with the configured attributes
+//
+// Parameters:
+//
+//   - name: The cookie name
+//   - value: The cookie value
+//   - path: The cookie path
+//   - expiresAt: The cookie expiration time, the cookie is deleted if it is zero
+//   - httpOnly: Whether the cookie is hidden from the client scripts
+//
+// Returns:
+//
+//   - *http.Cookie: The cookie
+func (c *CookieConfig) cookie(
+	name string,
+	value string,
+	path string,
+	expiresAt time.Time,
+	httpOnly bool,
+) *http.Cookie {
+	cookie := &http.Cookie{
+		Name:     name,
+		Value:    value,
+		Path:     path,
+		Domain:   c.Domain,
+		Secure:   !c.Insecure,
+		HttpOnly: httpOnly,
+		SameSite: c.SameSite,
+	}
+	if expiresAt.IsZero() {
+		cookie.MaxAge = -1
+	} else {
+		cookie.Expires = expiresAt
+	}
+	return cookie
+}
+
+// TokenCookieName returns the name of the cookie that carries the given token type
+//
+// Parameters:
+//
+//   - token: The token type
+//
+// Returns:
+//
+//   - string: The cookie name
+//   - error: An error if the token type is unexpected
+func (c *CookieConfig) TokenCookieName(token gojwttoken.Token) (string, error) {
+	switch token {
+	case gojwttoken.AccessToken:
+		return c.AccessTokenName, nil
+	case gojwttoken.RefreshToken:
+		return c.RefreshTokenName, nil
+	default:
+		return "", gojwttoken.ErrUnexpectedTokenType
+	}
+}
+
+// SetTokenCookies writes the access token, the refresh token and a new CSRF token into their cookies
+//
+// Parameters:
+//
+//   - w: The HTTP response writer
+//   - accessToken: The raw access token
+//   - accessTokenExpiresAt: The access token expiration time
+//   - refreshToken: The raw refresh token (optional, the refresh token cookie is not written if empty)
+//   - refreshTokenExpiresAt: The refresh token expiration time
+//
+// Returns:
+//
+//   - error: An error if the config is nil or if the CSRF token could not be generated
+func (c *CookieConfig) SetTokenCookies(
+	w http.ResponseWriter,
+	accessToken string,
+	accessTokenExpiresAt time.Time,
+	refreshToken string,
+	refreshTokenExpiresAt time.Time,
+) error {
+	if c == nil {
+		return ErrNilCookieConfig
+	}
+
+	// Generate the CSRF token, which lives as long as the session
+	csrfToken, err := GenerateCSRFToken()
+	if err != nil {
+		return err
+	}
+	csrfTokenExpiresAt := accessTokenExpiresAt
+
+	http.SetCookie(
+		w, c.cookie(
+			c.AccessTokenName,
+			accessToken,
+			c.AccessTokenPath,
+			accessTokenExpiresAt,
+			true,
+		),
+	)
+	if refreshToken != "" {
+		http.SetCookie(
+			w, c.cookie(
+				c.RefreshTokenName,
+				refreshToken,
+				c.RefreshTokenPath,
+				refreshTokenExpiresAt,
+				true,
+			),
+		)
+		csrfTokenExpiresAt = refreshTokenExpiresAt
+	}
+	http.SetCookie(
+		w, c.cookie(
+			c.CSRFName,
+			csrfToken,
+			"/",
+			csrfTokenExpiresAt,
+			false,
+		),
+	)
+	return nil
+}
+
+// ClearTokenCookies deletes the token and CSRF cookies
+//
+// Parameters:
+//
+//   - w: The HTTP response writer
+//
+// Returns:
+//
+//   - error: An error if the config is nil
+func (c *CookieConfig) ClearTokenCookies(w http.ResponseWriter) error {
+	if c == nil {
+		return ErrNilCookieConfig
+	}
+
+	http.SetCookie(
+		w,
+		c.cookie(c.AccessTokenName, "", c.AccessTokenPath, time.Time{}, true),
+	)
+	http.SetCookie(
+		w,
+		c.cookie(c.RefreshTokenName, "", c.RefreshTokenPath, time.Time{}, true),
+	)
+	http.SetCookie(w, c.cookie(c.CSRFName, "", "/", time.Time{}, false))
+	return nil
+}
+
+// GetTokenCookie gets the raw token of the given token type from the request cookies
+//
+// Parameters:
+//
+//   - r: The HTTP request
+//   - token: The token type
+//
+// Returns:
+//
+//   - string: The raw token
+//   - error: An error if the config is nil, if the token type is unexpected or if the cookie is missing
+func (c *CookieConfig) GetTokenCookie(
+	r *http.Request,
+	token gojwttoken.Token,
+) (string, error) {
+	if c == nil {
+		return "", ErrNilCookieConfig
+	}
+
+	// Get the cookie name
+	name, err := c.TokenCookieName(token)
+	if err != nil {
+		return "", err
+	}
+
+	// Get the cookie
+	cookie, err := r.Cookie(name)
+	if err != nil || cookie.Value == "" {
+		return "", ErrMissingTokenCookie
+	}
+	return cookie.Value, nil
+}
+
+// CheckCSRF checks the double-submit CSRF token of state-changing requests, which must be echoed in the CSRF header
+//
+// Parameters:
+//
+//   - r: The HTTP request
+//
+// Returns:
+//
+//   - error: An error if the config is nil, or if the CSRF token is missing or does not match its cookie
+func (c *CookieConfig) CheckCSRF(r *http.Request) error {
+	if c == nil {
+		return ErrNilCookieConfig
+	}
+
+	// Safe methods do not change state
+	if isSafeMethod(r.Method) {
+		return nil
+	}
+
+	// Get the CSRF token from its cookie and header
+	cookie, err := r.Cookie(c.CSRFName)
+	if err != nil || cookie.Value == "" {
+		return ErrMissingCSRFToken
+	}
+	header := r.Header.Get(c.CSRFHeaderKey)
+	if header == "" {
+		return ErrMissingCSRFToken
+	}
+
+	// Compare them in constant time
+	if subtle.ConstantTimeCompare([]byte(cookie.Value), []byte(header)) != 1 {
+		return ErrInvalidCSRFToken
+	}
+	return nil
+}
+
+// CSRFProtect returns a middleware that rejects the state-changing requests without a valid double-submit CSRF token
+//
+// Returns:
+//
+//   - func(http.Handler) http.Handler: The middleware
+func (c *CookieConfig) CSRFProtect() func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(
+			func(w http.ResponseWriter, r *http.Request) {
+				if err := c.CheckCSRF(r); err != nil {
+					http.Error(w, err.Error(), http.StatusForbidden)
+					return
+				}
+				next.ServeHTTP(w, r)
+			},
+		)
+	}
+}
+
+// GenerateCSRFToken generates a random CSRF token
+//
+// Returns:
+//
+//   - string: The CSRF token
+//   - error: An error if the random bytes could not be read
+func GenerateCSRFToken() (string, error) {
+	buffer := make([]byte, CSRFTokenLength)
+	if _, err := rand.Read(buffer); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(buffer), nil
+}
+
+// isSafeMethod checks if the HTTP method does not change state
+//
+// Parameters:
+//
+//   - method: The HTTP method
+//
+// Returns:
+//
+//   - bool: Whether the HTTP method is safe
+func isSafeMethod(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,15 @@
+package context
+
+import (
+	"errors"
+)
+
+var (
+	ErrNilCookieConfig             = errors.New("cookie config cannot be nil")
+	ErrEmptyRefreshTokenCookiePath = errors.New("refresh token cookie path cannot be empty")
+	ErrMissingTokenCookie          = errors.New("missing token cookie")
+	ErrMissingCSRFToken            = errors.New("missing csrf token")
+	ErrMissingTokenParam           = errors.New("missing token parameter")
+	ErrInvalidCSRFToken            = errors.New("invalid csrf token")
+	ErrFailedCSRFCheck             = errors.New("token cookie failed the csrf check")
+)
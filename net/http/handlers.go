@@ -0,0 +1,362 @@
+package context
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"mime"
+	"net/http"
+
+	gojwt "github.com/ralvarezdev/go-jwt"
+	gojwtservice "github.com/ralvarezdev/go-jwt/token/service"
+)
+
+type (
+	// IssueHook authenticates the request, such as by checking the user credentials, and returns the authenticated
+	// subject and the application claims of the tokens
+	IssueHook func(r *http.Request) (
+		subject string,
+		claims map[string]any,
+		err error,
+	)
+
+	// TokenHandlers are the mountable token endpoints built on top of a token service. The revoke and introspect
+	// endpoints must be protected by the application, since RFC 7009 and RFC 7662 require the callers to authenticate
+	TokenHandlers struct {
+		service *gojwtservice.Service
+		logger  *slog.Logger
+	}
+
+	// errorResponse is the OAuth 2.0 error response
+	errorResponse struct {
+		Error string `json:"error"`
+	}
+)
+
+// NewTokenHandlers creates new token handlers
+//
+// Parameters:
+//
+//   - service: The token service
+//   - logger: The logger (optional, can be nil)
+//
+// Returns:
+//
+//   - *TokenHandlers: The token handlers
+//   - error: An error if the token service is nil
+func NewTokenHandlers(
+	service *gojwtservice.Service,
+	logger *slog.Logger,
+) (*TokenHandlers, error) {
+	// Check if the token service is nil
+	if service == nil {
+		return nil, gojwtservice.ErrNilService
+	}
+
+	if logger != nil {
+		logger = logger.With(slog.String("component", "http_token_handlers"))
+	}
+
+	return &TokenHandlers{
+		service: service,
+		logger:  logger,
+	}, nil
+}
+
+// IssueHandler returns the handler that issues a new pair of tokens for the subject authenticated by the given hook
+//
+// Parameters:
+//
+//   - hook: The hook that authenticates the request
+//
+// Returns:
+//
+//   - http.Handler: The handler
+func (t *TokenHandlers) IssueHandler(hook IssueHook) http.Handler {
+	return http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			if r.Method != http.MethodPost {
+				writeMethodNotAllowed(w)
+				return
+			}
+
+			// Authenticate the request
+			subject, claims, err := hook(r)
+			if err != nil {
+				t.writeError(
+					w,
+					http.StatusUnauthorized,
+					InvalidGrantErrorCode,
+					err,
+				)
+				return
+			}
+
+			// Issue the tokens
+			pair, err := t.service.IssueTokens(r.Context(), subject, claims)
+			if err != nil {
+				t.writeServiceError(w, err)
+				return
+			}
+			writeJSON(w, http.StatusOK, pair)
+		},
+	)
+}
+
+// RefreshHandler returns the handler that exchanges the refresh_token parameter for a new pair of tokens
+//
+// Returns:
+//
+//   - http.Handler: The handler
+func (t *TokenHandlers) RefreshHandler() http.Handler {
+	return http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			if r.Method != http.MethodPost {
+				writeMethodNotAllowed(w)
+				return
+			}
+
+			// Read the refresh token
+			params, err := readTokenParams(w, r)
+			if err != nil || params[RefreshTokenParam] == "" {
+				t.writeError(
+					w,
+					http.StatusBadRequest,
+					gojwt.InvalidRequestErrorCode,
+					ErrMissingTokenParam,
+				)
+				return
+			}
+
+			// Refresh the tokens
+			pair, err := t.service.Refresh(r.Context(), params[RefreshTokenParam])
+			if err != nil {
+				t.writeServiceError(w, err)
+				return
+			}
+			writeJSON(w, http.StatusOK, pair)
+		},
+	)
+}
+
+// RevokeHandler returns the RFC 7009 handler that revokes the token parameter. It must be protected by the
+// authentication middleware, since only the tokens owned by the authenticated subject are revoked
+//
+// Returns:
+//
+//   - http.Handler: The handler
+func (t *TokenHandlers) RevokeHandler() http.Handler {
+	return http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			if r.Method != http.MethodPost {
+				writeMethodNotAllowed(w)
+				return
+			}
+
+			// Read the token
+			params, err := readTokenParams(w, r)
+			if err != nil || params[TokenParam] == "" {
+				t.writeError(
+					w,
+					http.StatusBadRequest,
+					gojwt.InvalidRequestErrorCode,
+					ErrMissingTokenParam,
+				)
+				return
+			}
+
+			// Get the authenticated subject
+			subject, err := GetCtxTokenClaimsSubject(r)
+			if err != nil {
+				t.writeError(w, http.StatusUnauthorized, InvalidClientErrorCode, err)
+				return
+			}
+
+			// Revoke the token, invalid tokens and tokens owned by another subject are also answered with a success
+			if err = t.service.RevokeOwned(
+				r.Context(),
+				subject,
+				params[TokenParam],
+				params[TokenTypeHintParam],
+			); err != nil {
+				t.writeServiceError(w, err)
+				return
+			}
+			w.Header().Set("Cache-Control", "no-store")
+			w.WriteHeader(http.StatusOK)
+		},
+	)
+}
+
+// IntrospectHandler returns the RFC 7662 handler that introspects the token parameter
+//
+// Returns:
+//
+//   - http.Handler: The handler
+func (t *TokenHandlers) IntrospectHandler() http.Handler {
+	return http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			if r.Method != http.MethodPost {
+				writeMethodNotAllowed(w)
+				return
+			}
+
+			// Read the token
+			params, err := readTokenParams(w, r)
+			if err != nil || params[TokenParam] == "" {
+				t.writeError(
+					w,
+					http.StatusBadRequest,
+					gojwt.InvalidRequestErrorCode,
+					ErrMissingTokenParam,
+				)
+				return
+			}
+
+			// Introspect the token
+			introspection, err := t.service.Introspect(
+				r.Context(),
+				params[TokenParam],
+				params[TokenTypeHintParam],
+			)
+			if err != nil {
+				t.writeServiceError(w, err)
+				return
+			}
+			writeJSON(w, http.StatusOK, introspection)
+		},
+	)
+}
+
+// writeServiceError writes the error response of a token service error
+//
+// Parameters:
+//
+//   - w: The HTTP response writer
+//   - err: The token service error
+func (t *TokenHandlers) writeServiceError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, gojwtservice.ErrInvalidGrant):
+		t.writeError(w, http.StatusBadRequest, InvalidGrantErrorCode, err)
+	case errors.Is(err, gojwtservice.ErrUnsupportedTokenTypeHint):
+		t.writeError(
+			w,
+			http.StatusBadRequest,
+			UnsupportedTokenTypeErrorCode,
+			err,
+		)
+	case errors.Is(err, gojwtservice.ErrEmptySubject),
+		errors.Is(err, gojwtservice.ErrReservedClaim):
+		t.writeError(
+			w,
+			http.StatusBadRequest,
+			gojwt.InvalidRequestErrorCode,
+			err,
+		)
+	default:
+		if t.logger != nil {
+			t.logger.Error(
+				"Token service failed",
+				slog.String("error", err.Error()),
+			)
+		}
+		writeJSON(
+			w,
+			http.StatusInternalServerError,
+			errorResponse{Error: ServerErrorCode},
+		)
+	}
+}
+
+// writeError writes an OAuth 2.0 error response. The error details are only logged, so the internal errors are not
+// exposed to the client
+//
+// Parameters:
+//
+//   - w: The HTTP response writer
+//   - status: The HTTP status code
+//   - errorCode: The OAuth 2.0 error code
+//   - err: The error
+func (t *TokenHandlers) writeError(
+	w http.ResponseWriter,
+	status int,
+	errorCode string,
+	err error,
+) {
+	if t.logger != nil {
+		t.logger.Debug(
+			"Token request rejected",
+			slog.String("error_code", errorCode),
+			slog.String("error", err.Error()),
+		)
+	}
+	writeJSON(w, status, errorResponse{Error: errorCode})
+}
+
+// readTokenParams reads the parameters of a token request, which are either form-encoded as required by the OAuth
+// 2.0 specifications, or a JSON object of strings
+//
+// Parameters:
+//
+//   - w: The HTTP response writer
+//   - r: The HTTP request
+//
+// Returns:
+//
+//   - map[string]string: The parameters
+//   - error: An error if the request body could not be read
+func readTokenParams(w http.ResponseWriter, r *http.Request) (
+	map[string]string,
+	error,
+) {
+	r.Body = http.MaxBytesReader(w, r.Body, MaxTokenRequestBodySize)
+
+	// Read the JSON object
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mediaType == "application/json" {
+		var params map[string]string
+		if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
+			return nil, err
+		}
+		return params, nil
+	}
+
+	// Read the form
+	if err := r.ParseForm(); err != nil {
+		return nil, err
+	}
+	params := make(map[string]string, len(r.PostForm))
+	for key := range r.PostForm {
+		params[key] = r.PostForm.Get(key)
+	}
+	return params, nil
+}
+
+// writeJSON writes a JSON response that must not be cached
+//
+// Parameters:
+//
+//   - w: The HTTP response writer
+//   - status: The HTTP status code
+//   - body: The response body
+func writeJSON(w http.ResponseWriter, status int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("Pragma", "no-cache")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+// writeMethodNotAllowed writes the response of a request with an unsupported method
+//
+// Parameters:
+//
+//   - w: The HTTP response writer
+func writeMethodNotAllowed(w http.ResponseWriter) {
+	w.Header().Set("Allow", http.MethodPost)
+	http.Error(
+		w,
+		http.StatusText(http.StatusMethodNotAllowed),
+		http.StatusMethodNotAllowed,
+	)
+}
@@ -0,0 +1,304 @@
+package context
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+
+	gojwt "github.com/ralvarezdev/go-jwt"
+	gojwttoken "github.com/ralvarezdev/go-jwt/token"
+	gojwtauthz "github.com/ralvarezdev/go-jwt/token/authz"
+	gojwtvalidator "github.com/ralvarezdev/go-jwt/token/validator"
+)
+
+type (
+	// Authenticator is the net/http authentication middleware that validates bearer tokens
+	Authenticator struct {
+		validator gojwtvalidator.Validator
+		realm     string
+		logger    *slog.Logger
+	}
+)
+
+// NewAuthenticator creates a new authenticator
+//
+// Parameters:
+//
+//   - validator: The token validator
+//   - realm: The protection realm sent in the authentication challenges (optional, gojwt.DefaultRealm is used if
+//     empty)
+//   - logger: The logger (optional, can be nil)
+//
+// Returns:
+//
+//   - *Authenticator: The authenticator
+//   - error: An error if the validator is nil
+func NewAuthenticator(
+	validator gojwtvalidator.Validator,
+	realm string,
+	logger *slog.Logger,
+) (*Authenticator, error) {
+	// Check if the validator is nil
+	if validator == nil {
+		return nil, gojwtvalidator.ErrNilValidator
+	}
+
+	if realm == "" {
+		realm = gojwt.DefaultRealm
+	}
+
+	if logger != nil {
+		logger = logger.With(slog.String("component", "http_authenticator"))
+	}
+
+	return &Authenticator{
+		validator: validator,
+		realm:     realm,
+		logger:    logger,
+	}, nil
+}
+
+// Authenticate returns a middleware that validates the bearer token of the request for the given token type. On
+// success, the raw token and its claims are set in the request context. On failure, an RFC 6750 response is written
+// with its WWW-Authenticate challenge
+//
+// Parameters:
+//
+//   - token: The expected token type
+//
+// Returns:
+//
+//   - func(http.Handler) http.Handler: The middleware
+func (a *Authenticator) Authenticate(token gojwttoken.Token) func(http.Handler) http.Handler {
+	return a.AuthenticateWith(gojwt.AuthorizationHeaderExtractor{}, nil, token)
+}
+
+// AuthenticateWith returns a middleware that validates the token found by the given extractor for the given token
+// type, such as a query parameter or a WebSocket subprotocol for endpoints that cannot send the authorization header.
+// The tokens found by a gojwt.CookieExtractor are only accepted if the request passes the CSRF check
+//
+// Parameters:
+//
+//   - extractor: The token extractor (optional, the authorization header is used if nil)
+//   - config: The cookie configuration whose CSRF check is run for the tokens found in cookies (optional, the tokens
+//     found in cookies are rejected if nil)
+//   - token: The expected token type
+//
+// Returns:
+//
+//   - func(http.Handler) http.Handler: The middleware
+func (a *Authenticator) AuthenticateWith(
+	extractor gojwt.TokenExtractor,
+	config *CookieConfig,
+	token gojwttoken.Token,
+) func(http.Handler) http.Handler {
+	if extractor == nil {
+		extractor = gojwt.AuthorizationHeaderExtractor{}
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(
+			func(w http.ResponseWriter, r *http.Request) {
+				// Extract the token from the request
+				rawToken, err := ExtractToken(r, extractor, config)
+				if err != nil {
+					// Requests without credentials must not receive an error code
+					if errors.Is(err, gojwt.ErrMissingToken) {
+						a.WriteChallenge(w, http.StatusUnauthorized, "", "")
+						return
+					}
+
+					// Check if the token cookie failed the CSRF check
+					if errors.Is(err, ErrFailedCSRFCheck) {
+						http.Error(w, err.Error(), http.StatusForbidden)
+						return
+					}
+					a.WriteChallenge(
+						w,
+						http.StatusBadRequest,
+						gojwt.InvalidRequestErrorCode,
+						err.Error(),
+					)
+					return
+				}
+
+				a.serveWithToken(w, r, next, rawToken, token)
+			},
+		)
+	}
+}
+
+// AuthenticateCookie returns a middleware that validates the token of the given token type carried by its cookie,
+// for browser clients. State-changing requests must also carry a valid double-submit CSRF token. On success, the raw
+// token and its claims are set in the request context
+//
+// Parameters:
+//
+//   - config: The cookie configuration
+//   - token: The expected token type
+//
+// Returns:
+//
+//   - func(http.Handler) http.Handler: The middleware
+func (a *Authenticator) AuthenticateCookie(
+	config *CookieConfig,
+	token gojwttoken.Token,
+) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(
+			func(w http.ResponseWriter, r *http.Request) {
+				// Get the token from its cookie
+				rawToken, err := config.GetTokenCookie(r, token)
+				if err != nil {
+					a.WriteChallenge(w, http.StatusUnauthorized, "", "")
+					return
+				}
+
+				// Check the CSRF token
+				if err = config.CheckCSRF(r); err != nil {
+					http.Error(w, err.Error(), http.StatusForbidden)
+					return
+				}
+
+				a.serveWithToken(w, r, next, rawToken, token)
+			},
+		)
+	}
+}
+
+// serveWithToken validates the raw token and calls the next handler with the raw token and its claims set in the
+// request context
+//
+// Parameters:
+//
+//   - w: The HTTP response writer
+//   - r: The HTTP request
+//   - next: The next handler
+//   - rawToken: The raw token
+//   - token: The expected token type
+func (a *Authenticator) serveWithToken(
+	w http.ResponseWriter,
+	r *http.Request,
+	next http.Handler,
+	rawToken string,
+	token gojwttoken.Token,
+) {
+	// Validate the token
+	claims, err := a.validator.ValidateClaims(r.Context(), rawToken, token)
+	if err != nil {
+		if a.logger != nil {
+			a.logger.Debug(
+				"Token validation failed",
+				slog.String("token", token.String()),
+				slog.String("path", r.URL.Path),
+				slog.String("error", err.Error()),
+			)
+		}
+		a.WriteChallenge(
+			w,
+			http.StatusUnauthorized,
+			gojwt.InvalidTokenErrorCode,
+			gojwt.InvalidTokenDescription(err),
+		)
+		return
+	}
+
+	// Check if the claims belong to the expected token type
+	if err = gojwt.CheckClaimsTokenType(claims, token); err != nil {
+		a.WriteChallenge(
+			w,
+			http.StatusUnauthorized,
+			gojwt.InvalidTokenErrorCode,
+			gojwt.InvalidTokenDescription(err),
+		)
+		return
+	}
+
+	// Set the raw token and its claims in the request context
+	r, err = SetCtxToken(r, rawToken)
+	if err != nil {
+		a.WriteChallenge(
+			w,
+			http.StatusBadRequest,
+			gojwt.InvalidRequestErrorCode,
+			err.Error(),
+		)
+		return
+	}
+	next.ServeHTTP(w, SetCtxTokenClaims(r, claims))
+}
+
+// WriteChallenge writes an RFC 6750 error response with its WWW-Authenticate challenge
+//
+// Parameters:
+//
+//   - w: The HTTP response writer
+//   - status: The HTTP status code
+//   - errorCode: The RFC 6750 error code (optional, omitted if empty)
+//   - errorDescription: The human-readable error description (optional, omitted if empty)
+func (a *Authenticator) WriteChallenge(
+	w http.ResponseWriter,
+	status int,
+	errorCode string,
+	errorDescription string,
+) {
+	realm := gojwt.DefaultRealm
+	if a != nil {
+		realm = a.realm
+	}
+
+	w.Header().Set(
+		gojwt.WWWAuthenticateHeaderKey,
+		gojwt.BearerChallenge(realm, errorCode, errorDescription),
+	)
+	http.Error(w, http.StatusText(status), status)
+}
+
+// RequirePolicy returns a middleware that requires the token claims set by the authentication middleware to satisfy
+// the given authorization policy
+//
+// Parameters:
+//
+//   - authorizer: The authorizer (optional, the roles grant no permissions if nil)
+//   - policy: The authorization policy
+//
+// Returns:
+//
+//   - func(http.Handler) http.Handler: The middleware
+func (a *Authenticator) RequirePolicy(
+	authorizer *gojwtauthz.Authorizer,
+	policy gojwtauthz.Policy,
+) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(
+			func(w http.ResponseWriter, r *http.Request) {
+				// Get the token claims
+				claims, err := GetCtxTokenClaims(r)
+				if err != nil {
+					a.WriteChallenge(w, http.StatusUnauthorized, "", "")
+					return
+				}
+
+				// Check the policy
+				if err = authorizer.Authorize(claims, policy); err != nil {
+					if errors.Is(err, gojwtauthz.ErrInsufficientScope) {
+						a.WriteChallenge(
+							w,
+							http.StatusForbidden,
+							gojwt.InsufficientScopeErrorCode,
+							err.Error(),
+						)
+						return
+					}
+					http.Error(
+						w,
+						http.StatusText(http.StatusForbidden),
+						http.StatusForbidden,
+					)
+					return
+				}
+				next.ServeHTTP(w, r)
+			},
+		)
+	}
+}
@@ -0,0 +1,166 @@
+package context
+
+import (
+	"io"
+	"net/http"
+	"strings"
+
+	gojwt "github.com/ralvarezdev/go-jwt"
+	gojwttokensource "github.com/ralvarezdev/go-jwt/token/source"
+)
+
+type (
+	// Transport is an HTTP round tripper that attaches the bearer token of a token source to the outgoing requests.
+	// When the server rejects the token as invalid, the token is refreshed and idempotent requests are replayed once
+	Transport struct {
+		base   http.RoundTripper
+		source gojwttokensource.Source
+	}
+)
+
+// NewTransport creates a new transport
+//
+// Parameters:
+//
+//   - base: The wrapped round tripper (optional, http.DefaultTransport is used if nil)
+//   - source: The token source
+//
+// Returns:
+//
+//   - *Transport: The transport
+//   - error: An error if the source is nil
+func NewTransport(
+	base http.RoundTripper,
+	source gojwttokensource.Source,
+) (*Transport, error) {
+	// Check if the source is nil
+	if source == nil {
+		return nil, gojwttokensource.ErrNilSource
+	}
+
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	return &Transport{
+		base:   base,
+		source: source,
+	}, nil
+}
+
+// RoundTrip executes the request with the bearer token
+//
+// Parameters:
+//
+//   - r: The HTTP request
+//
+// Returns:
+//
+//   - *http.Response: The HTTP response
+//   - error: An error if the token could not be obtained or if the request failed
+func (t *Transport) RoundTrip(r *http.Request) (*http.Response, error) {
+	// Get the token
+	rawToken, err := t.source.Token(r.Context())
+	if err != nil {
+		closeRequestBody(r)
+		return nil, err
+	}
+
+	// Send the request
+	res, err := t.base.RoundTrip(withBearerToken(r, rawToken))
+	if err != nil || !isInvalidTokenResponse(res) || !isReplayable(r) {
+		return res, err
+	}
+
+	// Refresh the token, sources without refresh support are not retried
+	rawToken, err = t.source.Refresh(r.Context())
+	if err != nil {
+		return res, nil
+	}
+
+	// Rewind the request body
+	replay := withBearerToken(r, rawToken)
+	if r.Body != nil && r.Body != http.NoBody {
+		if replay.Body, err = r.GetBody(); err != nil {
+			return res, nil
+		}
+	}
+
+	// Discard the rejected response and replay the request
+	_, _ = io.Copy(io.Discard, res.Body)
+	_ = res.Body.Close()
+	return t.base.RoundTrip(replay)
+}
+
+// withBearerToken returns a clone of the request with the bearer token, since round trippers must not modify the
+// original request
+//
+// Parameters:
+//
+//   - r: The HTTP request
+//   - rawToken: The raw token
+//
+// Returns:
+//
+//   - *http.Request: The cloned HTTP request
+func withBearerToken(r *http.Request, rawToken string) *http.Request {
+	clone := r.Clone(r.Context())
+	clone.Header.Set(
+		gojwt.AuthorizationHeaderKey,
+		gojwt.BearerPrefix+" "+rawToken,
+	)
+	return clone
+}
+
+// isInvalidTokenResponse checks if the response rejects the bearer token as invalid
+//
+// Parameters:
+//
+//   - res: The HTTP response
+//
+// Returns:
+//
+//   - bool: Whether the response is a 401 with the invalid_token error code
+func isInvalidTokenResponse(res *http.Response) bool {
+	if res.StatusCode != http.StatusUnauthorized {
+		return false
+	}
+	for _, challenge := range res.Header.Values(gojwt.WWWAuthenticateHeaderKey) {
+		if strings.Contains(
+			challenge,
+			`error="`+gojwt.InvalidTokenErrorCode+`"`,
+		) {
+			return true
+		}
+	}
+	return false
+}
+
+// isReplayable checks if the request is idempotent and its body can be sent again
+//
+// Parameters:
+//
+//   - r: The HTTP request
+//
+// Returns:
+//
+//   - bool: Whether the request can be replayed
+func isReplayable(r *http.Request) bool {
+	switch r.Method {
+	case "", http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace, http.MethodPut, http.MethodDelete:
+	default:
+		return false
+	}
+	return r.Body == nil || r.Body == http.NoBody || r.GetBody != nil
+}
+
+// closeRequestBody closes the request body, as required from round trippers even on errors
+//
+// Parameters:
+//
+//   - r: The HTTP request
+func closeRequestBody(r *http.Request) {
+	if r.Body != nil {
+		_ = r.Body.Close()
+	}
+}
@@ -0,0 +1,253 @@
+package context
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	gojwt "github.com/ralvarezdev/go-jwt"
+	gojwttokensource "github.com/ralvarezdev/go-jwt/token/source"
+)
+
+const (
+	// staleRawToken is the token rejected by the test server
+	staleRawToken = "stale-token"
+
+	// freshRawToken is the token accepted by the test server
+	freshRawToken = "fresh-token"
+
+	// rejectedBody is the body of the responses that reject the token
+	rejectedBody = "rejected"
+)
+
+type (
+	// refreshingSource is a token source that returns the stale token until it is refreshed
+	refreshingSource struct {
+		refreshes atomic.Int32
+	}
+
+	// testServer is a server that only accepts the fresh token and records the bodies it receives
+	testServer struct {
+		*httptest.Server
+		challenge string
+		mutex     sync.Mutex
+		bodies    []string
+	}
+)
+
+// Token returns the stale token
+//
+// Parameters:
+//
+//   - ctx: The context
+//
+// Returns:
+//
+//   - string: The raw token
+//   - error: Always nil
+func (s *refreshingSource) Token(ctx context.Context) (string, error) {
+	return staleRawToken, nil
+}
+
+// Refresh counts the refresh and returns the fresh token
+//
+// Parameters:
+//
+//   - ctx: The context
+//
+// Returns:
+//
+//   - string: The refreshed raw token
+//   - error: Always nil
+func (s *refreshingSource) Refresh(ctx context.Context) (string, error) {
+	s.refreshes.Add(1)
+	return freshRawToken, nil
+}
+
+// newTestServer creates a server that only accepts the fresh token, and rejects the others with the given challenge
+//
+// Parameters:
+//
+//   - t: The test
+//   - challenge: The WWW-Authenticate challenge of the rejections (optional, none is sent if empty)
+//
+// Returns:
+//
+//   - *testServer: The test server
+func newTestServer(t *testing.T, challenge string) *testServer {
+	t.Helper()
+
+	server := &testServer{challenge: challenge}
+	server.Server = httptest.NewServer(
+		http.HandlerFunc(
+			func(w http.ResponseWriter, r *http.Request) {
+				// Record the body
+				body, _ := io.ReadAll(r.Body)
+				server.mutex.Lock()
+				server.bodies = append(server.bodies, string(body))
+				server.mutex.Unlock()
+
+				// Check the token
+				if r.Header.Get(gojwt.AuthorizationHeaderKey) != gojwt.BearerPrefix+" "+freshRawToken {
+					if server.challenge != "" {
+						w.Header().Set(gojwt.WWWAuthenticateHeaderKey, server.challenge)
+					}
+					w.WriteHeader(http.StatusUnauthorized)
+					_, _ = io.WriteString(w, rejectedBody)
+					return
+				}
+				w.WriteHeader(http.StatusOK)
+			},
+		),
+	)
+	t.Cleanup(server.Close)
+	return server
+}
+
+// Bodies returns the bodies of the received requests
+//
+// Returns:
+//
+//   - []string: The bodies of the received requests
+func (s *testServer) Bodies() []string {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return append([]string(nil), s.bodies...)
+}
+
+func TestTransport_RoundTrip(t *testing.T) {
+	invalidTokenChallenge := gojwt.BearerPrefix + ` error="` + gojwt.InvalidTokenErrorCode + `"`
+
+	tests := []struct {
+		name          string
+		challenge     string
+		method        string
+		body          string
+		withoutRewind bool
+		wantStatus    int
+		wantBodies    []string
+		wantRefreshes int32
+	}{
+		{
+			name:          "GET replayed",
+			challenge:     invalidTokenChallenge,
+			method:        http.MethodGet,
+			wantStatus:    http.StatusOK,
+			wantBodies:    []string{"", ""},
+			wantRefreshes: 1,
+		},
+		{
+			name:          "PUT replayed with its rewound body",
+			challenge:     invalidTokenChallenge,
+			method:        http.MethodPut,
+			body:          "payload",
+			wantStatus:    http.StatusOK,
+			wantBodies:    []string{"payload", "payload"},
+			wantRefreshes: 1,
+		},
+		{
+			name:          "PUT without body rewind not replayed",
+			challenge:     invalidTokenChallenge,
+			method:        http.MethodPut,
+			body:          "payload",
+			withoutRewind: true,
+			wantStatus:    http.StatusUnauthorized,
+			wantBodies:    []string{"payload"},
+		},
+		{
+			name:       "POST not replayed",
+			challenge:  invalidTokenChallenge,
+			method:     http.MethodPost,
+			body:       "payload",
+			wantStatus: http.StatusUnauthorized,
+			wantBodies: []string{"payload"},
+		},
+		{
+			name:       "rejection without invalid token challenge not replayed",
+			method:     http.MethodGet,
+			wantStatus: http.StatusUnauthorized,
+			wantBodies: []string{""},
+		},
+	}
+	for _, test := range tests {
+		t.Run(
+			test.name, func(t *testing.T) {
+				server := newTestServer(t, test.challenge)
+				source := &refreshingSource{}
+				transport, err := NewTransport(nil, source)
+				if err != nil {
+					t.Fatalf("NewTransport() error = %v", err)
+				}
+
+				// Create the request
+				req, err := http.NewRequest(test.method, server.URL, strings.NewReader(test.body))
+				if err != nil {
+					t.Fatalf("NewRequest() error = %v", err)
+				}
+				if test.withoutRewind {
+					req.GetBody = nil
+				}
+
+				res, err := transport.RoundTrip(req)
+				if err != nil {
+					t.Fatalf("RoundTrip() error = %v", err)
+				}
+				defer func() {
+					_ = res.Body.Close()
+				}()
+
+				if res.StatusCode != test.wantStatus {
+					t.Errorf("RoundTrip() status = %d, want %d", res.StatusCode, test.wantStatus)
+				}
+				if refreshes := source.refreshes.Load(); refreshes != test.wantRefreshes {
+					t.Errorf("Refresh() calls = %d, want %d", refreshes, test.wantRefreshes)
+				}
+				if bodies := server.Bodies(); !slices.Equal(bodies, test.wantBodies) {
+					t.Errorf("server bodies = %q, want %q", bodies, test.wantBodies)
+				}
+			},
+		)
+	}
+}
+
+func TestTransport_RoundTrip_WithoutRefresh(t *testing.T) {
+	server := newTestServer(t, gojwt.BearerPrefix+` error="`+gojwt.InvalidTokenErrorCode+`"`)
+	source, err := gojwttokensource.NewStaticSource(staleRawToken)
+	if err != nil {
+		t.Fatalf("NewStaticSource() error = %v", err)
+	}
+	transport, err := NewTransport(nil, source)
+	if err != nil {
+		t.Fatalf("NewTransport() error = %v", err)
+	}
+
+	req, err := http.NewRequest(http.MethodGet, server.URL, nil)
+	if err != nil {
+		t.Fatalf("NewRequest() error = %v", err)
+	}
+	res, err := transport.RoundTrip(req)
+	if err != nil {
+		t.Fatalf("RoundTrip() error = %v", err)
+	}
+	defer func() {
+		_ = res.Body.Close()
+	}()
+
+	// The original rejection is returned with its body still readable
+	if res.StatusCode != http.StatusUnauthorized {
+		t.Errorf("RoundTrip() status = %d, want %d", res.StatusCode, http.StatusUnauthorized)
+	}
+	if body, err := io.ReadAll(res.Body); err != nil || string(body) != rejectedBody {
+		t.Errorf("RoundTrip() body = %q, %v, want %q", body, err, rejectedBody)
+	}
+	if bodies := server.Bodies(); len(bodies) != 1 {
+		t.Errorf("server requests = %d, want 1", len(bodies))
+	}
+}
@@ -0,0 +1,361 @@
+package rabbitmq
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/rabbitmq/amqp091-go"
+)
+
+type (
+	// ConnectionOptions are the options for the ConnectionManager
+	ConnectionOptions struct {
+		// Config is the AMQP connection configuration
+		Config amqp091.Config
+
+		// InitialBackoff is the wait before the first reconnection attempt, and before the second attempt of the initial
+		// connection, doubled on each failed attempt
+		InitialBackoff time.Duration
+
+		// MaxBackoff is the maximum wait between reconnection attempts
+		MaxBackoff time.Duration
+	}
+
+	// ConnectionManager is a RabbitMQ connection that is established and re-established with an exponential backoff
+	// when the broker is unreachable or closes it. The publisher and the consumer re-open their channels on the new
+	// connection, re-declaring their queues and re-subscribing to them
+	ConnectionManager struct {
+		url            string
+		config         amqp091.Config
+		initialBackoff time.Duration
+		maxBackoff     time.Duration
+		conn           *amqp091.Connection
+		state          ConnectionState
+		connectedCh    chan struct{}
+		closeCh        chan struct{}
+		logger         *slog.Logger
+		mutex          sync.RWMutex
+	}
+)
+
+// NewConnectionManager creates a new ConnectionManager
+//
+// Parameters:
+//
+//   - url: the AMQP URL of the broker
+//   - options: the options (optional, can be nil)
+//   - logger: the logger (optional, can be nil)
+//
+// Returns:
+//
+//   - *ConnectionManager: the ConnectionManager instance
+//   - error: an error if the URL is empty
+func NewConnectionManager(
+	url string,
+	options *ConnectionOptions,
+	logger *slog.Logger,
+) (*ConnectionManager, error) {
+	// Check if the URL is empty
+	if url == "" {
+		return nil, ErrEmptyURL
+	}
+
+	// Set the options
+	if options == nil {
+		options = &ConnectionOptions{}
+	}
+
+	initialBackoff := options.InitialBackoff
+	if initialBackoff <= 0 {
+		initialBackoff = DefaultReconnectInitialBackoff
+	}
+
+	maxBackoff := options.MaxBackoff
+	if maxBackoff < initialBackoff {
+		maxBackoff = max(DefaultReconnectMaxBackoff, initialBackoff)
+	}
+
+	if logger != nil {
+		logger = logger.With(
+			slog.String("component", "rabbitmq_connection_manager"),
+		)
+	}
+
+	return &ConnectionManager{
+		url:            url,
+		config:         options.Config,
+		initialBackoff: initialBackoff,
+		maxBackoff:     maxBackoff,
+		state:          Disconnected,
+		connectedCh:    make(chan struct{}),
+		closeCh:        make(chan struct{}),
+		logger:         logger,
+	}, nil
+}
+
+// Connect establishes the connection and starts watching it, so it is re-established when the broker closes it. The
+// failed attempts are retried with the same exponential backoff as the reconnections until the context is done, while
+// each attempt is bounded by the dial timeout of the AMQP configuration
+//
+// Parameters:
+//
+//   - ctx: the context
+//
+// Returns:
+//
+//   - error: the context error joined with the last dial error if the connection could not be established before
+//     the context is done, or ErrClosedConnectionManager if the connection manager is closed first
+func (c *ConnectionManager) Connect(ctx context.Context) error {
+	// Check if the connection manager is nil
+	if c == nil {
+		return ErrNilConnection
+	}
+
+	c.mutex.Lock()
+
+	// Check if the connection manager is closed, already connected or connecting
+	if c.state == Closed {
+		c.mutex.Unlock()
+		return ErrClosedConnectionManager
+	}
+	if c.state != Disconnected {
+		c.mutex.Unlock()
+		return nil
+	}
+	c.state = Connecting
+	c.mutex.Unlock()
+
+	// Establish the connection, so a later call can try again if the context is done first
+	err := c.dial(ctx, false)
+	if err != nil {
+		c.mutex.Lock()
+		if c.state == Connecting {
+			c.state = Disconnected
+		}
+		c.mutex.Unlock()
+
+		if c.logger != nil {
+			c.logger.Error(
+				"Failed to connect",
+				slog.String("error", err.Error()),
+			)
+		}
+	}
+	return err
+}
+
+// setConnection sets the established connection and watches it, the mutex must be held by the caller
+//
+// Parameters:
+//
+//   - conn: the established connection
+func (c *ConnectionManager) setConnection(conn *amqp091.Connection) {
+	c.conn = conn
+	c.state = Connected
+	close(c.connectedCh)
+
+	closeCh := conn.NotifyClose(make(chan *amqp091.Error, 1))
+	go c.watch(closeCh)
+
+	if c.logger != nil {
+		c.logger.Info("Connection established")
+	}
+}
+
+// watch waits for the connection to be closed, and re-establishes it unless it was closed by the application
+//
+// Parameters:
+//
+//   - closeCh: the channel notified when the connection is closed
+func (c *ConnectionManager) watch(closeCh <-chan *amqp091.Error) {
+	// The channel is closed without an error on a graceful shutdown
+	amqpErr, ok := <-closeCh
+	if !ok || amqpErr == nil {
+		return
+	}
+
+	c.mutex.Lock()
+	if c.state == Closed {
+		c.mutex.Unlock()
+		return
+	}
+	c.state = Reconnecting
+	c.connectedCh = make(chan struct{})
+	c.mutex.Unlock()
+
+	if c.logger != nil {
+		c.logger.Warn(
+			"Connection lost, reconnecting",
+			slog.String("error", amqpErr.Error()),
+		)
+	}
+
+	// Re-establish the connection until it succeeds or the connection manager is closed
+	_ = c.dial(context.Background(), true)
+}
+
+// dial establishes the connection with an exponential backoff until it succeeds, the context is done or the
+// connection manager is closed
+//
+// Parameters:
+//
+//   - ctx: the context
+//   - wait: whether to wait before the first attempt
+//
+// Returns:
+//
+//   - error: the context error joined with the last dial error if the context is done first, or
+//     ErrClosedConnectionManager if the connection manager is closed first
+func (c *ConnectionManager) dial(ctx context.Context, wait bool) error {
+	backoff := c.initialBackoff
+	var lastErr error
+	for attempt := 1; ; attempt++ {
+		// Wait before the attempt
+		if wait {
+			timer := time.NewTimer(backoff)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return errors.Join(ctx.Err(), lastErr)
+			case <-c.closeCh:
+				timer.Stop()
+				return ErrClosedConnectionManager
+			case <-timer.C:
+			}
+			backoff = min(backoff*2, c.maxBackoff)
+		}
+		wait = true
+
+		// Try to establish the connection
+		conn, err := amqp091.DialConfig(c.url, c.config)
+		if err == nil {
+			c.mutex.Lock()
+			if c.state == Closed {
+				c.mutex.Unlock()
+				_ = conn.Close()
+				return ErrClosedConnectionManager
+			}
+			c.setConnection(conn)
+			c.mutex.Unlock()
+			return nil
+		}
+		lastErr = err
+
+		if c.logger != nil {
+			c.logger.Warn(
+				"Failed to connect, retrying",
+				slog.Int("attempt", attempt),
+				slog.Duration("backoff", backoff),
+				slog.String("error", err.Error()),
+			)
+		}
+	}
+}
+
+// Channel opens a channel on the current connection
+//
+// Returns:
+//
+//   - *amqp091.Channel: the channel
+//   - error: ErrNotConnected if the connection is not established, or an error if the channel could not be opened
+func (c *ConnectionManager) Channel() (*amqp091.Channel, error) {
+	// Check if the connection manager is nil
+	if c == nil {
+		return nil, ErrNilConnection
+	}
+
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+
+	// Check if the connection is established
+	switch c.state {
+	case Closed:
+		return nil, ErrClosedConnectionManager
+	case Connected:
+		return c.conn.Channel()
+	default:
+		return nil, ErrNotConnected
+	}
+}
+
+// State returns the state of the connection
+//
+// Returns:
+//
+//   - ConnectionState: the state of the connection
+func (c *ConnectionManager) State() ConnectionState {
+	if c == nil {
+		return Closed
+	}
+
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	return c.state
+}
+
+// WaitConnected waits until the connection is established
+//
+// Parameters:
+//
+//   - ctx: the context
+//
+// Returns:
+//
+//   - error: the context error if the context is done first, or ErrClosedConnectionManager if the connection manager
+//     is closed
+func (c *ConnectionManager) WaitConnected(ctx context.Context) error {
+	// Check if the connection manager is nil
+	if c == nil {
+		return ErrNilConnection
+	}
+
+	c.mutex.RLock()
+	connectedCh := c.connectedCh
+	c.mutex.RUnlock()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-c.closeCh:
+		return ErrClosedConnectionManager
+	case <-connectedCh:
+		return nil
+	}
+}
+
+// Close closes the connection and stops re-establishing it
+//
+// Returns:
+//
+//   - error: an error if the connection could not be closed
+func (c *ConnectionManager) Close() error {
+	// Check if the connection manager is nil
+	if c == nil {
+		return ErrNilConnection
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	// Check if the connection manager is already closed
+	if c.state == Closed {
+		return nil
+	}
+	c.state = Closed
+	close(c.closeCh)
+
+	// Close the connection
+	if c.conn == nil {
+		return nil
+	}
+	if err := c.conn.Close(); err != nil && !errors.Is(err, amqp091.ErrClosed) {
+		return err
+	}
+	if c.logger != nil {
+		c.logger.Info("Connection closed")
+	}
+	return nil
+}
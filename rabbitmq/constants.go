@@ -0,0 +1,31 @@
+package rabbitmq
+
+import (
+	"time"
+)
+
+var (
+	// DefaultReconnectInitialBackoff is the default wait before the first reconnection attempt, doubled on each failed
+	// attempt
+	DefaultReconnectInitialBackoff = 500 * time.Millisecond
+
+	// DefaultReconnectMaxBackoff is the default maximum wait between reconnection attempts
+	DefaultReconnectMaxBackoff = 30 * time.Second
+)
+
+const (
+	// RetryCountHeader is the header that counts the redeliveries of a tokens message
+	RetryCountHeader = "x-retry-count"
+
+	// DeadLetterReasonHeader is the header that holds the reason why a tokens message was dead-lettered
+	DeadLetterReasonHeader = "x-dead-letter-reason"
+
+	// DeadLetterExchangeSuffix is the suffix appended to the queue name to get its dead-letter exchange name
+	DeadLetterExchangeSuffix = ".dlx"
+
+	// TopicWildcard is the binding key that matches every routing key of a topic exchange
+	TopicWildcard = "#"
+
+	// DeadLetterQueueSuffix is the suffix appended to the queue name to get its dead-letter queue name
+	DeadLetterQueueSuffix = ".dlq"
+)
@@ -5,9 +5,13 @@ import (
 )
 
 var (
-	// DefaultTickerInterval is the default interval for the ticker in the consumer
-	DefaultTickerInterval = 1 * time.Second
-
 	// DefaultTokensMessageConsumerChannelBufferSize is the default buffer size for the tokens message consumer channel
 	DefaultTokensMessageConsumerChannelBufferSize = 100
+
+	// DefaultPrefetchCount is the default number of unacknowledged deliveries the broker sends to the consumer
+	DefaultPrefetchCount = 10
+
+	// DefaultConfirmTimeout is the default time to wait for the broker to confirm a republished or dead-lettered
+	// message
+	DefaultConfirmTimeout = 5 * time.Second
 )
@@ -0,0 +1,64 @@
+package consumer
+
+import (
+	"github.com/rabbitmq/amqp091-go"
+	gojwtrabbitmq "github.com/ralvarezdev/go-jwt/rabbitmq"
+	gojwttokensync "github.com/ralvarezdev/go-jwt/tokensync"
+)
+
+type (
+	// Delivery is a tokens message delivered by the broker, which must be acknowledged once applied
+	Delivery struct {
+		Message  *gojwttokensync.TokensMessage
+		Envelope *gojwttokensync.TokensMessageEnvelope
+		delivery amqp091.Delivery
+		consumer *DefaultTokensMessagesConsumer
+	}
+)
+
+// GetEnvelope returns the envelope of the delivered tokens message
+//
+// Returns:
+//
+//   - *gojwttokensync.TokensMessageEnvelope: the envelope
+func (d *Delivery) GetEnvelope() *gojwttokensync.TokensMessageEnvelope {
+	// Check if the delivery is nil
+	if d == nil {
+		return nil
+	}
+	return d.Envelope
+}
+
+// Ack remembers the message ID of the applied tokens message to discard its duplicates, and acknowledges the
+// delivery, so the broker removes the message from the queue
+//
+// Returns:
+//
+//   - error: an error if the delivery could not be acknowledged
+func (d *Delivery) Ack() error {
+	// Check if the delivery is nil
+	if d == nil {
+		return gojwtrabbitmq.ErrNilMessage
+	}
+
+	d.consumer.markApplied(d.Envelope)
+	return d.delivery.Ack(false)
+}
+
+// Nack rejects the delivery of a tokens message that could not be applied. The message is redelivered until the
+// maximum number of redeliveries is reached, and then routed to the dead-letter exchange
+//
+// Parameters:
+//
+//   - cause: the error that prevented the message from being applied
+//
+// Returns:
+//
+//   - error: an error if the delivery could not be rejected
+func (d *Delivery) Nack(cause error) error {
+	// Check if the delivery is nil
+	if d == nil {
+		return gojwtrabbitmq.ErrNilMessage
+	}
+	return d.consumer.redeliver(d.delivery, cause)
+}
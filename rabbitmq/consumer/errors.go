@@ -5,5 +5,6 @@ import (
 )
 
 var (
-	ErrMissingJTI = errors.New("missing jti")
+	ErrMissingJTI            = errors.New("missing jti")
+	ErrRepublishNotConfirmed = errors.New("rabbitmq republished message not confirmed by the broker")
 )
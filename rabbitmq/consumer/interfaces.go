@@ -1,32 +1,17 @@
 package consumer
 
 import (
-	"context"
-
-	gojwtrabbitmq "github.com/ralvarezdev/go-jwt/rabbitmq"
-	gojwttokenclaims "github.com/ralvarezdev/go-jwt/token/claims"
+	gojwttokensync "github.com/ralvarezdev/go-jwt/tokensync"
 )
 
 type (
-	// TokensMessagesConsumer is the interface for the JWT RabbitMQ tokens messages consumer
-	TokensMessagesConsumer interface {
-		GetChannel() <-chan *gojwtrabbitmq.TokensMessage
-		ConsumeTokensMessages(ctx context.Context) error
-	}
+	// TokensMessagesConsumer is the interface for the JWT RabbitMQ tokens messages consumer, which is the
+	// transport-neutral subscription interface
+	TokensMessagesConsumer = gojwttokensync.TokensMessagesConsumer
 
-	// Consumer is the interface for the JWT RabbitMQ consumer
-	Consumer interface {
-		Open() error
-		Close() error
-		CreateTokensMessagesConsumer(ctx context.Context) (
-			TokensMessagesConsumer,
-			error,
-		)
-	}
+	// Consumer is the interface for the JWT RabbitMQ consumer, which is the transport-neutral consumer interface
+	Consumer = gojwttokensync.Consumer
 
-	// Service is the interface for the SQLite service for JWT IDs
-	Service interface {
-		gojwttokenclaims.TokenValidator
-		Start(ctx context.Context) error
-	}
+	// Service is the interface for the service for JWT IDs, which is the transport-neutral service interface
+	Service = gojwttokensync.Service
 )
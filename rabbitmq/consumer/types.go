@@ -2,34 +2,69 @@ package consumer
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
 	"log/slog"
 	"sync"
 	"time"
 
 	"github.com/rabbitmq/amqp091-go"
 	gojwtrabbitmq "github.com/ralvarezdev/go-jwt/rabbitmq"
+	gojwttokensync "github.com/ralvarezdev/go-jwt/tokensync"
 )
 
 type (
+	// Options are the options for the DefaultConsumer
+	Options struct {
+		// BufferSize is the buffer size of the deliveries channel
+		BufferSize int
+
+		// PrefetchCount is the number of unacknowledged deliveries the broker sends to the consumer
+		PrefetchCount int
+
+		// MaxRedeliveries is the number of times a tokens message that could not be applied is redelivered before
+		// being dead-lettered (optional, gojwttokensync.DefaultMaxRedeliveries is used if zero, and it is never
+		// redelivered if negative)
+		MaxRedeliveries int
+
+		// Topology is the routing topology of the messages (optional, the work queue mode is used by default)
+		Topology gojwtrabbitmq.Topology
+
+		// Deduplicator discards the deliveries of already applied messages (optional, a
+		// gojwttokensync.MemoryDeduplicator is used if nil)
+		Deduplicator gojwttokensync.Deduplicator
+
+		// ConfirmTimeout is the time to wait for the broker to confirm a republished or dead-lettered message, after
+		// which the delivery is requeued (optional, DefaultConfirmTimeout is used if not positive)
+		ConfirmTimeout time.Duration
+	}
+
 	// DefaultConsumer is the default implementation of the Consumer interface
 	DefaultConsumer struct {
-		conn                                    *amqp091.Connection
-		ch                                      *amqp091.Channel
-		queue                                   *amqp091.Queue
-		queueName                               string
-		logger                                  *slog.Logger
-		period                                  time.Duration
-		mutex                                   sync.Mutex
-		tokensMessagesConsumerChannelBufferSize int
+		conn            gojwtrabbitmq.Connection
+		ch              *amqp091.Channel
+		queue           *amqp091.Queue
+		queueName       string
+		topology        gojwtrabbitmq.Topology
+		logger          *slog.Logger
+		mutex           sync.Mutex
+		bufferSize      int
+		prefetchCount   int
+		maxRedeliveries int
+		deduplicator    gojwttokensync.Deduplicator
+		confirmTimeout  time.Duration
 	}
 
 	// DefaultTokensMessagesConsumer is the default implementation of the TokensMessagesConsumer interface
 	DefaultTokensMessagesConsumer struct {
-		period           time.Duration
-		deliveryCh       <-chan amqp091.Delivery
-		tokensMessagesCh chan *gojwtrabbitmq.TokensMessage
-		logger           *slog.Logger
+		ch                 *amqp091.Channel
+		queueName          string
+		deadLetterExchange string
+		maxRedeliveries    int
+		deduplicator       gojwttokensync.Deduplicator
+		confirmTimeout     time.Duration
+		deliveryCh         <-chan amqp091.Delivery
+		deliveriesCh       chan gojwttokensync.Delivery
+		logger             *slog.Logger
 	}
 )
 
@@ -37,21 +72,20 @@ type (
 //
 // Parameters:
 //
-//   - conn: the RabbitMQ connection
-//   - queueName: the name of the queue
-//   - period: the polling period
-//   - tokensMessagesConsumerChannelBufferSize: the buffer size for the messages channel
+//   - conn: the RabbitMQ connection, such as a ConnectionManager that re-establishes it
+//   - queueName: the name of the queue, which also names the dead-letter queue when the consumer instances bind their
+//     own exclusive queue
+//   - options: the options (optional, can be nil)
 //   - logger: the logger
 //
 // Returns:
 //
 //   - *DefaultConsumer: the DefaultConsumer instance
-//   - error: an error if the connection is nil
+//   - error: an error if the connection is nil, the queue name is empty or the topology is invalid
 func NewDefaultConsumer(
-	conn *amqp091.Connection,
+	conn gojwtrabbitmq.Connection,
 	queueName string,
-	period time.Duration,
-	tokensMessagesConsumerChannelBufferSize int,
+	options *Options,
 	logger *slog.Logger,
 ) (*DefaultConsumer, error) {
 	// Check if the connection is nil
@@ -64,14 +98,38 @@ func NewDefaultConsumer(
 		return nil, gojwtrabbitmq.ErrEmptyQueueName
 	}
 
-	// Check if the period is valid
-	if period <= 0 {
-		period = DefaultTickerInterval
+	// Set the options
+	if options == nil {
+		options = &Options{}
+	}
+
+	// Check if the topology is valid
+	if err := options.Topology.Validate(); err != nil {
+		return nil, err
+	}
+
+	bufferSize := options.BufferSize
+	if bufferSize <= 0 {
+		bufferSize = DefaultTokensMessageConsumerChannelBufferSize
+	}
+
+	prefetchCount := options.PrefetchCount
+	if prefetchCount <= 0 {
+		prefetchCount = DefaultPrefetchCount
+	}
+
+	maxRedeliveries := gojwttokensync.GetMaxRedeliveries(options.MaxRedeliveries)
+
+	deduplicator := options.Deduplicator
+	if deduplicator == nil {
+		deduplicator = gojwttokensync.NewMemoryDeduplicator(
+			gojwttokensync.DefaultDeduplicationCacheSize,
+		)
 	}
 
-	// Check if the consumerMessagesBufferSize is valid
-	if tokensMessagesConsumerChannelBufferSize <= 0 {
-		tokensMessagesConsumerChannelBufferSize = DefaultTokensMessageConsumerChannelBufferSize
+	confirmTimeout := options.ConfirmTimeout
+	if confirmTimeout <= 0 {
+		confirmTimeout = DefaultConfirmTimeout
 	}
 
 	if logger != nil {
@@ -82,11 +140,15 @@ func NewDefaultConsumer(
 
 	// Create a new consumer instance
 	consumer := &DefaultConsumer{
-		conn:                                    conn,
-		logger:                                  logger,
-		queueName:                               queueName,
-		period:                                  period,
-		tokensMessagesConsumerChannelBufferSize: tokensMessagesConsumerChannelBufferSize,
+		conn:            conn,
+		logger:          logger,
+		queueName:       queueName,
+		topology:        options.Topology,
+		bufferSize:      bufferSize,
+		prefetchCount:   prefetchCount,
+		maxRedeliveries: maxRedeliveries,
+		deduplicator:    deduplicator,
+		confirmTimeout:  confirmTimeout,
 	}
 	return consumer, nil
 }
@@ -95,34 +157,63 @@ func NewDefaultConsumer(
 //
 // Parameters:
 //
+//   - ch: the RabbitMQ channel in confirm mode, used to republish and dead-letter the tokens messages
 //   - deliveryCh: the RabbitMQ delivery channel
-//   - bufferSize: the buffer size for the messages channel
-//   - period: the polling period
+//   - queueName: the name of the consumed queue
+//   - deadLetterExchange: the name of the dead-letter exchange
+//   - bufferSize: the buffer size for the deliveries channel
+//   - maxRedeliveries: the number of redeliveries of a tokens message before being dead-lettered
+//   - deduplicator: the store of the applied message IDs (optional, the duplicates are not discarded if nil)
+//   - confirmTimeout: the time to wait for the broker to confirm a republished or dead-lettered message (optional,
+//     DefaultConfirmTimeout is used if not positive)
 //   - logger: the logger
 //
 // Returns:
 //
 //   - *DefaultTokensMessagesConsumer: the DefaultTokensMessagesConsumer instance
-//   - error: an error if the delivery channel is nil
+//   - error: an error if the channel or the delivery channel is nil
 func NewDefaultTokensMessagesConsumer(
+	ch *amqp091.Channel,
 	deliveryCh <-chan amqp091.Delivery,
+	queueName string,
+	deadLetterExchange string,
 	bufferSize int,
-	period time.Duration,
+	maxRedeliveries int,
+	deduplicator gojwttokensync.Deduplicator,
+	confirmTimeout time.Duration,
 	logger *slog.Logger,
 ) (*DefaultTokensMessagesConsumer, error) {
+	// Check if the channel is nil
+	if ch == nil {
+		return nil, gojwtrabbitmq.ErrNilChannel
+	}
+
 	// Check if the delivery channel is nil
 	if deliveryCh == nil {
 		return nil, gojwtrabbitmq.ErrNilDeliveryChannel
 	}
 
+	// Check if the queue or the dead-letter exchange name is empty
+	if queueName == "" {
+		return nil, gojwtrabbitmq.ErrEmptyQueueName
+	}
+	if deadLetterExchange == "" {
+		return nil, gojwtrabbitmq.ErrEmptyExchangeName
+	}
+
 	// Check if the buffer size is valid
 	if bufferSize <= 0 {
 		bufferSize = DefaultTokensMessageConsumerChannelBufferSize
 	}
 
-	// Check if the period is valid
-	if period <= 0 {
-		period = DefaultTickerInterval
+	// Check if the maximum number of redeliveries is valid
+	if maxRedeliveries < 0 {
+		maxRedeliveries = 0
+	}
+
+	// Check if the confirm timeout is valid
+	if confirmTimeout <= 0 {
+		confirmTimeout = DefaultConfirmTimeout
 	}
 
 	if logger != nil {
@@ -132,61 +223,102 @@ func NewDefaultTokensMessagesConsumer(
 	}
 
 	return &DefaultTokensMessagesConsumer{
-		deliveryCh:       deliveryCh,
-		tokensMessagesCh: make(chan *gojwtrabbitmq.TokensMessage, bufferSize),
-		period:           period,
-		logger:           logger,
+		ch:                 ch,
+		queueName:          queueName,
+		deadLetterExchange: deadLetterExchange,
+		maxRedeliveries:    maxRedeliveries,
+		deduplicator:       deduplicator,
+		confirmTimeout:     confirmTimeout,
+		deliveryCh:         deliveryCh,
+		deliveriesCh:       make(chan gojwttokensync.Delivery, bufferSize),
+		logger:             logger,
 	}, nil
 }
 
-// Open opens a RabbitMQ channel
+// open opens a RabbitMQ channel, the mutex must be held by the caller
 //
 // Returns:
 //
 //   - error: an error if the channel could not be opened
-func (d *DefaultConsumer) Open() error {
-	// Check if the consumer is nil
-	if d == nil {
-		return gojwtrabbitmq.ErrNilConsumer
-	}
-
-	// Lock the mutex to ensure thread safety
-	d.mutex.Lock()
-	defer d.mutex.Unlock()
-
+func (d *DefaultConsumer) open() error {
 	// Check if the consumer is already open
-	if d.ch != nil {
+	if d.ch != nil && !d.ch.IsClosed() {
 		return nil
 	}
 
 	// Create the channel
 	ch, err := d.conn.Channel()
 	if err != nil {
-		d.logger.Error(
-			"Failed to open a channel",
-			slog.String("error", err.Error()),
-		)
+		if d.logger != nil {
+			d.logger.Error(
+				"Failed to open a channel",
+				slog.String("error", err.Error()),
+			)
+		}
 		return err
 	}
 
-	// Set the channel
-	d.ch = ch
+	// Limit the unacknowledged deliveries and confirm the republished messages
+	if err = ch.Qos(d.prefetchCount, 0, false); err != nil {
+		_ = ch.Close()
+		return err
+	}
+	if err = ch.Confirm(false); err != nil {
+		_ = ch.Close()
+		return err
+	}
 
-	// Declare the queue
-	q, err := gojwtrabbitmq.DeclareTokensMessageQueue(d.ch, d.queueName)
-	if err != nil {
-		d.logger.Error(
-			"Failed to declare a queue",
-			slog.String("queue_name", d.queueName),
-			slog.String("error", err.Error()),
+	// Declare the queue and its dead-letter queue
+	q, err := gojwtrabbitmq.DeclareTokensMessageConsumerQueue(
+		ch,
+		d.queueName,
+		d.topology,
+	)
+	if err == nil {
+		_, err = gojwtrabbitmq.DeclareTokensMessageDeadLetterQueue(
+			ch,
+			d.queueName,
 		)
+	}
+	if err != nil {
+		if d.logger != nil {
+			d.logger.Error(
+				"Failed to declare a queue",
+				slog.String("queue_name", d.queueName),
+				slog.String("error", err.Error()),
+			)
+		}
+		_ = ch.Close()
 		return err
 	}
+
+	// Set the channel
+	d.ch = ch
 	d.queue = q
-	d.logger.Info("Consumer channel opened")
+	if d.logger != nil {
+		d.logger.Info("Consumer channel opened")
+	}
 	return nil
 }
 
+// Open opens a RabbitMQ channel
+//
+// Returns:
+//
+//   - error: an error if the channel could not be opened
+func (d *DefaultConsumer) Open() error {
+	// Check if the consumer is nil
+	if d == nil {
+		return gojwtrabbitmq.ErrNilConsumer
+	}
+
+	// Lock the mutex to ensure thread safety
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	return d.open()
+}
+
 // Close closes the RabbitMQ channel and connection
 //
 // Returns:
@@ -207,16 +339,20 @@ func (d *DefaultConsumer) Close() error {
 	}
 
 	// Close the channel
-	if err := d.ch.Close(); err != nil {
-		d.logger.Error(
-			"Failed to close channel", slog.String("error", err.Error()),
-		)
+	if err := d.ch.Close(); err != nil && !errors.Is(err, amqp091.ErrClosed) {
+		if d.logger != nil {
+			d.logger.Error(
+				"Failed to close channel", slog.String("error", err.Error()),
+			)
+		}
 		return err
 	}
 
 	// Set the channel to nil
 	d.ch = nil
-	d.logger.Info("Consumer channel closed")
+	if d.logger != nil {
+		d.logger.Info("Consumer channel closed")
+	}
 	return nil
 }
 
@@ -243,10 +379,8 @@ func (d *DefaultConsumer) CreateTokensMessagesConsumer(ctx context.Context) (
 	defer d.mutex.Unlock()
 
 	// Ensure the channel is open
-	if d.ch == nil {
-		if err := d.Open(); err != nil {
-			return nil, err
-		}
+	if err := d.open(); err != nil {
+		return nil, err
 	}
 
 	// Create a channel to receive messages
@@ -261,9 +395,14 @@ func (d *DefaultConsumer) CreateTokensMessagesConsumer(ctx context.Context) (
 
 	// Create the tokens messages consumer
 	consumer, err := NewDefaultTokensMessagesConsumer(
+		d.ch,
 		deliveryCh,
-		d.tokensMessagesConsumerChannelBufferSize,
-		d.period,
+		d.queue.Name,
+		gojwtrabbitmq.DeadLetterExchangeName(d.queueName),
+		d.bufferSize,
+		d.maxRedeliveries,
+		d.deduplicator,
+		d.confirmTimeout,
 		d.logger,
 	)
 	if err != nil {
@@ -272,27 +411,30 @@ func (d *DefaultConsumer) CreateTokensMessagesConsumer(ctx context.Context) (
 	return consumer, nil
 }
 
-// GetChannel returns the tokens message consumer channel
+// GetChannel returns the tokens messages deliveries channel
 //
 // Returns:
 //
-//   - <-chan gojwtrabbitmq.TokensMessage: the tokens message consumer channel
-func (d DefaultTokensMessagesConsumer) GetChannel() <-chan *gojwtrabbitmq.TokensMessage {
-	return d.tokensMessagesCh
+//   - <-chan gojwttokensync.Delivery: the tokens messages deliveries channel
+func (d *DefaultTokensMessagesConsumer) GetChannel() <-chan gojwttokensync.Delivery {
+	return d.deliveriesCh
 }
 
-// ConsumeTokensMessages consumes a tokens message and sends it to the channel
+// ConsumeTokensMessages decodes the delivered tokens messages and sends them to the deliveries channel. The messages
+// that cannot be decoded, such as the ones of an unknown major version, are dead-lettered, and the duplicates of the
+// applied messages are acknowledged without being sent
 //
 // Parameters:
 //
 //   - ctx: the context
 //
 // Returns:
-func (d DefaultTokensMessagesConsumer) ConsumeTokensMessages(
+//
+//   - error: the context error if the context is done, or an error if the delivery channel was closed
+func (d *DefaultTokensMessagesConsumer) ConsumeTokensMessages(
 	ctx context.Context,
 ) error {
-	// Create the ticker to poll the queue periodically
-	ticker := time.NewTicker(d.period)
+	defer close(d.deliveriesCh)
 
 	for {
 		select {
@@ -301,23 +443,215 @@ func (d DefaultTokensMessagesConsumer) ConsumeTokensMessages(
 				d.logger.Info("Context done. Exiting consume loop.")
 			}
 			return ctx.Err()
-		case <-ticker.C:
-			// Poll the queue for messages
-			for msg := range d.deliveryCh {
-				var parsedMsg gojwtrabbitmq.TokensMessage
-				if err := json.Unmarshal(msg.Body, &parsedMsg); err != nil {
-					if d.logger != nil {
-						d.logger.Error(
-							"Error decoding message",
-							slog.String("error", err.Error()),
-						)
-					}
-					continue
+		case msg, ok := <-d.deliveryCh:
+			// Check if the delivery channel was closed by the broker
+			if !ok {
+				return gojwtrabbitmq.ErrClosedDeliveryChannel
+			}
+
+			// Decode the message
+			envelope, err := gojwttokensync.UnmarshalTokensMessageEnvelope(
+				msg.Body,
+				msg.ContentType,
+			)
+			if err != nil {
+				if d.logger != nil {
+					d.logger.Error(
+						"Error decoding message",
+						slog.String("error", err.Error()),
+					)
+				}
+				if err = d.deadLetter(msg, err); err != nil {
+					return err
+				}
+				continue
+			}
+
+			// Discard the duplicates
+			if d.isDuplicate(envelope) {
+				if d.logger != nil {
+					d.logger.Debug(
+						"Discarding duplicated message",
+						slog.String("message_id", envelope.MessageID),
+					)
 				}
+				if err = msg.Ack(false); err != nil {
+					return err
+				}
+				continue
+			}
 
-				// Send the message to the channel
-				d.tokensMessagesCh <- &parsedMsg
+			// Send the delivery to the channel
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case d.deliveriesCh <- &Delivery{
+				Message:  envelope.Message,
+				Envelope: envelope,
+				delivery: msg,
+				consumer: d,
+			}:
 			}
 		}
 	}
 }
+
+// redeliver republishes a tokens message that could not be applied with its retry count increased, or dead-letters it
+// once the maximum number of redeliveries is reached
+//
+// Parameters:
+//
+//   - delivery: the delivery
+//   - cause: the error that prevented the message from being applied
+//
+// Returns:
+//
+//   - error: an error if the message could not be republished
+func (d *DefaultTokensMessagesConsumer) redeliver(
+	delivery amqp091.Delivery,
+	cause error,
+) error {
+	// Check if the redeliveries ran out
+	retryCount := getRetryCount(delivery.Headers)
+	if retryCount >= d.maxRedeliveries {
+		return d.deadLetter(delivery, cause)
+	}
+
+	if d.logger != nil {
+		d.logger.Warn(
+			"Redelivering message",
+			slog.Int("retry_count", retryCount+1),
+			slog.String("error", cause.Error()),
+		)
+	}
+
+	// Republish the message directly to the consumed queue, so the other bound queues do not receive it again
+	headers := cloneHeaders(delivery.Headers)
+	headers[gojwtrabbitmq.RetryCountHeader] = int32(retryCount + 1)
+	return d.republish(delivery, "", d.queueName, headers)
+}
+
+// deadLetter routes a tokens message to the dead-letter exchange
+//
+// Parameters:
+//
+//   - delivery: the delivery
+//   - cause: the error that prevented the message from being processed
+//
+// Returns:
+//
+//   - error: an error if the message could not be dead-lettered
+func (d *DefaultTokensMessagesConsumer) deadLetter(
+	delivery amqp091.Delivery,
+	cause error,
+) error {
+	if d.logger != nil {
+		d.logger.Error(
+			"Dead-lettering message",
+			slog.String("message_id", delivery.MessageId),
+			slog.String("error", cause.Error()),
+		)
+	}
+
+	headers := cloneHeaders(delivery.Headers)
+	headers[gojwtrabbitmq.DeadLetterReasonHeader] = cause.Error()
+	return d.republish(
+		delivery,
+		d.deadLetterExchange,
+		"",
+		headers,
+	)
+}
+
+// republish publishes a copy of the delivered message and acknowledges the delivery once the broker confirms the copy.
+// If the copy is not confirmed within the confirm timeout, the delivery is requeued
+//
+// Parameters:
+//
+//   - delivery: the delivery
+//   - exchange: the exchange of the copy
+//   - key: the routing key of the copy
+//   - headers: the headers of the copy
+//
+// Returns:
+//
+//   - error: an error if the delivery could not be acknowledged or requeued
+func (d *DefaultTokensMessagesConsumer) republish(
+	delivery amqp091.Delivery,
+	exchange string,
+	key string,
+	headers amqp091.Table,
+) error {
+	ctx, cancel := context.WithTimeout(context.Background(), d.confirmTimeout)
+	defer cancel()
+
+	confirmation, err := d.ch.PublishWithDeferredConfirmWithContext(
+		ctx,
+		exchange,
+		key,
+		false,
+		false,
+		amqp091.Publishing{
+			Headers:         headers,
+			ContentType:     delivery.ContentType,
+			ContentEncoding: delivery.ContentEncoding,
+			DeliveryMode:    amqp091.Persistent,
+			MessageId:       delivery.MessageId,
+			Timestamp:       delivery.Timestamp,
+			Type:            delivery.Type,
+			AppId:           delivery.AppId,
+			Body:            delivery.Body,
+		},
+	)
+	if err == nil {
+		// Wait for the broker to confirm the copy
+		var acked bool
+		acked, err = confirmation.WaitContext(ctx)
+		if err == nil && !acked {
+			err = ErrRepublishNotConfirmed
+		}
+	}
+	if err != nil {
+		if d.logger != nil {
+			d.logger.Error(
+				"Failed to republish message, requeuing it",
+				slog.String("error", err.Error()),
+			)
+		}
+		return delivery.Nack(false, true)
+	}
+	return delivery.Ack(false)
+}
+
+// isDuplicate checks if the message of an envelope was already applied
+//
+// Parameters:
+//
+//   - envelope: the tokens message envelope
+//
+// Returns:
+//
+//   - bool: true if the message was already applied
+func (d *DefaultTokensMessagesConsumer) isDuplicate(
+	envelope *gojwttokensync.TokensMessageEnvelope,
+) bool {
+	// The messages of the unversioned format do not have an ID
+	if d.deduplicator == nil || envelope.MessageID == "" {
+		return false
+	}
+	return d.deduplicator.IsDuplicate(envelope.MessageID)
+}
+
+// markApplied remembers the message ID of an applied envelope
+//
+// Parameters:
+//
+//   - envelope: the tokens message envelope
+func (d *DefaultTokensMessagesConsumer) markApplied(
+	envelope *gojwttokensync.TokensMessageEnvelope,
+) {
+	if d.deduplicator == nil || envelope == nil || envelope.MessageID == "" {
+		return
+	}
+	d.deduplicator.MarkApplied(envelope.MessageID)
+}
@@ -0,0 +1,49 @@
+package consumer
+
+import (
+	"maps"
+
+	"github.com/rabbitmq/amqp091-go"
+	gojwtrabbitmq "github.com/ralvarezdev/go-jwt/rabbitmq"
+)
+
+// getRetryCount gets the number of redeliveries of a tokens message from its headers
+//
+// Parameters:
+//
+//   - headers: the message headers
+//
+// Returns:
+//
+//   - int: the number of redeliveries, 0 if the header is missing
+func getRetryCount(headers amqp091.Table) int {
+	switch value := headers[gojwtrabbitmq.RetryCountHeader].(type) {
+	case int8:
+		return int(value)
+	case int16:
+		return int(value)
+	case int32:
+		return int(value)
+	case int64:
+		return int(value)
+	case int:
+		return value
+	default:
+		return 0
+	}
+}
+
+// cloneHeaders clones the headers of a message
+//
+// Parameters:
+//
+//   - headers: the message headers
+//
+// Returns:
+//
+//   - amqp091.Table: the cloned headers, never nil
+func cloneHeaders(headers amqp091.Table) amqp091.Table {
+	cloned := make(amqp091.Table, len(headers)+1)
+	maps.Copy(cloned, headers)
+	return cloned
+}
@@ -2,14 +2,22 @@ package rabbitmq
 
 import (
 	"errors"
+
+	gojwttokensync "github.com/ralvarezdev/go-jwt/tokensync"
 )
 
 var (
-	ErrNilConnection      = errors.New("nil rabbitmq connection")
-	ErrEmptyQueueName     = errors.New("empty queue name")
-	ErrNilChannel         = errors.New("nil rabbitmq channel")
-	ErrNilPublisher       = errors.New("nil rabbitmq publisher")
-	ErrNilConsumer        = errors.New("nil rabbitmq consumer")
-	ErrNilMessage         = errors.New("nil rabbitmq message")
-	ErrNilDeliveryChannel = errors.New("nil rabbitmq delivery channel")
+	ErrNilConnection           = errors.New("nil rabbitmq connection")
+	ErrEmptyQueueName          = errors.New("empty queue name")
+	ErrNilChannel              = errors.New("nil rabbitmq channel")
+	ErrNilPublisher            = gojwttokensync.ErrNilPublisher
+	ErrNilConsumer             = gojwttokensync.ErrNilConsumer
+	ErrNilMessage              = gojwttokensync.ErrNilMessage
+	ErrNilDeliveryChannel      = errors.New("nil rabbitmq delivery channel")
+	ErrEmptyExchangeName       = errors.New("empty exchange name")
+	ErrUnknownTopologyMode     = errors.New("unknown rabbitmq topology mode")
+	ErrEmptyURL                = errors.New("empty rabbitmq url")
+	ErrNotConnected            = errors.New("rabbitmq connection not established")
+	ErrClosedConnectionManager = errors.New("closed rabbitmq connection manager")
+	ErrClosedDeliveryChannel   = errors.New("closed rabbitmq delivery channel")
 )
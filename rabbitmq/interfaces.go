@@ -0,0 +1,13 @@
+package rabbitmq
+
+import (
+	"github.com/rabbitmq/amqp091-go"
+)
+
+type (
+	// Connection is the interface for a RabbitMQ connection that opens channels, implemented by *amqp091.Connection
+	// and by the ConnectionManager
+	Connection interface {
+		Channel() (*amqp091.Channel, error)
+	}
+)
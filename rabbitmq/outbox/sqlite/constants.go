@@ -0,0 +1,58 @@
+package sqlite
+
+const (
+	// CreateTokensMessagesOutboxTableQuery is the SQL query to create the tokens_messages_outbox table
+	CreateTokensMessagesOutboxTableQuery = `
+CREATE TABLE IF NOT EXISTS tokens_messages_outbox (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	body TEXT NOT NULL,
+	created_at DATETIME NOT NULL,
+	sent_at DATETIME
+);
+`
+
+	// CheckTokensMessagesOutboxSentAtColumnQuery is the SQL query to check if the sent_at column exists, since the
+	// tables created by previous versions do not have it
+	CheckTokensMessagesOutboxSentAtColumnQuery = `
+SELECT COUNT(1) FROM pragma_table_info('tokens_messages_outbox') WHERE name = 'sent_at';
+`
+
+	// AddTokensMessagesOutboxSentAtColumnQuery is the SQL query to add the sent_at column to the tables created by
+	// previous versions
+	AddTokensMessagesOutboxSentAtColumnQuery = `
+ALTER TABLE tokens_messages_outbox ADD COLUMN sent_at DATETIME;
+`
+
+	// CreateTokensMessagesOutboxUnsentIndexQuery is the SQL query to create the index used to list the unsent tokens
+	// messages
+	CreateTokensMessagesOutboxUnsentIndexQuery = `
+CREATE INDEX IF NOT EXISTS tokens_messages_outbox_unsent_idx ON tokens_messages_outbox (id) WHERE sent_at IS NULL;
+`
+)
+
+var (
+	// InsertTokensMessageQuery is the SQL query to insert a tokens message
+	InsertTokensMessageQuery = `
+INSERT INTO tokens_messages_outbox (body, created_at) VALUES (?, ?);
+`
+
+	// DeleteTokensMessageQuery is the SQL query to delete a tokens message
+	DeleteTokensMessageQuery = `
+DELETE FROM tokens_messages_outbox WHERE id = ?;
+`
+
+	// MarkTokensMessageSentQuery is the SQL query to mark a tokens message as sent
+	MarkTokensMessageSentQuery = `
+UPDATE tokens_messages_outbox SET sent_at = ? WHERE id = ? AND sent_at IS NULL;
+`
+
+	// DeleteSentTokensMessagesBeforeQuery is the SQL query to delete the tokens messages sent before a given time
+	DeleteSentTokensMessagesBeforeQuery = `
+DELETE FROM tokens_messages_outbox WHERE sent_at IS NOT NULL AND sent_at < ?;
+`
+
+	// ListTokensMessagesQuery is the SQL query to list the oldest unsent tokens messages
+	ListTokensMessagesQuery = `
+SELECT id, body, created_at FROM tokens_messages_outbox WHERE sent_at IS NULL ORDER BY id LIMIT ?;
+`
+)
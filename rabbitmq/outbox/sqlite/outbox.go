@@ -0,0 +1,404 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"log/slog"
+	"time"
+
+	godatabases "github.com/ralvarezdev/go-databases"
+	godatabasessql "github.com/ralvarezdev/go-databases/sql"
+	gojwttokensync "github.com/ralvarezdev/go-jwt/tokensync"
+)
+
+type (
+	// Outbox is the SQLite implementation of the Outbox and TransactionalOutbox interfaces
+	Outbox struct {
+		godatabasessql.Service
+		logger *slog.Logger
+	}
+)
+
+// NewOutbox creates a new SQLite outbox
+//
+// Parameters:
+//
+//   - service: the SQL connection service
+//   - logger: the logger (optional, can be nil)
+//
+// Returns:
+//
+//   - *Outbox: the Outbox instance
+//   - error: an error if the service is nil
+func NewOutbox(
+	service godatabasessql.Service,
+	logger *slog.Logger,
+) (*Outbox, error) {
+	// Check if the service is nil
+	if service == nil {
+		return nil, godatabases.ErrNilService
+	}
+
+	if logger != nil {
+		logger = logger.With(
+			slog.String("component", "rabbitmq_outbox_sqlite"),
+		)
+	}
+
+	return &Outbox{
+		Service: service,
+		logger:  logger,
+	}, nil
+}
+
+// Connect opens the database connection
+//
+// Parameters:
+//
+//   - ctx: the context
+//
+// Returns:
+//
+//   - error: an error if the connection could not be opened
+func (o *Outbox) Connect(ctx context.Context) error {
+	// Check if the outbox is nil
+	if o == nil {
+		return gojwttokensync.ErrNilOutbox
+	}
+
+	// Connect to the database
+	db, err := o.Service.Connect()
+	if err != nil {
+		if o.logger != nil {
+			o.logger.Error(
+				"Failed to connect to database",
+				slog.String("error", err.Error()),
+			)
+		}
+		return err
+	}
+
+	// Ensure the table exists
+	if _, err = db.ExecContext(
+		ctx,
+		CreateTokensMessagesOutboxTableQuery,
+	); err != nil {
+		return err
+	}
+
+	// Add the sent_at column to the tables created by previous versions
+	var count int
+	if err = db.QueryRowContext(
+		ctx,
+		CheckTokensMessagesOutboxSentAtColumnQuery,
+	).Scan(&count); err != nil {
+		return err
+	}
+	if count == 0 {
+		if _, err = db.ExecContext(
+			ctx,
+			AddTokensMessagesOutboxSentAtColumnQuery,
+		); err != nil {
+			return err
+		}
+	}
+
+	// Ensure the index exists
+	if _, err = db.ExecContext(
+		ctx,
+		CreateTokensMessagesOutboxUnsentIndexQuery,
+	); err != nil {
+		return err
+	}
+	return nil
+}
+
+// Add adds a tokens message envelope to the outbox
+//
+// Parameters:
+//
+//   - ctx: the context
+//   - envelope: the tokens message envelope
+//
+// Returns:
+//
+//   - int64: the ID of the outbox entry
+//   - error: an error if the message could not be added
+func (o *Outbox) Add(
+	ctx context.Context,
+	envelope *gojwttokensync.TokensMessageEnvelope,
+) (int64, error) {
+	// Check if the outbox is nil
+	if o == nil {
+		return 0, gojwttokensync.ErrNilOutbox
+	}
+
+	// Marshal the envelope to JSON
+	body, err := marshalEnvelope(envelope)
+	if err != nil {
+		return 0, err
+	}
+
+	// Insert the message
+	result, err := o.ExecWithCtx(
+		ctx,
+		&InsertTokensMessageQuery,
+		body,
+		time.Now().UTC(),
+	)
+	if err != nil {
+		if o.logger != nil {
+			o.logger.Error(
+				"Failed to add tokens message to the outbox",
+				slog.String("error", err.Error()),
+			)
+		}
+		return 0, err
+	}
+	return result.LastInsertId()
+}
+
+// AddWithTx adds a tokens message envelope to the outbox within the given transaction, so the message is only stored
+// if the transaction is committed. The transaction must be of the database of the outbox
+//
+// Parameters:
+//
+//   - ctx: the context
+//   - tx: the transaction
+//   - envelope: the tokens message envelope
+//
+// Returns:
+//
+//   - int64: the ID of the outbox entry
+//   - error: an error if the message could not be added
+func (o *Outbox) AddWithTx(
+	ctx context.Context,
+	tx *sql.Tx,
+	envelope *gojwttokensync.TokensMessageEnvelope,
+) (int64, error) {
+	// Check if the outbox is nil
+	if o == nil {
+		return 0, gojwttokensync.ErrNilOutbox
+	}
+
+	// Check if the transaction is nil
+	if tx == nil {
+		return 0, gojwttokensync.ErrNilOutboxTransaction
+	}
+
+	// Marshal the envelope to JSON
+	body, err := marshalEnvelope(envelope)
+	if err != nil {
+		return 0, err
+	}
+
+	// Insert the message
+	result, err := tx.ExecContext(
+		ctx,
+		InsertTokensMessageQuery,
+		body,
+		time.Now().UTC(),
+	)
+	if err != nil {
+		if o.logger != nil {
+			o.logger.Error(
+				"Failed to add tokens message to the outbox within the transaction",
+				slog.String("error", err.Error()),
+			)
+		}
+		return 0, err
+	}
+	return result.LastInsertId()
+}
+
+// MarkSent marks a tokens message of the outbox as sent, so it is no longer listed
+//
+// Parameters:
+//
+//   - ctx: the context
+//   - id: the ID of the outbox entry
+//
+// Returns:
+//
+//   - error: an error if the message could not be marked
+func (o *Outbox) MarkSent(ctx context.Context, id int64) error {
+	// Check if the outbox is nil
+	if o == nil {
+		return gojwttokensync.ErrNilOutbox
+	}
+
+	// Update the message
+	if _, err := o.ExecWithCtx(
+		ctx,
+		&MarkTokensMessageSentQuery,
+		time.Now().UTC(),
+		id,
+	); err != nil {
+		if o.logger != nil {
+			o.logger.Error(
+				"Failed to mark tokens message as sent",
+				slog.Int64("id", id),
+				slog.String("error", err.Error()),
+			)
+		}
+		return err
+	}
+	return nil
+}
+
+// DeleteSentBefore deletes the tokens messages sent before the given time
+//
+// Parameters:
+//
+//   - ctx: the context
+//   - before: the time before which the messages were sent
+//
+// Returns:
+//
+//   - error: an error if the messages could not be deleted
+func (o *Outbox) DeleteSentBefore(ctx context.Context, before time.Time) error {
+	// Check if the outbox is nil
+	if o == nil {
+		return gojwttokensync.ErrNilOutbox
+	}
+
+	// Delete the messages
+	if _, err := o.ExecWithCtx(
+		ctx,
+		&DeleteSentTokensMessagesBeforeQuery,
+		before.UTC(),
+	); err != nil {
+		if o.logger != nil {
+			o.logger.Error(
+				"Failed to delete the sent tokens messages",
+				slog.String("error", err.Error()),
+			)
+		}
+		return err
+	}
+	return nil
+}
+
+// Remove removes a tokens message from the outbox
+//
+// Parameters:
+//
+//   - ctx: the context
+//   - id: the ID of the outbox entry
+//
+// Returns:
+//
+//   - error: an error if the message could not be removed
+func (o *Outbox) Remove(ctx context.Context, id int64) error {
+	// Check if the outbox is nil
+	if o == nil {
+		return gojwttokensync.ErrNilOutbox
+	}
+
+	// Delete the message
+	if _, err := o.ExecWithCtx(ctx, &DeleteTokensMessageQuery, id); err != nil {
+		if o.logger != nil {
+			o.logger.Error(
+				"Failed to remove tokens message from the outbox",
+				slog.Int64("id", id),
+				slog.String("error", err.Error()),
+			)
+		}
+		return err
+	}
+	return nil
+}
+
+// List lists the oldest unsent tokens messages of the outbox in insertion order
+//
+// Parameters:
+//
+//   - ctx: the context
+//   - limit: the maximum number of messages (optional, all the messages are listed if not positive)
+//
+// Returns:
+//
+//   - []gojwttokensync.OutboxEntry: the outbox entries
+//   - error: an error if the messages could not be listed
+func (o *Outbox) List(ctx context.Context, limit int) (
+	[]gojwttokensync.OutboxEntry,
+	error,
+) {
+	// Check if the outbox is nil
+	if o == nil {
+		return nil, gojwttokensync.ErrNilOutbox
+	}
+
+	// SQLite does not limit the rows with a negative limit
+	if limit <= 0 {
+		limit = -1
+	}
+
+	// Get the database connection
+	db, err := o.DB()
+	if err != nil {
+		return nil, err
+	}
+
+	// Query the messages
+	rows, err := db.QueryContext(ctx, ListTokensMessagesQuery, limit)
+	if err != nil {
+		if o.logger != nil {
+			o.logger.Error(
+				"Failed to list the outbox tokens messages",
+				slog.String("error", err.Error()),
+			)
+		}
+		return nil, err
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	var entries []gojwttokensync.OutboxEntry
+	for rows.Next() {
+		var (
+			entry gojwttokensync.OutboxEntry
+			body  string
+		)
+		if err = rows.Scan(&entry.ID, &body, &entry.CreatedAt); err != nil {
+			return nil, err
+		}
+
+		// Unmarshal the envelope
+		var envelope gojwttokensync.TokensMessageEnvelope
+		if err = json.Unmarshal([]byte(body), &envelope); err != nil {
+			return nil, err
+		}
+		entry.Envelope = &envelope
+		entries = append(entries, entry)
+	}
+	return entries, rows.Err()
+}
+
+// marshalEnvelope encodes a tokens message envelope to be stored in the outbox
+//
+// Parameters:
+//
+//   - envelope: the tokens message envelope
+//
+// Returns:
+//
+//   - string: the JSON-encoded envelope
+//   - error: an error if the envelope is nil or could not be encoded
+func marshalEnvelope(envelope *gojwttokensync.TokensMessageEnvelope) (
+	string,
+	error,
+) {
+	// Check if the envelope is nil
+	if envelope == nil || envelope.Message == nil {
+		return "", gojwttokensync.ErrNilMessage
+	}
+
+	body, err := json.Marshal(envelope)
+	if err != nil {
+		return "", err
+	}
+	return string(body), nil
+}
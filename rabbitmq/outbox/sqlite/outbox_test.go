@@ -0,0 +1,264 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	godatabasessql "github.com/ralvarezdev/go-databases/sql"
+	gojwttokensync "github.com/ralvarezdev/go-jwt/tokensync"
+	_ "modernc.org/sqlite"
+)
+
+// newTestOutbox creates an outbox connected to the given SQLite database file
+//
+// Parameters:
+//
+//   - t: The test
+//   - dataSourceName: The path of the database file
+//
+// Returns:
+//
+//   - *Outbox: The outbox
+func newTestOutbox(t *testing.T, dataSourceName string) *Outbox {
+	t.Helper()
+
+	service, err := godatabasessql.NewDefaultService(
+		&godatabasessql.Config{
+			DriverName:     "sqlite",
+			DataSourceName: dataSourceName,
+		},
+	)
+	if err != nil {
+		t.Fatalf("failed to create the service: %v", err)
+	}
+	outbox, err := NewOutbox(service, nil)
+	if err != nil {
+		t.Fatalf("failed to create the outbox: %v", err)
+	}
+	if err = outbox.Connect(context.Background()); err != nil {
+		t.Fatalf("failed to connect: %v", err)
+	}
+	t.Cleanup(
+		func() {
+			_ = outbox.Disconnect()
+		},
+	)
+	return outbox
+}
+
+// newTestEnvelope creates a tokens message envelope that revokes the given refresh token
+//
+// Parameters:
+//
+//   - id: The ID of the revoked refresh token, also used as the message ID
+//
+// Returns:
+//
+//   - *gojwttokensync.TokensMessageEnvelope: The envelope
+func newTestEnvelope(id string) *gojwttokensync.TokensMessageEnvelope {
+	return &gojwttokensync.TokensMessageEnvelope{
+		MessageID: id,
+		IssuedAt:  time.Now().UTC(),
+		Message: &gojwttokensync.TokensMessage{
+			RevokedRefreshTokensID: []string{id},
+		},
+	}
+}
+
+// listTestMessageIDs lists the message IDs of the unsent entries of the outbox
+//
+// Parameters:
+//
+//   - t: The test
+//   - outbox: The outbox
+//   - limit: The maximum number of entries
+//
+// Returns:
+//
+//   - []string: The message IDs in the listed order
+func listTestMessageIDs(t *testing.T, outbox *Outbox, limit int) []string {
+	t.Helper()
+
+	entries, err := outbox.List(context.Background(), limit)
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	messageIDs := make([]string, len(entries))
+	for i, entry := range entries {
+		messageIDs[i] = entry.Envelope.MessageID
+	}
+	return messageIDs
+}
+
+// equalMessageIDs reports whether the given message IDs are equal
+//
+// Parameters:
+//
+//   - got: The listed message IDs
+//   - want: The expected message IDs
+//
+// Returns:
+//
+//   - bool: Whether the message IDs are equal
+func equalMessageIDs(got, want []string) bool {
+	if len(got) != len(want) {
+		return false
+	}
+	for i := range got {
+		if got[i] != want[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestOutbox(t *testing.T) {
+	ctx := context.Background()
+	outbox := newTestOutbox(t, filepath.Join(t.TempDir(), "outbox.db"))
+
+	// Add the messages, with and without a transaction
+	ids := make([]int64, 4)
+	for i := range ids {
+		var err error
+		envelope := newTestEnvelope("message-" + strconv.Itoa(i))
+		if i%2 == 0 {
+			ids[i], err = outbox.Add(ctx, envelope)
+		} else {
+			err = outbox.CreateTransaction(
+				ctx, func(tx *sql.Tx) error {
+					var txErr error
+					ids[i], txErr = outbox.AddWithTx(ctx, tx, envelope)
+					return txErr
+				}, nil,
+			)
+		}
+		if err != nil {
+			t.Fatalf("failed to add message %d: %v", i, err)
+		}
+	}
+
+	// The messages are listed in insertion order
+	if got, want := listTestMessageIDs(t, outbox, 0), []string{
+		"message-0",
+		"message-1",
+		"message-2",
+		"message-3",
+	}; !equalMessageIDs(got, want) {
+		t.Errorf("List() = %v, want %v", got, want)
+	}
+	if got, want := listTestMessageIDs(t, outbox, 2), []string{"message-0", "message-1"}; !equalMessageIDs(got, want) {
+		t.Errorf("List(2) = %v, want %v", got, want)
+	}
+
+	// The sent and removed messages are no longer listed
+	if err := outbox.MarkSent(ctx, ids[0]); err != nil {
+		t.Fatalf("MarkSent() error = %v", err)
+	}
+	if err := outbox.Remove(ctx, ids[2]); err != nil {
+		t.Fatalf("Remove() error = %v", err)
+	}
+	if got, want := listTestMessageIDs(t, outbox, 0), []string{"message-1", "message-3"}; !equalMessageIDs(got, want) {
+		t.Errorf("List() after MarkSent() and Remove() = %v, want %v", got, want)
+	}
+
+	// Only the messages sent before the given time are deleted
+	for _, test := range []struct {
+		before    time.Time
+		wantCount int
+	}{
+		{before: time.Now().Add(-time.Minute), wantCount: 3},
+		{before: time.Now().Add(time.Minute), wantCount: 2},
+	} {
+		if err := outbox.DeleteSentBefore(ctx, test.before); err != nil {
+			t.Fatalf("DeleteSentBefore() error = %v", err)
+		}
+		query := `SELECT COUNT(1) FROM tokens_messages_outbox;`
+		row, err := outbox.QueryRow(&query)
+		if err != nil {
+			t.Fatalf("failed to count the messages: %v", err)
+		}
+		var count int
+		if err = row.Scan(&count); err != nil {
+			t.Fatalf("failed to count the messages: %v", err)
+		}
+		if count != test.wantCount {
+			t.Errorf("messages after DeleteSentBefore(%v) = %d, want %d", test.before, count, test.wantCount)
+		}
+	}
+}
+
+func TestOutbox_AddWithTx_Rollback(t *testing.T) {
+	ctx := context.Background()
+	outbox := newTestOutbox(t, filepath.Join(t.TempDir(), "outbox.db"))
+
+	// The message is not stored if the transaction is rolled back
+	db, err := outbox.DB()
+	if err != nil {
+		t.Fatalf("DB() error = %v", err)
+	}
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		t.Fatalf("BeginTx() error = %v", err)
+	}
+	if _, err = outbox.AddWithTx(ctx, tx, newTestEnvelope("message")); err != nil {
+		t.Fatalf("AddWithTx() error = %v", err)
+	}
+	if err = tx.Rollback(); err != nil {
+		t.Fatalf("Rollback() error = %v", err)
+	}
+	if got := listTestMessageIDs(t, outbox, 0); len(got) != 0 {
+		t.Errorf("List() = %v, want no messages", got)
+	}
+}
+
+func TestOutbox_Connect_AddsSentAtColumn(t *testing.T) {
+	ctx := context.Background()
+	dataSourceName := filepath.Join(t.TempDir(), "outbox.db")
+
+	// Create the table with the schema of the previous versions, without the sent_at column
+	db, err := sql.Open("sqlite", dataSourceName)
+	if err != nil {
+		t.Fatalf("failed to open the database: %v", err)
+	}
+	if _, err = db.ExecContext(
+		ctx,
+		`CREATE TABLE tokens_messages_outbox (id INTEGER PRIMARY KEY AUTOINCREMENT, body TEXT NOT NULL, created_at DATETIME NOT NULL);`,
+	); err != nil {
+		t.Fatalf("failed to create the table: %v", err)
+	}
+	body, err := marshalEnvelope(newTestEnvelope("message"))
+	if err != nil {
+		t.Fatalf("failed to marshal the envelope: %v", err)
+	}
+	if _, err = db.ExecContext(ctx, InsertTokensMessageQuery, body, time.Now().UTC()); err != nil {
+		t.Fatalf("failed to add the message: %v", err)
+	}
+	if err = db.Close(); err != nil {
+		t.Fatalf("failed to close the database: %v", err)
+	}
+
+	// Connect, which adds the column, keeping the existing messages unsent
+	outbox := newTestOutbox(t, dataSourceName)
+	entries, err := outbox.List(ctx, 0)
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if len(entries) != 1 {
+		t.Fatalf("List() = %d entries, want 1", len(entries))
+	}
+	if err = outbox.MarkSent(ctx, entries[0].ID); err != nil {
+		t.Fatalf("MarkSent() error = %v", err)
+	}
+	if got := listTestMessageIDs(t, outbox, 0); len(got) != 0 {
+		t.Errorf("List() after MarkSent() = %v, want no messages", got)
+	}
+
+	// Connecting again leaves the table unchanged
+	if err = outbox.Connect(ctx); err != nil {
+		t.Fatalf("Connect() again error = %v", err)
+	}
+}
@@ -0,0 +1,31 @@
+package publisher
+
+import (
+	"time"
+)
+
+var (
+	// DefaultConfirmTimeout is the default time to wait for the broker to confirm a published message
+	DefaultConfirmTimeout = 5 * time.Second
+
+	// DefaultMaxPublishAttempts is the default number of attempts to publish a message until the broker confirms it
+	DefaultMaxPublishAttempts = 3
+
+	// DefaultRetryBackoff is the default wait before the first publish retry, doubled on each retry
+	DefaultRetryBackoff = 200 * time.Millisecond
+)
+
+var (
+	// DefaultRelayPollInterval is the default interval between the polls of the transactional outbox by the relay
+	DefaultRelayPollInterval = time.Second
+
+	// DefaultRelayBatchSize is the default number of messages read from the transactional outbox at once
+	DefaultRelayBatchSize = 100
+
+	// DefaultRelayRetention is the default time the sent messages are kept in the transactional outbox
+	DefaultRelayRetention = 24 * time.Hour
+
+	// DefaultRelayPurgeInterval is the default interval between the purges of the sent messages of the transactional
+	// outbox
+	DefaultRelayPurgeInterval = time.Hour
+)
@@ -0,0 +1,11 @@
+package publisher
+
+import (
+	"errors"
+)
+
+var (
+	ErrMessageNacked   = errors.New("rabbitmq message nacked by the broker")
+	ErrMessageReturned = errors.New("rabbitmq message returned by the broker as unroutable")
+	ErrNilRelay        = errors.New("nil rabbitmq outbox relay")
+)
@@ -1,14 +1,10 @@
 package publisher
 
 import (
-	gojwtrabbitmq "github.com/ralvarezdev/go-jwt/rabbitmq"
+	gojwttokensync "github.com/ralvarezdev/go-jwt/tokensync"
 )
 
 type (
-	// Publisher is the interface for the JWT RabbitMQ publisher
-	Publisher interface {
-		Open() error
-		Close() error
-		PublishTokensMessage(msg *gojwtrabbitmq.TokensMessage) error
-	}
+	// Publisher is the interface for the JWT RabbitMQ publisher, which is the transport-neutral publisher interface
+	Publisher = gojwttokensync.Publisher
 )
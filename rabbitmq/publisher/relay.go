@@ -0,0 +1,251 @@
+package publisher
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	gojwtrabbitmq "github.com/ralvarezdev/go-jwt/rabbitmq"
+	gojwttokensync "github.com/ralvarezdev/go-jwt/tokensync"
+)
+
+type (
+	// RelayOptions are the options for the Relay
+	RelayOptions struct {
+		// PollInterval is the interval between the polls of the outbox (optional, DefaultRelayPollInterval is used if
+		// not positive)
+		PollInterval time.Duration
+
+		// BatchSize is the number of messages read from the outbox at once (optional, DefaultRelayBatchSize is used if
+		// not positive)
+		BatchSize int
+
+		// Retention is the time the sent messages are kept in the outbox before being purged (optional,
+		// DefaultRelayRetention is used if zero, and the sent messages are never purged if negative)
+		Retention time.Duration
+
+		// PurgeInterval is the interval between the purges of the sent messages (optional, DefaultRelayPurgeInterval
+		// is used if not positive)
+		PurgeInterval time.Duration
+	}
+
+	// Relay drains a transactional outbox to a publisher with at-least-once semantics. The messages are published in
+	// insertion order, and each one is marked as sent once the broker confirms it, so a message is published again if
+	// the relay stops in between, and the consumers must tolerate duplicates. The sent messages are purged by Start
+	// once their retention elapses
+	Relay struct {
+		outbox        gojwttokensync.TransactionalOutbox
+		publisher     Publisher
+		pollInterval  time.Duration
+		batchSize     int
+		retention     time.Duration
+		purgeInterval time.Duration
+		notifyCh      chan struct{}
+		logger        *slog.Logger
+		mutex         sync.Mutex
+	}
+)
+
+// NewRelay creates a new Relay
+//
+// Parameters:
+//
+//   - outbox: the transactional outbox
+//   - publisher: the publisher
+//   - options: the options (optional, can be nil)
+//   - logger: the logger (optional, can be nil)
+//
+// Returns:
+//
+//   - *Relay: the Relay instance
+//   - error: an error if the outbox or the publisher is nil
+func NewRelay(
+	outbox gojwttokensync.TransactionalOutbox,
+	publisher Publisher,
+	options *RelayOptions,
+	logger *slog.Logger,
+) (*Relay, error) {
+	// Check if the outbox is nil
+	if outbox == nil {
+		return nil, gojwttokensync.ErrNilOutbox
+	}
+
+	// Check if the publisher is nil
+	if publisher == nil {
+		return nil, gojwtrabbitmq.ErrNilPublisher
+	}
+
+	// Set the options
+	if options == nil {
+		options = &RelayOptions{}
+	}
+
+	pollInterval := options.PollInterval
+	if pollInterval <= 0 {
+		pollInterval = DefaultRelayPollInterval
+	}
+
+	batchSize := options.BatchSize
+	if batchSize <= 0 {
+		batchSize = DefaultRelayBatchSize
+	}
+
+	retention := options.Retention
+	if retention == 0 {
+		retention = DefaultRelayRetention
+	}
+
+	purgeInterval := options.PurgeInterval
+	if purgeInterval <= 0 {
+		purgeInterval = DefaultRelayPurgeInterval
+	}
+
+	if logger != nil {
+		logger = logger.With(
+			slog.String("component", "jwt_rabbitmq_outbox_relay"),
+		)
+	}
+
+	return &Relay{
+		outbox:        outbox,
+		publisher:     publisher,
+		pollInterval:  pollInterval,
+		batchSize:     batchSize,
+		retention:     retention,
+		purgeInterval: purgeInterval,
+		notifyCh:      make(chan struct{}, 1),
+		logger:        logger,
+	}, nil
+}
+
+// Notify wakes the relay up before the next poll, such as after committing a transaction that added a message to
+// the outbox
+func (r *Relay) Notify() {
+	// Check if the relay is nil
+	if r == nil {
+		return
+	}
+
+	select {
+	case r.notifyCh <- struct{}{}:
+	default:
+	}
+}
+
+// Start drains the outbox on each poll or notification, and purges its sent messages on each purge interval, until
+// the context is done
+//
+// Parameters:
+//
+//   - ctx: the context
+//
+// Returns:
+//
+//   - error: the context error once the context is done
+func (r *Relay) Start(ctx context.Context) error {
+	// Check if the relay is nil
+	if r == nil {
+		return ErrNilRelay
+	}
+
+	ticker := time.NewTicker(r.pollInterval)
+	defer ticker.Stop()
+
+	purgeTicker := time.NewTicker(r.purgeInterval)
+	defer purgeTicker.Stop()
+
+	for {
+		// Drain the outbox, retrying the failed message on the next poll
+		if err := r.Drain(ctx); err != nil && ctx.Err() == nil && r.logger != nil {
+			r.logger.Warn(
+				"Failed to drain the outbox",
+				slog.String("error", err.Error()),
+			)
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		case <-r.notifyCh:
+		case <-purgeTicker.C:
+			// Purge the sent messages, retrying on the next purge interval
+			if err := r.Purge(ctx); err != nil && ctx.Err() == nil && r.logger != nil {
+				r.logger.Warn(
+					"Failed to purge the outbox",
+					slog.String("error", err.Error()),
+				)
+			}
+		}
+	}
+}
+
+// Purge deletes the messages of the outbox sent before the retention
+//
+// Parameters:
+//
+//   - ctx: the context
+//
+// Returns:
+//
+//   - error: an error if the messages could not be deleted
+func (r *Relay) Purge(ctx context.Context) error {
+	// Check if the relay is nil
+	if r == nil {
+		return ErrNilRelay
+	}
+
+	// Check if the sent messages are kept forever
+	if r.retention < 0 {
+		return nil
+	}
+	return r.outbox.DeleteSentBefore(ctx, time.Now().Add(-r.retention))
+}
+
+// Drain publishes the unsent messages of the outbox in insertion order, stopping at the first message that could not
+// be published
+//
+// Parameters:
+//
+//   - ctx: the context
+//
+// Returns:
+//
+//   - error: an error if a message could not be read, published or marked as sent
+func (r *Relay) Drain(ctx context.Context) error {
+	// Check if the relay is nil
+	if r == nil {
+		return ErrNilRelay
+	}
+
+	// Lock the mutex, so concurrent drains do not publish the same message twice
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	for {
+		entries, err := r.outbox.List(ctx, r.batchSize)
+		if err != nil {
+			return err
+		}
+
+		for _, entry := range entries {
+			if err = r.publisher.PublishTokensMessageEnvelope(
+				ctx,
+				entry.Envelope,
+			); err != nil {
+				return err
+			}
+
+			// A message that could not be marked as sent is published again on the next drain
+			if err = r.outbox.MarkSent(ctx, entry.ID); err != nil {
+				return err
+			}
+		}
+
+		// Check if the outbox was drained
+		if len(entries) < r.batchSize {
+			return nil
+		}
+	}
+}
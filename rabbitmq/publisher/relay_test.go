@@ -0,0 +1,227 @@
+package publisher
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	godatabasessql "github.com/ralvarezdev/go-databases/sql"
+	gojwtoutboxsqlite "github.com/ralvarezdev/go-jwt/rabbitmq/outbox/sqlite"
+	gojwttokensync "github.com/ralvarezdev/go-jwt/tokensync"
+	_ "modernc.org/sqlite"
+)
+
+// errPublishFailed is the error returned by the fakePublisher for the failing messages
+var errPublishFailed = errors.New("publish failed")
+
+type (
+	// fakePublisher is a publisher that records the published message IDs, and fails the messages of the given ID
+	fakePublisher struct {
+		gojwttokensync.Publisher
+		failMessageID string
+		messageIDs    []string
+		mutex         sync.Mutex
+	}
+)
+
+// PublishTokensMessageEnvelope records the message ID of the envelope
+//
+// Parameters:
+//
+//   - ctx: The context
+//   - envelope: The tokens message envelope
+//
+// Returns:
+//
+//   - error: errPublishFailed if the envelope has the failing message ID
+func (f *fakePublisher) PublishTokensMessageEnvelope(
+	ctx context.Context,
+	envelope *gojwttokensync.TokensMessageEnvelope,
+) error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if envelope.MessageID == f.failMessageID {
+		return errPublishFailed
+	}
+	f.messageIDs = append(f.messageIDs, envelope.MessageID)
+	return nil
+}
+
+// newTestRelay creates a relay over a new SQLite outbox holding the given number of messages
+//
+// Parameters:
+//
+//   - t: The test
+//   - publisher: The publisher
+//   - options: The relay options
+//   - messages: The number of messages added to the outbox
+//
+// Returns:
+//
+//   - *Relay: The relay
+//   - *gojwtoutboxsqlite.Outbox: The outbox
+func newTestRelay(
+	t *testing.T,
+	publisher Publisher,
+	options *RelayOptions,
+	messages int,
+) (*Relay, *gojwtoutboxsqlite.Outbox) {
+	t.Helper()
+
+	service, err := godatabasessql.NewDefaultService(
+		&godatabasessql.Config{
+			DriverName:     "sqlite",
+			DataSourceName: filepath.Join(t.TempDir(), "outbox.db"),
+		},
+	)
+	if err != nil {
+		t.Fatalf("failed to create the service: %v", err)
+	}
+	outbox, err := gojwtoutboxsqlite.NewOutbox(service, nil)
+	if err != nil {
+		t.Fatalf("failed to create the outbox: %v", err)
+	}
+	if err = outbox.Connect(context.Background()); err != nil {
+		t.Fatalf("failed to connect: %v", err)
+	}
+	t.Cleanup(
+		func() {
+			_ = outbox.Disconnect()
+		},
+	)
+
+	for i := range messages {
+		messageID := "message-" + strconv.Itoa(i)
+		if _, err = outbox.Add(
+			context.Background(),
+			&gojwttokensync.TokensMessageEnvelope{
+				MessageID: messageID,
+				Message:   &gojwttokensync.TokensMessage{RevokedRefreshTokensID: []string{messageID}},
+			},
+		); err != nil {
+			t.Fatalf("failed to add the message: %v", err)
+		}
+	}
+
+	relay, err := NewRelay(outbox, publisher, options, nil)
+	if err != nil {
+		t.Fatalf("NewRelay() error = %v", err)
+	}
+	return relay, outbox
+}
+
+// countTestOutboxMessages counts the sent and unsent messages of the outbox
+//
+// Parameters:
+//
+//   - t: The test
+//   - outbox: The outbox
+//
+// Returns:
+//
+//   - int: The number of messages
+func countTestOutboxMessages(t *testing.T, outbox *gojwtoutboxsqlite.Outbox) int {
+	t.Helper()
+
+	query := `SELECT COUNT(1) FROM tokens_messages_outbox;`
+	row, err := outbox.QueryRow(&query)
+	if err != nil {
+		t.Fatalf("failed to count the messages: %v", err)
+	}
+	var count int
+	if err = row.Scan(&count); err != nil {
+		t.Fatalf("failed to count the messages: %v", err)
+	}
+	return count
+}
+
+func TestRelay_Drain(t *testing.T) {
+	ctx := context.Background()
+	publisher := &fakePublisher{failMessageID: "message-2"}
+	relay, outbox := newTestRelay(t, publisher, &RelayOptions{BatchSize: 2}, 5)
+
+	// The drain stops at the message that could not be published
+	if err := relay.Drain(ctx); !errors.Is(err, errPublishFailed) {
+		t.Fatalf("Drain() error = %v, want %v", err, errPublishFailed)
+	}
+	entries, err := outbox.List(ctx, 0)
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if len(entries) != 3 || entries[0].Envelope.MessageID != "message-2" {
+		t.Fatalf("List() after a failed drain = %d entries, want 3 starting at message-2", len(entries))
+	}
+
+	// The next drain resumes from the failed message, in insertion order
+	publisher.mutex.Lock()
+	publisher.failMessageID = ""
+	publisher.mutex.Unlock()
+	if err = relay.Drain(ctx); err != nil {
+		t.Fatalf("Drain() error = %v", err)
+	}
+	want := []string{"message-0", "message-1", "message-2", "message-3", "message-4"}
+	if len(publisher.messageIDs) != len(want) {
+		t.Fatalf("published messages = %v, want %v", publisher.messageIDs, want)
+	}
+	for i := range want {
+		if publisher.messageIDs[i] != want[i] {
+			t.Errorf("published messages = %v, want %v", publisher.messageIDs, want)
+			break
+		}
+	}
+}
+
+func TestRelay_Purge(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name      string
+		retention time.Duration
+		wantCount int
+	}{
+		{name: "elapsed retention", retention: time.Nanosecond, wantCount: 0},
+		{name: "pending retention", retention: time.Hour, wantCount: 3},
+		{name: "no retention", retention: -1, wantCount: 3},
+	}
+	for _, test := range tests {
+		t.Run(
+			test.name, func(t *testing.T) {
+				relay, outbox := newTestRelay(
+					t,
+					&fakePublisher{},
+					&RelayOptions{Retention: test.retention},
+					3,
+				)
+				if err := relay.Drain(ctx); err != nil {
+					t.Fatalf("Drain() error = %v", err)
+				}
+				time.Sleep(time.Millisecond)
+
+				if err := relay.Purge(ctx); err != nil {
+					t.Fatalf("Purge() error = %v", err)
+				}
+				if count := countTestOutboxMessages(t, outbox); count != test.wantCount {
+					t.Errorf("messages after Purge() = %d, want %d", count, test.wantCount)
+				}
+			},
+		)
+	}
+}
+
+func TestRelay_Nil(t *testing.T) {
+	var relay *Relay
+	for name, fn := range map[string]func(ctx context.Context) error{
+		"Start": relay.Start,
+		"Drain": relay.Drain,
+		"Purge": relay.Purge,
+	} {
+		if err := fn(context.Background()); !errors.Is(err, ErrNilRelay) {
+			t.Errorf("%s() error = %v, want %v", name, err, ErrNilRelay)
+		}
+	}
+}
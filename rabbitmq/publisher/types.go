@@ -1,23 +1,69 @@
 package publisher
 
 import (
-	"encoding/json"
+	"context"
+	"errors"
 	"log/slog"
 	"sync"
+	"time"
 
 	"github.com/rabbitmq/amqp091-go"
 	gojwtrabbitmq "github.com/ralvarezdev/go-jwt/rabbitmq"
+	gojwttokensync "github.com/ralvarezdev/go-jwt/tokensync"
 )
 
 type (
-	// DefaultPublisher is the default implementation of the Publisher interface
+	// Options are the options for the DefaultPublisher
+	Options struct {
+		// ConfirmTimeout is the time to wait for the broker to confirm each publish attempt
+		ConfirmTimeout time.Duration
+
+		// MaxAttempts is the number of attempts to publish a message until the broker confirms it
+		MaxAttempts int
+
+		// RetryBackoff is the wait before the first retry, doubled on each retry
+		RetryBackoff time.Duration
+
+		// Outbox holds the messages until the broker confirms them (optional, the messages are not stored if nil)
+		Outbox gojwttokensync.Outbox
+
+		// Topology is the routing topology of the messages (optional, the work queue mode is used by default)
+		Topology gojwtrabbitmq.Topology
+
+		// ContentType is the encoding of the messages, either gojwttokensync.JSONContentType or
+		// gojwttokensync.ProtobufContentType (optional, gojwttokensync.JSONContentType is used if empty)
+		ContentType string
+
+		// Producer is the name of the service that publishes the messages, set in their envelope (optional)
+		Producer string
+
+		// EventLog keeps the messages served to the consumers that catch up after being offline (optional, the
+		// messages are not logged if nil)
+		EventLog gojwttokensync.EventLog
+	}
+
+	// DefaultPublisher is the default implementation of the Publisher interface. The messages are published as
+	// persistent messages on a channel in confirm mode, so they are only considered published once the broker has
+	// acknowledged them. In the work queue mode, they are also mandatory, so the unroutable messages are not confirmed
+	// as published
 	DefaultPublisher struct {
-		conn      *amqp091.Connection
-		ch        *amqp091.Channel
-		queue     *amqp091.Queue
-		queueName string
-		logger    *slog.Logger
-		mutex     sync.Mutex
+		conn           gojwtrabbitmq.Connection
+		ch             *amqp091.Channel
+		queueName      string
+		topology       gojwtrabbitmq.Topology
+		exchange       string
+		routingKey     string
+		returns        chan amqp091.Return
+		contentType    string
+		producer       string
+		confirmTimeout time.Duration
+		maxAttempts    int
+		retryBackoff   time.Duration
+		outbox         gojwttokensync.Outbox
+		eventLog       gojwttokensync.EventLog
+		logger         *slog.Logger
+		mutex          sync.Mutex
+		outboxMutex    sync.Mutex
 	}
 )
 
@@ -25,17 +71,20 @@ type (
 //
 // Parameters:
 //
-//   - conn: the RabbitMQ connection
-//   - queueName: the name of the queue
+//   - conn: the RabbitMQ connection, such as a ConnectionManager that re-establishes it
+//   - queueName: the name of the queue, only used by the work queue mode
+//   - options: the options (optional, can be nil)
 //   - logger: the logger
 //
 // Returns:
 //
 //   - *DefaultPublisher: the DefaultPublisher instance
-//   - error: an error if the connection is nil
+//   - error: an error if the connection is nil, the topology is invalid or the queue name is empty in the work queue
+//     mode
 func NewDefaultPublisher(
-	conn *amqp091.Connection,
+	conn gojwtrabbitmq.Connection,
 	queueName string,
+	options *Options,
 	logger *slog.Logger,
 ) (*DefaultPublisher, error) {
 	// Check if the connection is nil
@@ -43,10 +92,46 @@ func NewDefaultPublisher(
 		return nil, gojwtrabbitmq.ErrNilConnection
 	}
 
+	// Set the options
+	if options == nil {
+		options = &Options{}
+	}
+
+	// Check if the topology is valid
+	if err := options.Topology.Validate(); err != nil {
+		return nil, err
+	}
+
 	// Check if the queue name is empty
-	if queueName == "" {
+	if options.Topology.Mode == gojwtrabbitmq.WorkQueueTopology && queueName == "" {
 		return nil, gojwtrabbitmq.ErrEmptyQueueName
 	}
+	exchange, routingKey := options.Topology.PublishTarget(queueName)
+
+	// Check if the content type is supported
+	contentType := options.ContentType
+	switch contentType {
+	case "":
+		contentType = gojwttokensync.JSONContentType
+	case gojwttokensync.JSONContentType, gojwttokensync.ProtobufContentType:
+	default:
+		return nil, gojwttokensync.ErrUnsupportedContentType
+	}
+
+	confirmTimeout := options.ConfirmTimeout
+	if confirmTimeout <= 0 {
+		confirmTimeout = DefaultConfirmTimeout
+	}
+
+	maxAttempts := options.MaxAttempts
+	if maxAttempts <= 0 {
+		maxAttempts = DefaultMaxPublishAttempts
+	}
+
+	retryBackoff := options.RetryBackoff
+	if retryBackoff <= 0 {
+		retryBackoff = DefaultRetryBackoff
+	}
 
 	if logger != nil {
 		logger = logger.With(
@@ -56,61 +141,103 @@ func NewDefaultPublisher(
 
 	// Create a new publisher instance
 	publisher := &DefaultPublisher{
-		conn:      conn,
-		queueName: queueName,
-		logger:    logger,
+		conn:           conn,
+		queueName:      queueName,
+		topology:       options.Topology,
+		exchange:       exchange,
+		routingKey:     routingKey,
+		contentType:    contentType,
+		producer:       options.Producer,
+		confirmTimeout: confirmTimeout,
+		maxAttempts:    maxAttempts,
+		retryBackoff:   retryBackoff,
+		outbox:         options.Outbox,
+		eventLog:       options.EventLog,
+		logger:         logger,
 	}
 	return publisher, nil
 }
 
-// Open opens a RabbitMQ channel
+// open opens a RabbitMQ channel in confirm mode, the mutex must be held by the caller
 //
 // Returns:
 //
 //   - error: an error if the channel could not be opened
-func (d *DefaultPublisher) Open() error {
-	// Check if the publisher is nil
-	if d == nil {
-		return gojwtrabbitmq.ErrNilPublisher
-	}
-
-	// Lock the mutex to ensure thread safety
-	d.mutex.Lock()
-	defer d.mutex.Unlock()
-
+func (d *DefaultPublisher) open() error {
 	// Check if the publisher is already open
-	if d.ch != nil {
+	if d.ch != nil && !d.ch.IsClosed() {
 		return nil
 	}
 
 	// Create the channel
 	ch, err := d.conn.Channel()
 	if err != nil {
-		d.logger.Error(
-			"Failed to open a channel",
-			slog.String("error", err.Error()),
-		)
+		if d.logger != nil {
+			d.logger.Error(
+				"Failed to open a channel",
+				slog.String("error", err.Error()),
+			)
+		}
 		return err
 	}
 
-	// Set the channel
-	d.ch = ch
-
-	// Declare the queue
-	q, err := gojwtrabbitmq.DeclareTokensMessageQueue(d.ch, d.queueName)
+	// Declare the queue of the work queue mode, or the exchange of the other modes
+	if d.topology.Mode == gojwtrabbitmq.WorkQueueTopology {
+		_, err = gojwtrabbitmq.DeclareTokensMessageQueue(ch, d.queueName)
+	} else {
+		err = gojwtrabbitmq.DeclareTokensMessageExchange(ch, d.topology)
+	}
 	if err != nil {
-		d.logger.Error(
-			"Failed to declare a queue",
-			slog.String("queue_name", d.queueName),
-			slog.String("error", err.Error()),
-		)
+		if d.logger != nil {
+			d.logger.Error(
+				"Failed to declare the topology",
+				slog.String("topology", d.topology.Mode.String()),
+				slog.String("error", err.Error()),
+			)
+		}
+		_ = ch.Close()
 		return err
 	}
-	d.queue = q
-	d.logger.Info("Publisher channel opened")
+
+	// Put the channel in confirm mode
+	if err = ch.Confirm(false); err != nil {
+		if d.logger != nil {
+			d.logger.Error(
+				"Failed to put the channel in confirm mode",
+				slog.String("error", err.Error()),
+			)
+		}
+		_ = ch.Close()
+		return err
+	}
+
+	// Set the channel and listen for the unroutable messages
+	d.ch = ch
+	d.returns = ch.NotifyReturn(make(chan amqp091.Return, 1))
+	if d.logger != nil {
+		d.logger.Info("Publisher channel opened")
+	}
 	return nil
 }
 
+// Open opens a RabbitMQ channel in confirm mode
+//
+// Returns:
+//
+//   - error: an error if the channel could not be opened
+func (d *DefaultPublisher) Open() error {
+	// Check if the publisher is nil
+	if d == nil {
+		return gojwtrabbitmq.ErrNilPublisher
+	}
+
+	// Lock the mutex to ensure thread safety
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	return d.open()
+}
+
 // Close closes the RabbitMQ channel and connection
 //
 // Returns:
@@ -131,29 +258,54 @@ func (d *DefaultPublisher) Close() error {
 	}
 
 	// Close the channel
-	if err := d.ch.Close(); err != nil {
-		d.logger.Error(
-			"Failed to close channel", slog.String("error", err.Error()),
-		)
+	if err := d.ch.Close(); err != nil && !errors.Is(err, amqp091.ErrClosed) {
+		if d.logger != nil {
+			d.logger.Error(
+				"Failed to close channel", slog.String("error", err.Error()),
+			)
+		}
 		return err
 	}
 
 	// Set the channel to nil
 	d.ch = nil
-	d.logger.Info("Publisher channel closed")
+	if d.logger != nil {
+		d.logger.Info("Publisher channel closed")
+	}
 	return nil
 }
 
-// PublishTokensMessage publishes a tokens message to the RabbitMQ queue
+// PublishTokensMessage publishes a tokens message to the RabbitMQ queue or exchange
+//
+// Parameters:
+//
+//   - msg: the tokens message to publish
+//
+// Returns:
+//
+//   - error: an error if the message could not be published
+func (d *DefaultPublisher) PublishTokensMessage(msg *gojwttokensync.TokensMessage) error {
+	return d.PublishTokensMessageWithCtx(context.Background(), msg)
+}
+
+// PublishTokensMessageWithCtx publishes a tokens message in a new envelope to the RabbitMQ queue or exchange and waits
+// for the broker to confirm it, retrying the unconfirmed attempts with the same message ID. When an event log is
+// configured, the message is appended to it first, so the consumers can catch up with it even if it is not delivered.
+// When an outbox is configured, the message is stored before being published and removed once confirmed, so a failed
+// message is kept and published again by FlushOutbox, in order, before any newer message
 //
 // Parameters:
 //
+//   - ctx: the context
 //   - msg: the tokens message to publish
 //
 // Returns:
 //
 //   - error: an error if the message could not be published
-func (d *DefaultPublisher) PublishTokensMessage(msg *gojwtrabbitmq.TokensMessage) error {
+func (d *DefaultPublisher) PublishTokensMessageWithCtx(
+	ctx context.Context,
+	msg *gojwttokensync.TokensMessage,
+) error {
 	// Check if the publisher is nil
 	if d == nil {
 		return gojwtrabbitmq.ErrNilPublisher
@@ -164,20 +316,144 @@ func (d *DefaultPublisher) PublishTokensMessage(msg *gojwtrabbitmq.TokensMessage
 		return gojwtrabbitmq.ErrNilMessage
 	}
 
-	// Lock the mutex to ensure thread safety
-	d.mutex.Lock()
+	// Wrap the message in its envelope
+	envelope, err := gojwttokensync.NewTokensMessageEnvelope(msg, d.producer)
+	if err != nil {
+		return err
+	}
 
-	// Ensure the channel is open
-	if d.ch == nil {
-		if err := d.Open(); err != nil {
-			d.mutex.Unlock()
+	// Append the message to the event log
+	if d.eventLog != nil {
+		if err = d.eventLog.AppendTokensMessage(ctx, envelope); err != nil {
+			if d.logger != nil {
+				d.logger.Error(
+					"Failed to append message to the event log",
+					slog.String("message_id", envelope.MessageID),
+					slog.String("error", err.Error()),
+				)
+			}
 			return err
 		}
 	}
-	d.mutex.Unlock()
 
-	// Marshal the message to JSON
-	body, err := json.Marshal(msg)
+	// Store the message in the outbox, and publish it after the pending ones
+	if d.outbox != nil {
+		if _, err = d.outbox.Add(ctx, envelope); err != nil {
+			return err
+		}
+		return d.FlushOutbox(ctx)
+	}
+	return d.publish(ctx, envelope)
+}
+
+// PublishTokensMessageEnvelope publishes a tokens message envelope created elsewhere, such as by a transactional
+// outbox, keeping its message ID, and waits for the broker to confirm it. The envelope is appended to the event log,
+// if configured, but not stored in the outbox of the publisher
+//
+// Parameters:
+//
+//   - ctx: the context
+//   - envelope: the tokens message envelope to publish
+//
+// Returns:
+//
+//   - error: an error if the envelope could not be published
+func (d *DefaultPublisher) PublishTokensMessageEnvelope(
+	ctx context.Context,
+	envelope *gojwttokensync.TokensMessageEnvelope,
+) error {
+	// Check if the publisher is nil
+	if d == nil {
+		return gojwtrabbitmq.ErrNilPublisher
+	}
+
+	// Check if the envelope is nil
+	if envelope == nil || envelope.Message == nil {
+		return gojwtrabbitmq.ErrNilMessage
+	}
+
+	// Append the envelope to the event log, which ignores the already appended message IDs
+	if d.eventLog != nil {
+		if err := d.eventLog.AppendTokensMessage(ctx, envelope); err != nil {
+			if d.logger != nil {
+				d.logger.Error(
+					"Failed to append message to the event log",
+					slog.String("message_id", envelope.MessageID),
+					slog.String("error", err.Error()),
+				)
+			}
+			return err
+		}
+	}
+	return d.publish(ctx, envelope)
+}
+
+// FlushOutbox publishes the messages held by the outbox in insertion order, stopping at the first message that could
+// not be published
+//
+// Parameters:
+//
+//   - ctx: the context
+//
+// Returns:
+//
+//   - error: an error if a message could not be published
+func (d *DefaultPublisher) FlushOutbox(ctx context.Context) error {
+	// Check if the publisher is nil
+	if d == nil {
+		return gojwtrabbitmq.ErrNilPublisher
+	}
+
+	// Check if the outbox is nil
+	if d.outbox == nil {
+		return gojwttokensync.ErrNilOutbox
+	}
+
+	// Lock the outbox mutex, so concurrent flushes do not publish the same message twice
+	d.outboxMutex.Lock()
+	defer d.outboxMutex.Unlock()
+
+	entries, err := d.outbox.List(ctx, 0)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		if err = d.publish(ctx, entry.Envelope); err != nil {
+			return err
+		}
+
+		// A message that could not be removed is published again, so the consumers must tolerate duplicates
+		if err = d.outbox.Remove(ctx, entry.ID); err != nil && d.logger != nil {
+			d.logger.Warn(
+				"Failed to remove a published message from the outbox",
+				slog.Int64("id", entry.ID),
+				slog.String("error", err.Error()),
+			)
+		}
+	}
+	return nil
+}
+
+// publish publishes a tokens message envelope, retrying with a backoff until the broker confirms it or the attempts
+// run out
+//
+// Parameters:
+//
+//   - ctx: the context
+//   - envelope: the tokens message envelope to publish
+//
+// Returns:
+//
+//   - error: the error of the last attempt
+func (d *DefaultPublisher) publish(
+	ctx context.Context,
+	envelope *gojwttokensync.TokensMessageEnvelope,
+) error {
+	// Encode the envelope
+	body, err := gojwttokensync.MarshalTokensMessageEnvelope(
+		envelope,
+		d.contentType,
+	)
 	if err != nil {
 		if d.logger != nil {
 			d.logger.Error(
@@ -188,28 +464,111 @@ func (d *DefaultPublisher) PublishTokensMessage(msg *gojwtrabbitmq.TokensMessage
 		return err
 	}
 
+	backoff := d.retryBackoff
+	for attempt := 1; ; attempt++ {
+		if err = d.publishOnce(ctx, envelope, body); err == nil {
+			if d.logger != nil {
+				d.logger.Debug(
+					"Message published",
+					slog.String("message_id", envelope.MessageID),
+				)
+			}
+			return nil
+		}
+		if d.logger != nil {
+			d.logger.Warn(
+				"Failed to publish message",
+				slog.Int("attempt", attempt),
+				slog.String("error", err.Error()),
+			)
+		}
+
+		// Check if the attempts ran out
+		if attempt >= d.maxAttempts {
+			return err
+		}
+
+		// Wait before the next attempt
+		timer := time.NewTimer(backoff)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return errors.Join(err, ctx.Err())
+		case <-timer.C:
+		}
+		backoff *= 2
+	}
+}
+
+// publishOnce publishes a message body as a persistent message and waits for the broker to confirm it
+//
+// Parameters:
+//
+//   - ctx: the context
+//   - envelope: the tokens message envelope
+//   - body: the encoded envelope
+//
+// Returns:
+//
+//   - error: an error if the message could not be published, was nacked, returned or not confirmed in time
+func (d *DefaultPublisher) publishOnce(
+	ctx context.Context,
+	envelope *gojwttokensync.TokensMessageEnvelope,
+	body []byte,
+) error {
+	// Lock the mutex, so the returned messages can be matched with the published one
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	// Ensure the channel is open, reopening it if the broker closed it
+	if err := d.open(); err != nil {
+		return err
+	}
+
 	// Publish the message
-	err = d.ch.Publish(
-		"",
-		d.queue.Name,
-		false,
+	confirmation, err := d.ch.PublishWithDeferredConfirmWithContext(
+		ctx,
+		d.exchange,
+		d.routingKey,
+		d.topology.Mode == gojwtrabbitmq.WorkQueueTopology,
 		false,
 		amqp091.Publishing{
-			ContentType: "application/json",
-			Body:        body,
+			ContentType:  d.contentType,
+			DeliveryMode: amqp091.Persistent,
+			MessageId:    envelope.MessageID,
+			Timestamp:    envelope.IssuedAt,
+			Type:         gojwttokensync.TokensMessageType,
+			AppId:        envelope.Producer,
+			Body:         body,
 		},
 	)
 	if err != nil {
-		if d.logger != nil {
-			d.logger.Error(
-				"Failed to publish message",
-				slog.String("error", err.Error()),
-			)
-		}
 		return err
 	}
-	if d.logger != nil {
-		d.logger.Debug("Message published", slog.String("body", string(body)))
+
+	// Wait for the broker to confirm the message
+	confirmCtx, cancel := context.WithTimeout(ctx, d.confirmTimeout)
+	defer cancel()
+	acked, err := confirmation.WaitContext(confirmCtx)
+	if err != nil {
+		return err
+	}
+	if !acked {
+		return ErrMessageNacked
+	}
+
+	// The broker returns the unroutable messages before confirming them
+	for {
+		select {
+		case ret, ok := <-d.returns:
+			if !ok {
+				return nil
+			}
+			if ret.MessageId == envelope.MessageID {
+				return ErrMessageReturned
+			}
+		default:
+			return nil
+		}
 	}
-	return nil
 }
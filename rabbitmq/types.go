@@ -1,22 +1,150 @@
 package rabbitmq
 
 import (
-	"time"
+	"github.com/rabbitmq/amqp091-go"
+	gojwttokensync "github.com/ralvarezdev/go-jwt/tokensync"
 )
 
 type (
-	// TokenPair represents a pair of refresh and access token JTIs
-	TokenPair struct {
-		RefreshTokenID        string    `json:"refresh_token_id"`
-		RefreshTokenExpiresAt time.Time `json:"refresh_token_expires_at"`
-		AccessTokenID         string    `json:"access_token_id"`
-		AccessTokenExpiresAt  time.Time `json:"access_token_expires_at"`
+	// ConnectionState is the state of a managed RabbitMQ connection
+	ConnectionState int
+
+	// TopologyMode is the way the tokens messages are routed from the publisher to the consumers
+	TopologyMode int
+
+	// Topology is the routing topology of the tokens messages
+	Topology struct {
+		// Mode is the topology mode
+		Mode TopologyMode
+
+		// Exchange is the name of the exchange the messages are published to, required by the fanout and topic modes
+		Exchange string
+
+		// RoutingKey is the routing key of the published messages in the topic mode
+		RoutingKey string
+
+		// BindingKey is the binding key of the consumer queues in the topic mode (optional, TopicWildcard is used if
+		// empty)
+		BindingKey string
+
+		// Exclusive makes each consumer instance bind its own exclusive auto-delete queue, so every replica receives
+		// every message. Otherwise, the consumers bind the named durable queue, which is shared by the replicas of a
+		// service
+		Exclusive bool
 	}
 
-	// TokensMessage represents a message containing issued and revoked tokens
-	TokensMessage struct {
-		IssuedTokenPairs       []TokenPair `json:"issued_token_pairs"`
-		RevokedRefreshTokensID []string    `json:"revoked_refresh_tokens_id"`
-		RevokedAccessTokensID  []string    `json:"revoked_access_tokens_id"`
+	// TokenPair is the transport-neutral pair of refresh and access token JTIs, kept for compatibility
+	TokenPair = gojwttokensync.TokenPair
+
+	// TokensMessage is the transport-neutral tokens message, kept for compatibility
+	TokensMessage = gojwttokensync.TokensMessage
+)
+
+const (
+	// Disconnected is the state of a connection that has not been established yet
+	Disconnected ConnectionState = iota
+
+	// Connected is the state of an established connection
+	Connected
+
+	// Reconnecting is the state of a lost connection that is being re-established
+	Reconnecting
+
+	// Closed is the state of a connection closed by the application
+	Closed
+
+	// Connecting is the state of a connection that is being established for the first time
+	Connecting
+)
+
+// String returns the string representation of the connection state
+//
+// Returns:
+//
+//   - string: The string representation of the connection state
+func (c ConnectionState) String() string {
+	switch c {
+	case Disconnected:
+		return "disconnected"
+	case Connected:
+		return "connected"
+	case Reconnecting:
+		return "reconnecting"
+	case Closed:
+		return "closed"
+	case Connecting:
+		return "connecting"
+	default:
+		return "unknown"
 	}
+}
+
+const (
+	// WorkQueueTopology publishes the messages to a durable named queue, so each message reaches a single consumer
+	WorkQueueTopology TopologyMode = iota
+
+	// FanoutTopology publishes the messages to a fanout exchange, so each message reaches every bound queue
+	FanoutTopology
+
+	// TopicTopology publishes the messages to a topic exchange, so each message reaches every queue bound with a
+	// matching binding key
+	TopicTopology
 )
+
+// String returns the string representation of the topology mode
+//
+// Returns:
+//
+//   - string: The string representation of the topology mode
+func (t TopologyMode) String() string {
+	switch t {
+	case WorkQueueTopology:
+		return "work_queue"
+	case FanoutTopology:
+		return amqp091.ExchangeFanout
+	case TopicTopology:
+		return amqp091.ExchangeTopic
+	default:
+		return "unknown"
+	}
+}
+
+// Validate validates the topology
+//
+// Returns:
+//
+//   - error: an error if the mode is unknown, or if the exchange is missing in the fanout and topic modes
+func (t Topology) Validate() error {
+	switch t.Mode {
+	case WorkQueueTopology:
+		return nil
+	case FanoutTopology, TopicTopology:
+		if t.Exchange == "" {
+			return ErrEmptyExchangeName
+		}
+		return nil
+	default:
+		return ErrUnknownTopologyMode
+	}
+}
+
+// PublishTarget returns the exchange and the routing key the messages are published with
+//
+// Parameters:
+//
+//   - queueName: the name of the queue used by the work queue mode
+//
+// Returns:
+//
+//   - string: the exchange
+//   - string: the routing key
+func (t Topology) PublishTarget(queueName string) (string, string) {
+	switch t.Mode {
+	case FanoutTopology:
+		return t.Exchange, ""
+	case TopicTopology:
+		return t.Exchange, t.RoutingKey
+	default:
+		return "", queueName
+	}
+}
@@ -46,8 +46,202 @@ func DeclareTokensMessageQueue(ch *amqp091.Channel, queueName string) (
 	return &q, nil
 }
 
+// DeclareTokensMessageExchange creates the durable exchange of the fanout and topic topologies
+//
+// Parameters:
+//
+//   - ch: the RabbitMQ channel
+//   - topology: the topology
+//
+// Returns:
+//
+//   - error: an error if the topology is invalid or the exchange could not be declared
+func DeclareTokensMessageExchange(ch *amqp091.Channel, topology Topology) error {
+	// Check if the channel is nil
+	if ch == nil {
+		return ErrNilChannel
+	}
+
+	// Check if the topology is valid
+	if err := topology.Validate(); err != nil {
+		return err
+	}
+	if topology.Mode == WorkQueueTopology {
+		return nil
+	}
+
+	// Declare a durable exchange
+	return ch.ExchangeDeclare(
+		topology.Exchange,
+		topology.Mode.String(),
+		true,  // durable
+		false, // auto-deleted
+		false, // internal
+		false, // no-wait
+		nil,   // arguments
+	)
+}
+
+// DeclareTokensMessageConsumerQueue creates the queue consumed by a consumer instance. In the work queue mode, it is
+// the durable named queue. In the fanout and topic modes, it is either an exclusive auto-delete queue named by the
+// broker or the durable named queue, bound to the exchange
+//
+// Parameters:
+//
+//   - ch: the RabbitMQ channel
+//   - queueName: the name of the queue
+//   - topology: the topology
+//
+// Returns:
+//
+//   - *amqp091.Queue: the declared queue
+//   - error: an error if the queue could not be declared or bound
+func DeclareTokensMessageConsumerQueue(
+	ch *amqp091.Channel,
+	queueName string,
+	topology Topology,
+) (*amqp091.Queue, error) {
+	// Declare the exchange
+	if err := DeclareTokensMessageExchange(ch, topology); err != nil {
+		return nil, err
+	}
+	if topology.Mode == WorkQueueTopology || !topology.Exclusive {
+		q, err := DeclareTokensMessageQueue(ch, queueName)
+		if err != nil || topology.Mode == WorkQueueTopology {
+			return q, err
+		}
+		return q, bindTokensMessageQueue(ch, q.Name, topology)
+	}
+
+	// Declare an exclusive auto-delete queue named by the broker
+	q, err := ch.QueueDeclare(
+		"",    // name
+		false, // durable
+		true,  // delete when unused
+		true,  // exclusive
+		false, // no-wait
+		nil,   // arguments
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &q, bindTokensMessageQueue(ch, q.Name, topology)
+}
+
+// bindTokensMessageQueue binds a queue to the exchange of the topology
+//
+// Parameters:
+//
+//   - ch: the RabbitMQ channel
+//   - queueName: the name of the queue
+//   - topology: the topology
+//
+// Returns:
+//
+//   - error: an error if the queue could not be bound
+func bindTokensMessageQueue(
+	ch *amqp091.Channel,
+	queueName string,
+	topology Topology,
+) error {
+	bindingKey := ""
+	if topology.Mode == TopicTopology {
+		bindingKey = topology.BindingKey
+		if bindingKey == "" {
+			bindingKey = TopicWildcard
+		}
+	}
+	return ch.QueueBind(queueName, bindingKey, topology.Exchange, false, nil)
+}
+
+// DeadLetterExchangeName returns the name of the dead-letter exchange of a queue
+//
+// Parameters:
+//
+//   - queueName: the name of the queue
+//
+// Returns:
+//
+//   - string: the name of the dead-letter exchange
+func DeadLetterExchangeName(queueName string) string {
+	return queueName + DeadLetterExchangeSuffix
+}
+
+// DeadLetterQueueName returns the name of the dead-letter queue of a queue
+//
+// Parameters:
+//
+//   - queueName: the name of the queue
+//
+// Returns:
+//
+//   - string: the name of the dead-letter queue
+func DeadLetterQueueName(queueName string) string {
+	return queueName + DeadLetterQueueSuffix
+}
+
+// DeclareTokensMessageDeadLetterQueue creates the durable dead-letter exchange of the tokens messages queue, and the
+// durable dead-letter queue bound to it that holds the messages that could not be processed
+//
+// Parameters:
+//
+//   - ch: the RabbitMQ channel
+//   - queueName: the name of the tokens messages queue
+//
+// Returns:
+//
+//   - *amqp091.Queue: the declared dead-letter queue
+//   - error: an error if the exchange or the queue could not be declared
+func DeclareTokensMessageDeadLetterQueue(ch *amqp091.Channel, queueName string) (
+	*amqp091.Queue,
+	error,
+) {
+	// Check if the channel is nil
+	if ch == nil {
+		return nil, ErrNilChannel
+	}
+
+	// Check if the queue name is empty
+	if queueName == "" {
+		return nil, ErrEmptyQueueName
+	}
+
+	// Declare a durable fanout exchange
+	exchangeName := DeadLetterExchangeName(queueName)
+	if err := ch.ExchangeDeclare(
+		exchangeName,
+		amqp091.ExchangeFanout,
+		true,  // durable
+		false, // auto-deleted
+		false, // internal
+		false, // no-wait
+		nil,   // arguments
+	); err != nil {
+		return nil, err
+	}
+
+	// Declare a durable queue
+	q, err := ch.QueueDeclare(
+		DeadLetterQueueName(queueName),
+		true,  // durable
+		false, // delete when unused
+		false, // exclusive
+		false, // no-wait
+		nil,   // arguments
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	// Bind the queue to the exchange
+	if err = ch.QueueBind(q.Name, "", exchangeName, false, nil); err != nil {
+		return nil, err
+	}
+	return &q, nil
+}
+
 // CreateConsumeTokensMessageDeliveryChWithCtx sets up a consumer to receive messages from the specified queue using the
-// provided context
+// provided context. The deliveries must be acknowledged manually
 //
 // Parameters:
 //
@@ -74,14 +268,14 @@ func CreateConsumeTokensMessageDeliveryChWithCtx(
 		return nil, ErrEmptyQueueName
 	}
 
-	// Declare the queue to ensure it exists
+	// Consume the queue
 	deliveryCh, err := ch.ConsumeWithContext(
 		ctx,
-		"",
-		queueName, // name
-		true,      // durable
-		false,     // delete when unused
+		queueName, // queue
+		"",        // consumer
+		false,     // auto-ack
 		false,     // exclusive
+		false,     // no-local
 		false,     // no-wait
 		nil,       // arguments
 	)
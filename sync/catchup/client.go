@@ -0,0 +1,146 @@
+package catchup
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	gojwttokensync "github.com/ralvarezdev/go-jwt/tokensync"
+)
+
+type (
+	// Client is the HTTP client of the catch-up endpoint, which implements the EventSource interface
+	Client struct {
+		url        *url.URL
+		httpClient *http.Client
+	}
+)
+
+// NewClient creates a new catch-up client
+//
+// Parameters:
+//
+//   - rawURL: the URL of the catch-up endpoint of the authoritative service
+//   - httpClient: the HTTP client, which should authenticate the requests (optional, http.DefaultClient is used if
+//     nil)
+//
+// Returns:
+//
+//   - *Client: the catch-up client
+//   - error: an error if the URL is empty or invalid
+func NewClient(rawURL string, httpClient *http.Client) (*Client, error) {
+	// Check if the URL is empty
+	if rawURL == "" {
+		return nil, ErrEmptyURL
+	}
+
+	// Parse the URL
+	parsedURL, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, err
+	}
+
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+
+	return &Client{
+		url:        parsedURL,
+		httpClient: httpClient,
+	}, nil
+}
+
+// GetTokensMessagesSince requests the tokens messages issued after the given time and message ID, ordered by their
+// issue time and message ID
+//
+// Parameters:
+//
+//   - ctx: the context
+//   - since: the issue time of the last received message
+//   - afterMessageID: the message ID of the last received message (optional, the messages issued at the given time
+//     are included if empty)
+//   - limit: the maximum number of messages (optional, the server default is used if not positive)
+//
+// Returns:
+//
+//   - []*gojwttokensync.TokensMessageEnvelope: the tokens message envelopes
+//   - bool: true if there are more messages after the returned ones
+//   - error: an error if the request failed or a message is of an unsupported version
+func (c *Client) GetTokensMessagesSince(
+	ctx context.Context,
+	since time.Time,
+	afterMessageID string,
+	limit int,
+) ([]*gojwttokensync.TokensMessageEnvelope, bool, error) {
+	// Check if the client is nil
+	if c == nil {
+		return nil, false, ErrNilEventSource
+	}
+
+	// Build the request URL
+	requestURL := *c.url
+	query := requestURL.Query()
+	if !since.IsZero() {
+		query.Set(SinceParam, since.UTC().Format(time.RFC3339Nano))
+	}
+	if afterMessageID != "" {
+		query.Set(AfterParam, afterMessageID)
+	}
+	if limit > 0 {
+		query.Set(LimitParam, strconv.Itoa(limit))
+	}
+	requestURL.RawQuery = query.Encode()
+
+	// Send the request
+	req, err := http.NewRequestWithContext(
+		ctx,
+		http.MethodGet,
+		requestURL.String(),
+		nil,
+	)
+	if err != nil {
+		return nil, false, err
+	}
+	req.Header.Set("Accept", "application/json")
+	res, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, false, err
+	}
+	defer func() {
+		_ = res.Body.Close()
+	}()
+
+	// Check the response status
+	if res.StatusCode != http.StatusOK {
+		return nil, false, fmt.Errorf(
+			"%w: %s",
+			ErrUnexpectedResponse,
+			res.Status,
+		)
+	}
+
+	// Decode the response
+	var response Response
+	if err = json.NewDecoder(res.Body).Decode(&response); err != nil {
+		return nil, false, err
+	}
+
+	// Check the messages
+	for _, envelope := range response.Messages {
+		if envelope == nil || envelope.Message == nil {
+			return nil, false, fmt.Errorf(
+				"%w: %w",
+				ErrUnexpectedResponse,
+				gojwttokensync.ErrNilMessage,
+			)
+		}
+		if err = gojwttokensync.CheckTokensMessageVersion(envelope.Version); err != nil {
+			return nil, false, err
+		}
+	}
+	return response.Messages, response.HasMore, nil
+}
@@ -0,0 +1,13 @@
+package catchup
+
+const (
+	// SinceParam is the query parameter with the RFC 3339 time after which the requested messages were issued
+	SinceParam = "since"
+
+	// AfterParam is the query parameter with the message ID of the last received message, which was issued at the
+	// time of the since query parameter
+	AfterParam = "after"
+
+	// LimitParam is the query parameter with the maximum number of requested messages
+	LimitParam = "limit"
+)
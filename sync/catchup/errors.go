@@ -0,0 +1,13 @@
+package catchup
+
+import (
+	"errors"
+)
+
+var (
+	ErrNilEventSource     = errors.New("nil tokens messages event source")
+	ErrEmptyURL           = errors.New("empty catch-up url")
+	ErrInvalidSinceParam  = errors.New("invalid since parameter")
+	ErrInvalidLimitParam  = errors.New("invalid limit parameter")
+	ErrUnexpectedResponse = errors.New("unexpected catch-up response")
+)
@@ -0,0 +1,126 @@
+package catchup
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	gojwttokensync "github.com/ralvarezdev/go-jwt/tokensync"
+)
+
+type (
+	// Handler is the HTTP handler that serves the tokens messages issued after a given time, so the consumers can
+	// catch up with the messages they missed while offline. It must be protected by the application, since the
+	// messages contain the IDs of the issued tokens
+	Handler struct {
+		source gojwttokensync.EventSource
+		logger *slog.Logger
+	}
+)
+
+// NewHandler creates a new catch-up handler
+//
+// Parameters:
+//
+//   - source: the tokens messages event source
+//   - logger: the logger (optional, can be nil)
+//
+// Returns:
+//
+//   - *Handler: the catch-up handler
+//   - error: an error if the event source is nil
+func NewHandler(
+	source gojwttokensync.EventSource,
+	logger *slog.Logger,
+) (*Handler, error) {
+	// Check if the event source is nil
+	if source == nil {
+		return nil, ErrNilEventSource
+	}
+
+	if logger != nil {
+		logger = logger.With(slog.String("component", "sync_catchup_handler"))
+	}
+
+	return &Handler{
+		source: source,
+		logger: logger,
+	}, nil
+}
+
+// ServeHTTP serves a page of the tokens messages issued after the time of the since query parameter and the message
+// ID of the after query parameter
+//
+// Parameters:
+//
+//   - w: the HTTP response writer
+//   - r: the HTTP request
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	// Check if the method is allowed
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", http.MethodGet)
+		http.Error(
+			w,
+			http.StatusText(http.StatusMethodNotAllowed),
+			http.StatusMethodNotAllowed,
+		)
+		return
+	}
+
+	// Parse the query parameters
+	query := r.URL.Query()
+	var since time.Time
+	if rawSince := query.Get(SinceParam); rawSince != "" {
+		parsedSince, err := time.Parse(time.RFC3339Nano, rawSince)
+		if err != nil {
+			http.Error(w, ErrInvalidSinceParam.Error(), http.StatusBadRequest)
+			return
+		}
+		since = parsedSince
+	}
+	afterMessageID := query.Get(AfterParam)
+	var limit int
+	if rawLimit := query.Get(LimitParam); rawLimit != "" {
+		parsedLimit, err := strconv.Atoi(rawLimit)
+		if err != nil || parsedLimit < 0 {
+			http.Error(w, ErrInvalidLimitParam.Error(), http.StatusBadRequest)
+			return
+		}
+		limit = parsedLimit
+	}
+
+	// Get the tokens messages
+	envelopes, hasMore, err := h.source.GetTokensMessagesSince(
+		r.Context(),
+		since,
+		afterMessageID,
+		limit,
+	)
+	if err != nil {
+		if h.logger != nil {
+			h.logger.Error(
+				"Failed to get the tokens messages to catch up",
+				slog.Time("since", since),
+				slog.String("error", err.Error()),
+			)
+		}
+		http.Error(
+			w,
+			http.StatusText(http.StatusInternalServerError),
+			http.StatusInternalServerError,
+		)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(
+		Response{
+			Messages: envelopes,
+			HasMore:  hasMore,
+		},
+	)
+}
@@ -0,0 +1,188 @@
+package catchup
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	godatabasessql "github.com/ralvarezdev/go-databases/sql"
+	gojwtsyncsqlite "github.com/ralvarezdev/go-jwt/sync/sqlite"
+	gojwttokensync "github.com/ralvarezdev/go-jwt/tokensync"
+	_ "modernc.org/sqlite"
+)
+
+// newTestClient serves the catch-up handler of an event log with the given messages, issued at the same time, and
+// returns a client of the handler
+//
+// Parameters:
+//
+//   - t: The test
+//   - count: The number of messages
+//
+// Returns:
+//
+//   - *Client: The catch-up client
+//   - []string: The message IDs of the appended messages
+func newTestClient(t *testing.T, count int) (*Client, []string) {
+	t.Helper()
+
+	// Create the event log
+	service, err := godatabasessql.NewDefaultService(
+		&godatabasessql.Config{
+			DriverName:     "sqlite",
+			DataSourceName: filepath.Join(t.TempDir(), "sync.db"),
+		},
+	)
+	if err != nil {
+		t.Fatalf("failed to create the service: %v", err)
+	}
+	eventLog, err := gojwtsyncsqlite.NewEventLog(service, nil)
+	if err != nil {
+		t.Fatalf("failed to create the event log: %v", err)
+	}
+	if err = eventLog.Connect(context.Background()); err != nil {
+		t.Fatalf("failed to connect: %v", err)
+	}
+	t.Cleanup(
+		func() {
+			_ = eventLog.Disconnect()
+		},
+	)
+
+	// Append the messages
+	issuedAt := time.Now()
+	messageIDs := make([]string, 0, count)
+	for range count {
+		envelope, err := gojwttokensync.NewTokensMessageEnvelope(
+			&gojwttokensync.TokensMessage{RevokedAccessTokensID: []string{"access"}},
+			"test",
+		)
+		if err != nil {
+			t.Fatalf("NewTokensMessageEnvelope() error = %v", err)
+		}
+		envelope.IssuedAt = issuedAt
+		if err = eventLog.AppendTokensMessage(context.Background(), envelope); err != nil {
+			t.Fatalf("AppendTokensMessage() error = %v", err)
+		}
+		messageIDs = append(messageIDs, envelope.MessageID)
+	}
+
+	// Serve the handler
+	handler, err := NewHandler(eventLog, nil)
+	if err != nil {
+		t.Fatalf("NewHandler() error = %v", err)
+	}
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	client, err := NewClient(server.URL, server.Client())
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	return client, messageIDs
+}
+
+func TestClient_GetTokensMessagesSince(t *testing.T) {
+	client, messageIDs := newTestClient(t, 5)
+
+	tests := []struct {
+		name      string
+		limit     int
+		wantPages int
+	}{
+		{name: "one message per page", limit: 1, wantPages: 5},
+		{name: "page boundary inside the shared issue time", limit: 2, wantPages: 3},
+		{name: "single page", limit: 10, wantPages: 1},
+	}
+	for _, test := range tests {
+		t.Run(
+			test.name, func(t *testing.T) {
+				// Page through the messages with the cursor of the last received one
+				received := make(map[string]int)
+				var since time.Time
+				var afterMessageID string
+				pages := 0
+				for {
+					envelopes, hasMore, err := client.GetTokensMessagesSince(
+						context.Background(),
+						since,
+						afterMessageID,
+						test.limit,
+					)
+					if err != nil {
+						t.Fatalf("GetTokensMessagesSince() error = %v", err)
+					}
+					pages++
+					for _, envelope := range envelopes {
+						received[envelope.MessageID]++
+						since = envelope.IssuedAt
+						afterMessageID = envelope.MessageID
+					}
+					if !hasMore || len(envelopes) == 0 {
+						break
+					}
+				}
+
+				if pages != test.wantPages {
+					t.Errorf("pages = %d, want %d", pages, test.wantPages)
+				}
+				if len(received) != len(messageIDs) {
+					t.Errorf("received %d messages, want %d", len(received), len(messageIDs))
+				}
+				for _, messageID := range messageIDs {
+					if received[messageID] != 1 {
+						t.Errorf("message %q received %d times, want 1", messageID, received[messageID])
+					}
+				}
+			},
+		)
+	}
+}
+
+func TestHandler_ServeHTTP_InvalidRequest(t *testing.T) {
+	// The invalid requests never reach the event source
+	handler, err := NewHandler(&Client{}, nil)
+	if err != nil {
+		t.Fatalf("NewHandler() error = %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		method     string
+		target     string
+		wantStatus int
+	}{
+		{
+			name:       "method not allowed",
+			method:     http.MethodPost,
+			target:     "/",
+			wantStatus: http.StatusMethodNotAllowed,
+		},
+		{
+			name:       "invalid since parameter",
+			method:     http.MethodGet,
+			target:     "/?" + SinceParam + "=yesterday",
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "invalid limit parameter",
+			method:     http.MethodGet,
+			target:     "/?" + LimitParam + "=-1",
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+	for _, test := range tests {
+		t.Run(
+			test.name, func(t *testing.T) {
+				recorder := httptest.NewRecorder()
+				handler.ServeHTTP(recorder, httptest.NewRequest(test.method, test.target, nil))
+				if recorder.Code != test.wantStatus {
+					t.Errorf("ServeHTTP() status = %d, want %d", recorder.Code, test.wantStatus)
+				}
+			},
+		)
+	}
+}
@@ -0,0 +1,13 @@
+package catchup
+
+import (
+	gojwttokensync "github.com/ralvarezdev/go-jwt/tokensync"
+)
+
+type (
+	// Response is the response of the catch-up endpoint
+	Response struct {
+		Messages []*gojwttokensync.TokensMessageEnvelope `json:"messages"`
+		HasMore  bool                                    `json:"has_more"`
+	}
+)
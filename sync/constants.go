@@ -0,0 +1,22 @@
+package sync
+
+import (
+	"time"
+)
+
+var (
+	// DefaultCatchUpLimit is the default number of tokens messages requested per catch-up page
+	DefaultCatchUpLimit = 1000
+
+	// MaxCatchUpLimit is the maximum number of tokens messages returned per catch-up page
+	MaxCatchUpLimit = 10000
+
+	// DefaultCatchUpOverlap is the default time subtracted from the last sync timestamp when catching up, so the
+	// messages issued by producers with a skewed clock are not missed. The overlapped messages are applied again,
+	// which is a no-op
+	DefaultCatchUpOverlap = time.Minute
+
+	// DefaultSyncInterval is the default minimum interval between the updates of the last sync timestamp while
+	// consuming the live messages
+	DefaultSyncInterval = time.Minute
+)
@@ -5,6 +5,38 @@ const (
 	CreateSyncTokensTableQuery = `
 CREATE TABLE IF NOT EXISTS sync_tokens (id INTEGER PRIMARY KEY AUTOINCREMENT, updated_at DATETIME NOT NULL);
 `
+
+	// CreateTokensMessagesLogTableQuery is the SQL query to create the tokens_messages_log table. The issue time is
+	// stored in Unix nanoseconds, so it is compared as a number
+	CreateTokensMessagesLogTableQuery = `
+CREATE TABLE IF NOT EXISTS tokens_messages_log (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	message_id TEXT NOT NULL UNIQUE,
+	issued_at INTEGER NOT NULL,
+	body TEXT NOT NULL
+);
+`
+
+	// CreateTokensMessagesLogCursorIndexQuery is the SQL query to create the index used to page the tokens messages by
+	// their issue time and message ID, and to delete the messages issued before a given time
+	CreateTokensMessagesLogCursorIndexQuery = `
+CREATE INDEX IF NOT EXISTS tokens_messages_log_cursor_idx ON tokens_messages_log (issued_at, message_id);
+`
+
+	// CreateRevokedTokensTableQuery is the SQL query to create the revoked_tokens table, which remembers the revoked
+	// token IDs until their expiration time in Unix seconds
+	CreateRevokedTokensTableQuery = `
+CREATE TABLE IF NOT EXISTS revoked_tokens (
+	token TEXT NOT NULL,
+	id TEXT NOT NULL,
+	expires_at INTEGER NOT NULL,
+	PRIMARY KEY (token, id)
+);
+`
+
+	// MaxRevokedTokensPerStatement is the maximum number of revoked token IDs inserted by a single statement, to stay
+	// below the SQLite bound parameters limit
+	MaxRevokedTokensPerStatement = 200
 )
 
 var (
@@ -16,5 +48,37 @@ INSERT INTO sync_tokens (updated_at) VALUES (?);
 	// GetLastSyncTokensUpdatedAtQuery is the SQL query to get the last sync tokens record
 	GetLastSyncTokensUpdatedAtQuery = `
 SELECT updated_at FROM sync_tokens ORDER BY updated_at DESC LIMIT 1;
+`
+
+	// InsertTokensMessageLogQuery is the SQL query to append a tokens message to the log, ignoring the duplicates
+	InsertTokensMessageLogQuery = `
+INSERT OR IGNORE INTO tokens_messages_log (message_id, issued_at, body) VALUES (?, ?, ?);
+`
+
+	// GetTokensMessagesLogSinceQuery is the SQL query to get the tokens messages after a given issue time and message
+	// ID, so the messages that share an issue time are never skipped between pages
+	GetTokensMessagesLogSinceQuery = `
+SELECT body FROM tokens_messages_log WHERE (issued_at, message_id) > (?, ?) ORDER BY issued_at, message_id LIMIT ?;
+`
+
+	// DeleteTokensMessagesLogBeforeQuery is the SQL query to delete the tokens messages issued before a given time
+	DeleteTokensMessagesLogBeforeQuery = `
+DELETE FROM tokens_messages_log WHERE issued_at < ?;
+`
+)
+
+var (
+	// InsertRevokedTokensQueryPrefix is the prefix of the SQL query to remember multiple revoked token IDs, followed by
+	// the values placeholders. A revoked token ID marked again is remembered for a new retention period
+	InsertRevokedTokensQueryPrefix = `INSERT OR REPLACE INTO revoked_tokens (token, id, expires_at) VALUES `
+
+	// IsTokenRevokedQuery is the SQL query to check if a token ID was revoked and is still remembered
+	IsTokenRevokedQuery = `
+SELECT COUNT(1) FROM revoked_tokens WHERE token = ? AND id = ? AND expires_at > ?;
+`
+
+	// DeleteExpiredRevokedTokensQuery is the SQL query to forget the revoked token IDs whose retention period ended
+	DeleteExpiredRevokedTokensQuery = `
+DELETE FROM revoked_tokens WHERE expires_at <= ?;
 `
 )
@@ -0,0 +1,276 @@
+package sync
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"math"
+	"time"
+
+	godatabases "github.com/ralvarezdev/go-databases"
+	godatabasessql "github.com/ralvarezdev/go-databases/sql"
+	gojwtsync "github.com/ralvarezdev/go-jwt/sync"
+	gojwttokensync "github.com/ralvarezdev/go-jwt/tokensync"
+)
+
+type (
+	// EventLog is the SQLite implementation of the EventLog interface
+	EventLog struct {
+		godatabasessql.Service
+		logger *slog.Logger
+	}
+)
+
+// NewEventLog creates a new EventLog
+//
+// Parameters:
+//
+//   - service: the SQL connection service
+//   - logger: the logger (optional, can be nil)
+//
+// Returns:
+//
+//   - *EventLog: the EventLog instance
+//   - error: an error if the service is nil
+func NewEventLog(
+	service godatabasessql.Service,
+	logger *slog.Logger,
+) (*EventLog, error) {
+	// Check if the service is nil
+	if service == nil {
+		return nil, godatabases.ErrNilService
+	}
+
+	if logger != nil {
+		logger = logger.With(
+			slog.String("component", "sync_sqlite_event_log"),
+		)
+	}
+
+	return &EventLog{
+		Service: service,
+		logger:  logger,
+	}, nil
+}
+
+// Connect opens the database connection
+//
+// Parameters:
+//
+//   - ctx: the context
+//
+// Returns:
+//
+//   - error: an error if the connection could not be opened
+func (e *EventLog) Connect(ctx context.Context) error {
+	// Check if the event log is nil
+	if e == nil {
+		return godatabases.ErrNilService
+	}
+
+	// Connect to the database
+	db, err := e.Service.Connect()
+	if err != nil {
+		if e.logger != nil {
+			e.logger.Error(
+				"Failed to connect to database",
+				slog.String("error", err.Error()),
+			)
+		}
+		return err
+	}
+
+	// Ensure the table exists
+	for _, query := range []string{
+		CreateTokensMessagesLogTableQuery,
+		CreateTokensMessagesLogCursorIndexQuery,
+	} {
+		if _, err = db.ExecContext(ctx, query); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// AppendTokensMessage appends a tokens message envelope to the log, ignoring the already appended message IDs
+//
+// Parameters:
+//
+//   - ctx: the context
+//   - envelope: the tokens message envelope
+//
+// Returns:
+//
+//   - error: an error if the message could not be appended
+func (e *EventLog) AppendTokensMessage(
+	ctx context.Context,
+	envelope *gojwttokensync.TokensMessageEnvelope,
+) error {
+	// Check if the event log is nil
+	if e == nil {
+		return godatabases.ErrNilService
+	}
+
+	// Check if the envelope is nil
+	if envelope == nil || envelope.Message == nil {
+		return gojwttokensync.ErrNilMessage
+	}
+
+	// Check if the message ID is empty
+	if envelope.MessageID == "" {
+		return gojwttokensync.ErrMissingMessageID
+	}
+
+	// Marshal the envelope to JSON
+	body, err := json.Marshal(envelope)
+	if err != nil {
+		return err
+	}
+
+	// Insert the message
+	if _, err = e.ExecWithCtx(
+		ctx,
+		&InsertTokensMessageLogQuery,
+		envelope.MessageID,
+		envelope.IssuedAt.UnixNano(),
+		string(body),
+	); err != nil {
+		if e.logger != nil {
+			e.logger.Error(
+				"Failed to append tokens message to the log",
+				slog.String("message_id", envelope.MessageID),
+				slog.String("error", err.Error()),
+			)
+		}
+		return err
+	}
+	return nil
+}
+
+// GetTokensMessagesSince gets the tokens messages issued after the given time and message ID, ordered by their issue
+// time and message ID
+//
+// Parameters:
+//
+//   - ctx: the context
+//   - since: the issue time of the last received message (optional, every message is included if zero)
+//   - afterMessageID: the message ID of the last received message (optional, the messages issued at the given time
+//     are included if empty)
+//   - limit: the maximum number of messages (optional, gojwtsync.DefaultCatchUpLimit is used if not positive), capped
+//     to gojwtsync.MaxCatchUpLimit
+//
+// Returns:
+//
+//   - []*gojwttokensync.TokensMessageEnvelope: the tokens message envelopes
+//   - bool: true if there are more messages after the returned ones
+//   - error: an error if the messages could not be retrieved
+func (e *EventLog) GetTokensMessagesSince(
+	ctx context.Context,
+	since time.Time,
+	afterMessageID string,
+	limit int,
+) ([]*gojwttokensync.TokensMessageEnvelope, bool, error) {
+	// Check if the event log is nil
+	if e == nil {
+		return nil, false, godatabases.ErrNilService
+	}
+
+	// Check if the limit is valid
+	if limit <= 0 {
+		limit = gojwtsync.DefaultCatchUpLimit
+	}
+	limit = min(limit, gojwtsync.MaxCatchUpLimit)
+
+	// Get the database connection
+	db, err := e.DB()
+	if err != nil {
+		return nil, false, err
+	}
+
+	// Check if the issue time is set, the Unix time of the zero time cannot be represented in nanoseconds
+	sinceUnixNano := int64(math.MinInt64)
+	if !since.IsZero() {
+		sinceUnixNano = since.UnixNano()
+	}
+
+	// Query one more message than the limit to know if there are more messages
+	rows, err := db.QueryContext(
+		ctx,
+		GetTokensMessagesLogSinceQuery,
+		sinceUnixNano,
+		afterMessageID,
+		limit+1,
+	)
+	if err != nil {
+		if e.logger != nil {
+			e.logger.Error(
+				"Failed to query the tokens messages log",
+				slog.String("error", err.Error()),
+			)
+		}
+		return nil, false, err
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	envelopes := make([]*gojwttokensync.TokensMessageEnvelope, 0, limit)
+	for rows.Next() {
+		var body string
+		if err = rows.Scan(&body); err != nil {
+			return nil, false, err
+		}
+
+		// Unmarshal the envelope
+		var envelope gojwttokensync.TokensMessageEnvelope
+		if err = json.Unmarshal([]byte(body), &envelope); err != nil {
+			return nil, false, err
+		}
+		envelopes = append(envelopes, &envelope)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, false, err
+	}
+
+	// Check if there are more messages
+	if len(envelopes) > limit {
+		return envelopes[:limit], true, nil
+	}
+	return envelopes, false, nil
+}
+
+// DeleteTokensMessagesBefore deletes the tokens messages issued before the given time, which must be older than the
+// longest offline period of the consumers
+//
+// Parameters:
+//
+//   - ctx: the context
+//   - before: the time before which the messages were issued
+//
+// Returns:
+//
+//   - error: an error if the messages could not be deleted
+func (e *EventLog) DeleteTokensMessagesBefore(
+	ctx context.Context,
+	before time.Time,
+) error {
+	// Check if the event log is nil
+	if e == nil {
+		return godatabases.ErrNilService
+	}
+
+	if _, err := e.ExecWithCtx(
+		ctx,
+		&DeleteTokensMessagesLogBeforeQuery,
+		before.UnixNano(),
+	); err != nil {
+		if e.logger != nil {
+			e.logger.Error(
+				"Failed to delete the tokens messages log",
+				slog.String("error", err.Error()),
+			)
+		}
+		return err
+	}
+	return nil
+}
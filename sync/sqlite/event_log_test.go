@@ -0,0 +1,150 @@
+package sync
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	godatabasessql "github.com/ralvarezdev/go-databases/sql"
+	gojwttokensync "github.com/ralvarezdev/go-jwt/tokensync"
+	_ "modernc.org/sqlite"
+)
+
+// newTestEventLog creates an event log connected to a new SQLite database file
+//
+// Parameters:
+//
+//   - t: The test
+//
+// Returns:
+//
+//   - *EventLog: The event log
+func newTestEventLog(t *testing.T) *EventLog {
+	t.Helper()
+
+	service, err := godatabasessql.NewDefaultService(
+		&godatabasessql.Config{
+			DriverName:     "sqlite",
+			DataSourceName: filepath.Join(t.TempDir(), "sync.db"),
+		},
+	)
+	if err != nil {
+		t.Fatalf("failed to create the service: %v", err)
+	}
+	eventLog, err := NewEventLog(service, nil)
+	if err != nil {
+		t.Fatalf("failed to create the event log: %v", err)
+	}
+	if err = eventLog.Connect(context.Background()); err != nil {
+		t.Fatalf("failed to connect: %v", err)
+	}
+	t.Cleanup(
+		func() {
+			_ = eventLog.Disconnect()
+		},
+	)
+	return eventLog
+}
+
+func TestEventLog_GetTokensMessagesSince(t *testing.T) {
+	ctx := context.Background()
+	eventLog := newTestEventLog(t)
+
+	// Append messages that share their issue time, around one issued before and one issued after them
+	issuedAt := time.Now()
+	appended := make(map[string]struct{})
+	for i, offset := range []time.Duration{-time.Second, 0, 0, 0, 0, 0, time.Second} {
+		envelope, err := gojwttokensync.NewTokensMessageEnvelope(
+			&gojwttokensync.TokensMessage{RevokedAccessTokensID: []string{"access"}},
+			"test",
+		)
+		if err != nil {
+			t.Fatalf("NewTokensMessageEnvelope() error = %v", err)
+		}
+		envelope.IssuedAt = issuedAt.Add(offset)
+		if err = eventLog.AppendTokensMessage(ctx, envelope); err != nil {
+			t.Fatalf("AppendTokensMessage() error = %v", err)
+		}
+		if i > 0 {
+			appended[envelope.MessageID] = struct{}{}
+		}
+	}
+
+	tests := []struct {
+		name  string
+		limit int
+	}{
+		{name: "page boundary inside the shared issue time", limit: 2},
+		{name: "one message per page", limit: 1},
+		{name: "single page", limit: 10},
+	}
+	for _, test := range tests {
+		t.Run(
+			test.name, func(t *testing.T) {
+				// Page through the messages issued at or after the shared issue time
+				received := make(map[string]int)
+				since := issuedAt
+				var afterMessageID string
+				for {
+					envelopes, hasMore, err := eventLog.GetTokensMessagesSince(
+						ctx,
+						since,
+						afterMessageID,
+						test.limit,
+					)
+					if err != nil {
+						t.Fatalf("GetTokensMessagesSince() error = %v", err)
+					}
+					for _, envelope := range envelopes {
+						received[envelope.MessageID]++
+						since = envelope.IssuedAt
+						afterMessageID = envelope.MessageID
+					}
+					if !hasMore || len(envelopes) == 0 {
+						break
+					}
+				}
+
+				if len(received) != len(appended) {
+					t.Errorf("received %d messages, want %d", len(received), len(appended))
+				}
+				for messageID := range appended {
+					if received[messageID] != 1 {
+						t.Errorf("message %q received %d times, want 1", messageID, received[messageID])
+					}
+				}
+			},
+		)
+	}
+}
+
+func TestEventLog_GetTokensMessagesSince_ZeroTime(t *testing.T) {
+	ctx := context.Background()
+	eventLog := newTestEventLog(t)
+
+	// Append messages issued at different times
+	issuedAt := time.Now()
+	for _, offset := range []time.Duration{-time.Hour, 0, time.Hour} {
+		envelope, err := gojwttokensync.NewTokensMessageEnvelope(
+			&gojwttokensync.TokensMessage{RevokedAccessTokensID: []string{"access"}},
+			"test",
+		)
+		if err != nil {
+			t.Fatalf("NewTokensMessageEnvelope() error = %v", err)
+		}
+		envelope.IssuedAt = issuedAt.Add(offset)
+		if err = eventLog.AppendTokensMessage(ctx, envelope); err != nil {
+			t.Fatalf("AppendTokensMessage() error = %v", err)
+		}
+	}
+
+	// The zero time includes every message
+	envelopes, hasMore, err := eventLog.GetTokensMessagesSince(ctx, time.Time{}, "", 10)
+	if err != nil {
+		t.Fatalf("GetTokensMessagesSince() error = %v", err)
+	}
+	if len(envelopes) != 3 || hasMore {
+		t.Errorf("GetTokensMessagesSince() = %d messages, %v, want 3, false", len(envelopes), hasMore)
+	}
+}
@@ -0,0 +1,239 @@
+package sync
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"time"
+
+	godatabases "github.com/ralvarezdev/go-databases"
+	godatabasessql "github.com/ralvarezdev/go-databases/sql"
+	gojwttoken "github.com/ralvarezdev/go-jwt/token"
+	gojwttokensync "github.com/ralvarezdev/go-jwt/tokensync"
+)
+
+type (
+	// RevocationStore is the SQLite implementation of the RevocationStore interface, which remembers the revoked token
+	// IDs for a retention period. Unlike the in-memory store, the revocations stay sticky across restarts
+	RevocationStore struct {
+		godatabasessql.Service
+		retention time.Duration
+		now       func() time.Time
+		logger    *slog.Logger
+	}
+)
+
+// NewRevocationStore creates a new RevocationStore
+//
+// Parameters:
+//
+//   - service: the SQL connection service
+//   - retention: the time a revoked token ID is remembered (optional, gojwttokensync.DefaultRevocationRetention is
+//     used if not positive)
+//   - now: the clock (optional, time.Now is used if nil)
+//   - logger: the logger (optional, can be nil)
+//
+// Returns:
+//
+//   - *RevocationStore: the RevocationStore instance
+//   - error: an error if the service is nil
+func NewRevocationStore(
+	service godatabasessql.Service,
+	retention time.Duration,
+	now func() time.Time,
+	logger *slog.Logger,
+) (*RevocationStore, error) {
+	// Check if the service is nil
+	if service == nil {
+		return nil, godatabases.ErrNilService
+	}
+
+	if retention <= 0 {
+		retention = gojwttokensync.DefaultRevocationRetention
+	}
+	if now == nil {
+		now = time.Now
+	}
+
+	if logger != nil {
+		logger = logger.With(
+			slog.String("component", "sync_sqlite_revocation_store"),
+		)
+	}
+
+	return &RevocationStore{
+		Service:   service,
+		retention: retention,
+		now:       now,
+		logger:    logger,
+	}, nil
+}
+
+// Connect opens the database connection
+//
+// Parameters:
+//
+//   - ctx: the context
+//
+// Returns:
+//
+//   - error: an error if the connection could not be opened
+func (r *RevocationStore) Connect(ctx context.Context) error {
+	// Check if the revocation store is nil
+	if r == nil {
+		return godatabases.ErrNilService
+	}
+
+	// Connect to the database
+	db, err := r.Service.Connect()
+	if err != nil {
+		if r.logger != nil {
+			r.logger.Error(
+				"Failed to connect to database",
+				slog.String("error", err.Error()),
+			)
+		}
+		return err
+	}
+
+	// Ensure the table exists
+	_, err = db.ExecContext(ctx, CreateRevokedTokensTableQuery)
+	return err
+}
+
+// MarkRevoked remembers the revoked token IDs
+//
+// Parameters:
+//
+//   - ctx: the context
+//   - token: the token type
+//   - ids: the revoked token IDs
+//
+// Returns:
+//
+//   - error: an error if the revoked token IDs could not be stored
+func (r *RevocationStore) MarkRevoked(
+	ctx context.Context,
+	token gojwttoken.Token,
+	ids []string,
+) error {
+	// Check if the revocation store is nil
+	if r == nil {
+		return godatabases.ErrNilService
+	}
+
+	// Check if there are token IDs to remember
+	if len(ids) == 0 {
+		return nil
+	}
+
+	// Get the database connection
+	db, err := r.DB()
+	if err != nil {
+		return err
+	}
+
+	// Insert the revoked token IDs by chunks
+	expiresAt := r.now().Add(r.retention).Unix()
+	for start := 0; start < len(ids); start += MaxRevokedTokensPerStatement {
+		chunk := ids[start:min(start+MaxRevokedTokensPerStatement, len(ids))]
+		params := make([]any, 0, len(chunk)*3)
+		for _, id := range chunk {
+			params = append(params, token.String(), id, expiresAt)
+		}
+		placeholders := strings.TrimSuffix(strings.Repeat("(?, ?, ?), ", len(chunk)), ", ")
+		if _, err = db.ExecContext(
+			ctx,
+			InsertRevokedTokensQueryPrefix+placeholders,
+			params...,
+		); err != nil {
+			if r.logger != nil {
+				r.logger.Error(
+					"Failed to store the revoked token IDs",
+					slog.String("token", token.String()),
+					slog.String("error", err.Error()),
+				)
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+// IsRevoked checks if a token ID was revoked
+//
+// Parameters:
+//
+//   - ctx: the context
+//   - token: the token type
+//   - id: the token ID
+//
+// Returns:
+//
+//   - bool: true if the token ID was revoked within the retention period
+//   - error: an error if the revoked token IDs could not be checked
+func (r *RevocationStore) IsRevoked(
+	ctx context.Context,
+	token gojwttoken.Token,
+	id string,
+) (bool, error) {
+	// Check if the revocation store is nil
+	if r == nil {
+		return false, godatabases.ErrNilService
+	}
+
+	var revoked bool
+	row, err := r.QueryRowWithCtx(
+		ctx,
+		&IsTokenRevokedQuery,
+		token.String(),
+		id,
+		r.now().Unix(),
+	)
+	if err == nil {
+		err = row.Scan(&revoked)
+	}
+	if err != nil {
+		if r.logger != nil {
+			r.logger.Error(
+				"Failed to check the revoked token ID",
+				slog.String("token", token.String()),
+				slog.String("id", id),
+				slog.String("error", err.Error()),
+			)
+		}
+		return false, err
+	}
+	return revoked, nil
+}
+
+// DeleteExpired forgets the revoked token IDs whose retention period ended
+//
+// Parameters:
+//
+//   - ctx: the context
+//
+// Returns:
+//
+//   - error: an error if the revoked token IDs could not be deleted
+func (r *RevocationStore) DeleteExpired(ctx context.Context) error {
+	// Check if the revocation store is nil
+	if r == nil {
+		return godatabases.ErrNilService
+	}
+
+	if _, err := r.ExecWithCtx(
+		ctx,
+		&DeleteExpiredRevokedTokensQuery,
+		r.now().Unix(),
+	); err != nil {
+		if r.logger != nil {
+			r.logger.Error(
+				"Failed to delete the expired revoked token IDs",
+				slog.String("error", err.Error()),
+			)
+		}
+		return err
+	}
+	return nil
+}
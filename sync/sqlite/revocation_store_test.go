@@ -0,0 +1,111 @@
+package sync
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	godatabasessql "github.com/ralvarezdev/go-databases/sql"
+	gojwttoken "github.com/ralvarezdev/go-jwt/token"
+	_ "modernc.org/sqlite"
+)
+
+// newTestRevocationStore creates a revocation store connected to the given SQLite database file
+//
+// Parameters:
+//
+//   - t: The test
+//   - path: The database file path
+//   - now: The clock
+//
+// Returns:
+//
+//   - *RevocationStore: The revocation store
+func newTestRevocationStore(
+	t *testing.T,
+	path string,
+	now func() time.Time,
+) *RevocationStore {
+	t.Helper()
+
+	service, err := godatabasessql.NewDefaultService(
+		&godatabasessql.Config{
+			DriverName:     "sqlite",
+			DataSourceName: path,
+		},
+	)
+	if err != nil {
+		t.Fatalf("failed to create the service: %v", err)
+	}
+	revocationStore, err := NewRevocationStore(service, time.Hour, now, nil)
+	if err != nil {
+		t.Fatalf("failed to create the revocation store: %v", err)
+	}
+	if err = revocationStore.Connect(context.Background()); err != nil {
+		t.Fatalf("failed to connect: %v", err)
+	}
+	t.Cleanup(
+		func() {
+			_ = revocationStore.Disconnect()
+		},
+	)
+	return revocationStore
+}
+
+func TestRevocationStore_IsRevoked(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "sync.db")
+	now := time.Now()
+	clock := func() time.Time {
+		return now
+	}
+
+	// Remember the revoked token IDs, and forget them by closing the store
+	revocationStore := newTestRevocationStore(t, path, clock)
+	if err := revocationStore.MarkRevoked(
+		ctx,
+		gojwttoken.RefreshToken,
+		[]string{"revoked"},
+	); err != nil {
+		t.Fatalf("MarkRevoked() error = %v", err)
+	}
+	if err := revocationStore.Disconnect(); err != nil {
+		t.Fatalf("Disconnect() error = %v", err)
+	}
+
+	// The revocations must survive a restart
+	revocationStore = newTestRevocationStore(t, path, clock)
+
+	tests := []struct {
+		name    string
+		advance time.Duration
+		token   gojwttoken.Token
+		id      string
+		want    bool
+	}{
+		{name: "revoked", token: gojwttoken.RefreshToken, id: "revoked", want: true},
+		{name: "other token type", token: gojwttoken.AccessToken, id: "revoked"},
+		{name: "not revoked", token: gojwttoken.RefreshToken, id: "valid"},
+		{
+			name:    "retention period ended",
+			advance: 2 * time.Hour,
+			token:   gojwttoken.RefreshToken,
+			id:      "revoked",
+		},
+	}
+	for _, test := range tests {
+		t.Run(
+			test.name, func(t *testing.T) {
+				now = now.Add(test.advance)
+				revoked, err := revocationStore.IsRevoked(ctx, test.token, test.id)
+				if err != nil {
+					t.Fatalf("IsRevoked() error = %v", err)
+				}
+				if revoked != test.want {
+					t.Errorf("IsRevoked() = %v, want %v", revoked, test.want)
+				}
+			},
+		)
+	}
+}
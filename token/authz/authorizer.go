@@ -0,0 +1,187 @@
+package authz
+
+import (
+	"fmt"
+
+	"github.com/golang-jwt/jwt/v5"
+	gojwt "github.com/ralvarezdev/go-jwt"
+)
+
+type (
+	// Authorizer checks the authorization policies against the token claims, expanding the roles into the
+	// permissions they grant
+	Authorizer struct {
+		roles map[string]Role
+	}
+)
+
+// NewAuthorizer creates a new authorizer
+//
+// Parameters:
+//
+//   - roles: The role definitions by role name (optional, the roles grant no permissions if nil)
+//
+// Returns:
+//
+//   - *Authorizer: The authorizer
+//   - error: An error if the inherited roles have a cycle
+func NewAuthorizer(roles map[string]Role) (*Authorizer, error) {
+	// Copy the role definitions
+	copied := make(map[string]Role, len(roles))
+	for name, role := range roles {
+		copied[name] = Role{
+			Permissions: append([]string(nil), role.Permissions...),
+			Inherits:    append([]string(nil), role.Inherits...),
+		}
+	}
+	authorizer := &Authorizer{roles: copied}
+
+	// Check the inherited roles for cycles
+	for name := range copied {
+		if err := authorizer.checkCycle(name, map[string]bool{}); err != nil {
+			return nil, err
+		}
+	}
+	return authorizer, nil
+}
+
+// checkCycle checks if the given role inherits itself
+//
+// Parameters:
+//
+//   - name: The role name
+//   - path: The roles visited on the current inheritance path
+//
+// Returns:
+//
+//   - error: ErrInheritedRoleCycle if the role inherits itself
+func (a *Authorizer) checkCycle(name string, path map[string]bool) error {
+	if path[name] {
+		return fmt.Errorf("%w: %s", ErrInheritedRoleCycle, name)
+	}
+	path[name] = true
+	for _, inherited := range a.roles[name].Inherits {
+		if err := a.checkCycle(inherited, path); err != nil {
+			return err
+		}
+	}
+	delete(path, name)
+	return nil
+}
+
+// expandRoles returns the given roles together with all the roles they inherit
+//
+// Parameters:
+//
+//   - roles: The granted roles
+//
+// Returns:
+//
+//   - []string: The granted and inherited roles
+func (a *Authorizer) expandRoles(roles []string) []string {
+	visited := make(map[string]bool, len(roles))
+	expanded := make([]string, 0, len(roles))
+	pending := append([]string(nil), roles...)
+	for len(pending) > 0 {
+		name := pending[len(pending)-1]
+		pending = pending[:len(pending)-1]
+		if visited[name] {
+			continue
+		}
+		visited[name] = true
+		expanded = append(expanded, name)
+		pending = append(pending, a.roles[name].Inherits...)
+	}
+	return expanded
+}
+
+// Roles returns the roles granted by the roles claim, including the inherited ones
+//
+// Parameters:
+//
+//   - claims: The token claims
+//
+// Returns:
+//
+//   - []string: The granted roles
+func (a *Authorizer) Roles(claims jwt.MapClaims) []string {
+	if a == nil {
+		return gojwt.GetClaimsStrings(claims, gojwt.RolesClaim)
+	}
+	return a.expandRoles(gojwt.GetClaimsStrings(claims, gojwt.RolesClaim))
+}
+
+// Permissions returns the permissions granted by the permissions claim and by the granted roles
+//
+// Parameters:
+//
+//   - claims: The token claims
+//
+// Returns:
+//
+//   - []string: The granted permissions, which may contain wildcards
+func (a *Authorizer) Permissions(claims jwt.MapClaims) []string {
+	permissions := gojwt.GetClaimsStrings(claims, gojwt.PermissionsClaim)
+	if a == nil {
+		return permissions
+	}
+	for _, role := range a.Roles(claims) {
+		permissions = append(permissions, a.roles[role].Permissions...)
+	}
+	return permissions
+}
+
+// Authorize checks if the token claims satisfy the policy. The granted scopes, permissions and roles are all matched
+// as patterns, so a granted * role satisfies every required role, like a granted * permission satisfies every
+// required permission
+//
+// Parameters:
+//
+//   - claims: The token claims
+//   - policy: The authorization policy
+//
+// Returns:
+//
+//   - error: ErrInsufficientScope, ErrMissingPermission or ErrMissingRole if the policy is not satisfied
+func (a *Authorizer) Authorize(claims jwt.MapClaims, policy Policy) error {
+	if policy.IsEmpty() {
+		return nil
+	}
+
+	// Check if the claims are nil
+	if claims == nil {
+		return ErrNilClaims
+	}
+
+	// Check the scopes
+	if len(policy.Scopes) > 0 {
+		if scope, ok := MatchAll(
+			gojwt.GetClaimsScopes(claims),
+			policy.Scopes,
+		); !ok {
+			return fmt.Errorf("%w: %s", ErrInsufficientScope, scope)
+		}
+	}
+
+	// Check the permissions
+	if len(policy.Permissions) > 0 {
+		if permission, ok := MatchAll(
+			a.Permissions(claims),
+			policy.Permissions,
+		); !ok {
+			return fmt.Errorf("%w: %s", ErrMissingPermission, permission)
+		}
+	}
+
+	// Check the roles, a granted wildcard role satisfies any of them
+	if len(policy.Roles) > 0 {
+		granted := a.Roles(claims)
+		for _, role := range policy.Roles {
+			if MatchAny(granted, role) {
+				return nil
+			}
+		}
+		return ErrMissingRole
+	}
+	return nil
+}
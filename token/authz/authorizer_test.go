@@ -0,0 +1,187 @@
+package authz
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/golang-jwt/jwt/v5"
+	gojwt "github.com/ralvarezdev/go-jwt"
+)
+
+// newTestAuthorizer creates an authorizer whose admin role inherits the editor role, which inherits the viewer role
+//
+// Parameters:
+//
+//   - t: The test
+//
+// Returns:
+//
+//   - *Authorizer: The authorizer
+func newTestAuthorizer(t *testing.T) *Authorizer {
+	t.Helper()
+
+	authorizer, err := NewAuthorizer(
+		map[string]Role{
+			"viewer": {Permissions: []string{"orders:read"}},
+			"editor": {Permissions: []string{"orders:write"}, Inherits: []string{"viewer"}},
+			"admin":  {Permissions: []string{"users:*"}, Inherits: []string{"editor"}},
+		},
+	)
+	if err != nil {
+		t.Fatalf("NewAuthorizer() error = %v", err)
+	}
+	return authorizer
+}
+
+func TestNewAuthorizer(t *testing.T) {
+	tests := []struct {
+		name    string
+		roles   map[string]Role
+		wantErr error
+	}{
+		{name: "no roles"},
+		{
+			name: "inherited roles",
+			roles: map[string]Role{
+				"viewer": {},
+				"editor": {Inherits: []string{"viewer"}},
+				"admin":  {Inherits: []string{"editor", "viewer"}},
+			},
+		},
+		{
+			name:  "undefined inherited role",
+			roles: map[string]Role{"editor": {Inherits: []string{"viewer"}}},
+		},
+		{
+			name:    "self inheritance",
+			roles:   map[string]Role{"admin": {Inherits: []string{"admin"}}},
+			wantErr: ErrInheritedRoleCycle,
+		},
+		{
+			name: "inheritance cycle",
+			roles: map[string]Role{
+				"viewer": {Inherits: []string{"admin"}},
+				"editor": {Inherits: []string{"viewer"}},
+				"admin":  {Inherits: []string{"editor"}},
+			},
+			wantErr: ErrInheritedRoleCycle,
+		},
+	}
+	for _, test := range tests {
+		t.Run(
+			test.name, func(t *testing.T) {
+				if _, err := NewAuthorizer(test.roles); !errors.Is(err, test.wantErr) {
+					t.Errorf("NewAuthorizer() error = %v, want %v", err, test.wantErr)
+				}
+			},
+		)
+	}
+}
+
+func TestAuthorizer_Roles(t *testing.T) {
+	authorizer := newTestAuthorizer(t)
+
+	roles := authorizer.Roles(jwt.MapClaims{gojwt.RolesClaim: []any{"admin"}})
+	slices.Sort(roles)
+	if want := []string{"admin", "editor", "viewer"}; !slices.Equal(roles, want) {
+		t.Errorf("Roles() = %v, want %v", roles, want)
+	}
+}
+
+func TestAuthorizer_Authorize(t *testing.T) {
+	authorizer := newTestAuthorizer(t)
+
+	tests := []struct {
+		name       string
+		authorizer *Authorizer
+		claims     jwt.MapClaims
+		policy     Policy
+		wantErr    error
+	}{
+		{
+			name:       "empty policy",
+			authorizer: authorizer,
+			policy:     Policy{},
+		},
+		{
+			name:       "nil claims",
+			authorizer: authorizer,
+			policy:     Policy{Scopes: []string{"orders:read"}},
+			wantErr:    ErrNilClaims,
+		},
+		{
+			name:       "granted scope",
+			authorizer: authorizer,
+			claims:     jwt.MapClaims{gojwt.ScopeClaim: "orders:* users:read"},
+			policy:     Policy{Scopes: []string{"orders:read", "users:read"}},
+		},
+		{
+			name:       "insufficient scope",
+			authorizer: authorizer,
+			claims:     jwt.MapClaims{gojwt.ScopeClaim: "orders:read"},
+			policy:     Policy{Scopes: []string{"orders:read", "orders:write"}},
+			wantErr:    ErrInsufficientScope,
+		},
+		{
+			name:       "granted permission",
+			authorizer: authorizer,
+			claims:     jwt.MapClaims{gojwt.PermissionsClaim: []any{"orders:read"}},
+			policy:     Policy{Permissions: []string{"orders:read"}},
+		},
+		{
+			name:       "permission granted by an inherited role",
+			authorizer: authorizer,
+			claims:     jwt.MapClaims{gojwt.RolesClaim: []any{"admin"}},
+			policy:     Policy{Permissions: []string{"orders:read", "users:delete"}},
+		},
+		{
+			name:       "permission not granted by the role",
+			authorizer: authorizer,
+			claims:     jwt.MapClaims{gojwt.RolesClaim: []any{"editor"}},
+			policy:     Policy{Permissions: []string{"users:delete"}},
+			wantErr:    ErrMissingPermission,
+		},
+		{
+			name:       "nil authorizer does not expand the roles",
+			authorizer: nil,
+			claims:     jwt.MapClaims{gojwt.RolesClaim: []any{"admin"}},
+			policy:     Policy{Permissions: []string{"orders:read"}},
+			wantErr:    ErrMissingPermission,
+		},
+		{
+			name:       "granted role",
+			authorizer: authorizer,
+			claims:     jwt.MapClaims{gojwt.RolesClaim: []any{"editor"}},
+			policy:     Policy{Roles: []string{"admin", "editor"}},
+		},
+		{
+			name:       "inherited role",
+			authorizer: authorizer,
+			claims:     jwt.MapClaims{gojwt.RolesClaim: []any{"admin"}},
+			policy:     Policy{Roles: []string{"viewer"}},
+		},
+		{
+			name:       "missing role",
+			authorizer: authorizer,
+			claims:     jwt.MapClaims{gojwt.RolesClaim: []any{"viewer"}},
+			policy:     Policy{Roles: []string{"admin", "editor"}},
+			wantErr:    ErrMissingRole,
+		},
+		{
+			name:       "wildcard role satisfies every role",
+			authorizer: authorizer,
+			claims:     jwt.MapClaims{gojwt.RolesClaim: []any{Wildcard}},
+			policy:     Policy{Roles: []string{"admin"}},
+		},
+	}
+	for _, test := range tests {
+		t.Run(
+			test.name, func(t *testing.T) {
+				if err := test.authorizer.Authorize(test.claims, test.policy); !errors.Is(err, test.wantErr) {
+					t.Errorf("Authorize() error = %v, want %v", err, test.wantErr)
+				}
+			},
+		)
+	}
+}
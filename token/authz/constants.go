@@ -0,0 +1,9 @@
+package authz
+
+const (
+	// Separator is the separator of the hierarchy levels of a scope or permission, such as orders:items:read
+	Separator = ":"
+
+	// Wildcard matches any value of a hierarchy level. As the last level, it also matches all the levels below it
+	Wildcard = "*"
+)
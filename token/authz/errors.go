@@ -0,0 +1,13 @@
+package authz
+
+import (
+	"errors"
+)
+
+var (
+	ErrNilClaims          = errors.New("claims cannot be nil")
+	ErrInsufficientScope  = errors.New("insufficient scope")
+	ErrMissingPermission  = errors.New("missing permission")
+	ErrMissingRole        = errors.New("missing role")
+	ErrInheritedRoleCycle = errors.New("inherited role cycle")
+)
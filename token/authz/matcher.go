@@ -0,0 +1,78 @@
+package authz
+
+import (
+	"strings"
+)
+
+// Match checks if the granted pattern implies the required value. The levels are compared one by one, a wildcard
+// level matches any value, and a trailing wildcard also matches all the levels below it, so orders:* implies both
+// orders:read and orders:items:read
+//
+// Parameters:
+//
+//   - pattern: The granted pattern
+//   - value: The required value
+//
+// Returns:
+//
+//   - bool: Whether the pattern implies the value
+func Match(pattern, value string) bool {
+	if pattern == value || pattern == Wildcard {
+		return true
+	}
+
+	patternLevels := strings.Split(pattern, Separator)
+	valueLevels := strings.Split(value, Separator)
+	for i, patternLevel := range patternLevels {
+		// A trailing wildcard matches all the remaining levels
+		if patternLevel == Wildcard && i == len(patternLevels)-1 {
+			return len(valueLevels) > i
+		}
+		if i >= len(valueLevels) {
+			return false
+		}
+		if patternLevel != Wildcard && patternLevel != valueLevels[i] {
+			return false
+		}
+	}
+	return len(patternLevels) == len(valueLevels)
+}
+
+// MatchAny checks if any of the granted patterns implies the required value
+//
+// Parameters:
+//
+//   - patterns: The granted patterns
+//   - value: The required value
+//
+// Returns:
+//
+//   - bool: Whether any pattern implies the value
+func MatchAny(patterns []string, value string) bool {
+	for _, pattern := range patterns {
+		if Match(pattern, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// MatchAll checks if every required value is implied by any of the granted patterns
+//
+// Parameters:
+//
+//   - patterns: The granted patterns
+//   - values: The required values
+//
+// Returns:
+//
+//   - string: The first required value that is not implied, empty if all of them are
+//   - bool: Whether every required value is implied
+func MatchAll(patterns []string, values []string) (string, bool) {
+	for _, value := range values {
+		if !MatchAny(patterns, value) {
+			return value, false
+		}
+	}
+	return "", true
+}
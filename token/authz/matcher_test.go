@@ -0,0 +1,109 @@
+package authz
+
+import (
+	"testing"
+)
+
+func TestMatch(t *testing.T) {
+	tests := []struct {
+		name    string
+		pattern string
+		value   string
+		want    bool
+	}{
+		{name: "equal", pattern: "orders:read", value: "orders:read", want: true},
+		{name: "different", pattern: "orders:read", value: "orders:write", want: false},
+		{name: "wildcard", pattern: "*", value: "orders:items:read", want: true},
+		{name: "trailing wildcard", pattern: "orders:*", value: "orders:read", want: true},
+		{name: "trailing wildcard below", pattern: "orders:*", value: "orders:items:read", want: true},
+		{name: "trailing wildcard on its parent", pattern: "orders:*", value: "orders", want: false},
+		{name: "trailing wildcard on another parent", pattern: "orders:*", value: "users:read", want: false},
+		{name: "middle wildcard", pattern: "orders:*:read", value: "orders:items:read", want: true},
+		{name: "middle wildcard on another action", pattern: "orders:*:read", value: "orders:items:write", want: false},
+		{name: "middle wildcard below", pattern: "orders:*:read", value: "orders:items:read:all", want: false},
+		{name: "shorter value", pattern: "orders:items:read", value: "orders:items", want: false},
+		{name: "longer value", pattern: "orders:items", value: "orders:items:read", want: false},
+		{name: "wildcard value", pattern: "orders:read", value: "orders:*", want: false},
+	}
+	for _, test := range tests {
+		t.Run(
+			test.name, func(t *testing.T) {
+				if got := Match(test.pattern, test.value); got != test.want {
+					t.Errorf("Match(%q, %q) = %v, want %v", test.pattern, test.value, got, test.want)
+				}
+			},
+		)
+	}
+}
+
+func TestMatchAny(t *testing.T) {
+	tests := []struct {
+		name     string
+		patterns []string
+		value    string
+		want     bool
+	}{
+		{name: "no patterns", value: "orders:read", want: false},
+		{name: "matching pattern", patterns: []string{"users:read", "orders:*"}, value: "orders:read", want: true},
+		{name: "no matching pattern", patterns: []string{"users:read", "orders:write"}, value: "orders:read", want: false},
+		{name: "wildcard pattern", patterns: []string{"*"}, value: "admin", want: true},
+	}
+	for _, test := range tests {
+		t.Run(
+			test.name, func(t *testing.T) {
+				if got := MatchAny(test.patterns, test.value); got != test.want {
+					t.Errorf("MatchAny(%v, %q) = %v, want %v", test.patterns, test.value, got, test.want)
+				}
+			},
+		)
+	}
+}
+
+func TestMatchAll(t *testing.T) {
+	tests := []struct {
+		name        string
+		patterns    []string
+		values      []string
+		wantMissing string
+		want        bool
+	}{
+		{name: "no values", patterns: []string{"orders:read"}, want: true},
+		{
+			name:     "all values",
+			patterns: []string{"orders:*", "users:read"},
+			values:   []string{"orders:read", "orders:write", "users:read"},
+			want:     true,
+		},
+		{
+			name:        "missing value",
+			patterns:    []string{"orders:*"},
+			values:      []string{"orders:read", "users:read", "users:write"},
+			wantMissing: "users:read",
+			want:        false,
+		},
+		{
+			name:     "wildcard pattern",
+			patterns: []string{"*"},
+			values:   []string{"orders:read", "users:write"},
+			want:     true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(
+			test.name, func(t *testing.T) {
+				missing, got := MatchAll(test.patterns, test.values)
+				if got != test.want || missing != test.wantMissing {
+					t.Errorf(
+						"MatchAll(%v, %v) = (%q, %v), want (%q, %v)",
+						test.patterns,
+						test.values,
+						missing,
+						got,
+						test.wantMissing,
+						test.want,
+					)
+				}
+			},
+		)
+	}
+}
@@ -0,0 +1,34 @@
+package authz
+
+type (
+	// Role is a role definition
+	Role struct {
+		// Permissions are the permissions granted by the role, which may contain wildcards
+		Permissions []string
+
+		// Inherits are the roles whose permissions are also granted by the role
+		Inherits []string
+	}
+
+	// Policy is a declarative authorization policy of a route or RPC method. Empty requirements are not checked
+	Policy struct {
+		// Scopes are the scopes that must all be granted by the scope claim
+		Scopes []string
+
+		// Permissions are the permissions that must all be granted by the permissions claim or by the roles
+		Permissions []string
+
+		// Roles are the roles of which at least one must be granted by the roles claim, directly or by inheritance.
+		// The granted roles are matched as patterns, so a granted * role satisfies every required role
+		Roles []string
+	}
+)
+
+// IsEmpty checks if the policy has no requirements
+//
+// Returns:
+//
+//   - bool: Whether the policy has no requirements
+func (p Policy) IsEmpty() bool {
+	return len(p.Scopes) == 0 && len(p.Permissions) == 0 && len(p.Roles) == 0
+}
@@ -1,13 +1,32 @@
 package cache
 
 import (
+	"time"
+
 	gostringsseparator "github.com/ralvarezdev/go-strings/separator"
 )
 
+const (
+	// SnapshotVersion is the current version of the snapshot format
+	SnapshotVersion = 1
+
+	// SnapshotFilePermissions are the permissions used for the snapshot files
+	SnapshotFilePermissions = 0o600
+)
+
 var (
 	// KeySeparator is the separator for the cache keys
 	KeySeparator = gostringsseparator.Dots
 
 	// ParentRefreshTokenIDPrefix is the prefix of the Parent Refresh Token ID key
 	ParentRefreshTokenIDPrefix = "PRT"
+
+	// AccessTokenParentPrefix is the prefix of the key that holds the parent refresh token ID of an access token
+	AccessTokenParentPrefix = "ATP"
+
+	// DefaultSnapshotInterval is the default interval for the periodic snapshots
+	DefaultSnapshotInterval = 30 * time.Second
+
+	// MinKeysPruneLen is the minimum number of tracked keys that triggers the pruning of the expired ones
+	MinKeysPruneLen = 1024
 )
@@ -8,4 +8,9 @@ var (
 	ErrParentRefreshTokenNotFound    = errors.New("parent refresh token not found")
 	ErrInvalidParentRefreshTokenItem = errors.New("invalid parent refresh token item")
 	ErrInvalidTokenItem              = errors.New("invalid token item")
+	ErrNilSnapshotWriter             = errors.New("nil snapshot writer")
+	ErrNilSnapshotReader             = errors.New("nil snapshot reader")
+	ErrUnsupportedSnapshotVersion    = errors.New("unsupported snapshot version")
+	ErrInvalidSnapshotItem           = errors.New("invalid snapshot item")
+	ErrEmptySnapshotPath             = errors.New("empty snapshot path")
 )
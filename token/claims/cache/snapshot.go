@@ -0,0 +1,347 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"time"
+
+	gocachetimed "github.com/ralvarezdev/go-cache/timed"
+	gojwttokenclaims "github.com/ralvarezdev/go-jwt/token/claims"
+)
+
+type (
+	// SnapshotItem is a cache item stored in a snapshot
+	SnapshotItem struct {
+		Key       string    `json:"key"`
+		Value     any       `json:"value"`
+		ExpiresAt time.Time `json:"expires_at"`
+	}
+
+	// Snapshot is the versioned representation of the token validator cache content
+	Snapshot struct {
+		Version   int            `json:"version"`
+		CreatedAt time.Time      `json:"created_at"`
+		Items     []SnapshotItem `json:"items"`
+	}
+)
+
+// Snapshot writes the non-expired cache content to the given writer as a versioned JSON document
+//
+// Parameters:
+//
+//   - w: The writer to write the snapshot to
+//
+// Returns:
+//
+//   - error: An error if the token validator or the writer is nil, or if the snapshot could not be written
+func (t *TokenValidator) Snapshot(w io.Writer) error {
+	if t == nil {
+		return gojwttokenclaims.ErrNilTokenValidator
+	}
+
+	// Check if the writer is nil
+	if w == nil {
+		return ErrNilSnapshotWriter
+	}
+
+	// Collect the non-expired items
+	t.mutex.RLock()
+	snapshot := Snapshot{
+		Version:   SnapshotVersion,
+		CreatedAt: time.Now(),
+		Items:     make([]SnapshotItem, 0, len(t.keys)),
+	}
+	var expiredKeys []string
+	for key := range t.keys {
+		// Check if the item has expired before reading it
+		expiresAt := t.cache.GetExpirationTime(key)
+		if !expiresAt.After(snapshot.CreatedAt) {
+			expiredKeys = append(expiredKeys, key)
+			continue
+		}
+
+		// Get the item value
+		value, found := t.cache.Get(key)
+		if !found {
+			expiredKeys = append(expiredKeys, key)
+			continue
+		}
+
+		snapshot.Items = append(
+			snapshot.Items, SnapshotItem{
+				Key:       key,
+				Value:     value,
+				ExpiresAt: expiresAt,
+			},
+		)
+	}
+	t.mutex.RUnlock()
+
+	// Stop tracking the expired keys, checking them again since they may have been set again in between
+	if len(expiredKeys) > 0 {
+		t.mutex.Lock()
+		now := time.Now()
+		for _, key := range expiredKeys {
+			if !t.cache.GetExpirationTime(key).After(now) {
+				delete(t.keys, key)
+			}
+		}
+		t.mutex.Unlock()
+	}
+
+	// Encode the snapshot
+	if err := json.NewEncoder(w).Encode(snapshot); err != nil {
+		if t.logger != nil {
+			t.logger.Error(
+				"Failed to write snapshot",
+				slog.String("error", err.Error()),
+			)
+		}
+		return err
+	}
+	if t.logger != nil {
+		t.logger.Debug(
+			"Snapshot written",
+			slog.Int("items", len(snapshot.Items)),
+		)
+	}
+	return nil
+}
+
+// Restore reads a snapshot written by Snapshot from the given reader and loads its non-expired items into the cache.
+// Items already in the cache with the same key are overwritten
+//
+// Parameters:
+//
+//   - r: The reader to read the snapshot from
+//
+// Returns:
+//
+//   - error: An error if the token validator or the reader is nil, if the snapshot version is not supported or if
+//     the snapshot is malformed
+func (t *TokenValidator) Restore(r io.Reader) error {
+	if t == nil {
+		return gojwttokenclaims.ErrNilTokenValidator
+	}
+
+	// Check if the reader is nil
+	if r == nil {
+		return ErrNilSnapshotReader
+	}
+
+	// Decode the snapshot
+	var snapshot Snapshot
+	if err := json.NewDecoder(r).Decode(&snapshot); err != nil {
+		if t.logger != nil {
+			t.logger.Error(
+				"Failed to read snapshot",
+				slog.String("error", err.Error()),
+			)
+		}
+		return err
+	}
+
+	// Check the snapshot version
+	if snapshot.Version != SnapshotVersion {
+		return fmt.Errorf(
+			"%w: %d",
+			ErrUnsupportedSnapshotVersion,
+			snapshot.Version,
+		)
+	}
+
+	// Load the non-expired items
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	now := time.Now()
+	restored := 0
+	for _, item := range snapshot.Items {
+		if !item.ExpiresAt.After(now) {
+			continue
+		}
+
+		// Check the item value, which is either the token validity or the child access token ID
+		switch item.Value.(type) {
+		case bool, string:
+		default:
+			return fmt.Errorf("%w: %s", ErrInvalidSnapshotItem, item.Key)
+		}
+
+		if err := t.setItem(
+			item.Key,
+			gocachetimed.NewTimedItem(item.Value, item.ExpiresAt),
+		); err != nil {
+			// The item may have expired while restoring
+			if errors.Is(err, gocachetimed.ErrItemHasExpired) {
+				continue
+			}
+			return err
+		}
+		restored++
+	}
+
+	if t.logger != nil {
+		t.logger.Info(
+			"Snapshot restored",
+			slog.Int("items", restored),
+		)
+	}
+	return nil
+}
+
+// SnapshotToFile writes a snapshot to the given file path. The snapshot is first written to a temporary file in the
+// same directory, which then replaces the previous snapshot, so a crash never leaves a partially written file
+//
+// Parameters:
+//
+//   - path: The path of the snapshot file
+//
+// Returns:
+//
+//   - error: An error if the path is empty or if the snapshot could not be written
+func (t *TokenValidator) SnapshotToFile(path string) error {
+	if t == nil {
+		return gojwttokenclaims.ErrNilTokenValidator
+	}
+
+	// Check if the path is empty
+	if path == "" {
+		return ErrEmptySnapshotPath
+	}
+	path = filepath.Clean(path)
+
+	// Create the temporary file
+	file, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	tmpPath := file.Name()
+
+	// Write the snapshot
+	if err = t.Snapshot(file); err != nil {
+		_ = file.Close()
+		_ = os.Remove(tmpPath)
+		return err
+	}
+	if err = file.Sync(); err != nil {
+		_ = file.Close()
+		_ = os.Remove(tmpPath)
+		return err
+	}
+	if err = file.Close(); err != nil {
+		_ = os.Remove(tmpPath)
+		return err
+	}
+	if err = os.Chmod(tmpPath, SnapshotFilePermissions); err != nil {
+		_ = os.Remove(tmpPath)
+		return err
+	}
+
+	// Replace the previous snapshot
+	if err = os.Rename(tmpPath, path); err != nil {
+		_ = os.Remove(tmpPath)
+		return err
+	}
+	return nil
+}
+
+// RestoreFromFile restores a snapshot from the given file path. A missing file is not considered an error, since
+// there is nothing to restore on the first start
+//
+// Parameters:
+//
+//   - path: The path of the snapshot file
+//
+// Returns:
+//
+//   - error: An error if the path is empty or if the snapshot could not be restored
+func (t *TokenValidator) RestoreFromFile(path string) error {
+	if t == nil {
+		return gojwttokenclaims.ErrNilTokenValidator
+	}
+
+	// Check if the path is empty
+	if path == "" {
+		return ErrEmptySnapshotPath
+	}
+
+	// Open the snapshot file
+	file, err := os.Open(filepath.Clean(path))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			if t.logger != nil {
+				t.logger.Info(
+					"Snapshot file not found, nothing to restore",
+					slog.String("path", path),
+				)
+			}
+			return nil
+		}
+		return err
+	}
+	defer func() {
+		_ = file.Close()
+	}()
+
+	return t.Restore(file)
+}
+
+// StartPeriodicSnapshots writes a snapshot to the given file path every interval until the context is done. A final
+// snapshot is written before returning
+//
+// Parameters:
+//
+//   - ctx: The context for managing cancellation
+//   - path: The path of the snapshot file
+//   - interval: The interval between snapshots (optional, DefaultSnapshotInterval is used if not positive)
+//
+// Returns:
+//
+//   - error: An error if the path is empty or if the final snapshot could not be written
+func (t *TokenValidator) StartPeriodicSnapshots(
+	ctx context.Context,
+	path string,
+	interval time.Duration,
+) error {
+	if t == nil {
+		return gojwttokenclaims.ErrNilTokenValidator
+	}
+
+	// Check if the path is empty
+	if path == "" {
+		return ErrEmptySnapshotPath
+	}
+
+	// Check if the interval is valid
+	if interval <= 0 {
+		interval = DefaultSnapshotInterval
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			if t.logger != nil {
+				t.logger.Info("Context done, writing final snapshot")
+			}
+			return t.SnapshotToFile(path)
+		case <-ticker.C:
+			if err := t.SnapshotToFile(path); err != nil && t.logger != nil {
+				t.logger.Error(
+					"Failed to write periodic snapshot",
+					slog.String("path", path),
+					slog.String("error", err.Error()),
+				)
+			}
+		}
+	}
+}
@@ -0,0 +1,240 @@
+package cache
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	gojwttoken "github.com/ralvarezdev/go-jwt/token"
+)
+
+// lookupTestToken looks up a token and fails the test if the lookup fails
+//
+// Parameters:
+//
+//   - t: The test
+//   - tokenValidator: The token validator
+//   - token: The token
+//   - id: The ID associated with the token
+//
+// Returns:
+//
+//   - bool: Whether the token is valid
+//   - bool: Whether the token is in the cache
+func lookupTestToken(
+	t *testing.T,
+	tokenValidator *TokenValidator,
+	token gojwttoken.Token,
+	id string,
+) (bool, bool) {
+	t.Helper()
+
+	isValid, found, err := tokenValidator.LookupToken(context.Background(), token, id)
+	if err != nil {
+		t.Fatalf("LookupToken() error = %v", err)
+	}
+	return isValid, found
+}
+
+func TestTokenValidator_SnapshotRestore(t *testing.T) {
+	ctx := context.Background()
+	tokenValidator := NewTokenValidator(nil)
+
+	now := time.Now()
+	for _, id := range []string{"refresh", "revoked-refresh"} {
+		if err := tokenValidator.AddRefreshToken(ctx, id, now.Add(time.Hour)); err != nil {
+			t.Fatalf("AddRefreshToken() error = %v", err)
+		}
+	}
+	if err := tokenValidator.AddAccessToken(ctx, "access", "refresh", now.Add(time.Minute)); err != nil {
+		t.Fatalf("AddAccessToken() error = %v", err)
+	}
+	if err := tokenValidator.RevokeToken(ctx, gojwttoken.RefreshToken, "revoked-refresh"); err != nil {
+		t.Fatalf("RevokeToken() error = %v", err)
+	}
+
+	// Round trip the cache content
+	var buffer bytes.Buffer
+	if err := tokenValidator.Snapshot(&buffer); err != nil {
+		t.Fatalf("Snapshot() error = %v", err)
+	}
+	restored := NewTokenValidator(nil)
+	if err := restored.Restore(&buffer); err != nil {
+		t.Fatalf("Restore() error = %v", err)
+	}
+
+	tests := []struct {
+		name      string
+		token     gojwttoken.Token
+		id        string
+		wantValid bool
+		wantFound bool
+	}{
+		{name: "refresh token", token: gojwttoken.RefreshToken, id: "refresh", wantValid: true, wantFound: true},
+		{name: "access token", token: gojwttoken.AccessToken, id: "access", wantValid: true, wantFound: true},
+		{name: "revoked refresh token", token: gojwttoken.RefreshToken, id: "revoked-refresh", wantFound: true},
+		{name: "missing token", token: gojwttoken.AccessToken, id: "missing"},
+	}
+	for _, test := range tests {
+		t.Run(
+			test.name, func(t *testing.T) {
+				isValid, found := lookupTestToken(t, restored, test.token, test.id)
+				if isValid != test.wantValid || found != test.wantFound {
+					t.Errorf(
+						"LookupToken() = %v, %v, want %v, %v",
+						isValid,
+						found,
+						test.wantValid,
+						test.wantFound,
+					)
+				}
+			},
+		)
+	}
+
+	// The restored access token is still revoked together with its parent refresh token
+	if err := restored.RevokeToken(ctx, gojwttoken.RefreshToken, "refresh"); err != nil {
+		t.Fatalf("RevokeToken() error = %v", err)
+	}
+	if isValid, _ := lookupTestToken(t, restored, gojwttoken.AccessToken, "access"); isValid {
+		t.Error("LookupToken() = true for the access token of a revoked refresh token, want false")
+	}
+}
+
+func TestTokenValidator_Snapshot_SkipsExpiredItems(t *testing.T) {
+	ctx := context.Background()
+	tokenValidator := NewTokenValidator(nil)
+
+	now := time.Now()
+	if err := tokenValidator.AddRefreshToken(ctx, "refresh", now.Add(time.Hour)); err != nil {
+		t.Fatalf("AddRefreshToken() error = %v", err)
+	}
+	if err := tokenValidator.AddRefreshToken(ctx, "expiring-refresh", now.Add(10*time.Millisecond)); err != nil {
+		t.Fatalf("AddRefreshToken() error = %v", err)
+	}
+	time.Sleep(20 * time.Millisecond)
+
+	// The expired items are not written
+	var buffer bytes.Buffer
+	if err := tokenValidator.Snapshot(&buffer); err != nil {
+		t.Fatalf("Snapshot() error = %v", err)
+	}
+	var snapshot Snapshot
+	if err := json.Unmarshal(buffer.Bytes(), &snapshot); err != nil {
+		t.Fatalf("failed to decode the snapshot: %v", err)
+	}
+	if len(snapshot.Items) != 1 {
+		t.Errorf("Snapshot() items = %d, want 1", len(snapshot.Items))
+	}
+
+	// The items that expired since the snapshot was written are not restored
+	refreshTokenKey, err := tokenValidator.GetTokenKey(gojwttoken.RefreshToken, "refresh")
+	if err != nil {
+		t.Fatalf("GetTokenKey() error = %v", err)
+	}
+	snapshot.Items = append(
+		snapshot.Items, SnapshotItem{
+			Key:       refreshTokenKey + "-expired",
+			Value:     true,
+			ExpiresAt: now.Add(-time.Minute),
+		},
+	)
+	encoded, err := json.Marshal(snapshot)
+	if err != nil {
+		t.Fatalf("failed to encode the snapshot: %v", err)
+	}
+	restored := NewTokenValidator(nil)
+	if err = restored.Restore(bytes.NewReader(encoded)); err != nil {
+		t.Fatalf("Restore() error = %v", err)
+	}
+	if _, found := lookupTestToken(t, restored, gojwttoken.RefreshToken, "refresh-expired"); found {
+		t.Error("LookupToken() found an expired restored item, want missing")
+	}
+	if _, found := lookupTestToken(t, restored, gojwttoken.RefreshToken, "expiring-refresh"); found {
+		t.Error("LookupToken() found an expired item, want missing")
+	}
+	if _, found := lookupTestToken(t, restored, gojwttoken.RefreshToken, "refresh"); !found {
+		t.Error("LookupToken() missed the restored refresh token, want found")
+	}
+}
+
+func TestTokenValidator_Restore_UnsupportedVersion(t *testing.T) {
+	tokenValidator := NewTokenValidator(nil)
+
+	err := tokenValidator.Restore(bytes.NewReader([]byte(`{"version": 99, "items": []}`)))
+	if !errors.Is(err, ErrUnsupportedSnapshotVersion) {
+		t.Errorf("Restore() error = %v, want %v", err, ErrUnsupportedSnapshotVersion)
+	}
+}
+
+func TestTokenValidator_SnapshotToFile(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "tokens.snapshot")
+
+	// A missing snapshot file is nothing to restore
+	restored := NewTokenValidator(nil)
+	if err := restored.RestoreFromFile(path); err != nil {
+		t.Fatalf("RestoreFromFile() error = %v, want nil for a missing file", err)
+	}
+
+	tokenValidator := NewTokenValidator(nil)
+	if err := tokenValidator.AddRefreshToken(ctx, "refresh", time.Now().Add(time.Hour)); err != nil {
+		t.Fatalf("AddRefreshToken() error = %v", err)
+	}
+	if err := tokenValidator.SnapshotToFile(path); err != nil {
+		t.Fatalf("SnapshotToFile() error = %v", err)
+	}
+
+	// The snapshot file replaces the temporary file and is only readable by its owner
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("failed to stat the snapshot file: %v", err)
+	}
+	if perm := info.Mode().Perm(); perm != SnapshotFilePermissions {
+		t.Errorf("snapshot file permissions = %o, want %o", perm, SnapshotFilePermissions)
+	}
+	entries, err := os.ReadDir(filepath.Dir(path))
+	if err != nil {
+		t.Fatalf("failed to read the snapshot directory: %v", err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("snapshot directory entries = %d, want 1", len(entries))
+	}
+
+	if err = restored.RestoreFromFile(path); err != nil {
+		t.Fatalf("RestoreFromFile() error = %v", err)
+	}
+	if isValid, found := lookupTestToken(t, restored, gojwttoken.RefreshToken, "refresh"); !isValid || !found {
+		t.Errorf("LookupToken() = %v, %v, want true, true", isValid, found)
+	}
+}
+
+func TestTokenValidator_PrunesExpiredKeys(t *testing.T) {
+	ctx := context.Background()
+	tokenValidator := NewTokenValidator(nil)
+
+	// Add short-lived tokens up to the pruning threshold
+	expiresAt := time.Now().Add(10 * time.Millisecond)
+	for i := range MinKeysPruneLen - 1 {
+		if err := tokenValidator.AddRefreshToken(ctx, "refresh-"+strconv.Itoa(i), expiresAt); err != nil {
+			t.Fatalf("AddRefreshToken() error = %v", err)
+		}
+	}
+	time.Sleep(20 * time.Millisecond)
+
+	// The expired keys stop being tracked without any snapshot
+	if err := tokenValidator.AddRefreshToken(ctx, "refresh", time.Now().Add(time.Hour)); err != nil {
+		t.Fatalf("AddRefreshToken() error = %v", err)
+	}
+	tokenValidator.mutex.RLock()
+	defer tokenValidator.mutex.RUnlock()
+	if len(tokenValidator.keys) != 1 {
+		t.Errorf("tracked keys = %d, want 1", len(tokenValidator.keys))
+	}
+}
@@ -2,7 +2,9 @@ package cache
 
 import (
 	"context"
+	"errors"
 	"log/slog"
+	"sync"
 	"time"
 
 	gocache "github.com/ralvarezdev/go-cache"
@@ -15,8 +17,11 @@ import (
 type (
 	// TokenValidator struct
 	TokenValidator struct {
-		logger *slog.Logger
-		cache  gocachetimed.TimedCache
+		logger     *slog.Logger
+		cache      gocachetimed.TimedCache
+		keys       map[string]struct{}
+		pruneAtLen int
+		mutex      sync.RWMutex
 	}
 )
 
@@ -39,8 +44,10 @@ func NewTokenValidator(logger *slog.Logger) *TokenValidator {
 		)
 	}
 	return &TokenValidator{
-		cache:  gocachetimed.NewDefaultTimedCache(),
-		logger: logger,
+		cache:      gocachetimed.NewDefaultTimedCache(),
+		keys:       make(map[string]struct{}),
+		pruneAtLen: MinKeysPruneLen,
+		logger:     logger,
 	}
 }
 
@@ -97,27 +104,78 @@ func (t *TokenValidator) GetParentRefreshTokenKey(
 	), nil
 }
 
-// AddRefreshToken sets a token in the cache
+// GetAccessTokenParentKey gets the key that holds the parent refresh token ID of an access token
 //
 // Parameters:
 //
-//   - ctx: The context (not used, but kept for interface consistency)
-//   - token: The token
-//   - id: The ID associated with the token
-//   - expiresAt: The expiration time of the token
+//   - id: The ID of the access token
 //
 // Returns:
 //
-//   - error: An error if the token validator is nil or if setting the token in the cache fails
-func (t *TokenValidator) AddRefreshToken(
-	ctx context.Context,
+//   - string: The key for the cache
+//   - error: An error if the token validator is nil
+func (t *TokenValidator) GetAccessTokenParentKey(
 	id string,
-	expiresAt time.Time,
-) error {
+) (string, error) {
 	if t == nil {
-		return gojwttokenclaims.ErrNilTokenValidator
+		return "", gojwttokenclaims.ErrNilTokenValidator
 	}
 
+	return gostringsadd.Prefixes(
+		AccessTokenParentPrefix,
+		KeySeparator,
+		id,
+	), nil
+}
+
+// setItem sets an item in the cache and keeps track of its key, so it can be included in snapshots. The mutex must be
+// held by the caller
+//
+// Parameters:
+//
+//   - key: The key for the cache
+//   - item: The timed item to set
+//
+// Returns:
+//
+//   - error: An error if setting the item in the cache fails
+func (t *TokenValidator) setItem(key string, item *gocachetimed.TimedItem) error {
+	if err := t.cache.Set(key, item); err != nil {
+		return err
+	}
+
+	// Keep track of the key
+	t.keys[key] = struct{}{}
+
+	// Stop tracking the expired keys once the tracked keys double, so they do not grow without periodic snapshots
+	if len(t.keys) >= t.pruneAtLen {
+		t.pruneKeys()
+		t.pruneAtLen = max(2*len(t.keys), MinKeysPruneLen)
+	}
+	return nil
+}
+
+// pruneKeys stops tracking the keys whose items have expired. The mutex must be held by the caller
+func (t *TokenValidator) pruneKeys() {
+	now := time.Now()
+	for key := range t.keys {
+		if !t.cache.GetExpirationTime(key).After(now) {
+			delete(t.keys, key)
+		}
+	}
+}
+
+// addRefreshToken sets a refresh token in the cache. The mutex must be held by the caller
+//
+// Parameters:
+//
+//   - id: The ID associated with the token
+//   - expiresAt: The expiration time of the token
+//
+// Returns:
+//
+//   - error: An error if setting the token in the cache fails
+func (t *TokenValidator) addRefreshToken(id string, expiresAt time.Time) error {
 	// Get the key
 	key, err := t.GetTokenKey(gojwttoken.RefreshToken, id)
 	if err != nil {
@@ -125,35 +183,84 @@ func (t *TokenValidator) AddRefreshToken(
 	}
 
 	// Set the token in the cache
-	err = t.cache.Set(key, gocachetimed.NewTimedItem(true, expiresAt))
+	err = t.setItem(key, gocachetimed.NewTimedItem(true, expiresAt))
 	if err != nil {
 		gojwttokenclaims.SetTokenFailed(err, t.logger)
 	}
 	return err
 }
 
-// AddAccessToken sets a token in the cache
+// AddRefreshToken sets a token in the cache
 //
 // Parameters:
 //
 //   - ctx: The context (not used, but kept for interface consistency)
 //   - id: The ID associated with the token
-//   - parentRefreshTokenID: The parent refresh token ID
 //   - expiresAt: The expiration time of the token
 //
 // Returns:
 //
 //   - error: An error if the token validator is nil or if setting the token in the cache fails
-func (t *TokenValidator) AddAccessToken(
+func (t *TokenValidator) AddRefreshToken(
 	ctx context.Context,
 	id string,
-	parentRefreshTokenID string,
 	expiresAt time.Time,
 ) error {
 	if t == nil {
 		return gojwttokenclaims.ErrNilTokenValidator
 	}
 
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	return t.addRefreshToken(id, expiresAt)
+}
+
+// AddRefreshTokens sets multiple refresh tokens in the cache while holding the lock once
+//
+// Parameters:
+//
+//   - ctx: The context (not used, but kept for interface consistency)
+//   - tokens: The refresh tokens to set
+//
+// Returns:
+//
+//   - error: An error if the token validator is nil or if setting any token in the cache fails
+func (t *TokenValidator) AddRefreshTokens(
+	ctx context.Context,
+	tokens []gojwttokenclaims.RefreshTokenRecord,
+) error {
+	if t == nil {
+		return gojwttokenclaims.ErrNilTokenValidator
+	}
+
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	for _, token := range tokens {
+		if err := t.addRefreshToken(token.ID, token.ExpiresAt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// addAccessToken sets an access token in the cache. The mutex must be held by the caller
+//
+// Parameters:
+//
+//   - id: The ID associated with the token
+//   - parentRefreshTokenID: The parent refresh token ID
+//   - expiresAt: The expiration time of the token
+//
+// Returns:
+//
+//   - error: An error if the parent refresh token is not in the cache or if setting the token in the cache fails
+func (t *TokenValidator) addAccessToken(
+	id string,
+	parentRefreshTokenID string,
+	expiresAt time.Time,
+) error {
 	// Check if the parent refresh token has already been set
 	refreshTokenKey, err := t.GetTokenKey(
 		gojwttoken.RefreshToken,
@@ -169,17 +276,11 @@ func (t *TokenValidator) AddAccessToken(
 		return ErrParentRefreshTokenNotFound
 	}
 
-	// Parse the value to check if it's valid
-	refreshTokenItem, ok := value.(*gocachetimed.TimedItem)
-	if !ok {
+	// Parse the value to check if it's valid. Expired items are never returned by the cache
+	if _, ok := value.(bool); !ok {
 		return ErrInvalidParentRefreshTokenItem
 	}
 
-	// Check if the parent refresh token is still valid
-	if refreshTokenItem.HasExpired() {
-		return nil
-	}
-
 	// Get the key
 	key, err := t.GetTokenKey(gojwttoken.AccessToken, id)
 	if err != nil {
@@ -187,7 +288,7 @@ func (t *TokenValidator) AddAccessToken(
 	}
 
 	// Set the token in the cache
-	err = t.cache.Set(key, gocachetimed.NewTimedItem(true, expiresAt))
+	err = t.setItem(key, gocachetimed.NewTimedItem(true, expiresAt))
 	if err != nil {
 		gojwttokenclaims.SetTokenFailed(err, t.logger)
 		return err
@@ -202,35 +303,105 @@ func (t *TokenValidator) AddAccessToken(
 	}
 
 	// Set the parent refresh token in the cache
-	if err = t.cache.Set(
+	if err = t.setItem(
 		parentRefreshTokenKey,
 		gocachetimed.NewTimedItem(id, expiresAt),
+	); err != nil {
+		gojwttokenclaims.SetTokenFailed(err, t.logger)
+		return err
+	}
+
+	// Also set the access token parent key to point to the parent refresh token
+	accessTokenParentKey, err := t.GetAccessTokenParentKey(id)
+	if err != nil {
+		return err
+	}
+
+	// Set the access token parent in the cache
+	if err = t.setItem(
+		accessTokenParentKey,
+		gocachetimed.NewTimedItem(parentRefreshTokenID, expiresAt),
 	); err != nil {
 		gojwttokenclaims.SetTokenFailed(err, t.logger)
 	}
 	return err
 }
 
-// RevokeToken revokes a token in the cache
+// AddAccessToken sets a token in the cache
 //
 // Parameters:
 //
 //   - ctx: The context (not used, but kept for interface consistency)
-//   - token: The token
 //   - id: The ID associated with the token
+//   - parentRefreshTokenID: The parent refresh token ID
+//   - expiresAt: The expiration time of the token
 //
 // Returns:
 //
-//   - error: An error if the token validator is nil or if revoking the token in the cache fails
-func (t *TokenValidator) RevokeToken(
+//   - error: An error if the token validator is nil or if setting the token in the cache fails
+func (t *TokenValidator) AddAccessToken(
 	ctx context.Context,
-	token gojwttoken.Token,
 	id string,
+	parentRefreshTokenID string,
+	expiresAt time.Time,
+) error {
+	if t == nil {
+		return gojwttokenclaims.ErrNilTokenValidator
+	}
+
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	return t.addAccessToken(id, parentRefreshTokenID, expiresAt)
+}
+
+// AddAccessTokens sets multiple access tokens in the cache while holding the lock once
+//
+// Parameters:
+//
+//   - ctx: The context (not used, but kept for interface consistency)
+//   - tokens: The access tokens to set
+//
+// Returns:
+//
+//   - error: An error if the token validator is nil or if setting any token in the cache fails
+func (t *TokenValidator) AddAccessTokens(
+	ctx context.Context,
+	tokens []gojwttokenclaims.AccessTokenRecord,
 ) error {
 	if t == nil {
 		return gojwttokenclaims.ErrNilTokenValidator
 	}
 
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	for _, token := range tokens {
+		if err := t.addAccessToken(
+			token.ID,
+			token.ParentRefreshTokenID,
+			token.ExpiresAt,
+		); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// revokeToken revokes a token in the cache. The mutex must be held by the caller
+//
+// Parameters:
+//
+//   - token: The token
+//   - id: The ID associated with the token
+//
+// Returns:
+//
+//   - error: An error if the token is not in the cache or if revoking the token in the cache fails
+func (t *TokenValidator) revokeToken(
+	token gojwttoken.Token,
+	id string,
+) error {
 	// Get the key
 	key, err := t.GetTokenKey(token, id)
 	if err != nil {
@@ -243,14 +414,16 @@ func (t *TokenValidator) RevokeToken(
 		return gocache.ErrItemNotFound
 	}
 
-	// Check if the item is a TimedItem
-	item, ok := value.(*gocachetimed.TimedItem)
-	if !ok {
+	// Check if the value is a token validity flag
+	if _, ok := value.(bool); !ok {
 		return ErrInvalidTokenItem
 	}
 
 	// Revoke the token in the cache
-	item.SetValue(false)
+	if err = t.cache.UpdateValue(key, false); err != nil {
+		gojwttokenclaims.RevokeTokenFailed(err, t.logger)
+		return err
+	}
 
 	// Also, revoke the access token if it's a refresh token
 	if token != gojwttoken.RefreshToken {
@@ -272,23 +445,103 @@ func (t *TokenValidator) RevokeToken(
 	}
 
 	// Parse the value to get the access token ID
-	parentRefreshTokenItem, ok := value.(*gocachetimed.TimedItem)
+	accessTokenID, ok := value.(string)
 	if !ok {
 		return ErrInvalidParentRefreshTokenItem
 	}
 
-	// Get the access token ID from the item
-	accessTokenID, ok := parentRefreshTokenItem.GetValue().(string)
-	if !ok {
-		return ErrInvalidParentRefreshTokenItem
+	// Revoke the access token in the cache
+	return t.revokeToken(gojwttoken.AccessToken, accessTokenID)
+}
+
+// RevokeToken revokes a token in the cache
+//
+// Parameters:
+//
+//   - ctx: The context (not used, but kept for interface consistency)
+//   - token: The token
+//   - id: The ID associated with the token
+//
+// Returns:
+//
+//   - error: An error if the token validator is nil or if revoking the token in the cache fails
+func (t *TokenValidator) RevokeToken(
+	ctx context.Context,
+	token gojwttoken.Token,
+	id string,
+) error {
+	if t == nil {
+		return gojwttokenclaims.ErrNilTokenValidator
 	}
 
-	// Revoke the access token in the cache
-	return t.RevokeToken(
-		ctx,
-		gojwttoken.AccessToken,
-		accessTokenID,
-	)
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	return t.revokeToken(token, id)
+}
+
+// RevokeTokenIfValid revokes a token in the cache only if it is still valid, while holding the lock
+//
+// Parameters:
+//
+//   - ctx: The context (not used, but kept for interface consistency)
+//   - token: The token
+//   - id: The ID associated with the token
+//
+// Returns:
+//
+//   - bool: Whether the token was valid and is now revoked
+//   - error: An error if the token validator is nil or if revoking the token in the cache fails
+func (t *TokenValidator) RevokeTokenIfValid(
+	ctx context.Context,
+	token gojwttoken.Token,
+	id string,
+) (bool, error) {
+	if t == nil {
+		return false, gojwttokenclaims.ErrNilTokenValidator
+	}
+
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	// Check if the token is still valid
+	isValid, _, err := t.lookupToken(token, id)
+	if err != nil || !isValid {
+		return false, err
+	}
+	return true, t.revokeToken(token, id)
+}
+
+// RevokeTokens revokes multiple tokens in the cache while holding the lock once. Tokens that are not in the cache are
+// skipped
+//
+// Parameters:
+//
+//   - ctx: The context (not used, but kept for interface consistency)
+//   - token: The token
+//   - ids: The IDs associated with the tokens
+//
+// Returns:
+//
+//   - error: An error if the token validator is nil or if revoking any token in the cache fails
+func (t *TokenValidator) RevokeTokens(
+	ctx context.Context,
+	token gojwttoken.Token,
+	ids []string,
+) error {
+	if t == nil {
+		return gojwttokenclaims.ErrNilTokenValidator
+	}
+
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	for _, id := range ids {
+		if err := t.revokeToken(token, id); err != nil && !errors.Is(err, gocache.ErrItemNotFound) {
+			return err
+		}
+	}
+	return nil
 }
 
 // IsTokenValid checks if a token is valid in the cache
@@ -302,7 +555,7 @@ func (t *TokenValidator) RevokeToken(
 // Returns:
 //
 //   - bool: Whether the token is valid
-//   - error: An error if the token validator is nil or if checking the token in the cache fails
+//   - error: An error if the token validator is nil, if the token is not in the cache or if checking the token fails
 func (t *TokenValidator) IsTokenValid(
 	ctx context.Context,
 	token gojwttoken.Token,
@@ -312,34 +565,195 @@ func (t *TokenValidator) IsTokenValid(
 		return false, gojwttokenclaims.ErrNilTokenValidator
 	}
 
+	// Look up the token
+	isValid, found, err := t.LookupToken(ctx, token, id)
+	if err != nil {
+		return false, err
+	}
+	if !found {
+		return false, gocache.ErrItemNotFound
+	}
+	return isValid, nil
+}
+
+// lookupToken looks up a token in the cache. The mutex must be held by the caller
+//
+// Parameters:
+//
+//   - token: The token
+//   - id: The ID associated with the token
+//
+// Returns:
+//
+//   - bool: Whether the token is valid
+//   - bool: Whether the token is in the cache
+//   - error: An error if checking the token in the cache fails
+func (t *TokenValidator) lookupToken(
+	token gojwttoken.Token,
+	id string,
+) (bool, bool, error) {
 	// Get the key
 	key, err := t.GetTokenKey(token, id)
 	if err != nil {
-		return false, err
+		return false, false, err
 	}
 
 	// Get the token from the cache
 	value, found := t.cache.Get(key)
 	if !found {
-		return false, gocache.ErrItemNotFound
+		return false, false, nil
 	}
 
-	// Check if the value is a TimedItem
-	timedItem, ok := value.(*gocachetimed.TimedItem)
+	// Return the validity of the token. Expired items are never returned by the cache
+	isValid, ok := value.(bool)
 	if !ok {
-		return false, ErrInvalidTokenItem
+		return false, true, ErrInvalidTokenItem
 	}
+	return isValid, true, nil
+}
 
-	// Check if the item has expired
-	if timedItem.HasExpired() {
-		return false, nil
+// LookupToken looks up a token in the cache
+//
+// Parameters:
+//
+//   - ctx: The context (not used, but kept for interface consistency)
+//   - token: The token
+//   - id: The ID associated with the token
+//
+// Returns:
+//
+//   - bool: Whether the token is valid
+//   - bool: Whether the token is in the cache
+//   - error: An error if the token validator is nil or if checking the token in the cache fails
+func (t *TokenValidator) LookupToken(
+	ctx context.Context,
+	token gojwttoken.Token,
+	id string,
+) (bool, bool, error) {
+	if t == nil {
+		return false, false, gojwttokenclaims.ErrNilTokenValidator
+	}
+
+	t.mutex.RLock()
+	defer t.mutex.RUnlock()
+
+	return t.lookupToken(token, id)
+}
+
+// LookupParentRefreshToken looks up the parent refresh token ID of an access token in the cache
+//
+// Parameters:
+//
+//   - ctx: The context (not used, but kept for interface consistency)
+//   - id: The ID associated with the access token
+//
+// Returns:
+//
+//   - string: The parent refresh token ID
+//   - bool: Whether the access token parent is in the cache
+//   - error: An error if the token validator is nil or if the access token parent item is invalid
+func (t *TokenValidator) LookupParentRefreshToken(
+	ctx context.Context,
+	id string,
+) (string, bool, error) {
+	if t == nil {
+		return "", false, gojwttokenclaims.ErrNilTokenValidator
+	}
+
+	// Get the key
+	key, err := t.GetAccessTokenParentKey(id)
+	if err != nil {
+		return "", false, err
 	}
 
-	// Return the validity of the token
-	isValid, ok := timedItem.GetValue().(bool)
+	t.mutex.RLock()
+	defer t.mutex.RUnlock()
+
+	// Get the parent refresh token ID from the cache. Expired items are never returned by the cache
+	value, found := t.cache.Get(key)
+	if !found {
+		return "", false, nil
+	}
+	parentRefreshTokenID, ok := value.(string)
 	if !ok {
-		return false, ErrInvalidTokenItem
+		return "", true, ErrInvalidParentRefreshTokenItem
 	}
+	return parentRefreshTokenID, true, nil
+}
 
-	return isValid, nil
+// AreTokensValid checks if multiple tokens are valid in the cache while holding the lock once. Tokens that are not in
+// the cache are considered invalid
+//
+// Parameters:
+//
+//   - ctx: The context (not used, but kept for interface consistency)
+//   - token: The token
+//   - ids: The IDs associated with the tokens
+//
+// Returns:
+//
+//   - []bool: Whether each token is valid, in the same order as the IDs
+//   - error: An error if the token validator is nil or if checking any token in the cache fails
+func (t *TokenValidator) AreTokensValid(
+	ctx context.Context,
+	token gojwttoken.Token,
+	ids []string,
+) ([]bool, error) {
+	if t == nil {
+		return nil, gojwttokenclaims.ErrNilTokenValidator
+	}
+
+	t.mutex.RLock()
+	defer t.mutex.RUnlock()
+
+	areValid := make([]bool, len(ids))
+	for i, id := range ids {
+		isValid, _, err := t.lookupToken(token, id)
+		if err != nil {
+			return nil, err
+		}
+		areValid[i] = isValid
+	}
+	return areValid, nil
+}
+
+// SetTokenValidity stores the validity of a token that was found elsewhere, such as in a lower tier of a layered
+// token validator
+//
+// Parameters:
+//
+//   - ctx: The context (not used, but kept for interface consistency)
+//   - token: The token
+//   - id: The ID associated with the token
+//   - isValid: Whether the token is valid
+//   - expiresAt: The time until the validity is kept in the cache
+//
+// Returns:
+//
+//   - error: An error if the token validator is nil or if setting the token in the cache fails
+func (t *TokenValidator) SetTokenValidity(
+	ctx context.Context,
+	token gojwttoken.Token,
+	id string,
+	isValid bool,
+	expiresAt time.Time,
+) error {
+	if t == nil {
+		return gojwttokenclaims.ErrNilTokenValidator
+	}
+
+	// Get the key
+	key, err := t.GetTokenKey(token, id)
+	if err != nil {
+		return err
+	}
+
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	// Set the token in the cache
+	if err = t.setItem(key, gocachetimed.NewTimedItem(isValid, expiresAt)); err != nil {
+		gojwttokenclaims.SetTokenFailed(err, t.logger)
+	}
+	return err
 }
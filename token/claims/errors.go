@@ -5,9 +5,11 @@ import (
 )
 
 var (
-	ErrIDClaimNotFound    = errors.New("id claim not found")
-	ErrInvalidIDClaim     = errors.New("invalid id claim")
-	ErrNilClaims          = errors.New("claims is nil")
-	ErrNilTokenValidator  = errors.New("nil token validator")
-	ErrNilClaimsValidator = errors.New("nil claims validator")
+	ErrIDClaimNotFound               = errors.New("id claim not found")
+	ErrInvalidIDClaim                = errors.New("invalid id claim")
+	ErrNilClaims                     = errors.New("claims is nil")
+	ErrNilTokenValidator             = errors.New("nil token validator")
+	ErrNilClaimsValidator            = errors.New("nil claims validator")
+	ErrDegradedDecision              = errors.New("token accepted without checking the token store")
+	ErrConditionalRevokeNotSupported = errors.New("conditional token revocation not supported")
 )
@@ -33,5 +33,52 @@ type (
 		) error
 		RevokeToken(ctx context.Context, token gojwttoken.Token, id string) error
 		IsTokenValid(ctx context.Context, token gojwttoken.Token, id string) (bool, error)
+		AddRefreshTokens(ctx context.Context, tokens []RefreshTokenRecord) error
+		AddAccessTokens(ctx context.Context, tokens []AccessTokenRecord) error
+		RevokeTokens(ctx context.Context, token gojwttoken.Token, ids []string) error
+		AreTokensValid(
+			ctx context.Context,
+			token gojwttoken.Token,
+			ids []string,
+		) ([]bool, error)
+	}
+
+	// TokenLookuper is the interface for token validators that can tell a missing token apart from a revoked one
+	TokenLookuper interface {
+		LookupToken(ctx context.Context, token gojwttoken.Token, id string) (
+			isValid bool,
+			found bool,
+			err error,
+		)
+	}
+
+	// ConditionalTokenRevoker is the interface for token validators that can revoke a token only if it is still valid,
+	// as a single atomic operation, so concurrent callers cannot both revoke the same token
+	ConditionalTokenRevoker interface {
+		RevokeTokenIfValid(ctx context.Context, token gojwttoken.Token, id string) (
+			revoked bool,
+			err error,
+		)
+	}
+
+	// ParentRefreshTokenLookuper is the interface for token validators that can find the parent refresh token of a
+	// valid access token, so the access token can be populated in other token validators together with its parent
+	ParentRefreshTokenLookuper interface {
+		LookupParentRefreshToken(ctx context.Context, id string) (
+			parentRefreshTokenID string,
+			found bool,
+			err error,
+		)
+	}
+
+	// TokenPopulator is the interface for token validators that can store the validity of a token found elsewhere
+	TokenPopulator interface {
+		SetTokenValidity(
+			ctx context.Context,
+			token gojwttoken.Token,
+			id string,
+			isValid bool,
+			expiresAt time.Time,
+		) error
 	}
 )
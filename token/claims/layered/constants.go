@@ -0,0 +1,13 @@
+package layered
+
+import (
+	"time"
+)
+
+var (
+	// DefaultPopulateTTL is the default time a token validity found in a lower tier is kept in the upper tiers
+	DefaultPopulateTTL = 1 * time.Minute
+
+	// DefaultNegativeTTL is the default time a token missing from every tier is kept as invalid in the upper tiers
+	DefaultNegativeTTL = 30 * time.Second
+)
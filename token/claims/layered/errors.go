@@ -0,0 +1,10 @@
+package layered
+
+import (
+	"errors"
+)
+
+var (
+	ErrNoTiers = errors.New("at least one tier is required")
+	ErrNilTier = errors.New("nil tier token validator")
+)
@@ -0,0 +1,57 @@
+package layered
+
+import (
+	"time"
+
+	gojwttokenclaims "github.com/ralvarezdev/go-jwt/token/claims"
+)
+
+type (
+	// FailurePolicy is the policy applied when a tier fails
+	FailurePolicy int
+
+	// Tier is a token validator layer
+	Tier struct {
+		// Name is the name of the tier used for logging
+		Name string
+
+		// TokenValidator is the token validator of the tier
+		TokenValidator gojwttokenclaims.TokenValidator
+
+		// FailurePolicy is the policy applied when the tier fails
+		FailurePolicy FailurePolicy
+	}
+
+	// Options are the options for the layered token validator
+	Options struct {
+		// PopulateTTL is the time a token validity found in a lower tier is kept in the upper tiers
+		PopulateTTL time.Duration
+
+		// NegativeTTL is the time a token missing from every tier is kept as invalid in the upper tiers
+		NegativeTTL time.Duration
+	}
+)
+
+const (
+	// FailClosed returns the tier error to the caller
+	FailClosed FailurePolicy = iota
+
+	// FailOpen logs the tier error and continues with the next tier
+	FailOpen
+)
+
+// String returns the string representation of the failure policy
+//
+// Returns:
+//
+//   - string: The string representation of the failure policy
+func (f FailurePolicy) String() string {
+	switch f {
+	case FailClosed:
+		return "fail_closed"
+	case FailOpen:
+		return "fail_open"
+	default:
+		return "unknown"
+	}
+}
@@ -0,0 +1,663 @@
+package layered
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	gocache "github.com/ralvarezdev/go-cache"
+	gojwttoken "github.com/ralvarezdev/go-jwt/token"
+	gojwttokenclaims "github.com/ralvarezdev/go-jwt/token/claims"
+	gojwttokenclaimscache "github.com/ralvarezdev/go-jwt/token/claims/cache"
+)
+
+type (
+	// TokenValidator is a token validator that stacks other token validators. The first tier is the fastest one and
+	// the last tier is the authoritative one.
+	//
+	// Reads fall through the tiers until one of them knows the token, and the upper tiers that implement
+	// gojwttokenclaims.TokenPopulator are populated with the result. Tokens unknown to every tier are cached as
+	// invalid, unless a tier that fails open could not answer. Valid access tokens are populated together with their
+	// parent refresh token, so revoking the parent reaches them, which requires the tier that knows them to implement
+	// gojwttokenclaims.ParentRefreshTokenLookuper, otherwise they are not populated. Writes are applied to every tier,
+	// and the upper tiers that miss the parent refresh token of an added access token are skipped.
+	TokenValidator struct {
+		tiers       []Tier
+		populateTTL time.Duration
+		negativeTTL time.Duration
+		logger      *slog.Logger
+	}
+)
+
+// NewTokenValidator creates a new layered token validator
+//
+// Parameters:
+//
+//   - tiers: The tiers, from the fastest to the authoritative one
+//   - options: The options (optional, can be nil)
+//   - logger: The logger (optional, can be nil)
+//
+// Returns:
+//
+//   - *TokenValidator: The token validator
+//   - error: An error if there are no tiers or if any tier token validator is nil
+func NewTokenValidator(
+	tiers []Tier,
+	options *Options,
+	logger *slog.Logger,
+) (*TokenValidator, error) {
+	// Check if there are tiers
+	if len(tiers) == 0 {
+		return nil, ErrNoTiers
+	}
+
+	// Check the tiers, working on a copy to not modify the caller slice
+	tiers = append([]Tier(nil), tiers...)
+	for i, tier := range tiers {
+		if tier.TokenValidator == nil {
+			return nil, fmt.Errorf("%w: tier %d", ErrNilTier, i)
+		}
+		if tier.Name == "" {
+			tiers[i].Name = fmt.Sprintf("tier_%d", i)
+		}
+	}
+
+	// Set the TTLs
+	populateTTL := DefaultPopulateTTL
+	negativeTTL := DefaultNegativeTTL
+	if options != nil {
+		if options.PopulateTTL > 0 {
+			populateTTL = options.PopulateTTL
+		}
+		if options.NegativeTTL > 0 {
+			negativeTTL = options.NegativeTTL
+		}
+	}
+
+	if logger != nil {
+		logger = logger.With(slog.String("component", "layered_token_validator"))
+	}
+
+	return &TokenValidator{
+		tiers:       tiers,
+		populateTTL: populateTTL,
+		negativeTTL: negativeTTL,
+		logger:      logger,
+	}, nil
+}
+
+// handleTierError applies the failure policy of the tier to the given error
+//
+// Parameters:
+//
+//   - tier: The tier that failed
+//   - operation: The operation that failed, used for logging
+//   - err: The error returned by the tier
+//
+// Returns:
+//
+//   - error: The error if the tier fails closed, nil otherwise
+func (t *TokenValidator) handleTierError(
+	tier Tier,
+	operation string,
+	err error,
+) error {
+	if tier.FailurePolicy == FailClosed {
+		return fmt.Errorf("%s: %w", tier.Name, err)
+	}
+
+	if t.logger != nil {
+		t.logger.Warn(
+			"Tier failed, ignoring it",
+			slog.String("tier", tier.Name),
+			slog.String("operation", operation),
+			slog.String("error", err.Error()),
+		)
+	}
+	return nil
+}
+
+// applyToTiers applies the given function to the tiers in the given order. Every tier is always reached, and the
+// errors of the tiers that fail closed are joined
+//
+// Parameters:
+//
+//   - operation: The operation name, used for logging
+//   - topDown: Whether to start from the fastest tier
+//   - fn: The function to apply
+//
+// Returns:
+//
+//   - error: The joined errors of the tiers that fail closed
+func (t *TokenValidator) applyToTiers(
+	operation string,
+	topDown bool,
+	fn func(tier Tier) error,
+) error {
+	var errs []error
+	for i := range t.tiers {
+		tier := t.tiers[i]
+		if !topDown {
+			tier = t.tiers[len(t.tiers)-1-i]
+		}
+
+		if err := fn(tier); err != nil {
+			if tierErr := t.handleTierError(tier, operation, err); tierErr != nil {
+				errs = append(errs, tierErr)
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// AddRefreshToken adds a refresh token to every tier, starting from the authoritative one
+//
+// Parameters:
+//
+//   - ctx: The context
+//   - id: The ID associated with the token
+//   - expiresAt: The expiration time of the token
+//
+// Returns:
+//
+//   - error: An error if the token validator is nil or if a tier that fails closed could not add the token
+func (t *TokenValidator) AddRefreshToken(
+	ctx context.Context,
+	id string,
+	expiresAt time.Time,
+) error {
+	if t == nil {
+		return gojwttokenclaims.ErrNilTokenValidator
+	}
+
+	return t.applyToTiers(
+		"add_refresh_token", false, func(tier Tier) error {
+			return tier.TokenValidator.AddRefreshToken(ctx, id, expiresAt)
+		},
+	)
+}
+
+// AddAccessToken adds an access token to every tier, starting from the authoritative one
+//
+// Parameters:
+//
+//   - ctx: The context
+//   - id: The ID associated with the token
+//   - parentRefreshTokenID: The parent refresh token ID
+//   - expiresAt: The expiration time of the token
+//
+// Returns:
+//
+//   - error: An error if the token validator is nil or if a tier that fails closed could not add the token
+func (t *TokenValidator) AddAccessToken(
+	ctx context.Context,
+	id string,
+	parentRefreshTokenID string,
+	expiresAt time.Time,
+) error {
+	if t == nil {
+		return gojwttokenclaims.ErrNilTokenValidator
+	}
+
+	return t.applyToTiers(
+		"add_access_token", false, func(tier Tier) error {
+			err := tier.TokenValidator.AddAccessToken(
+				ctx,
+				id,
+				parentRefreshTokenID,
+				expiresAt,
+			)
+
+			// The tiers that miss the parent refresh token miss the access token too
+			if errors.Is(err, gojwttokenclaimscache.ErrParentRefreshTokenNotFound) {
+				return nil
+			}
+			return err
+		},
+	)
+}
+
+// RevokeToken revokes a token in every tier, starting from the fastest one so the revocation takes effect as soon as
+// possible. Tiers that do not hold the token are ignored
+//
+// Parameters:
+//
+//   - ctx: The context
+//   - token: The token
+//   - id: The ID associated with the token
+//
+// Returns:
+//
+//   - error: An error if the token validator is nil or if a tier that fails closed could not revoke the token
+func (t *TokenValidator) RevokeToken(
+	ctx context.Context,
+	token gojwttoken.Token,
+	id string,
+) error {
+	if t == nil {
+		return gojwttokenclaims.ErrNilTokenValidator
+	}
+
+	return t.applyToTiers(
+		"revoke_token", true, func(tier Tier) error {
+			err := tier.TokenValidator.RevokeToken(ctx, token, id)
+			if errors.Is(err, gocache.ErrItemNotFound) {
+				return nil
+			}
+			return err
+		},
+	)
+}
+
+// RevokeTokenIfValid revokes a token only if it is still valid in the authoritative tier, which must implement the
+// ConditionalTokenRevoker interface. Once revoked there, the token is revoked in every tier
+//
+// Parameters:
+//
+//   - ctx: The context
+//   - token: The token
+//   - id: The ID associated with the token
+//
+// Returns:
+//
+//   - bool: Whether the token was valid and is now revoked
+//   - error: ErrConditionalRevokeNotSupported if the authoritative tier cannot revoke conditionally, or an error if
+//     revoking the token fails
+func (t *TokenValidator) RevokeTokenIfValid(
+	ctx context.Context,
+	token gojwttoken.Token,
+	id string,
+) (bool, error) {
+	if t == nil {
+		return false, gojwttokenclaims.ErrNilTokenValidator
+	}
+
+	// Check if the authoritative tier can revoke conditionally
+	authoritative := t.tiers[len(t.tiers)-1]
+	revoker, ok := authoritative.TokenValidator.(gojwttokenclaims.ConditionalTokenRevoker)
+	if !ok {
+		return false, gojwttokenclaims.ErrConditionalRevokeNotSupported
+	}
+
+	// Revoke the token in the authoritative tier
+	revoked, err := revoker.RevokeTokenIfValid(ctx, token, id)
+	if err != nil {
+		return false, fmt.Errorf("%s: %w", authoritative.Name, err)
+	}
+	if !revoked {
+		return false, nil
+	}
+
+	// Revoke the token in every tier, which is a no-op for the authoritative one
+	return true, t.RevokeToken(ctx, token, id)
+}
+
+// lookupToken looks up a token in the given tier
+//
+// Parameters:
+//
+//   - ctx: The context
+//   - tier: The tier
+//   - token: The token
+//   - id: The ID associated with the token
+//
+// Returns:
+//
+//   - bool: Whether the token is valid
+//   - bool: Whether the tier knows the token
+//   - error: An error if the lookup failed
+func (t *TokenValidator) lookupToken(
+	ctx context.Context,
+	tier Tier,
+	token gojwttoken.Token,
+	id string,
+) (bool, bool, error) {
+	// Prefer the lookup, which tells missing tokens apart from revoked ones
+	if lookuper, ok := tier.TokenValidator.(gojwttokenclaims.TokenLookuper); ok {
+		return lookuper.LookupToken(ctx, token, id)
+	}
+
+	isValid, err := tier.TokenValidator.IsTokenValid(ctx, token, id)
+	if err != nil {
+		if errors.Is(err, gocache.ErrItemNotFound) {
+			return false, false, nil
+		}
+		return false, false, err
+	}
+	return isValid, true, nil
+}
+
+// populateTiers stores the validity of a token in the given tiers. Valid access tokens are skipped, since they must
+// be populated together with their parent refresh token by populateAccessToken
+//
+// Parameters:
+//
+//   - ctx: The context
+//   - tiers: The tiers to populate
+//   - token: The token
+//   - id: The ID associated with the token
+//   - isValid: Whether the token is valid
+//   - ttl: The time the validity is kept in the tiers
+func (t *TokenValidator) populateTiers(
+	ctx context.Context,
+	tiers []Tier,
+	token gojwttoken.Token,
+	id string,
+	isValid bool,
+	ttl time.Duration,
+) {
+	// Check if the token is a valid access token
+	if isValid && token == gojwttoken.AccessToken {
+		return
+	}
+
+	expiresAt := time.Now().Add(ttl)
+	for _, tier := range tiers {
+		populator, ok := tier.TokenValidator.(gojwttokenclaims.TokenPopulator)
+		if !ok {
+			continue
+		}
+
+		if err := populator.SetTokenValidity(
+			ctx,
+			token,
+			id,
+			isValid,
+			expiresAt,
+		); err != nil && t.logger != nil {
+			t.logger.Warn(
+				"Failed to populate tier",
+				slog.String("tier", tier.Name),
+				slog.String("error", err.Error()),
+			)
+		}
+	}
+}
+
+// populateAccessToken adds a valid access token to the given tiers together with its parent refresh token, which is
+// found in the tier that knows the access token, so revoking the parent in the tiers reaches the access token
+//
+// Parameters:
+//
+//   - ctx: The context
+//   - source: The tier that knows the access token
+//   - tiers: The tiers to populate
+//   - id: The ID associated with the access token
+func (t *TokenValidator) populateAccessToken(
+	ctx context.Context,
+	source Tier,
+	tiers []Tier,
+	id string,
+) {
+	// Check if there are tiers to populate
+	if len(tiers) == 0 {
+		return
+	}
+
+	// Get the parent refresh token from the tier that knows the access token
+	lookuper, ok := source.TokenValidator.(gojwttokenclaims.ParentRefreshTokenLookuper)
+	if !ok {
+		return
+	}
+	parentRefreshTokenID, found, err := lookuper.LookupParentRefreshToken(ctx, id)
+	if err != nil || !found {
+		if err != nil && t.logger != nil {
+			t.logger.Warn(
+				"Failed to look up the parent refresh token",
+				slog.String("tier", source.Name),
+				slog.String("error", err.Error()),
+			)
+		}
+		return
+	}
+
+	// Check if the parent refresh token is still valid, since it may have been revoked after the access token lookup
+	isParentValid, found, err := t.lookupToken(
+		ctx,
+		source,
+		gojwttoken.RefreshToken,
+		parentRefreshTokenID,
+	)
+	if err != nil || !found || !isParentValid {
+		return
+	}
+
+	expiresAt := time.Now().Add(t.populateTTL)
+	for _, tier := range tiers {
+		err = tier.TokenValidator.AddAccessToken(
+			ctx,
+			id,
+			parentRefreshTokenID,
+			expiresAt,
+		)
+
+		// Populate the parent refresh token of the tiers that miss it, and add the access token again
+		if errors.Is(err, gojwttokenclaimscache.ErrParentRefreshTokenNotFound) {
+			populator, ok := tier.TokenValidator.(gojwttokenclaims.TokenPopulator)
+			if !ok {
+				continue
+			}
+			if err = populator.SetTokenValidity(
+				ctx,
+				gojwttoken.RefreshToken,
+				parentRefreshTokenID,
+				true,
+				expiresAt,
+			); err == nil {
+				err = tier.TokenValidator.AddAccessToken(
+					ctx,
+					id,
+					parentRefreshTokenID,
+					expiresAt,
+				)
+			}
+		}
+
+		if err != nil && t.logger != nil {
+			t.logger.Warn(
+				"Failed to populate tier",
+				slog.String("tier", tier.Name),
+				slog.String("error", err.Error()),
+			)
+		}
+	}
+}
+
+// IsTokenValid checks if a token is valid, falling through the tiers until one of them knows the token
+//
+// Parameters:
+//
+//   - ctx: The context
+//   - token: The token
+//   - id: The ID associated with the token
+//
+// Returns:
+//
+//   - bool: Whether the token is valid
+//   - error: An error if the token validator is nil, if a tier that fails closed failed or if every tier failed
+func (t *TokenValidator) IsTokenValid(
+	ctx context.Context,
+	token gojwttoken.Token,
+	id string,
+) (bool, error) {
+	if t == nil {
+		return false, gojwttokenclaims.ErrNilTokenValidator
+	}
+
+	var missedTiers []Tier
+	var lastErr error
+	authoritativeMissed := false
+	for i, tier := range t.tiers {
+		isValid, found, err := t.lookupToken(ctx, tier, token, id)
+		if err != nil {
+			if tierErr := t.handleTierError(tier, "is_token_valid", err); tierErr != nil {
+				return false, tierErr
+			}
+			lastErr = err
+			continue
+		}
+
+		// Fall through to the next tier if the token is missing
+		if !found {
+			if i == len(t.tiers)-1 {
+				authoritativeMissed = true
+			} else {
+				missedTiers = append(missedTiers, tier)
+			}
+			continue
+		}
+
+		// Populate the upper tiers that missed the token
+		if isValid && token == gojwttoken.AccessToken {
+			t.populateAccessToken(ctx, tier, missedTiers, id)
+		} else {
+			t.populateTiers(ctx, missedTiers, token, id, isValid, t.populateTTL)
+		}
+		return isValid, nil
+	}
+
+	// Check if the authoritative tier could not answer, in which case the token is not cached as invalid
+	if !authoritativeMissed {
+		return false, lastErr
+	}
+
+	// Cache the token as invalid in the upper tiers, the authoritative tier is never populated
+	t.populateTiers(ctx, missedTiers, token, id, false, t.negativeTTL)
+	return false, nil
+}
+
+// AddRefreshTokens adds multiple refresh tokens to every tier, starting from the authoritative one
+//
+// Parameters:
+//
+//   - ctx: The context
+//   - tokens: The refresh tokens to add
+//
+// Returns:
+//
+//   - error: An error if the token validator is nil or if a tier that fails closed could not add the tokens
+func (t *TokenValidator) AddRefreshTokens(
+	ctx context.Context,
+	tokens []gojwttokenclaims.RefreshTokenRecord,
+) error {
+	if t == nil {
+		return gojwttokenclaims.ErrNilTokenValidator
+	}
+
+	return t.applyToTiers(
+		"add_refresh_tokens", false, func(tier Tier) error {
+			return tier.TokenValidator.AddRefreshTokens(ctx, tokens)
+		},
+	)
+}
+
+// AddAccessTokens adds multiple access tokens to every tier, starting from the authoritative one
+//
+// Parameters:
+//
+//   - ctx: The context
+//   - tokens: The access tokens to add
+//
+// Returns:
+//
+//   - error: An error if the token validator is nil or if a tier that fails closed could not add the tokens
+func (t *TokenValidator) AddAccessTokens(
+	ctx context.Context,
+	tokens []gojwttokenclaims.AccessTokenRecord,
+) error {
+	if t == nil {
+		return gojwttokenclaims.ErrNilTokenValidator
+	}
+
+	return t.applyToTiers(
+		"add_access_tokens", false, func(tier Tier) error {
+			err := tier.TokenValidator.AddAccessTokens(ctx, tokens)
+			if !errors.Is(err, gojwttokenclaimscache.ErrParentRefreshTokenNotFound) {
+				return err
+			}
+
+			// Add the tokens one by one, so the tokens whose parent refresh token is missing from the tier are
+			// skipped instead of the whole batch
+			for _, token := range tokens {
+				if err = tier.TokenValidator.AddAccessToken(
+					ctx,
+					token.ID,
+					token.ParentRefreshTokenID,
+					token.ExpiresAt,
+				); err != nil && !errors.Is(
+					err,
+					gojwttokenclaimscache.ErrParentRefreshTokenNotFound,
+				) {
+					return err
+				}
+			}
+			return nil
+		},
+	)
+}
+
+// RevokeTokens revokes multiple tokens in every tier, starting from the fastest one
+//
+// Parameters:
+//
+//   - ctx: The context
+//   - token: The token
+//   - ids: The IDs associated with the tokens
+//
+// Returns:
+//
+//   - error: An error if the token validator is nil or if a tier that fails closed could not revoke the tokens
+func (t *TokenValidator) RevokeTokens(
+	ctx context.Context,
+	token gojwttoken.Token,
+	ids []string,
+) error {
+	if t == nil {
+		return gojwttokenclaims.ErrNilTokenValidator
+	}
+
+	return t.applyToTiers(
+		"revoke_tokens", true, func(tier Tier) error {
+			err := tier.TokenValidator.RevokeTokens(ctx, token, ids)
+			if errors.Is(err, gocache.ErrItemNotFound) {
+				return nil
+			}
+			return err
+		},
+	)
+}
+
+// AreTokensValid checks if multiple tokens are valid. Each token falls through the tiers on its own, as in
+// IsTokenValid
+//
+// Parameters:
+//
+//   - ctx: The context
+//   - token: The token
+//   - ids: The IDs associated with the tokens
+//
+// Returns:
+//
+//   - []bool: Whether each token is valid, in the same order as the IDs
+//   - error: An error if the token validator is nil or if checking any token fails
+func (t *TokenValidator) AreTokensValid(
+	ctx context.Context,
+	token gojwttoken.Token,
+	ids []string,
+) ([]bool, error) {
+	if t == nil {
+		return nil, gojwttokenclaims.ErrNilTokenValidator
+	}
+
+	areValid := make([]bool, len(ids))
+	for i, id := range ids {
+		isValid, err := t.IsTokenValid(ctx, token, id)
+		if err != nil {
+			return nil, err
+		}
+		areValid[i] = isValid
+	}
+	return areValid, nil
+}
@@ -0,0 +1,199 @@
+package layered
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	gojwttoken "github.com/ralvarezdev/go-jwt/token"
+	gojwttokenclaims "github.com/ralvarezdev/go-jwt/token/claims"
+	gojwttokenclaimscache "github.com/ralvarezdev/go-jwt/token/claims/cache"
+)
+
+// newTestTokenValidator creates a layered token validator with a cache tier on top of an authoritative cache tier,
+// both failing closed
+//
+// Parameters:
+//
+//   - t: The test
+//
+// Returns:
+//
+//   - *TokenValidator: The layered token validator
+//   - *gojwttokenclaimscache.TokenValidator: The upper tier
+//   - *gojwttokenclaimscache.TokenValidator: The authoritative tier
+func newTestTokenValidator(t *testing.T) (
+	*TokenValidator,
+	*gojwttokenclaimscache.TokenValidator,
+	*gojwttokenclaimscache.TokenValidator,
+) {
+	t.Helper()
+
+	upper := gojwttokenclaimscache.NewTokenValidator(nil)
+	authoritative := gojwttokenclaimscache.NewTokenValidator(nil)
+	tokenValidator, err := NewTokenValidator(
+		[]Tier{
+			{Name: "cache", TokenValidator: upper},
+			{Name: "authoritative", TokenValidator: authoritative},
+		},
+		nil,
+		nil,
+	)
+	if err != nil {
+		t.Fatalf("NewTokenValidator() error = %v", err)
+	}
+	return tokenValidator, upper, authoritative
+}
+
+func TestTokenValidator_RevokeParentOfReadAccessToken(t *testing.T) {
+	ctx := context.Background()
+	tokenValidator, upper, authoritative := newTestTokenValidator(t)
+
+	// Add the tokens to the authoritative tier only
+	now := time.Now()
+	if err := authoritative.AddRefreshToken(ctx, "refresh", now.Add(time.Hour)); err != nil {
+		t.Fatalf("AddRefreshToken() error = %v", err)
+	}
+	if err := authoritative.AddAccessToken(ctx, "access", "refresh", now.Add(time.Minute)); err != nil {
+		t.Fatalf("AddAccessToken() error = %v", err)
+	}
+
+	// Read the access token through the layers, and then revoke its parent refresh token
+	isValid, err := tokenValidator.IsTokenValid(ctx, gojwttoken.AccessToken, "access")
+	if err != nil || !isValid {
+		t.Fatalf("IsTokenValid() = %v, %v, want true, nil", isValid, err)
+	}
+
+	// The access token is populated in the upper tier together with its parent refresh token
+	for _, token := range []gojwttoken.Token{gojwttoken.AccessToken, gojwttoken.RefreshToken} {
+		id := "access"
+		if token == gojwttoken.RefreshToken {
+			id = "refresh"
+		}
+		isValid, found, err := upper.LookupToken(ctx, token, id)
+		if err != nil || !found || !isValid {
+			t.Fatalf("upper LookupToken(%s) = %v, %v, %v, want true, true, nil", token, isValid, found, err)
+		}
+	}
+
+	if err = tokenValidator.RevokeToken(ctx, gojwttoken.RefreshToken, "refresh"); err != nil {
+		t.Fatalf("RevokeToken() error = %v", err)
+	}
+
+	// The access token must not be served as valid by the upper tier
+	isValid, err = tokenValidator.IsTokenValid(ctx, gojwttoken.AccessToken, "access")
+	if err != nil {
+		t.Fatalf("IsTokenValid() error = %v", err)
+	}
+	if isValid {
+		t.Error("IsTokenValid() = true after revoking the parent refresh token, want false")
+	}
+}
+
+func TestTokenValidator_AddAccessTokenMissingParent(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+
+	tests := []struct {
+		name string
+		add  func(tokenValidator *TokenValidator) error
+	}{
+		{
+			name: "single",
+			add: func(tokenValidator *TokenValidator) error {
+				return tokenValidator.AddAccessToken(ctx, "access", "refresh", now.Add(time.Minute))
+			},
+		},
+		{
+			name: "batch",
+			add: func(tokenValidator *TokenValidator) error {
+				return tokenValidator.AddAccessTokens(
+					ctx,
+					[]gojwttokenclaims.AccessTokenRecord{
+						{ID: "access", ParentRefreshTokenID: "refresh", ExpiresAt: now.Add(time.Minute)},
+					},
+				)
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(
+			test.name, func(t *testing.T) {
+				tokenValidator, _, authoritative := newTestTokenValidator(t)
+
+				// Add the parent refresh token to the authoritative tier only
+				if err := authoritative.AddRefreshToken(ctx, "refresh", now.Add(time.Hour)); err != nil {
+					t.Fatalf("AddRefreshToken() error = %v", err)
+				}
+
+				// The upper tier misses the parent refresh token, which must not fail the write
+				if err := test.add(tokenValidator); err != nil {
+					t.Fatalf("add error = %v, want nil", err)
+				}
+				isValid, err := tokenValidator.IsTokenValid(ctx, gojwttoken.AccessToken, "access")
+				if err != nil || !isValid {
+					t.Errorf("IsTokenValid() = %v, %v, want true, nil", isValid, err)
+				}
+			},
+		)
+	}
+}
+
+// errTierUnavailable is the error returned by the failingTokenValidator
+var errTierUnavailable = errors.New("tier unavailable")
+
+type (
+	// failingTokenValidator is a token validator whose reads always fail
+	failingTokenValidator struct {
+		gojwttokenclaims.TokenValidator
+	}
+)
+
+// IsTokenValid always fails
+//
+// Parameters:
+//
+//   - ctx: The context
+//   - token: The token
+//   - id: The ID associated with the token
+//
+// Returns:
+//
+//   - bool: Always false
+//   - error: Always errTierUnavailable
+func (f failingTokenValidator) IsTokenValid(
+	ctx context.Context,
+	token gojwttoken.Token,
+	id string,
+) (bool, error) {
+	return false, errTierUnavailable
+}
+
+func TestTokenValidator_IsTokenValid_FailingAuthoritativeTier(t *testing.T) {
+	ctx := context.Background()
+
+	upper := gojwttokenclaimscache.NewTokenValidator(nil)
+	tokenValidator, err := NewTokenValidator(
+		[]Tier{
+			{Name: "cache", TokenValidator: upper},
+			{Name: "authoritative", TokenValidator: failingTokenValidator{}, FailurePolicy: FailOpen},
+		},
+		nil,
+		nil,
+	)
+	if err != nil {
+		t.Fatalf("NewTokenValidator() error = %v", err)
+	}
+
+	// The error of the authoritative tier is returned instead of an invalid token
+	isValid, err := tokenValidator.IsTokenValid(ctx, gojwttoken.AccessToken, "access")
+	if !errors.Is(err, errTierUnavailable) || isValid {
+		t.Errorf("IsTokenValid() = %v, %v, want false, %v", isValid, err, errTierUnavailable)
+	}
+
+	// The token is not cached as invalid in the upper tier
+	if _, found, err := upper.LookupToken(ctx, gojwttoken.AccessToken, "access"); err != nil || found {
+		t.Errorf("upper LookupToken() found = %v, %v, want false, nil", found, err)
+	}
+}
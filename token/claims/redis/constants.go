@@ -8,6 +8,9 @@ var (
 	// ParentRefreshTokenIDPrefix is the prefix of the Parent Refresh Token ID key
 	ParentRefreshTokenIDPrefix = "prt"
 
+	// AccessTokenParentPrefix is the prefix of the key that holds the parent refresh token ID of an access token
+	AccessTokenParentPrefix = "atp"
+
 	// KeySeparator is the separator for the Redis keys
 	KeySeparator = gostringsseparator.Dots
 )
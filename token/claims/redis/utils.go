@@ -50,4 +50,23 @@ func GetParentRefreshTokenKey(
 		KeySeparator,
 		ParentRefreshTokenIDPrefix,
 	)
-}
\ No newline at end of file
+}
+
+// GetAccessTokenParentKey gets the key that holds the parent refresh token ID of an access token
+//
+// Parameters:
+//
+//   - id: The ID associated with the access token
+//
+// Returns:
+//
+//   - string: The key for the parent refresh token ID of the access token
+func GetAccessTokenParentKey(
+	id string,
+) string {
+	return gostringsadd.Prefixes(
+		id,
+		KeySeparator,
+		AccessTokenParentPrefix,
+	)
+}
@@ -162,6 +162,17 @@ func (t *TokenValidator) AddAccessToken(
 		return setErr
 	}
 
+	// Set the access token ID with its parent refresh token ID
+	if setErr := t.redisClient.Set(
+		ctx,
+		GetAccessTokenParentKey(id),
+		parentRefreshTokenID,
+		time.Until(expiresAt),
+	).Err(); setErr != nil {
+		gojwttokenclaims.SetTokenFailed(setErr, t.logger)
+		return setErr
+	}
+
 	return t.setKey(ctx, key, true, expiresAt)
 }
 
@@ -216,12 +227,16 @@ func (t *TokenValidator) RevokeToken(
 	// Get the parent refresh token key
 	parentRefreshTokenKey := GetParentRefreshTokenKey(id)
 
-	// Get the associated access token ID
+	// Get the associated access token ID, whose key expires together with the access token
 	accessTokenID, err := t.redisClient.Get(
 		ctx,
 		parentRefreshTokenKey,
 	).Result()
 	if err != nil {
+		// Check if the error is a redis.Nil error (the access token already expired)
+		if errors.Is(err, redis.Nil) {
+			return nil
+		}
 		gojwttokenclaims.GetTokenFailed(err, t.logger)
 		return err
 	}
@@ -241,6 +256,11 @@ func (t *TokenValidator) RevokeToken(
 		return err
 	}
 
+	// Check if the access token key already expired
+	if accessTokenTTL <= 0 {
+		return nil
+	}
+
 	// Update the value maintaining the TTL
 	if err = t.setKey(
 		ctx,
@@ -254,6 +274,64 @@ func (t *TokenValidator) RevokeToken(
 	return nil
 }
 
+// RevokeTokenIfValid revokes the token only if it is still valid. The validity is swapped in a single command, so only
+// one of the concurrent callers revokes the token
+//
+// Parameters:
+//
+//   - ctx: The context
+//   - token: The token
+//   - id: The ID associated with the token
+//
+// Returns:
+//
+//   - bool: Whether the token was valid and is now revoked
+//   - error: An error if the token validator is nil or if revoking the token fails
+func (t *TokenValidator) RevokeTokenIfValid(
+	ctx context.Context,
+	token gojwttoken.Token,
+	id string,
+) (bool, error) {
+	if t == nil {
+		return false, gojwttokenclaims.ErrNilTokenValidator
+	}
+
+	// Get the key
+	key, err := GetKey(token, id)
+	if err != nil {
+		return false, err
+	}
+
+	// Swap the validity of an existing key, keeping its TTL
+	previous, err := t.redisClient.SetArgs(
+		ctx,
+		key,
+		false,
+		redis.SetArgs{Mode: "XX", KeepTTL: true, Get: true},
+	).Result()
+	if err != nil {
+		// Check if the error is a redis.Nil error (key does not exist)
+		if errors.Is(err, redis.Nil) {
+			return false, nil
+		}
+		gojwttokenclaims.RevokeTokenFailed(err, t.logger)
+		return false, err
+	}
+
+	// Check if the token was valid
+	if wasValid, parseErr := strconv.ParseBool(previous); parseErr != nil || !wasValid {
+		return false, nil
+	}
+
+	// Revoke the associated access token of a refresh token
+	if token == gojwttoken.RefreshToken {
+		if err = t.RevokeToken(ctx, token, id); err != nil && !errors.Is(err, redis.Nil) {
+			return true, err
+		}
+	}
+	return true, nil
+}
+
 // IsTokenValid checks if the token is valid
 //
 // Parameters:
@@ -275,10 +353,37 @@ func (t *TokenValidator) IsTokenValid(
 		return false, gojwttokenclaims.ErrNilTokenValidator
 	}
 
+	// Look up the token, a missing key is considered invalid
+	isValid, _, err := t.LookupToken(ctx, token, id)
+	return isValid, err
+}
+
+// LookupToken looks up the token
+//
+// Parameters:
+//
+//   - ctx: The context
+//   - token: The token
+//   - id: The ID associated with the token
+//
+// Returns:
+//
+//   - bool: True if the token is valid, false if revoked
+//   - bool: True if the token key exists
+//   - error: An error if the token validator is nil or if checking the token fails
+func (t *TokenValidator) LookupToken(
+	ctx context.Context,
+	token gojwttoken.Token,
+	id string,
+) (bool, bool, error) {
+	if t == nil {
+		return false, false, gojwttokenclaims.ErrNilTokenValidator
+	}
+
 	// Get the key
 	key, err := GetKey(token, id)
 	if err != nil {
-		return false, err
+		return false, false, err
 	}
 
 	// Get the value
@@ -289,16 +394,389 @@ func (t *TokenValidator) IsTokenValid(
 	if err != nil {
 		// Check if the error is a redis.Nil error (key does not exist)
 		if errors.Is(err, redis.Nil) {
-			return false, nil
+			return false, false, nil
 		}
 		gojwttokenclaims.GetTokenFailed(err, t.logger)
-		return false, err
+		return false, false, err
 	}
 
 	// Parse the value
 	parsedIsValue, err := strconv.ParseBool(isValid)
 	if err != nil {
-		return false, err
+		return false, true, err
+	}
+	return parsedIsValue, true, nil
+}
+
+// LookupParentRefreshToken looks up the parent refresh token ID of an access token
+//
+// Parameters:
+//
+//   - ctx: The context
+//   - id: The ID associated with the access token
+//
+// Returns:
+//
+//   - string: The parent refresh token ID
+//   - bool: True if the access token parent key exists, which is missing for the access tokens added by previous
+//     versions
+//   - error: An error if the token validator is nil or if getting the parent refresh token ID fails
+func (t *TokenValidator) LookupParentRefreshToken(
+	ctx context.Context,
+	id string,
+) (string, bool, error) {
+	if t == nil {
+		return "", false, gojwttokenclaims.ErrNilTokenValidator
+	}
+
+	// Get the parent refresh token ID
+	parentRefreshTokenID, err := t.redisClient.Get(
+		ctx,
+		GetAccessTokenParentKey(id),
+	).Result()
+	if err != nil {
+		// Check if the error is a redis.Nil error (key does not exist)
+		if errors.Is(err, redis.Nil) {
+			return "", false, nil
+		}
+		gojwttokenclaims.GetTokenFailed(err, t.logger)
+		return "", false, err
+	}
+	return parentRefreshTokenID, true, nil
+}
+
+// SetTokenValidity stores the validity of a token that was found elsewhere, such as in a lower tier of a layered
+// token validator
+//
+// Parameters:
+//
+//   - ctx: The context
+//   - token: The token
+//   - id: The ID associated with the token
+//   - isValid: Whether the token is valid
+//   - expiresAt: The time until the validity is kept
+//
+// Returns:
+//
+//   - error: An error if the token validator is nil or if setting the token fails
+func (t *TokenValidator) SetTokenValidity(
+	ctx context.Context,
+	token gojwttoken.Token,
+	id string,
+	isValid bool,
+	expiresAt time.Time,
+) error {
+	if t == nil {
+		return gojwttokenclaims.ErrNilTokenValidator
+	}
+
+	// Get the key
+	key, err := GetKey(token, id)
+	if err != nil {
+		return err
+	}
+
+	return t.setKey(ctx, key, isValid, expiresAt)
+}
+
+// AddRefreshTokens adds multiple refresh tokens in a single pipeline
+//
+// Parameters:
+//
+//   - ctx: The context
+//   - tokens: The refresh tokens to add
+//
+// Returns:
+//
+//   - error: An error if the token validator is nil or if adding any refresh token fails
+func (t *TokenValidator) AddRefreshTokens(
+	ctx context.Context,
+	tokens []gojwttokenclaims.RefreshTokenRecord,
+) error {
+	if t == nil {
+		return gojwttokenclaims.ErrNilTokenValidator
+	}
+
+	// Check if there are tokens to add
+	if len(tokens) == 0 {
+		return nil
+	}
+
+	// Set the keys with their expiration time
+	if _, err := t.redisClient.Pipelined(
+		ctx, func(pipe redis.Pipeliner) error {
+			for _, token := range tokens {
+				key, err := GetKey(gojwttoken.RefreshToken, token.ID)
+				if err != nil {
+					return err
+				}
+				pipe.SetArgs(
+					ctx,
+					key,
+					true,
+					redis.SetArgs{ExpireAt: token.ExpiresAt},
+				)
+			}
+			return nil
+		},
+	); err != nil {
+		gojwttokenclaims.SetTokenFailed(err, t.logger)
+		return err
+	}
+	return nil
+}
+
+// AddAccessTokens adds multiple access tokens in a single pipeline
+//
+// Parameters:
+//
+//   - ctx: The context
+//   - tokens: The access tokens to add
+//
+// Returns:
+//
+//   - error: An error if the token validator is nil or if adding any access token fails
+func (t *TokenValidator) AddAccessTokens(
+	ctx context.Context,
+	tokens []gojwttokenclaims.AccessTokenRecord,
+) error {
+	if t == nil {
+		return gojwttokenclaims.ErrNilTokenValidator
+	}
+
+	// Check if there are tokens to add
+	if len(tokens) == 0 {
+		return nil
+	}
+
+	// Set the parent refresh token keys, the access token parent keys and the access token keys with their expiration
+	// time
+	if _, err := t.redisClient.Pipelined(
+		ctx, func(pipe redis.Pipeliner) error {
+			for _, token := range tokens {
+				key, err := GetKey(gojwttoken.AccessToken, token.ID)
+				if err != nil {
+					return err
+				}
+				pipe.SetArgs(
+					ctx,
+					GetParentRefreshTokenKey(token.ParentRefreshTokenID),
+					token.ID,
+					redis.SetArgs{ExpireAt: token.ExpiresAt},
+				)
+				pipe.SetArgs(
+					ctx,
+					GetAccessTokenParentKey(token.ID),
+					token.ParentRefreshTokenID,
+					redis.SetArgs{ExpireAt: token.ExpiresAt},
+				)
+				pipe.SetArgs(
+					ctx,
+					key,
+					true,
+					redis.SetArgs{ExpireAt: token.ExpiresAt},
+				)
+			}
+			return nil
+		},
+	); err != nil {
+		gojwttokenclaims.SetTokenFailed(err, t.logger)
+		return err
+	}
+	return nil
+}
+
+// revokeKeys marks the given keys as revoked in a single pipeline, keeping their TTL. Missing keys are skipped
+//
+// Parameters:
+//
+//   - ctx: The context
+//   - keys: The keys to revoke
+//
+// Returns:
+//
+//   - error: An error if revoking any key fails
+func (t *TokenValidator) revokeKeys(ctx context.Context, keys []string) error {
+	// Check if there are keys to revoke
+	if len(keys) == 0 {
+		return nil
+	}
+
+	// Only update existing keys, so revoking an unknown token never creates a key without expiration
+	cmds, err := t.redisClient.Pipelined(
+		ctx, func(pipe redis.Pipeliner) error {
+			for _, key := range keys {
+				pipe.SetArgs(
+					ctx,
+					key,
+					false,
+					redis.SetArgs{Mode: "XX", KeepTTL: true},
+				)
+			}
+			return nil
+		},
+	)
+	if err != nil && !errors.Is(err, redis.Nil) {
+		gojwttokenclaims.RevokeTokenFailed(err, t.logger)
+		return err
+	}
+	for _, cmd := range cmds {
+		if cmdErr := cmd.Err(); cmdErr != nil && !errors.Is(cmdErr, redis.Nil) {
+			gojwttokenclaims.RevokeTokenFailed(cmdErr, t.logger)
+			return cmdErr
+		}
+	}
+	return nil
+}
+
+// RevokeTokens revokes multiple tokens using pipelines. The access tokens associated with revoked refresh tokens are
+// also revoked
+//
+// Parameters:
+//
+//   - ctx: The context
+//   - token: The token
+//   - ids: The IDs associated with the tokens
+//
+// Returns:
+//
+//   - error: An error if the token validator is nil or if revoking any token fails
+func (t *TokenValidator) RevokeTokens(
+	ctx context.Context,
+	token gojwttoken.Token,
+	ids []string,
+) error {
+	if t == nil {
+		return gojwttokenclaims.ErrNilTokenValidator
+	}
+
+	// Check if there are tokens to revoke
+	if len(ids) == 0 {
+		return nil
+	}
+
+	// Get the keys
+	keys := make([]string, len(ids))
+	for i, id := range ids {
+		key, err := GetKey(token, id)
+		if err != nil {
+			return err
+		}
+		keys[i] = key
+	}
+
+	// Revoke the tokens
+	if err := t.revokeKeys(ctx, keys); err != nil {
+		return err
+	}
+
+	// Check if the tokens are refresh tokens to revoke their associated access tokens
+	if token == gojwttoken.AccessToken {
+		return nil
+	}
+
+	// Get the associated access token IDs
+	cmds, err := t.redisClient.Pipelined(
+		ctx, func(pipe redis.Pipeliner) error {
+			for _, id := range ids {
+				pipe.Get(ctx, GetParentRefreshTokenKey(id))
+			}
+			return nil
+		},
+	)
+	if err != nil && !errors.Is(err, redis.Nil) {
+		gojwttokenclaims.GetTokenFailed(err, t.logger)
+		return err
+	}
+
+	// Get the access token keys
+	accessTokenKeys := make([]string, 0, len(cmds))
+	for _, cmd := range cmds {
+		stringCmd, ok := cmd.(*redis.StringCmd)
+		if !ok {
+			continue
+		}
+		accessTokenID, cmdErr := stringCmd.Result()
+		if cmdErr != nil {
+			if errors.Is(cmdErr, redis.Nil) {
+				continue
+			}
+			gojwttokenclaims.GetTokenFailed(cmdErr, t.logger)
+			return cmdErr
+		}
+
+		accessTokenKey, keyErr := GetKey(gojwttoken.AccessToken, accessTokenID)
+		if keyErr != nil {
+			return keyErr
+		}
+		accessTokenKeys = append(accessTokenKeys, accessTokenKey)
+	}
+
+	// Revoke the associated access tokens
+	return t.revokeKeys(ctx, accessTokenKeys)
+}
+
+// AreTokensValid checks if multiple tokens are valid in a single pipeline. Missing tokens are considered invalid
+//
+// Parameters:
+//
+//   - ctx: The context
+//   - token: The token
+//   - ids: The IDs associated with the tokens
+//
+// Returns:
+//
+//   - []bool: Whether each token is valid, in the same order as the IDs
+//   - error: An error if the token validator is nil or if checking any token fails
+func (t *TokenValidator) AreTokensValid(
+	ctx context.Context,
+	token gojwttoken.Token,
+	ids []string,
+) ([]bool, error) {
+	if t == nil {
+		return nil, gojwttokenclaims.ErrNilTokenValidator
+	}
+
+	// Check if there are tokens to check
+	areValid := make([]bool, len(ids))
+	if len(ids) == 0 {
+		return areValid, nil
+	}
+
+	// Get the values
+	cmds := make([]*redis.StringCmd, len(ids))
+	if _, err := t.redisClient.Pipelined(
+		ctx, func(pipe redis.Pipeliner) error {
+			for i, id := range ids {
+				key, err := GetKey(token, id)
+				if err != nil {
+					return err
+				}
+				cmds[i] = pipe.Get(ctx, key)
+			}
+			return nil
+		},
+	); err != nil && !errors.Is(err, redis.Nil) {
+		gojwttokenclaims.GetTokenFailed(err, t.logger)
+		return nil, err
+	}
+
+	// Parse the values
+	for i, cmd := range cmds {
+		value, err := cmd.Result()
+		if err != nil {
+			if errors.Is(err, redis.Nil) {
+				continue
+			}
+			gojwttokenclaims.GetTokenFailed(err, t.logger)
+			return nil, err
+		}
+
+		isValid, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, err
+		}
+		areValid[i] = isValid
 	}
-	return parsedIsValue, nil
+	return areValid, nil
 }
@@ -0,0 +1,203 @@
+package redis
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	gojwttoken "github.com/ralvarezdev/go-jwt/token"
+	"github.com/redis/go-redis/v9"
+)
+
+// newTestTokenValidator creates a token validator connected to a new Redis server
+//
+// Parameters:
+//
+//   - t: The test
+//
+// Returns:
+//
+//   - *TokenValidator: The token validator
+//   - *miniredis.Miniredis: The Redis server
+func newTestTokenValidator(t *testing.T) (*TokenValidator, *miniredis.Miniredis) {
+	t.Helper()
+
+	server := miniredis.RunT(t)
+	redisClient := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	t.Cleanup(
+		func() {
+			_ = redisClient.Close()
+		},
+	)
+
+	tokenValidator, err := NewTokenValidator(redisClient, nil)
+	if err != nil {
+		t.Fatalf("NewTokenValidator() error = %v", err)
+	}
+	return tokenValidator, server
+}
+
+func TestTokenValidator_RevokeTokenIfValid(t *testing.T) {
+	ctx := context.Background()
+	tokenValidator, _ := newTestTokenValidator(t)
+
+	now := time.Now()
+	if err := tokenValidator.AddRefreshToken(ctx, "refresh", now.Add(time.Hour)); err != nil {
+		t.Fatalf("AddRefreshToken() error = %v", err)
+	}
+	if err := tokenValidator.AddAccessToken(ctx, "access", "refresh", now.Add(time.Minute)); err != nil {
+		t.Fatalf("AddAccessToken() error = %v", err)
+	}
+
+	// Only one of the concurrent revocations of the refresh token succeeds
+	var revocations atomic.Int32
+	var wg sync.WaitGroup
+	for range 16 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			revoked, err := tokenValidator.RevokeTokenIfValid(ctx, gojwttoken.RefreshToken, "refresh")
+			if err != nil {
+				t.Errorf("RevokeTokenIfValid() error = %v", err)
+			}
+			if revoked {
+				revocations.Add(1)
+			}
+		}()
+	}
+	wg.Wait()
+	if revocations.Load() != 1 {
+		t.Errorf("revocations = %d, want 1", revocations.Load())
+	}
+
+	// The refresh token and its access token are revoked
+	for _, check := range []struct {
+		token gojwttoken.Token
+		id    string
+	}{
+		{token: gojwttoken.RefreshToken, id: "refresh"},
+		{token: gojwttoken.AccessToken, id: "access"},
+	} {
+		isValid, err := tokenValidator.IsTokenValid(ctx, check.token, check.id)
+		if err != nil {
+			t.Fatalf("IsTokenValid() error = %v", err)
+		}
+		if isValid {
+			t.Errorf("%s token valid after the revocation", check.token)
+		}
+	}
+
+	// Unknown tokens are not revoked
+	revoked, err := tokenValidator.RevokeTokenIfValid(ctx, gojwttoken.RefreshToken, "unknown")
+	if err != nil || revoked {
+		t.Errorf("RevokeTokenIfValid() = %v, %v, want false, nil", revoked, err)
+	}
+}
+
+func TestTokenValidator_RevokeToken_ExpiredAccessToken(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name   string
+		expire func(t *testing.T, server *miniredis.Miniredis)
+	}{
+		{
+			name: "expired access token",
+			expire: func(t *testing.T, server *miniredis.Miniredis) {
+				server.FastForward(2 * time.Minute)
+			},
+		},
+		{
+			name: "missing access token key",
+			expire: func(t *testing.T, server *miniredis.Miniredis) {
+				key, err := GetKey(gojwttoken.AccessToken, "access")
+				if err != nil {
+					t.Fatalf("GetKey() error = %v", err)
+				}
+				server.Del(key)
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(
+			test.name, func(t *testing.T) {
+				tokenValidator, server := newTestTokenValidator(t)
+
+				now := time.Now()
+				if err := tokenValidator.AddRefreshToken(ctx, "refresh", now.Add(time.Hour)); err != nil {
+					t.Fatalf("AddRefreshToken() error = %v", err)
+				}
+				if err := tokenValidator.AddAccessToken(ctx, "access", "refresh", now.Add(time.Minute)); err != nil {
+					t.Fatalf("AddAccessToken() error = %v", err)
+				}
+				test.expire(t, server)
+
+				// The refresh token is revoked without its gone access token
+				if err := tokenValidator.RevokeToken(ctx, gojwttoken.RefreshToken, "refresh"); err != nil {
+					t.Fatalf("RevokeToken() error = %v", err)
+				}
+				isValid, err := tokenValidator.IsTokenValid(ctx, gojwttoken.RefreshToken, "refresh")
+				if err != nil {
+					t.Fatalf("IsTokenValid() error = %v", err)
+				}
+				if isValid {
+					t.Error("IsTokenValid() = true for the revoked refresh token, want false")
+				}
+
+				// The gone access token is not restored
+				accessTokenKey, err := GetKey(gojwttoken.AccessToken, "access")
+				if err != nil {
+					t.Fatalf("GetKey() error = %v", err)
+				}
+				if server.Exists(accessTokenKey) {
+					t.Error("the access token key exists after the revocation, want it gone")
+				}
+			},
+		)
+	}
+}
+
+func TestTokenValidator_LookupParentRefreshToken(t *testing.T) {
+	ctx := context.Background()
+	tokenValidator, _ := newTestTokenValidator(t)
+
+	now := time.Now()
+	if err := tokenValidator.AddRefreshToken(ctx, "refresh", now.Add(time.Hour)); err != nil {
+		t.Fatalf("AddRefreshToken() error = %v", err)
+	}
+	if err := tokenValidator.AddAccessToken(ctx, "access", "refresh", now.Add(time.Minute)); err != nil {
+		t.Fatalf("AddAccessToken() error = %v", err)
+	}
+
+	tests := []struct {
+		name      string
+		id        string
+		wantID    string
+		wantFound bool
+	}{
+		{name: "access token", id: "access", wantID: "refresh", wantFound: true},
+		{name: "missing access token", id: "missing"},
+	}
+	for _, test := range tests {
+		t.Run(
+			test.name, func(t *testing.T) {
+				parentRefreshTokenID, found, err := tokenValidator.LookupParentRefreshToken(ctx, test.id)
+				if err != nil {
+					t.Fatalf("LookupParentRefreshToken() error = %v", err)
+				}
+				if parentRefreshTokenID != test.wantID || found != test.wantFound {
+					t.Errorf(
+						"LookupParentRefreshToken() = %q, %v, want %q, %v",
+						parentRefreshTokenID,
+						found,
+						test.wantID,
+						test.wantFound,
+					)
+				}
+			},
+		)
+	}
+}
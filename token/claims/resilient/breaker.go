@@ -0,0 +1,145 @@
+package resilient
+
+import (
+	"sync"
+	"time"
+)
+
+type (
+	// CircuitBreaker is a consecutive failures circuit breaker
+	CircuitBreaker struct {
+		failureThreshold int
+		openTimeout      time.Duration
+		state            CircuitState
+		failures         int
+		openedAt         time.Time
+		probing          bool
+		now              func() time.Time
+		mutex            sync.Mutex
+	}
+)
+
+// NewCircuitBreaker creates a new circuit breaker
+//
+// Parameters:
+//
+//   - failureThreshold: The number of consecutive failures that opens the circuit (optional, DefaultFailureThreshold
+//     is used if not positive)
+//   - openTimeout: The time the circuit stays open before letting a probe call through (optional, DefaultOpenTimeout
+//     is used if not positive)
+//
+// Returns:
+//
+//   - *CircuitBreaker: The circuit breaker
+func NewCircuitBreaker(
+	failureThreshold int,
+	openTimeout time.Duration,
+) *CircuitBreaker {
+	if failureThreshold <= 0 {
+		failureThreshold = DefaultFailureThreshold
+	}
+	if openTimeout <= 0 {
+		openTimeout = DefaultOpenTimeout
+	}
+
+	return &CircuitBreaker{
+		failureThreshold: failureThreshold,
+		openTimeout:      openTimeout,
+		now:              time.Now,
+	}
+}
+
+// Allow checks if a call can go through
+//
+// Returns:
+//
+//   - error: ErrCircuitOpen if the circuit is open or a probe call is already in flight
+func (c *CircuitBreaker) Allow() error {
+	if c == nil {
+		return nil
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	switch c.state {
+	case CircuitOpen:
+		// Check if the circuit can be half-opened
+		if c.now().Sub(c.openedAt) < c.openTimeout {
+			return ErrCircuitOpen
+		}
+		c.state = CircuitHalfOpen
+		c.probing = true
+		return nil
+	case CircuitHalfOpen:
+		// Only a single probe call is allowed
+		if c.probing {
+			return ErrCircuitOpen
+		}
+		c.probing = true
+		return nil
+	default:
+		return nil
+	}
+}
+
+// RecordSuccess records a successful call, closing the circuit
+func (c *CircuitBreaker) RecordSuccess() {
+	if c == nil {
+		return
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.state = CircuitClosed
+	c.failures = 0
+	c.probing = false
+}
+
+// RecordFailure records a failed call, opening the circuit if the failure threshold is reached or if the failed call
+// was a probe
+func (c *CircuitBreaker) RecordFailure() {
+	if c == nil {
+		return
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.failures++
+	if c.state == CircuitHalfOpen || c.failures >= c.failureThreshold {
+		c.state = CircuitOpen
+		c.openedAt = c.now()
+		c.probing = false
+	}
+}
+
+// Release releases a call whose result says nothing about the health of the protected resource, such as a call
+// canceled by its caller, without changing the circuit state
+func (c *CircuitBreaker) Release() {
+	if c == nil {
+		return
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.probing = false
+}
+
+// State returns the current state of the circuit
+//
+// Returns:
+//
+//   - CircuitState: The current state of the circuit
+func (c *CircuitBreaker) State() CircuitState {
+	if c == nil {
+		return CircuitClosed
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	return c.state
+}
@@ -0,0 +1,135 @@
+package resilient
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+type (
+	// testClock is a manually advanced clock
+	testClock struct {
+		now time.Time
+	}
+)
+
+// Now returns the current time of the clock
+//
+// Returns:
+//
+//   - time.Time: The current time of the clock
+func (c *testClock) Now() time.Time {
+	return c.now
+}
+
+// Advance moves the clock forward
+//
+// Parameters:
+//
+//   - d: The duration to move the clock forward
+func (c *testClock) Advance(d time.Duration) {
+	c.now = c.now.Add(d)
+}
+
+// newTestCircuitBreaker creates a circuit breaker that opens after two consecutive failures for ten seconds, driven
+// by a manually advanced clock
+//
+// Parameters:
+//
+//   - t: The test
+//
+// Returns:
+//
+//   - *CircuitBreaker: The circuit breaker
+//   - *testClock: The clock of the circuit breaker
+func newTestCircuitBreaker(t *testing.T) (*CircuitBreaker, *testClock) {
+	t.Helper()
+
+	clock := &testClock{now: time.Unix(0, 0)}
+	breaker := NewCircuitBreaker(2, 10*time.Second)
+	breaker.now = clock.Now
+	return breaker, clock
+}
+
+// checkAllow checks the result of a call to Allow and the resulting circuit state
+//
+// Parameters:
+//
+//   - t: The test
+//   - breaker: The circuit breaker
+//   - wantErr: The expected error
+//   - wantState: The expected circuit state
+func checkAllow(
+	t *testing.T,
+	breaker *CircuitBreaker,
+	wantErr error,
+	wantState CircuitState,
+) {
+	t.Helper()
+
+	if err := breaker.Allow(); !errors.Is(err, wantErr) {
+		t.Fatalf("Allow() error = %v, want %v", err, wantErr)
+	}
+	if state := breaker.State(); state != wantState {
+		t.Fatalf("State() = %v, want %v", state, wantState)
+	}
+}
+
+func TestCircuitBreaker(t *testing.T) {
+	breaker, clock := newTestCircuitBreaker(t)
+
+	// The circuit opens after the consecutive failures
+	checkAllow(t, breaker, nil, CircuitClosed)
+	breaker.RecordFailure()
+	checkAllow(t, breaker, nil, CircuitClosed)
+	breaker.RecordFailure()
+	checkAllow(t, breaker, ErrCircuitOpen, CircuitOpen)
+
+	// The circuit stays open until the open timeout elapses
+	clock.Advance(9 * time.Second)
+	checkAllow(t, breaker, ErrCircuitOpen, CircuitOpen)
+
+	// A single probe call goes through once the open timeout elapses
+	clock.Advance(time.Second)
+	checkAllow(t, breaker, nil, CircuitHalfOpen)
+	checkAllow(t, breaker, ErrCircuitOpen, CircuitHalfOpen)
+
+	// A failed probe opens the circuit again for the whole open timeout
+	breaker.RecordFailure()
+	checkAllow(t, breaker, ErrCircuitOpen, CircuitOpen)
+	clock.Advance(9 * time.Second)
+	checkAllow(t, breaker, ErrCircuitOpen, CircuitOpen)
+
+	// A released probe lets another probe through
+	clock.Advance(time.Second)
+	checkAllow(t, breaker, nil, CircuitHalfOpen)
+	breaker.Release()
+	checkAllow(t, breaker, nil, CircuitHalfOpen)
+
+	// A successful probe closes the circuit
+	breaker.RecordSuccess()
+	checkAllow(t, breaker, nil, CircuitClosed)
+	checkAllow(t, breaker, nil, CircuitClosed)
+}
+
+func TestCircuitBreaker_RecordSuccess(t *testing.T) {
+	breaker, _ := newTestCircuitBreaker(t)
+
+	// A success resets the consecutive failures
+	breaker.RecordFailure()
+	breaker.RecordSuccess()
+	breaker.RecordFailure()
+	checkAllow(t, breaker, nil, CircuitClosed)
+	breaker.RecordFailure()
+	checkAllow(t, breaker, ErrCircuitOpen, CircuitOpen)
+}
+
+func TestCircuitBreaker_Nil(t *testing.T) {
+	var breaker *CircuitBreaker
+
+	// A nil circuit breaker lets every call through
+	breaker.RecordFailure()
+	breaker.Release()
+	breaker.RecordSuccess()
+	checkAllow(t, breaker, nil, CircuitClosed)
+}
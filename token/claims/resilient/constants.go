@@ -0,0 +1,16 @@
+package resilient
+
+import (
+	"time"
+)
+
+var (
+	// DefaultLookupTimeout is the default timeout for each token store call
+	DefaultLookupTimeout = 500 * time.Millisecond
+
+	// DefaultFailureThreshold is the default number of consecutive failures that opens the circuit
+	DefaultFailureThreshold = 5
+
+	// DefaultOpenTimeout is the default time the circuit stays open before letting a probe call through
+	DefaultOpenTimeout = 30 * time.Second
+)
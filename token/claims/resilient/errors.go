@@ -0,0 +1,9 @@
+package resilient
+
+import (
+	"errors"
+)
+
+var (
+	ErrCircuitOpen = errors.New("token store circuit is open")
+)
@@ -0,0 +1,86 @@
+package resilient
+
+import (
+	"time"
+)
+
+type (
+	// DegradedMode is the behavior applied when the token store cannot be reached
+	DegradedMode int
+
+	// CircuitState is the state of a circuit breaker
+	CircuitState int
+
+	// Options are the options for the resilient token validator
+	Options struct {
+		// LookupTimeout is the timeout for each token store call
+		LookupTimeout time.Duration
+
+		// FailureThreshold is the number of consecutive failures that opens the circuit
+		FailureThreshold int
+
+		// OpenTimeout is the time the circuit stays open before letting a probe call through
+		OpenTimeout time.Duration
+
+		// DegradedMode is the behavior applied when the token store cannot be reached
+		DegradedMode DegradedMode
+
+		// IsUnavailableError tells if an error returned by the wrapped token validator means that the token store
+		// could not be reached (optional, IsUnavailableError is used if nil)
+		IsUnavailableError func(err error) bool
+	}
+)
+
+const (
+	// DegradedModeDisabled rejects every token when the token store cannot be reached
+	DegradedModeDisabled DegradedMode = iota
+
+	// DegradedModeAcceptAccessTokens accepts access tokens on their signature and expiration alone when the token
+	// store cannot be reached. Refresh tokens are always rejected
+	DegradedModeAcceptAccessTokens
+)
+
+const (
+	// CircuitClosed lets every call through
+	CircuitClosed CircuitState = iota
+
+	// CircuitOpen rejects every call
+	CircuitOpen
+
+	// CircuitHalfOpen lets a single probe call through
+	CircuitHalfOpen
+)
+
+// String returns the string representation of the degraded mode
+//
+// Returns:
+//
+//   - string: The string representation of the degraded mode
+func (d DegradedMode) String() string {
+	switch d {
+	case DegradedModeDisabled:
+		return "disabled"
+	case DegradedModeAcceptAccessTokens:
+		return "accept_access_tokens"
+	default:
+		return "unknown"
+	}
+}
+
+// String returns the string representation of the circuit state
+//
+// Returns:
+//
+//   - string: The string representation of the circuit state
+func (c CircuitState) String() string {
+	switch c {
+	case CircuitClosed:
+		return "closed"
+	case CircuitOpen:
+		return "open"
+	case CircuitHalfOpen:
+		return "half_open"
+	default:
+		return "unknown"
+	}
+}
@@ -0,0 +1,46 @@
+package resilient
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"net"
+	"syscall"
+)
+
+// IsUnavailableError checks if an error means that the token store could not be reached. Only the circuit breaker being
+// open, a lookup timeout and transport errors count, data errors returned by a reachable store do not
+//
+// Parameters:
+//
+//   - err: The error to check
+//
+// Returns:
+//
+//   - bool: Whether the error means that the token store could not be reached
+func IsUnavailableError(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	// Check if the circuit is open or the lookup timed out
+	if errors.Is(err, ErrCircuitOpen) || errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+
+	// Check if the connection to the token store failed
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return true
+	}
+	return errors.Is(err, io.EOF) ||
+		errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, net.ErrClosed) ||
+		errors.Is(err, syscall.ECONNREFUSED) ||
+		errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, syscall.EPIPE) ||
+		errors.Is(err, driver.ErrBadConn) ||
+		errors.Is(err, sql.ErrConnDone)
+}
@@ -0,0 +1,46 @@
+package resilient
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"syscall"
+	"testing"
+)
+
+func TestIsUnavailableError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{name: "nil", err: nil, want: false},
+		{name: "circuit open", err: ErrCircuitOpen, want: true},
+		{name: "lookup timeout", err: fmt.Errorf("lookup: %w", context.DeadlineExceeded), want: true},
+		{name: "network error", err: &net.OpError{Op: "dial", Err: errors.New("no route to host")}, want: true},
+		{name: "connection refused", err: fmt.Errorf("dial: %w", syscall.ECONNREFUSED), want: true},
+		{name: "connection reset", err: syscall.ECONNRESET, want: true},
+		{name: "broken pipe", err: syscall.EPIPE, want: true},
+		{name: "closed connection", err: net.ErrClosed, want: true},
+		{name: "end of file", err: io.EOF, want: true},
+		{name: "unexpected end of file", err: io.ErrUnexpectedEOF, want: true},
+		{name: "bad driver connection", err: driver.ErrBadConn, want: true},
+		{name: "done database connection", err: sql.ErrConnDone, want: true},
+		{name: "canceled caller", err: context.Canceled, want: false},
+		{name: "no rows", err: sql.ErrNoRows, want: false},
+		{name: "data error", err: errors.New("malformed token"), want: false},
+	}
+	for _, test := range tests {
+		t.Run(
+			test.name, func(t *testing.T) {
+				if got := IsUnavailableError(test.err); got != test.want {
+					t.Errorf("IsUnavailableError(%v) = %v, want %v", test.err, got, test.want)
+				}
+			},
+		)
+	}
+}
@@ -0,0 +1,472 @@
+package resilient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	gojwttoken "github.com/ralvarezdev/go-jwt/token"
+	gojwttokenclaims "github.com/ralvarezdev/go-jwt/token/claims"
+)
+
+type (
+	// TokenValidator wraps a token validator with a timeout per call, a circuit breaker and a degraded mode used
+	// when the wrapped token store cannot be reached
+	TokenValidator struct {
+		tokenValidator     gojwttokenclaims.TokenValidator
+		breaker            *CircuitBreaker
+		lookupTimeout      time.Duration
+		degradedMode       DegradedMode
+		isUnavailableError func(err error) bool
+		logger             *slog.Logger
+	}
+)
+
+// NewTokenValidator creates a new resilient token validator
+//
+// Parameters:
+//
+//   - tokenValidator: The wrapped token validator
+//   - options: The options (optional, can be nil)
+//   - logger: The logger (optional, can be nil)
+//
+// Returns:
+//
+//   - *TokenValidator: The token validator
+//   - error: An error if the wrapped token validator is nil
+func NewTokenValidator(
+	tokenValidator gojwttokenclaims.TokenValidator,
+	options *Options,
+	logger *slog.Logger,
+) (*TokenValidator, error) {
+	// Check if the token validator is nil
+	if tokenValidator == nil {
+		return nil, gojwttokenclaims.ErrNilTokenValidator
+	}
+
+	// Set the options
+	if options == nil {
+		options = &Options{}
+	}
+	lookupTimeout := options.LookupTimeout
+	if lookupTimeout <= 0 {
+		lookupTimeout = DefaultLookupTimeout
+	}
+	isUnavailableError := options.IsUnavailableError
+	if isUnavailableError == nil {
+		isUnavailableError = IsUnavailableError
+	}
+
+	if logger != nil {
+		logger = logger.With(slog.String("component", "resilient_token_validator"))
+	}
+
+	return &TokenValidator{
+		tokenValidator: tokenValidator,
+		breaker: NewCircuitBreaker(
+			options.FailureThreshold,
+			options.OpenTimeout,
+		),
+		lookupTimeout:      lookupTimeout,
+		degradedMode:       options.DegradedMode,
+		isUnavailableError: isUnavailableError,
+		logger:             logger,
+	}, nil
+}
+
+// CircuitState returns the current state of the circuit breaker
+//
+// Returns:
+//
+//   - CircuitState: The current state of the circuit breaker
+func (t *TokenValidator) CircuitState() CircuitState {
+	if t == nil {
+		return CircuitClosed
+	}
+	return t.breaker.State()
+}
+
+// call runs the given function through the circuit breaker with the lookup timeout
+//
+// Parameters:
+//
+//   - ctx: The context
+//   - fn: The function to run
+//
+// Returns:
+//
+//   - error: ErrCircuitOpen if the circuit is open, or the error returned by the function
+func (t *TokenValidator) call(
+	ctx context.Context,
+	fn func(ctx context.Context) error,
+) error {
+	// Check if the call can go through
+	if err := t.breaker.Allow(); err != nil {
+		return err
+	}
+
+	// Run the function with the timeout
+	callCtx, cancel := context.WithTimeout(ctx, t.lookupTimeout)
+	defer cancel()
+	err := fn(callCtx)
+
+	// Record the result. A canceled caller says nothing about the store health, and any error other than an
+	// unavailable store is an answer from a reachable store
+	switch {
+	case ctx.Err() != nil:
+		t.breaker.Release()
+	case err != nil && t.isUnavailableError(err):
+		t.breaker.RecordFailure()
+	default:
+		t.breaker.RecordSuccess()
+	}
+	return err
+}
+
+// isDegraded checks if the result of a token store call must be decided by the degraded mode
+//
+// Parameters:
+//
+//   - ctx: The caller context
+//   - err: The error returned by the call
+//
+// Returns:
+//
+//   - bool: Whether the degraded mode applies
+func (t *TokenValidator) isDegraded(ctx context.Context, err error) bool {
+	// Check if the degraded mode is disabled or the caller gave up on the call
+	if err == nil || t.degradedMode == DegradedModeDisabled || ctx.Err() != nil {
+		return false
+	}
+	return errors.Is(err, ErrCircuitOpen) || t.isUnavailableError(err)
+}
+
+// AddRefreshToken adds a refresh token
+//
+// Parameters:
+//
+//   - ctx: The context
+//   - id: The ID associated with the token
+//   - expiresAt: The expiration time of the token
+//
+// Returns:
+//
+//   - error: An error if the token validator is nil, if the circuit is open or if adding the token fails
+func (t *TokenValidator) AddRefreshToken(
+	ctx context.Context,
+	id string,
+	expiresAt time.Time,
+) error {
+	if t == nil {
+		return gojwttokenclaims.ErrNilTokenValidator
+	}
+
+	return t.call(
+		ctx, func(ctx context.Context) error {
+			return t.tokenValidator.AddRefreshToken(ctx, id, expiresAt)
+		},
+	)
+}
+
+// AddAccessToken adds an access token
+//
+// Parameters:
+//
+//   - ctx: The context
+//   - id: The ID associated with the token
+//   - parentRefreshTokenID: The parent refresh token ID
+//   - expiresAt: The expiration time of the token
+//
+// Returns:
+//
+//   - error: An error if the token validator is nil, if the circuit is open or if adding the token fails
+func (t *TokenValidator) AddAccessToken(
+	ctx context.Context,
+	id string,
+	parentRefreshTokenID string,
+	expiresAt time.Time,
+) error {
+	if t == nil {
+		return gojwttokenclaims.ErrNilTokenValidator
+	}
+
+	return t.call(
+		ctx, func(ctx context.Context) error {
+			return t.tokenValidator.AddAccessToken(
+				ctx,
+				id,
+				parentRefreshTokenID,
+				expiresAt,
+			)
+		},
+	)
+}
+
+// RevokeToken revokes a token
+//
+// Parameters:
+//
+//   - ctx: The context
+//   - token: The token
+//   - id: The ID associated with the token
+//
+// Returns:
+//
+//   - error: An error if the token validator is nil, if the circuit is open or if revoking the token fails
+func (t *TokenValidator) RevokeToken(
+	ctx context.Context,
+	token gojwttoken.Token,
+	id string,
+) error {
+	if t == nil {
+		return gojwttokenclaims.ErrNilTokenValidator
+	}
+
+	return t.call(
+		ctx, func(ctx context.Context) error {
+			return t.tokenValidator.RevokeToken(ctx, token, id)
+		},
+	)
+}
+
+// RevokeTokenIfValid revokes a token only if it is still valid, if the wrapped token validator implements the
+// ConditionalTokenRevoker interface
+//
+// Parameters:
+//
+//   - ctx: The context
+//   - token: The token
+//   - id: The ID associated with the token
+//
+// Returns:
+//
+//   - bool: Whether the token was valid and is now revoked
+//   - error: ErrConditionalRevokeNotSupported if the wrapped token validator cannot revoke conditionally, or an error
+//     if the circuit is open or if revoking the token fails
+func (t *TokenValidator) RevokeTokenIfValid(
+	ctx context.Context,
+	token gojwttoken.Token,
+	id string,
+) (bool, error) {
+	if t == nil {
+		return false, gojwttokenclaims.ErrNilTokenValidator
+	}
+
+	// Check if the wrapped token validator can revoke conditionally
+	revoker, ok := t.tokenValidator.(gojwttokenclaims.ConditionalTokenRevoker)
+	if !ok {
+		return false, gojwttokenclaims.ErrConditionalRevokeNotSupported
+	}
+
+	var revoked bool
+	err := t.call(
+		ctx, func(ctx context.Context) error {
+			var revokeErr error
+			revoked, revokeErr = revoker.RevokeTokenIfValid(ctx, token, id)
+			return revokeErr
+		},
+	)
+	return revoked, err
+}
+
+// IsTokenValid checks if a token is valid. If the token store cannot be reached, the degraded mode decides the result
+//
+// Parameters:
+//
+//   - ctx: The context
+//   - token: The token
+//   - id: The ID associated with the token
+//
+// Returns:
+//
+//   - bool: Whether the token is valid
+//   - error: An error if the token validator is nil or if the token store cannot be reached. An access token accepted
+//     by the degraded mode is returned as valid with an error wrapping ErrDegradedDecision
+func (t *TokenValidator) IsTokenValid(
+	ctx context.Context,
+	token gojwttoken.Token,
+	id string,
+) (bool, error) {
+	if t == nil {
+		return false, gojwttokenclaims.ErrNilTokenValidator
+	}
+
+	var isValid bool
+	err := t.call(
+		ctx, func(ctx context.Context) error {
+			var validErr error
+			isValid, validErr = t.tokenValidator.IsTokenValid(ctx, token, id)
+			return validErr
+		},
+	)
+	if !t.isDegraded(ctx, err) {
+		return isValid, err
+	}
+
+	// Access tokens were already checked by their signature and expiration, refresh tokens are always rejected
+	if token == gojwttoken.AccessToken && t.degradedMode == DegradedModeAcceptAccessTokens {
+		if t.logger != nil {
+			t.logger.Warn(
+				"Degraded decision: access token accepted without checking the token store",
+				slog.String("id", id),
+				slog.String("circuit_state", t.breaker.State().String()),
+				slog.String("error", err.Error()),
+			)
+		}
+		return true, fmt.Errorf("%w: %w", gojwttokenclaims.ErrDegradedDecision, err)
+	}
+
+	if t.logger != nil {
+		t.logger.Warn(
+			"Degraded decision: token rejected without checking the token store",
+			slog.String("token", token.String()),
+			slog.String("id", id),
+			slog.String("circuit_state", t.breaker.State().String()),
+			slog.String("error", err.Error()),
+		)
+	}
+	return false, err
+}
+
+// AddRefreshTokens adds multiple refresh tokens
+//
+// Parameters:
+//
+//   - ctx: The context
+//   - tokens: The refresh tokens to add
+//
+// Returns:
+//
+//   - error: An error if the token validator is nil, if the circuit is open or if adding the tokens fails
+func (t *TokenValidator) AddRefreshTokens(
+	ctx context.Context,
+	tokens []gojwttokenclaims.RefreshTokenRecord,
+) error {
+	if t == nil {
+		return gojwttokenclaims.ErrNilTokenValidator
+	}
+
+	return t.call(
+		ctx, func(ctx context.Context) error {
+			return t.tokenValidator.AddRefreshTokens(ctx, tokens)
+		},
+	)
+}
+
+// AddAccessTokens adds multiple access tokens
+//
+// Parameters:
+//
+//   - ctx: The context
+//   - tokens: The access tokens to add
+//
+// Returns:
+//
+//   - error: An error if the token validator is nil, if the circuit is open or if adding the tokens fails
+func (t *TokenValidator) AddAccessTokens(
+	ctx context.Context,
+	tokens []gojwttokenclaims.AccessTokenRecord,
+) error {
+	if t == nil {
+		return gojwttokenclaims.ErrNilTokenValidator
+	}
+
+	return t.call(
+		ctx, func(ctx context.Context) error {
+			return t.tokenValidator.AddAccessTokens(ctx, tokens)
+		},
+	)
+}
+
+// RevokeTokens revokes multiple tokens
+//
+// Parameters:
+//
+//   - ctx: The context
+//   - token: The token
+//   - ids: The IDs associated with the tokens
+//
+// Returns:
+//
+//   - error: An error if the token validator is nil, if the circuit is open or if revoking the tokens fails
+func (t *TokenValidator) RevokeTokens(
+	ctx context.Context,
+	token gojwttoken.Token,
+	ids []string,
+) error {
+	if t == nil {
+		return gojwttokenclaims.ErrNilTokenValidator
+	}
+
+	return t.call(
+		ctx, func(ctx context.Context) error {
+			return t.tokenValidator.RevokeTokens(ctx, token, ids)
+		},
+	)
+}
+
+// AreTokensValid checks if multiple tokens are valid. If the token store cannot be reached, the degraded mode decides
+// the result
+//
+// Parameters:
+//
+//   - ctx: The context
+//   - token: The token
+//   - ids: The IDs associated with the tokens
+//
+// Returns:
+//
+//   - []bool: Whether each token is valid, in the same order as the IDs
+//   - error: An error if the token validator is nil or if the token store cannot be reached. Access tokens accepted by
+//     the degraded mode are returned as valid with an error wrapping ErrDegradedDecision
+func (t *TokenValidator) AreTokensValid(
+	ctx context.Context,
+	token gojwttoken.Token,
+	ids []string,
+) ([]bool, error) {
+	if t == nil {
+		return nil, gojwttokenclaims.ErrNilTokenValidator
+	}
+
+	var areValid []bool
+	err := t.call(
+		ctx, func(ctx context.Context) error {
+			var validErr error
+			areValid, validErr = t.tokenValidator.AreTokensValid(ctx, token, ids)
+			return validErr
+		},
+	)
+	if !t.isDegraded(ctx, err) {
+		return areValid, err
+	}
+
+	// Access tokens were already checked by their signature and expiration, refresh tokens are always rejected
+	if token == gojwttoken.AccessToken && t.degradedMode == DegradedModeAcceptAccessTokens {
+		if t.logger != nil {
+			t.logger.Warn(
+				"Degraded decision: access tokens accepted without checking the token store",
+				slog.Int("count", len(ids)),
+				slog.String("circuit_state", t.breaker.State().String()),
+				slog.String("error", err.Error()),
+			)
+		}
+		areValid = make([]bool, len(ids))
+		for i := range areValid {
+			areValid[i] = true
+		}
+		return areValid, fmt.Errorf("%w: %w", gojwttokenclaims.ErrDegradedDecision, err)
+	}
+
+	if t.logger != nil {
+		t.logger.Warn(
+			"Degraded decision: tokens rejected without checking the token store",
+			slog.String("token", token.String()),
+			slog.Int("count", len(ids)),
+			slog.String("circuit_state", t.breaker.State().String()),
+			slog.String("error", err.Error()),
+		)
+	}
+	return nil, err
+}
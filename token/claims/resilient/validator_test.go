@@ -0,0 +1,340 @@
+package resilient
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"syscall"
+	"testing"
+	"time"
+
+	gojwttoken "github.com/ralvarezdev/go-jwt/token"
+	gojwttokenclaims "github.com/ralvarezdev/go-jwt/token/claims"
+)
+
+// errTestData is the error returned by a reachable token store that cannot answer
+var errTestData = errors.New("malformed token record")
+
+type (
+	// fakeTokenValidator is a token validator whose reads return the configured error, or valid tokens if nil
+	fakeTokenValidator struct {
+		gojwttokenclaims.TokenValidator
+		err   error
+		block bool
+		calls int
+	}
+)
+
+// IsTokenValid returns the configured error, or a valid token if nil
+//
+// Parameters:
+//
+//   - ctx: The context
+//   - token: The token
+//   - id: The ID associated with the token
+//
+// Returns:
+//
+//   - bool: Whether the configured error is nil
+//   - error: The configured error, or the context error if the call blocks
+func (f *fakeTokenValidator) IsTokenValid(
+	ctx context.Context,
+	_ gojwttoken.Token,
+	_ string,
+) (bool, error) {
+	f.calls++
+	if f.block {
+		<-ctx.Done()
+		return false, ctx.Err()
+	}
+	return f.err == nil, f.err
+}
+
+// AreTokensValid returns the configured error, or valid tokens if nil
+//
+// Parameters:
+//
+//   - ctx: The context
+//   - token: The token
+//   - ids: The IDs associated with the tokens
+//
+// Returns:
+//
+//   - []bool: Whether each token is valid
+//   - error: The configured error
+func (f *fakeTokenValidator) AreTokensValid(
+	_ context.Context,
+	_ gojwttoken.Token,
+	ids []string,
+) ([]bool, error) {
+	f.calls++
+	if f.err != nil {
+		return nil, f.err
+	}
+	areValid := make([]bool, len(ids))
+	for i := range areValid {
+		areValid[i] = true
+	}
+	return areValid, nil
+}
+
+// newTestTokenValidator creates a resilient token validator whose circuit opens after two consecutive failures for
+// ten seconds, driven by a manually advanced clock
+//
+// Parameters:
+//
+//   - t: The test
+//   - store: The wrapped token validator
+//   - degradedMode: The degraded mode
+//
+// Returns:
+//
+//   - *TokenValidator: The token validator
+//   - *testClock: The clock of the circuit breaker
+func newTestTokenValidator(
+	t *testing.T,
+	store *fakeTokenValidator,
+	degradedMode DegradedMode,
+) (*TokenValidator, *testClock) {
+	t.Helper()
+
+	tokenValidator, err := NewTokenValidator(
+		store,
+		&Options{
+			LookupTimeout:    10 * time.Millisecond,
+			FailureThreshold: 2,
+			OpenTimeout:      10 * time.Second,
+			DegradedMode:     degradedMode,
+		},
+		nil,
+	)
+	if err != nil {
+		t.Fatalf("NewTokenValidator() error = %v", err)
+	}
+	clock := &testClock{now: time.Unix(0, 0)}
+	tokenValidator.breaker.now = clock.Now
+	return tokenValidator, clock
+}
+
+func TestTokenValidator_IsTokenValid_CircuitBreaker(t *testing.T) {
+	ctx := context.Background()
+	store := &fakeTokenValidator{err: syscall.ECONNREFUSED}
+	tokenValidator, clock := newTestTokenValidator(t, store, DegradedModeDisabled)
+
+	// The unavailable store opens the circuit
+	for range 2 {
+		if _, err := tokenValidator.IsTokenValid(ctx, gojwttoken.AccessToken, "access"); !errors.Is(
+			err,
+			syscall.ECONNREFUSED,
+		) {
+			t.Fatalf("IsTokenValid() error = %v, want %v", err, syscall.ECONNREFUSED)
+		}
+	}
+	if state := tokenValidator.CircuitState(); state != CircuitOpen {
+		t.Fatalf("CircuitState() = %v, want %v", state, CircuitOpen)
+	}
+
+	// The open circuit does not call the store
+	if _, err := tokenValidator.IsTokenValid(ctx, gojwttoken.AccessToken, "access"); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("IsTokenValid() error = %v, want %v", err, ErrCircuitOpen)
+	}
+	if store.calls != 2 {
+		t.Fatalf("store calls = %d, want 2", store.calls)
+	}
+
+	// The probe call closes the circuit once the store is reachable again
+	store.err = nil
+	clock.Advance(10 * time.Second)
+	if isValid, err := tokenValidator.IsTokenValid(ctx, gojwttoken.AccessToken, "access"); err != nil || !isValid {
+		t.Fatalf("IsTokenValid() = %v, %v, want true, nil", isValid, err)
+	}
+	if state := tokenValidator.CircuitState(); state != CircuitClosed {
+		t.Fatalf("CircuitState() = %v, want %v", state, CircuitClosed)
+	}
+}
+
+func TestTokenValidator_IsTokenValid_CircuitStaysClosed(t *testing.T) {
+	tests := []struct {
+		name  string
+		ctx   func() context.Context
+		store *fakeTokenValidator
+	}{
+		{
+			name:  "data error",
+			ctx:   context.Background,
+			store: &fakeTokenValidator{err: errTestData},
+		},
+		{
+			name: "canceled caller",
+			ctx: func() context.Context {
+				ctx, cancel := context.WithCancel(context.Background())
+				cancel()
+				return ctx
+			},
+			store: &fakeTokenValidator{block: true},
+		},
+	}
+	for _, test := range tests {
+		t.Run(
+			test.name, func(t *testing.T) {
+				tokenValidator, _ := newTestTokenValidator(t, test.store, DegradedModeAcceptAccessTokens)
+
+				// The errors are returned as they are, without a degraded decision
+				for range 3 {
+					isValid, err := tokenValidator.IsTokenValid(test.ctx(), gojwttoken.AccessToken, "access")
+					if isValid || err == nil || errors.Is(err, gojwttokenclaims.ErrDegradedDecision) {
+						t.Fatalf("IsTokenValid() = %v, %v, want false and a non degraded error", isValid, err)
+					}
+				}
+				if state := tokenValidator.CircuitState(); state != CircuitClosed {
+					t.Errorf("CircuitState() = %v, want %v", state, CircuitClosed)
+				}
+			},
+		)
+	}
+}
+
+func TestTokenValidator_IsTokenValid_DegradedMode(t *testing.T) {
+	tests := []struct {
+		name         string
+		degradedMode DegradedMode
+		token        gojwttoken.Token
+		store        *fakeTokenValidator
+		wantValid    bool
+		wantErr      error
+		wantDegraded bool
+	}{
+		{
+			name:         "disabled",
+			degradedMode: DegradedModeDisabled,
+			token:        gojwttoken.AccessToken,
+			store:        &fakeTokenValidator{err: syscall.ECONNREFUSED},
+			wantErr:      syscall.ECONNREFUSED,
+		},
+		{
+			name:         "access token accepted",
+			degradedMode: DegradedModeAcceptAccessTokens,
+			token:        gojwttoken.AccessToken,
+			store:        &fakeTokenValidator{err: syscall.ECONNREFUSED},
+			wantValid:    true,
+			wantErr:      syscall.ECONNREFUSED,
+			wantDegraded: true,
+		},
+		{
+			name:         "access token accepted after a lookup timeout",
+			degradedMode: DegradedModeAcceptAccessTokens,
+			token:        gojwttoken.AccessToken,
+			store:        &fakeTokenValidator{block: true},
+			wantValid:    true,
+			wantErr:      context.DeadlineExceeded,
+			wantDegraded: true,
+		},
+		{
+			name:         "refresh token rejected",
+			degradedMode: DegradedModeAcceptAccessTokens,
+			token:        gojwttoken.RefreshToken,
+			store:        &fakeTokenValidator{err: syscall.ECONNREFUSED},
+			wantErr:      syscall.ECONNREFUSED,
+		},
+		{
+			name:         "data error",
+			degradedMode: DegradedModeAcceptAccessTokens,
+			token:        gojwttoken.AccessToken,
+			store:        &fakeTokenValidator{err: errTestData},
+			wantErr:      errTestData,
+		},
+		{
+			name:         "reachable store",
+			degradedMode: DegradedModeAcceptAccessTokens,
+			token:        gojwttoken.AccessToken,
+			store:        &fakeTokenValidator{},
+			wantValid:    true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(
+			test.name, func(t *testing.T) {
+				tokenValidator, _ := newTestTokenValidator(t, test.store, test.degradedMode)
+
+				isValid, err := tokenValidator.IsTokenValid(context.Background(), test.token, "id")
+				if isValid != test.wantValid || !errors.Is(err, test.wantErr) {
+					t.Errorf("IsTokenValid() = %v, %v, want %v, %v", isValid, err, test.wantValid, test.wantErr)
+				}
+				if degraded := errors.Is(err, gojwttokenclaims.ErrDegradedDecision); degraded != test.wantDegraded {
+					t.Errorf("IsTokenValid() degraded = %v, want %v", degraded, test.wantDegraded)
+				}
+			},
+		)
+	}
+}
+
+func TestTokenValidator_IsTokenValid_DegradedModeWithOpenCircuit(t *testing.T) {
+	ctx := context.Background()
+	store := &fakeTokenValidator{err: syscall.ECONNREFUSED}
+	tokenValidator, _ := newTestTokenValidator(t, store, DegradedModeAcceptAccessTokens)
+
+	// Open the circuit
+	for range 2 {
+		_, _ = tokenValidator.IsTokenValid(ctx, gojwttoken.AccessToken, "access")
+	}
+
+	// The access tokens are accepted and the refresh tokens rejected without calling the store
+	isValid, err := tokenValidator.IsTokenValid(ctx, gojwttoken.AccessToken, "access")
+	if !isValid || !errors.Is(err, gojwttokenclaims.ErrDegradedDecision) || !errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("IsTokenValid() = %v, %v, want true and a degraded %v", isValid, err, ErrCircuitOpen)
+	}
+	isValid, err = tokenValidator.IsTokenValid(ctx, gojwttoken.RefreshToken, "refresh")
+	if isValid || !errors.Is(err, ErrCircuitOpen) || errors.Is(err, gojwttokenclaims.ErrDegradedDecision) {
+		t.Errorf("IsTokenValid() = %v, %v, want false, %v", isValid, err, ErrCircuitOpen)
+	}
+	if store.calls != 2 {
+		t.Errorf("store calls = %d, want 2", store.calls)
+	}
+}
+
+func TestTokenValidator_AreTokensValid_DegradedMode(t *testing.T) {
+	ids := []string{"first", "second"}
+
+	tests := []struct {
+		name         string
+		token        gojwttoken.Token
+		wantValid    []bool
+		wantDegraded bool
+	}{
+		{
+			name:         "access tokens accepted",
+			token:        gojwttoken.AccessToken,
+			wantValid:    []bool{true, true},
+			wantDegraded: true,
+		},
+		{
+			name:  "refresh tokens rejected",
+			token: gojwttoken.RefreshToken,
+		},
+	}
+	for _, test := range tests {
+		t.Run(
+			test.name, func(t *testing.T) {
+				tokenValidator, _ := newTestTokenValidator(
+					t,
+					&fakeTokenValidator{err: syscall.ECONNREFUSED},
+					DegradedModeAcceptAccessTokens,
+				)
+
+				areValid, err := tokenValidator.AreTokensValid(context.Background(), test.token, ids)
+				if !slices.Equal(areValid, test.wantValid) || !errors.Is(err, syscall.ECONNREFUSED) {
+					t.Errorf(
+						"AreTokensValid() = %v, %v, want %v, %v",
+						areValid,
+						err,
+						test.wantValid,
+						syscall.ECONNREFUSED,
+					)
+				}
+				if degraded := errors.Is(err, gojwttokenclaims.ErrDegradedDecision); degraded != test.wantDegraded {
+					t.Errorf("AreTokensValid() degraded = %v, want %v", degraded, test.wantDegraded)
+				}
+			},
+		)
+	}
+}
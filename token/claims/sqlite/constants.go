@@ -6,9 +6,60 @@ const (
 CREATE TABLE IF NOT EXISTS refresh_tokens (id TEXT PRIMARY KEY, expires_at DATETIME NOT NULL);
 `
 
-	// CreateAccessTokensTableQuery is the SQL query to create the access_tokens table
+	// AccessTokensTableColumns are the column definitions of the access_tokens table. Access tokens are deleted
+	// together with their parent refresh token
+	AccessTokensTableColumns = `(
+	id TEXT PRIMARY KEY,
+	parent_refresh_token_id TEXT NOT NULL REFERENCES refresh_tokens (id) ON DELETE CASCADE,
+	expires_at DATETIME NOT NULL
+)`
+
+	// CreateAccessTokensTableQuery is the SQL query to create the access_tokens table. Tables created by previous
+	// versions without the cascading foreign key are migrated on Connect
 	CreateAccessTokensTableQuery = `
-CREATE TABLE IF NOT EXISTS access_tokens (id TEXT PRIMARY KEY, parent_refresh_token_id TEXT, expires_at DATETIME NOT NULL);
+CREATE TABLE IF NOT EXISTS access_tokens ` + AccessTokensTableColumns + `;
+`
+
+	// CheckAccessTokensForeignKeyQuery is the SQL query to check if the access_tokens table has the cascading foreign
+	// key to the refresh_tokens table
+	CheckAccessTokensForeignKeyQuery = `
+SELECT COUNT(1) FROM pragma_foreign_key_list('access_tokens') WHERE "table" = 'refresh_tokens' AND on_delete = 'CASCADE';
+`
+
+	// CreateMigratedAccessTokensTableQuery is the SQL query to create the table that replaces an access_tokens table
+	// without the cascading foreign key
+	CreateMigratedAccessTokensTableQuery = `
+CREATE TABLE access_tokens_migrated ` + AccessTokensTableColumns + `;
+`
+
+	// CopyAccessTokensToMigratedTableQuery is the SQL query to copy the access tokens to the migrated table. The
+	// access tokens whose parent refresh token is missing are dropped, since they were already revoked
+	CopyAccessTokensToMigratedTableQuery = `
+INSERT INTO access_tokens_migrated (id, parent_refresh_token_id, expires_at)
+SELECT id, parent_refresh_token_id, expires_at FROM access_tokens
+WHERE parent_refresh_token_id IN (SELECT id FROM refresh_tokens);
+`
+
+	// DropAccessTokensTableQuery is the SQL query to drop the access_tokens table, together with its indexes
+	DropAccessTokensTableQuery = `
+DROP TABLE access_tokens;
+`
+
+	// RenameMigratedAccessTokensTableQuery is the SQL query to rename the migrated table to access_tokens
+	RenameMigratedAccessTokensTableQuery = `
+ALTER TABLE access_tokens_migrated RENAME TO access_tokens;
+`
+
+	// CreateAccessTokensParentRefreshTokenIndexQuery is the SQL query to create the index used to find the access
+	// tokens of a refresh token
+	CreateAccessTokensParentRefreshTokenIndexQuery = `
+CREATE INDEX IF NOT EXISTS access_tokens_parent_refresh_token_id_idx ON access_tokens (parent_refresh_token_id);
+`
+
+	// EnableForeignKeysQuery is the SQL query to enable the foreign keys enforcement, which SQLite disables by default
+	// for each connection
+	EnableForeignKeysQuery = `
+PRAGMA foreign_keys = ON;
 `
 )
 
@@ -21,11 +72,16 @@ INSERT OR IGNORE INTO refresh_tokens (id, expires_at) VALUES (?, ?);
 	// DeleteRefreshTokenQuery is the SQL query to delete a refresh token
 	DeleteRefreshTokenQuery = `
 DELETE FROM refresh_tokens WHERE id = ?;
+`
+
+	// DeleteValidRefreshTokenQuery is the SQL query to delete a refresh token only if it has not expired
+	DeleteValidRefreshTokenQuery = `
+DELETE FROM refresh_tokens WHERE id = ? AND expires_at > CAST(strftime('%s', 'now') AS INTEGER);
 `
 
 	// CheckRefreshTokenQuery is the SQL query to check if a refresh token exists
 	CheckRefreshTokenQuery = `
-SELECT COUNT(1) FROM refresh_tokens WHERE id = ? AND expires_at > CURRENT_TIMESTAMP;
+SELECT COUNT(1) FROM refresh_tokens WHERE id = ? AND expires_at > CAST(strftime('%s', 'now') AS INTEGER);
 `
 
 	// InsertAccessTokenQuery is the SQL query to insert a new access token
@@ -41,10 +97,64 @@ DELETE FROM access_tokens WHERE id = ?;
 	// DeleteAccessTokenByRefreshTokenQuery deletes access tokens by refresh token JTI
 	DeleteAccessTokenByRefreshTokenQuery = `
 DELETE FROM access_tokens WHERE parent_refresh_token_id = ?;
+`
+
+	// DeleteValidAccessTokenQuery is the SQL query to delete an access token only if it has not expired
+	DeleteValidAccessTokenQuery = `
+DELETE FROM access_tokens WHERE id = ? AND expires_at > CAST(strftime('%s', 'now') AS INTEGER);
 `
 
 	// CheckAccessTokenQuery is the SQL query to check if an access token exists
 	CheckAccessTokenQuery = `
-SELECT COUNT(1) FROM access_tokens WHERE id = ? AND expires_at > CURRENT_TIMESTAMP;
+SELECT COUNT(1) FROM access_tokens WHERE id = ? AND expires_at > CAST(strftime('%s', 'now') AS INTEGER);
+`
+
+	// GetAccessTokenParentRefreshTokenQuery is the SQL query to get the parent refresh token ID of an access token
+	GetAccessTokenParentRefreshTokenQuery = `
+SELECT parent_refresh_token_id FROM access_tokens WHERE id = ? AND expires_at > CAST(strftime('%s', 'now') AS INTEGER);
 `
 )
+
+const (
+	// MaxRowsPerStatement is the maximum number of rows handled by a single multi-row statement, to stay below the
+	// SQLite bound parameters limit
+	MaxRowsPerStatement = 200
+)
+
+var (
+	// InsertRefreshTokensQueryPrefix is the prefix of the SQL query to insert multiple refresh tokens, followed by the
+	// values placeholders
+	InsertRefreshTokensQueryPrefix = `INSERT OR IGNORE INTO refresh_tokens (id, expires_at) VALUES `
+
+	// InsertAccessTokensQueryPrefix is the prefix of the SQL query to insert multiple access tokens, followed by the
+	// values placeholders
+	InsertAccessTokensQueryPrefix = `INSERT OR IGNORE INTO access_tokens (id, parent_refresh_token_id, expires_at) VALUES `
+
+	// DeleteRefreshTokensQueryPrefix is the prefix of the SQL query to delete multiple refresh tokens, followed by the
+	// IN placeholders
+	DeleteRefreshTokensQueryPrefix = `DELETE FROM refresh_tokens WHERE id IN `
+
+	// DeleteAccessTokensQueryPrefix is the prefix of the SQL query to delete multiple access tokens, followed by the
+	// IN placeholders
+	DeleteAccessTokensQueryPrefix = `DELETE FROM access_tokens WHERE id IN `
+
+	// DeleteAccessTokensByRefreshTokensQueryPrefix is the prefix of the SQL query to delete the access tokens of
+	// multiple refresh tokens, followed by the IN placeholders
+	DeleteAccessTokensByRefreshTokensQueryPrefix = `DELETE FROM access_tokens WHERE parent_refresh_token_id IN `
+
+	// CheckRefreshTokensQueryPrefix is the prefix of the SQL query to get which of multiple refresh tokens exist,
+	// followed by the IN placeholders
+	CheckRefreshTokensQueryPrefix = `SELECT id FROM refresh_tokens WHERE expires_at > CAST(strftime('%s', 'now') AS INTEGER) AND id IN `
+
+	// CheckAccessTokensQueryPrefix is the prefix of the SQL query to get which of multiple access tokens exist,
+	// followed by the IN placeholders
+	CheckAccessTokensQueryPrefix = `SELECT id FROM access_tokens WHERE expires_at > CAST(strftime('%s', 'now') AS INTEGER) AND id IN `
+
+	// GetRefreshTokensExpiresAtQueryPrefix is the prefix of the SQL query to get the expiration time of multiple
+	// refresh tokens, followed by the IN placeholders
+	GetRefreshTokensExpiresAtQueryPrefix = `SELECT id, expires_at FROM refresh_tokens WHERE id IN `
+
+	// GetAccessTokensByRefreshTokensQueryPrefix is the prefix of the SQL query to get the access tokens of multiple
+	// refresh tokens, followed by the IN placeholders
+	GetAccessTokensByRefreshTokensQueryPrefix = `SELECT id FROM access_tokens WHERE parent_refresh_token_id IN `
+)
@@ -0,0 +1,138 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	gojwttokensync "github.com/ralvarezdev/go-jwt/tokensync"
+)
+
+// addToOutbox adds a tokens message describing the changes made by a transaction to the outbox, within the same
+// transaction, so the message is only stored if the changes are committed
+//
+// Parameters:
+//
+//   - ctx: the context for the query
+//   - tx: the transaction
+//   - msg: the tokens message
+//
+// Returns:
+//
+//   - error: an error if the message could not be added
+func (t *TokenValidator) addToOutbox(
+	ctx context.Context,
+	tx *sql.Tx,
+	msg *gojwttokensync.TokensMessage,
+) error {
+	// Check if the outbox is nil or the message is empty
+	if t.outbox == nil || (len(msg.IssuedTokenPairs) == 0 &&
+		len(msg.RevokedRefreshTokensID) == 0 &&
+		len(msg.RevokedAccessTokensID) == 0) {
+		return nil
+	}
+
+	// Wrap the message in its envelope
+	envelope, err := gojwttokensync.NewTokensMessageEnvelope(msg, t.producer)
+	if err != nil {
+		return err
+	}
+
+	_, err = t.outbox.AddWithTx(ctx, tx, envelope)
+	return err
+}
+
+// getRefreshTokensExpiresAt gets the expiration time of the given refresh tokens
+//
+// Parameters:
+//
+//   - ctx: the context for the query
+//   - tx: the transaction
+//   - ids: the refresh token JTIs
+//
+// Returns:
+//
+//   - map[string]time.Time: the expiration time of the found refresh tokens by their JTI
+//   - error: an error if the query could not be performed
+func getRefreshTokensExpiresAt(
+	ctx context.Context,
+	tx *sql.Tx,
+	ids []string,
+) (map[string]time.Time, error) {
+	expiresAt := make(map[string]time.Time, len(ids))
+	for _, chunk := range Chunks(ids) {
+		params := make([]any, len(chunk))
+		for i, id := range chunk {
+			params[i] = id
+		}
+		if err := func() error {
+			rows, err := tx.QueryContext(
+				ctx,
+				GetRefreshTokensExpiresAtQueryPrefix+InPlaceholders(len(chunk)),
+				params...,
+			)
+			if err != nil {
+				return err
+			}
+			defer func() {
+				_ = rows.Close()
+			}()
+
+			for rows.Next() {
+				var (
+					id                string
+					expiresAtUnixTime int64
+				)
+				if err = rows.Scan(&id, &expiresAtUnixTime); err != nil {
+					return err
+				}
+				expiresAt[id] = time.Unix(expiresAtUnixTime, 0)
+			}
+			return rows.Err()
+		}(); err != nil {
+			return nil, err
+		}
+	}
+	return expiresAt, nil
+}
+
+// getAccessTokensByRefreshTokens gets the access tokens of the given refresh tokens
+//
+// Parameters:
+//
+//   - ctx: the context for the query
+//   - tx: the transaction
+//   - ids: the refresh token JTIs
+//
+// Returns:
+//
+//   - []string: the access token JTIs
+//   - error: an error if the query could not be performed
+func getAccessTokensByRefreshTokens(
+	ctx context.Context,
+	tx *sql.Tx,
+	ids []string,
+) ([]string, error) {
+	existing := make(map[string]struct{})
+	for _, chunk := range Chunks(ids) {
+		params := make([]any, len(chunk))
+		for i, id := range chunk {
+			params[i] = id
+		}
+		if err := scanExistingIDs(
+			ctx,
+			tx,
+			GetAccessTokensByRefreshTokensQueryPrefix+InPlaceholders(len(chunk)),
+			params,
+			existing,
+		); err != nil {
+			return nil, err
+		}
+	}
+
+	accessTokensID := make([]string, 0, len(existing))
+	for id := range existing {
+		accessTokensID = append(accessTokensID, id)
+	}
+	return accessTokensID, nil
+}
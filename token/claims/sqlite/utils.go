@@ -0,0 +1,54 @@
+package sqlite
+
+import (
+	"strings"
+)
+
+// ValuesPlaceholders returns the placeholders for a multi-row VALUES clause
+//
+// Parameters:
+//
+//   - rows: The number of rows
+//   - columns: The number of columns of each row
+//
+// Returns:
+//
+//   - string: The placeholders, such as "(?, ?), (?, ?)"
+func ValuesPlaceholders(rows, columns int) string {
+	row := "(" + strings.TrimSuffix(strings.Repeat("?, ", columns), ", ") + ")"
+	return strings.TrimSuffix(strings.Repeat(row+", ", rows), ", ")
+}
+
+// InPlaceholders returns the placeholders for an IN clause
+//
+// Parameters:
+//
+//   - n: The number of values
+//
+// Returns:
+//
+//   - string: The placeholders, such as "(?, ?, ?)"
+func InPlaceholders(n int) string {
+	return "(" + strings.TrimSuffix(strings.Repeat("?, ", n), ", ") + ")"
+}
+
+// Chunks splits the given slice into chunks of at most MaxRowsPerStatement elements
+//
+// Parameters:
+//
+//   - values: The values to split
+//
+// Returns:
+//
+//   - [][]T: The chunks
+func Chunks[T any](values []T) [][]T {
+	var chunks [][]T
+	for len(values) > MaxRowsPerStatement {
+		chunks = append(chunks, values[:MaxRowsPerStatement])
+		values = values[MaxRowsPerStatement:]
+	}
+	if len(values) > 0 {
+		chunks = append(chunks, values)
+	}
+	return chunks
+}
@@ -11,13 +11,18 @@ import (
 	godatabasessql "github.com/ralvarezdev/go-databases/sql"
 	gojwttoken "github.com/ralvarezdev/go-jwt/token"
 	gojwttokenclaims "github.com/ralvarezdev/go-jwt/token/claims"
+	gojwttokensync "github.com/ralvarezdev/go-jwt/tokensync"
 )
 
 type (
-	// TokenValidator is the default implementation of the Service interface
+	// TokenValidator is the default implementation of the Service interface. When an outbox is configured, each
+	// change is described by a tokens message added to the outbox in the same transaction, so a change is never
+	// committed without its message
 	TokenValidator struct {
 		godatabasessql.Service
-		logger *slog.Logger
+		outbox   gojwttokensync.TransactionalOutbox
+		producer string
+		logger   *slog.Logger
 	}
 )
 
@@ -53,6 +58,40 @@ func NewTokenValidator(
 	}, nil
 }
 
+// NewTokenValidatorWithOutbox creates a new TokenValidator that adds the tokens messages of its changes to the given
+// outbox, which must be stored in the same database
+//
+// Parameters:
+//
+//   - service: the SQL connection service
+//   - outbox: the transactional outbox
+//   - producer: the name of the issuing service, set in the envelope of the messages (optional)
+//   - logger: the logger (optional, can be nil)
+//
+// Returns:
+//
+//   - *TokenValidator: the TokenValidator instance
+//   - error: an error if the service or the outbox is nil
+func NewTokenValidatorWithOutbox(
+	service godatabasessql.Service,
+	outbox gojwttokensync.TransactionalOutbox,
+	producer string,
+	logger *slog.Logger,
+) (*TokenValidator, error) {
+	// Check if the outbox is nil
+	if outbox == nil {
+		return nil, gojwttokensync.ErrNilOutbox
+	}
+
+	tokenValidator, err := NewTokenValidator(service, logger)
+	if err != nil {
+		return nil, err
+	}
+	tokenValidator.outbox = outbox
+	tokenValidator.producer = producer
+	return tokenValidator, nil
+}
+
 // Connect opens the database connection
 //
 // Parameters:
@@ -80,13 +119,156 @@ func (t *TokenValidator) Connect(ctx context.Context) error {
 	}
 
 	// Ensure the tables exist
-	if _, err = db.ExecContext(ctx, CreateRefreshTokensTableQuery); err != nil {
+	for _, query := range []string{
+		EnableForeignKeysQuery,
+		CreateRefreshTokensTableQuery,
+		CreateAccessTokensTableQuery,
+	} {
+		if _, err = db.ExecContext(ctx, query); err != nil {
+			return err
+		}
+	}
+
+	// Migrate the access_tokens table created by previous versions
+	if err = t.migrateAccessTokensTable(ctx); err != nil {
+		if t.logger != nil {
+			t.logger.Error(
+				"Failed to migrate the access tokens table",
+				slog.String("error", err.Error()),
+			)
+		}
 		return err
 	}
-	if _, err = db.ExecContext(ctx, CreateAccessTokensTableQuery); err != nil {
+
+	// Ensure the index exists, since the migration drops it
+	_, err = db.ExecContext(ctx, CreateAccessTokensParentRefreshTokenIndexQuery)
+	return err
+}
+
+// migrateAccessTokensTable rebuilds the access_tokens table if it was created without the cascading foreign key to
+// the refresh_tokens table, since SQLite cannot add a foreign key to an existing table
+//
+// Parameters:
+//
+//   - ctx: the context
+//
+// Returns:
+//
+//   - error: an error if the table could not be checked or rebuilt, in which case it is left unchanged
+func (t *TokenValidator) migrateAccessTokensTable(ctx context.Context) error {
+	return t.transaction(
+		ctx, func(tx *sql.Tx) error {
+			// Check if the table already has the foreign key
+			var count int
+			if err := tx.QueryRowContext(
+				ctx,
+				CheckAccessTokensForeignKeyQuery,
+			).Scan(&count); err != nil {
+				return err
+			}
+			if count > 0 {
+				return nil
+			}
+
+			if t.logger != nil {
+				t.logger.Info("Migrating the access tokens table to the cascading foreign key")
+			}
+
+			// Rebuild the table
+			for _, query := range []string{
+				CreateMigratedAccessTokensTableQuery,
+				CopyAccessTokensToMigratedTableQuery,
+				DropAccessTokensTableQuery,
+				RenameMigratedAccessTokensTableQuery,
+			} {
+				if _, err := tx.ExecContext(ctx, query); err != nil {
+					return err
+				}
+			}
+			return nil
+		}, nil,
+	)
+}
+
+// transaction runs the given function within a transaction. The transaction runs on a dedicated connection with the
+// foreign keys enforcement enabled, since SQLite disables it by default for each connection of the pool
+//
+// Parameters:
+//
+//   - ctx: the context for the transaction
+//   - fn: the function to run within the transaction
+//   - opts: the transaction options (optional, can be nil)
+//
+// Returns:
+//
+//   - error: an error if the transaction could not be performed, in which case it is rolled back
+func (t *TokenValidator) transaction(
+	ctx context.Context,
+	fn godatabasessql.TransactionFn,
+	opts *sql.TxOptions,
+) error {
+	// Get the database connection
+	db, err := t.DB()
+	if err != nil {
 		return err
 	}
-	return nil
+
+	// Get a dedicated connection
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = conn.Close()
+	}()
+
+	// Enable the foreign keys enforcement, which is a no-op within a transaction
+	if _, err = conn.ExecContext(ctx, EnableForeignKeysQuery); err != nil {
+		return err
+	}
+
+	// Start the transaction
+	tx, err := conn.BeginTx(ctx, opts)
+	if err != nil {
+		return err
+	}
+
+	// Run the function
+	if fnErr := fn(tx); fnErr != nil {
+		if rollbackErr := tx.Rollback(); rollbackErr != nil {
+			return errors.Join(fnErr, rollbackErr)
+		}
+		return fnErr
+	}
+	return tx.Commit()
+}
+
+// exec runs the given query within a transaction, and adds the tokens message describing its change to the outbox
+//
+// Parameters:
+//
+//   - ctx: the context for the query
+//   - msg: the tokens message
+//   - query: the query to run
+//   - params: the query parameters
+//
+// Returns:
+//
+//   - error: an error if the query could not be performed
+func (t *TokenValidator) exec(
+	ctx context.Context,
+	msg *gojwttokensync.TokensMessage,
+	query string,
+	params ...any,
+) error {
+	return t.transaction(
+		ctx, func(tx *sql.Tx) error {
+			if _, err := tx.ExecContext(ctx, query, params...); err != nil {
+				return err
+			}
+			return t.addToOutbox(ctx, tx, msg)
+		}, nil,
+	)
 }
 
 // AddRefreshToken inserts a refresh token JTI into the database
@@ -111,17 +293,28 @@ func (t *TokenValidator) AddRefreshToken(
 	}
 
 	// Insert the refresh token JTI
-	if _, err := t.ExecWithCtx(
+	if err := t.exec(
 		ctx,
-		&InsertRefreshTokenQuery,
+		&gojwttokensync.TokensMessage{
+			IssuedTokenPairs: []gojwttokensync.TokenPair{
+				{
+					RefreshTokenID:        id,
+					RefreshTokenExpiresAt: expiresAt,
+				},
+			},
+		},
+		InsertRefreshTokenQuery,
 		id,
 		expiresAt.Unix(),
-	); err != nil && t.logger != nil {
-		t.logger.Error(
-			"Failed to insert refresh token JTI",
-			slog.String("id", id),
-			slog.String("error", err.Error()),
-		)
+	); err != nil {
+		if t.logger != nil {
+			t.logger.Error(
+				"Failed to insert refresh token JTI",
+				slog.String("id", id),
+				slog.String("error", err.Error()),
+			)
+		}
+		return err
 	}
 	return nil
 }
@@ -148,19 +341,61 @@ func (t *TokenValidator) AddAccessToken(
 		return gojwttokenclaims.ErrNilTokenValidator
 	}
 
-	// Insert the access token JTI
-	if _, err := t.ExecWithCtx(
-		ctx,
-		&InsertAccessTokenQuery,
-		id,
-		parentRefreshTokenID,
-		expiresAt.Unix(),
-	); err != nil && t.logger != nil {
-		t.logger.Error(
-			"Failed to insert access token JTI",
-			slog.String("id", id),
-			slog.String("error", err.Error()),
-		)
+	// Insert the access token JTI, which fails if the parent refresh token does not exist
+	if err := t.transaction(
+		ctx, func(tx *sql.Tx) error {
+			if _, err := tx.ExecContext(
+				ctx,
+				InsertAccessTokenQuery,
+				id,
+				parentRefreshTokenID,
+				expiresAt.Unix(),
+			); err != nil {
+				return err
+			}
+
+			// Check if the outbox is nil
+			if t.outbox == nil {
+				return nil
+			}
+
+			// Get the expiration time of the parent refresh token, which outlives the access token
+			parentExpiresAt, err := getRefreshTokensExpiresAt(
+				ctx,
+				tx,
+				[]string{parentRefreshTokenID},
+			)
+			if err != nil {
+				return err
+			}
+			refreshTokenExpiresAt, ok := parentExpiresAt[parentRefreshTokenID]
+			if !ok {
+				refreshTokenExpiresAt = expiresAt
+			}
+			return t.addToOutbox(
+				ctx,
+				tx,
+				&gojwttokensync.TokensMessage{
+					IssuedTokenPairs: []gojwttokensync.TokenPair{
+						{
+							RefreshTokenID:        parentRefreshTokenID,
+							RefreshTokenExpiresAt: refreshTokenExpiresAt,
+							AccessTokenID:         id,
+							AccessTokenExpiresAt:  expiresAt,
+						},
+					},
+				},
+			)
+		}, nil,
+	); err != nil {
+		if t.logger != nil {
+			t.logger.Error(
+				"Failed to insert access token JTI",
+				slog.String("id", id),
+				slog.String("error", err.Error()),
+			)
+		}
+		return err
 	}
 	return nil
 }
@@ -182,16 +417,46 @@ func (t *TokenValidator) RevokeAccessTokenByRefreshToken(ctx context.Context, id
 	}
 
 	// Revoke the access tokens associated with the refresh token JTI
-	if _, err := t.ExecWithCtx(
-		ctx,
-		&DeleteAccessTokenByRefreshTokenQuery,
-		id,
-	); err != nil && t.logger != nil {
-		t.logger.Error(
-			"Failed to revoke access tokens by refresh token JTI",
-			slog.String("id", id),
-			slog.String("error", err.Error()),
-		)
+	if err := t.transaction(
+		ctx, func(tx *sql.Tx) error {
+			// Get the access token JTIs to revoke, which are sent in the tokens message
+			var accessTokensID []string
+			if t.outbox != nil {
+				var err error
+				accessTokensID, err = getAccessTokensByRefreshTokens(
+					ctx,
+					tx,
+					[]string{id},
+				)
+				if err != nil {
+					return err
+				}
+			}
+
+			if _, err := tx.ExecContext(
+				ctx,
+				DeleteAccessTokenByRefreshTokenQuery,
+				id,
+			); err != nil {
+				return err
+			}
+			return t.addToOutbox(
+				ctx,
+				tx,
+				&gojwttokensync.TokensMessage{
+					RevokedAccessTokensID: accessTokensID,
+				},
+			)
+		}, nil,
+	); err != nil {
+		if t.logger != nil {
+			t.logger.Error(
+				"Failed to revoke access tokens by refresh token JTI",
+				slog.String("id", id),
+				slog.String("error", err.Error()),
+			)
+		}
+		return err
 	}
 	return nil
 }
@@ -212,21 +477,43 @@ func (t *TokenValidator) RevokeRefreshToken(ctx context.Context, id string) erro
 		return gojwttokenclaims.ErrNilTokenValidator
 	}
 
-	// Revoke the refresh token JTI
-	if _, err := t.ExecWithCtx(
-		ctx,
-		&DeleteRefreshTokenQuery,
-		id,
-	); err != nil && t.logger != nil {
-		t.logger.Error(
-			"Failed to revoke refresh token JTI",
-			slog.String("id", id),
-			slog.String("error", err.Error()),
-		)
+	// Revoke the refresh token JTI and its associated access tokens in the same transaction. The access tokens are
+	// deleted explicitly, so the revocation does not depend on the foreign keys enforcement
+	if err := t.transaction(
+		ctx, func(tx *sql.Tx) error {
+			if _, err := tx.ExecContext(
+				ctx,
+				DeleteAccessTokenByRefreshTokenQuery,
+				id,
+			); err != nil {
+				return err
+			}
+			if _, err := tx.ExecContext(
+				ctx,
+				DeleteRefreshTokenQuery,
+				id,
+			); err != nil {
+				return err
+			}
+			return t.addToOutbox(
+				ctx,
+				tx,
+				&gojwttokensync.TokensMessage{
+					RevokedRefreshTokensID: []string{id},
+				},
+			)
+		}, nil,
+	); err != nil {
+		if t.logger != nil {
+			t.logger.Error(
+				"Failed to revoke refresh token JTI",
+				slog.String("id", id),
+				slog.String("error", err.Error()),
+			)
+		}
+		return err
 	}
-
-	// Revoke associated access tokens first
-	return t.RevokeAccessTokenByRefreshToken(ctx, id)
+	return nil
 }
 
 // RevokeAccessToken revokes an access token JTI from the database
@@ -246,16 +533,20 @@ func (t *TokenValidator) RevokeAccessToken(ctx context.Context, id string) error
 	}
 
 	// Revoke the access token JTI
-	if _, err := t.ExecWithCtx(
+	if err := t.exec(
 		ctx,
-		&DeleteAccessTokenQuery,
+		&gojwttokensync.TokensMessage{RevokedAccessTokensID: []string{id}},
+		DeleteAccessTokenQuery,
 		id,
-	); err != nil && t.logger != nil {
-		t.logger.Error(
-			"Failed to revoke access token JTI",
-			slog.String("id", id),
-			slog.String("error", err.Error()),
-		)
+	); err != nil {
+		if t.logger != nil {
+			t.logger.Error(
+				"Failed to revoke access token JTI",
+				slog.String("id", id),
+				slog.String("error", err.Error()),
+			)
+		}
+		return err
 	}
 	return nil
 }
@@ -293,8 +584,93 @@ func (t *TokenValidator) RevokeToken(
 				slog.String("token", token.String()),
 			)
 		}
-		return nil
+		return gojwttoken.ErrUnexpectedTokenType
+	}
+}
+
+// RevokeTokenIfValid revokes a token JTI from the database only if it exists and has not expired. The token is deleted
+// with a single conditional statement, so only one of the concurrent callers revokes it
+//
+// Parameters:
+//
+//   - ctx: the context for the query
+//   - token: the token type (access or refresh)
+//   - id: the token JTI to revoke
+//
+// Returns:
+//
+//   - bool: true if the token was valid and is now revoked, false otherwise
+//   - error: an error if the revocation could not be performed
+func (t *TokenValidator) RevokeTokenIfValid(
+	ctx context.Context,
+	token gojwttoken.Token,
+	id string,
+) (bool, error) {
+	if t == nil {
+		return false, gojwttokenclaims.ErrNilTokenValidator
+	}
+
+	// Determine the query based on the token type
+	var query string
+	switch token {
+	case gojwttoken.AccessToken:
+		query = DeleteValidAccessTokenQuery
+	case gojwttoken.RefreshToken:
+		query = DeleteValidRefreshTokenQuery
+	default:
+		return false, gojwttoken.ErrUnexpectedTokenType
 	}
+
+	var revoked bool
+	if err := t.transaction(
+		ctx, func(tx *sql.Tx) error {
+			// Delete the token only if it is still valid
+			result, err := tx.ExecContext(ctx, query, id)
+			if err != nil {
+				return err
+			}
+			rowsAffected, err := result.RowsAffected()
+			if err != nil || rowsAffected == 0 {
+				return err
+			}
+			revoked = true
+
+			// Revoke the access tokens of a refresh token explicitly, so the revocation does not depend on the foreign
+			// keys enforcement
+			if token == gojwttoken.AccessToken {
+				return t.addToOutbox(
+					ctx,
+					tx,
+					&gojwttokensync.TokensMessage{RevokedAccessTokensID: []string{id}},
+				)
+			}
+			if _, err = tx.ExecContext(
+				ctx,
+				DeleteAccessTokenByRefreshTokenQuery,
+				id,
+			); err != nil {
+				return err
+			}
+			return t.addToOutbox(
+				ctx,
+				tx,
+				&gojwttokensync.TokensMessage{
+					RevokedRefreshTokensID: []string{id},
+				},
+			)
+		}, nil,
+	); err != nil {
+		if t.logger != nil {
+			t.logger.Error(
+				"Failed to revoke token JTI",
+				slog.String("token", token.String()),
+				slog.String("id", id),
+				slog.String("error", err.Error()),
+			)
+		}
+		return false, err
+	}
+	return revoked, nil
 }
 
 // IsRefreshTokenValid checks if the given refresh token JTI exists in the database
@@ -418,3 +794,446 @@ func (t *TokenValidator) IsTokenValid(ctx context.Context, token gojwttoken.Toke
 	}
 	return exists, nil
 }
+
+// LookupToken looks up the token. Revoked tokens are deleted from the database, so a token is only found if it is
+// valid
+//
+// Parameters:
+//
+//   - ctx: the context for the query
+//   - token: the token type
+//   - id: the ID associated with the token
+//
+// Returns:
+//
+//   - bool: true if the token is valid, false otherwise
+//   - bool: true if the token was found, false otherwise
+//   - error: an error if the validation could not be performed
+func (t *TokenValidator) LookupToken(ctx context.Context, token gojwttoken.Token, id string) (
+	bool,
+	bool,
+	error,
+) {
+	// Check if the service is nil
+	if t == nil {
+		return false, false, gojwttokenclaims.ErrNilTokenValidator
+	}
+
+	// Check if the token exists
+	exists, err := t.IsTokenValid(ctx, token, id)
+	if err != nil {
+		return false, false, err
+	}
+	return exists, exists, nil
+}
+
+// LookupParentRefreshToken looks up the parent refresh token ID of an access token
+//
+// Parameters:
+//
+//   - ctx: the context for the query
+//   - id: the access token JTI
+//
+// Returns:
+//
+//   - string: the parent refresh token JTI
+//   - bool: true if the access token exists and has not expired
+//   - error: an error if the query could not be performed
+func (t *TokenValidator) LookupParentRefreshToken(ctx context.Context, id string) (
+	string,
+	bool,
+	error,
+) {
+	// Check if the service is nil
+	if t == nil {
+		return "", false, gojwttokenclaims.ErrNilTokenValidator
+	}
+
+	// Get the parent refresh token JTI
+	query := GetAccessTokenParentRefreshTokenQuery
+	row, err := t.QueryRowWithCtx(ctx, &query, id)
+	if err != nil {
+		return "", false, err
+	}
+	var parentRefreshTokenID string
+	if err = row.Scan(&parentRefreshTokenID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return "", false, nil
+		}
+		return "", false, err
+	}
+	return parentRefreshTokenID, true, nil
+}
+
+// AddRefreshTokens inserts multiple refresh token JTIs into the database in a single transaction using multi-row
+// statements
+//
+// Parameters:
+//
+//   - ctx: the context for the query
+//   - tokens: the refresh tokens to insert
+//
+// Returns:
+//
+//   - error: an error if the insertion could not be performed
+func (t *TokenValidator) AddRefreshTokens(
+	ctx context.Context,
+	tokens []gojwttokenclaims.RefreshTokenRecord,
+) error {
+	// Check if the service is nil
+	if t == nil {
+		return gojwttokenclaims.ErrNilTokenValidator
+	}
+
+	// Check if there are tokens to insert
+	if len(tokens) == 0 {
+		return nil
+	}
+
+	// Insert the refresh token JTIs
+	if err := t.transaction(
+		ctx, func(tx *sql.Tx) error {
+			for _, chunk := range Chunks(tokens) {
+				params := make([]any, 0, len(chunk)*2)
+				for _, token := range chunk {
+					params = append(params, token.ID, token.ExpiresAt.Unix())
+				}
+				if _, err := tx.ExecContext(
+					ctx,
+					InsertRefreshTokensQueryPrefix+ValuesPlaceholders(len(chunk), 2),
+					params...,
+				); err != nil {
+					return err
+				}
+			}
+
+			// Add the tokens message of the issued refresh tokens
+			if t.outbox == nil {
+				return nil
+			}
+			pairs := make([]gojwttokensync.TokenPair, len(tokens))
+			for i, token := range tokens {
+				pairs[i] = gojwttokensync.TokenPair{
+					RefreshTokenID:        token.ID,
+					RefreshTokenExpiresAt: token.ExpiresAt,
+				}
+			}
+			return t.addToOutbox(
+				ctx,
+				tx,
+				&gojwttokensync.TokensMessage{IssuedTokenPairs: pairs},
+			)
+		}, nil,
+	); err != nil {
+		if t.logger != nil {
+			t.logger.Error(
+				"Failed to insert refresh token JTIs",
+				slog.Int("count", len(tokens)),
+				slog.String("error", err.Error()),
+			)
+		}
+		return err
+	}
+	return nil
+}
+
+// AddAccessTokens inserts multiple access token JTIs into the database in a single transaction using multi-row
+// statements
+//
+// Parameters:
+//
+//   - ctx: the context for the query
+//   - tokens: the access tokens to insert
+//
+// Returns:
+//
+//   - error: an error if the insertion could not be performed
+func (t *TokenValidator) AddAccessTokens(
+	ctx context.Context,
+	tokens []gojwttokenclaims.AccessTokenRecord,
+) error {
+	// Check if the service is nil
+	if t == nil {
+		return gojwttokenclaims.ErrNilTokenValidator
+	}
+
+	// Check if there are tokens to insert
+	if len(tokens) == 0 {
+		return nil
+	}
+
+	// Insert the access token JTIs
+	if err := t.transaction(
+		ctx, func(tx *sql.Tx) error {
+			for _, chunk := range Chunks(tokens) {
+				params := make([]any, 0, len(chunk)*3)
+				for _, token := range chunk {
+					params = append(
+						params,
+						token.ID,
+						token.ParentRefreshTokenID,
+						token.ExpiresAt.Unix(),
+					)
+				}
+				if _, err := tx.ExecContext(
+					ctx,
+					InsertAccessTokensQueryPrefix+ValuesPlaceholders(len(chunk), 3),
+					params...,
+				); err != nil {
+					return err
+				}
+			}
+
+			// Add the tokens message of the issued access tokens, paired with their parent refresh tokens
+			if t.outbox == nil {
+				return nil
+			}
+			parentRefreshTokensID := make([]string, len(tokens))
+			for i, token := range tokens {
+				parentRefreshTokensID[i] = token.ParentRefreshTokenID
+			}
+			parentsExpiresAt, err := getRefreshTokensExpiresAt(
+				ctx,
+				tx,
+				parentRefreshTokensID,
+			)
+			if err != nil {
+				return err
+			}
+			pairs := make([]gojwttokensync.TokenPair, len(tokens))
+			for i, token := range tokens {
+				refreshTokenExpiresAt, ok := parentsExpiresAt[token.ParentRefreshTokenID]
+				if !ok {
+					refreshTokenExpiresAt = token.ExpiresAt
+				}
+				pairs[i] = gojwttokensync.TokenPair{
+					RefreshTokenID:        token.ParentRefreshTokenID,
+					RefreshTokenExpiresAt: refreshTokenExpiresAt,
+					AccessTokenID:         token.ID,
+					AccessTokenExpiresAt:  token.ExpiresAt,
+				}
+			}
+			return t.addToOutbox(
+				ctx,
+				tx,
+				&gojwttokensync.TokensMessage{IssuedTokenPairs: pairs},
+			)
+		}, nil,
+	); err != nil {
+		if t.logger != nil {
+			t.logger.Error(
+				"Failed to insert access token JTIs",
+				slog.Int("count", len(tokens)),
+				slog.String("error", err.Error()),
+			)
+		}
+		return err
+	}
+	return nil
+}
+
+// RevokeTokens revokes multiple token JTIs from the database in a single transaction based on the token type. The
+// access tokens associated with revoked refresh tokens are also revoked
+//
+// Parameters:
+//
+//   - ctx: the context for the query
+//   - token: the token type (access or refresh)
+//   - ids: the token JTIs to revoke
+//
+// Returns:
+//
+//   - error: an error if the revocation could not be performed
+func (t *TokenValidator) RevokeTokens(
+	ctx context.Context,
+	token gojwttoken.Token,
+	ids []string,
+) error {
+	// Check if the service is nil
+	if t == nil {
+		return gojwttokenclaims.ErrNilTokenValidator
+	}
+
+	// Determine the queries based on the token type
+	var queryPrefixes []string
+	switch token {
+	case gojwttoken.AccessToken:
+		queryPrefixes = []string{DeleteAccessTokensQueryPrefix}
+	case gojwttoken.RefreshToken:
+		queryPrefixes = []string{
+			DeleteAccessTokensByRefreshTokensQueryPrefix,
+			DeleteRefreshTokensQueryPrefix,
+		}
+	default:
+		if t.logger != nil {
+			t.logger.Error(
+				"Unknown token type",
+				slog.String("token", token.String()),
+			)
+		}
+		return gojwttoken.ErrUnexpectedTokenType
+	}
+
+	// Check if there are tokens to revoke
+	if len(ids) == 0 {
+		return nil
+	}
+
+	// Revoke the token JTIs
+	if err := t.transaction(
+		ctx, func(tx *sql.Tx) error {
+			for _, chunk := range Chunks(ids) {
+				params := make([]any, len(chunk))
+				for i, id := range chunk {
+					params[i] = id
+				}
+				for _, queryPrefix := range queryPrefixes {
+					if _, err := tx.ExecContext(
+						ctx,
+						queryPrefix+InPlaceholders(len(chunk)),
+						params...,
+					); err != nil {
+						return err
+					}
+				}
+			}
+
+			// Add the tokens message of the revoked tokens
+			msg := &gojwttokensync.TokensMessage{}
+			if token == gojwttoken.AccessToken {
+				msg.RevokedAccessTokensID = ids
+			} else {
+				msg.RevokedRefreshTokensID = ids
+			}
+			return t.addToOutbox(ctx, tx, msg)
+		}, nil,
+	); err != nil {
+		if t.logger != nil {
+			t.logger.Error(
+				"Failed to revoke token JTIs",
+				slog.String("token", token.String()),
+				slog.Int("count", len(ids)),
+				slog.String("error", err.Error()),
+			)
+		}
+		return err
+	}
+	return nil
+}
+
+// AreTokensValid checks which of the given token JTIs exist in the database
+//
+// Parameters:
+//
+//   - ctx: the context for the query
+//   - token: the token type
+//   - ids: the token JTIs to validate
+//
+// Returns:
+//
+//   - []bool: whether each token is valid, in the same order as the IDs
+//   - error: an error if the validation could not be performed
+func (t *TokenValidator) AreTokensValid(
+	ctx context.Context,
+	token gojwttoken.Token,
+	ids []string,
+) ([]bool, error) {
+	// Check if the service is nil
+	if t == nil {
+		return nil, gojwttokenclaims.ErrNilTokenValidator
+	}
+
+	// Determine the query based on the token type
+	var queryPrefix string
+	switch token {
+	case gojwttoken.AccessToken:
+		queryPrefix = CheckAccessTokensQueryPrefix
+	case gojwttoken.RefreshToken:
+		queryPrefix = CheckRefreshTokensQueryPrefix
+	default:
+		if t.logger != nil {
+			t.logger.Error(
+				"Unknown token type",
+				slog.String("token", token.String()),
+			)
+		}
+		return make([]bool, len(ids)), nil
+	}
+
+	// Get the existing token JTIs
+	existing := make(map[string]struct{}, len(ids))
+	if err := t.transaction(
+		ctx, func(tx *sql.Tx) error {
+			for _, chunk := range Chunks(ids) {
+				params := make([]any, len(chunk))
+				for i, id := range chunk {
+					params[i] = id
+				}
+				if err := scanExistingIDs(
+					ctx,
+					tx,
+					queryPrefix+InPlaceholders(len(chunk)),
+					params,
+					existing,
+				); err != nil {
+					return err
+				}
+			}
+			return nil
+		}, &sql.TxOptions{ReadOnly: true},
+	); err != nil {
+		if t.logger != nil {
+			t.logger.Error(
+				"Failed to validate token JTIs",
+				slog.String("token", token.String()),
+				slog.Int("count", len(ids)),
+				slog.String("error", err.Error()),
+			)
+		}
+		return nil, err
+	}
+
+	areValid := make([]bool, len(ids))
+	for i, id := range ids {
+		_, areValid[i] = existing[id]
+	}
+	return areValid, nil
+}
+
+// scanExistingIDs runs the given query and adds the returned IDs to the existing set
+//
+// Parameters:
+//
+//   - ctx: the context for the query
+//   - tx: the transaction
+//   - query: the query returning a single ID column
+//   - params: the query parameters
+//   - existing: the set of existing IDs
+//
+// Returns:
+//
+//   - error: an error if the query could not be performed
+func scanExistingIDs(
+	ctx context.Context,
+	tx *sql.Tx,
+	query string,
+	params []any,
+	existing map[string]struct{},
+) error {
+	rows, err := tx.QueryContext(ctx, query, params...)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	for rows.Next() {
+		var id string
+		if err = rows.Scan(&id); err != nil {
+			return err
+		}
+		existing[id] = struct{}{}
+	}
+	return rows.Err()
+}
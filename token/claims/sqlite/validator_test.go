@@ -0,0 +1,310 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	godatabasessql "github.com/ralvarezdev/go-databases/sql"
+	gojwttoken "github.com/ralvarezdev/go-jwt/token"
+	gojwttokenclaims "github.com/ralvarezdev/go-jwt/token/claims"
+	_ "modernc.org/sqlite"
+)
+
+// newTestTokenValidator creates a token validator connected to a new SQLite database file
+//
+// Parameters:
+//
+//   - t: The test
+//
+// Returns:
+//
+//   - *TokenValidator: The token validator
+func newTestTokenValidator(t *testing.T) *TokenValidator {
+	t.Helper()
+
+	service, err := godatabasessql.NewDefaultService(
+		&godatabasessql.Config{
+			DriverName:     "sqlite",
+			DataSourceName: filepath.Join(t.TempDir(), "tokens.db"),
+		},
+	)
+	if err != nil {
+		t.Fatalf("failed to create the service: %v", err)
+	}
+	tokenValidator, err := NewTokenValidator(service, nil)
+	if err != nil {
+		t.Fatalf("failed to create the token validator: %v", err)
+	}
+	if err = tokenValidator.Connect(context.Background()); err != nil {
+		t.Fatalf("failed to connect: %v", err)
+	}
+	t.Cleanup(
+		func() {
+			_ = tokenValidator.Disconnect()
+		},
+	)
+	return tokenValidator
+}
+
+func TestTokenValidator_IsTokenValid(t *testing.T) {
+	ctx := context.Background()
+	tokenValidator := newTestTokenValidator(t)
+
+	now := time.Now()
+	if err := tokenValidator.AddRefreshToken(ctx, "refresh", now.Add(time.Hour)); err != nil {
+		t.Fatalf("failed to add the refresh token: %v", err)
+	}
+	if err := tokenValidator.AddAccessToken(ctx, "access", "refresh", now.Add(time.Minute)); err != nil {
+		t.Fatalf("failed to add the access token: %v", err)
+	}
+	if err := tokenValidator.AddRefreshToken(ctx, "expired-refresh", now.Add(-time.Minute)); err != nil {
+		t.Fatalf("failed to add the expired refresh token: %v", err)
+	}
+	if err := tokenValidator.AddAccessToken(ctx, "expired-access", "refresh", now.Add(-time.Minute)); err != nil {
+		t.Fatalf("failed to add the expired access token: %v", err)
+	}
+
+	tests := []struct {
+		name  string
+		token gojwttoken.Token
+		id    string
+		want  bool
+	}{
+		{name: "fresh refresh token", token: gojwttoken.RefreshToken, id: "refresh", want: true},
+		{name: "fresh access token", token: gojwttoken.AccessToken, id: "access", want: true},
+		{name: "expired refresh token", token: gojwttoken.RefreshToken, id: "expired-refresh"},
+		{name: "expired access token", token: gojwttoken.AccessToken, id: "expired-access"},
+		{name: "missing refresh token", token: gojwttoken.RefreshToken, id: "missing"},
+		{name: "access token checked as refresh token", token: gojwttoken.RefreshToken, id: "access"},
+	}
+	for _, test := range tests {
+		t.Run(
+			test.name, func(t *testing.T) {
+				isValid, err := tokenValidator.IsTokenValid(ctx, test.token, test.id)
+				if err != nil {
+					t.Fatalf("IsTokenValid() error = %v", err)
+				}
+				if isValid != test.want {
+					t.Errorf("IsTokenValid() = %v, want %v", isValid, test.want)
+				}
+
+				isValid, found, err := tokenValidator.LookupToken(ctx, test.token, test.id)
+				if err != nil {
+					t.Fatalf("LookupToken() error = %v", err)
+				}
+				if isValid != test.want || found != test.want {
+					t.Errorf("LookupToken() = %v, %v, want %v, %v", isValid, found, test.want, test.want)
+				}
+
+				areValid, err := tokenValidator.AreTokensValid(ctx, test.token, []string{test.id})
+				if err != nil {
+					t.Fatalf("AreTokensValid() error = %v", err)
+				}
+				if len(areValid) != 1 || areValid[0] != test.want {
+					t.Errorf("AreTokensValid() = %v, want [%v]", areValid, test.want)
+				}
+			},
+		)
+	}
+}
+
+func TestTokenValidator_AreTokensValid(t *testing.T) {
+	ctx := context.Background()
+	tokenValidator := newTestTokenValidator(t)
+
+	// Add more tokens than a single statement can handle
+	expiresAt := time.Now().Add(time.Hour)
+	refreshTokens := make([]gojwttokenclaims.RefreshTokenRecord, MaxRowsPerStatement+1)
+	ids := make([]string, 0, len(refreshTokens)+1)
+	for i := range refreshTokens {
+		refreshTokens[i] = gojwttokenclaims.RefreshTokenRecord{
+			ID:        "refresh-" + strconv.Itoa(i),
+			ExpiresAt: expiresAt,
+		}
+		ids = append(ids, refreshTokens[i].ID)
+	}
+	if err := tokenValidator.AddRefreshTokens(ctx, refreshTokens); err != nil {
+		t.Fatalf("failed to add the refresh tokens: %v", err)
+	}
+	ids = append(ids, "missing")
+
+	areValid, err := tokenValidator.AreTokensValid(ctx, gojwttoken.RefreshToken, ids)
+	if err != nil {
+		t.Fatalf("AreTokensValid() error = %v", err)
+	}
+	for i, isValid := range areValid {
+		if want := i < len(refreshTokens); isValid != want {
+			t.Errorf("AreTokensValid()[%d] = %v, want %v", i, isValid, want)
+		}
+	}
+}
+
+func TestTokenValidator_RevokeTokenIfValid(t *testing.T) {
+	ctx := context.Background()
+	tokenValidator := newTestTokenValidator(t)
+
+	now := time.Now()
+	if err := tokenValidator.AddRefreshToken(ctx, "refresh", now.Add(time.Hour)); err != nil {
+		t.Fatalf("failed to add the refresh token: %v", err)
+	}
+	if err := tokenValidator.AddAccessToken(ctx, "access", "refresh", now.Add(time.Minute)); err != nil {
+		t.Fatalf("failed to add the access token: %v", err)
+	}
+
+	// Only the first revocation of a valid token succeeds
+	for i, want := range []bool{true, false} {
+		revoked, err := tokenValidator.RevokeTokenIfValid(ctx, gojwttoken.RefreshToken, "refresh")
+		if err != nil {
+			t.Fatalf("RevokeTokenIfValid() error = %v", err)
+		}
+		if revoked != want {
+			t.Errorf("RevokeTokenIfValid() call %d = %v, want %v", i, revoked, want)
+		}
+	}
+
+	// The access token is revoked together with its parent refresh token
+	isValid, err := tokenValidator.IsTokenValid(ctx, gojwttoken.AccessToken, "access")
+	if err != nil {
+		t.Fatalf("IsTokenValid() error = %v", err)
+	}
+	if isValid {
+		t.Error("IsTokenValid() = true for the access token of a revoked refresh token, want false")
+	}
+}
+
+func TestTokenValidator_Connect_MigratesAccessTokensTable(t *testing.T) {
+	ctx := context.Background()
+	dataSourceName := filepath.Join(t.TempDir(), "tokens.db")
+
+	// Create the tables with the schema of the previous versions, without the cascading foreign key
+	db, err := sql.Open("sqlite", dataSourceName)
+	if err != nil {
+		t.Fatalf("failed to open the database: %v", err)
+	}
+	expiresAt := time.Now().Add(time.Hour).Unix()
+	for _, query := range []string{
+		CreateRefreshTokensTableQuery,
+		`CREATE TABLE access_tokens (id TEXT PRIMARY KEY, parent_refresh_token_id TEXT, expires_at DATETIME NOT NULL);`,
+	} {
+		if _, err = db.ExecContext(ctx, query); err != nil {
+			t.Fatalf("failed to create the table: %v", err)
+		}
+	}
+	if _, err = db.ExecContext(ctx, InsertRefreshTokenQuery, "refresh", expiresAt); err != nil {
+		t.Fatalf("failed to add the refresh token: %v", err)
+	}
+	for _, id := range []string{"access", "orphan-access"} {
+		parentID := "refresh"
+		if id == "orphan-access" {
+			parentID = "missing"
+		}
+		if _, err = db.ExecContext(ctx, InsertAccessTokenQuery, id, parentID, expiresAt); err != nil {
+			t.Fatalf("failed to add the access token: %v", err)
+		}
+	}
+	if err = db.Close(); err != nil {
+		t.Fatalf("failed to close the database: %v", err)
+	}
+
+	// Connect, which migrates the table
+	service, err := godatabasessql.NewDefaultService(
+		&godatabasessql.Config{
+			DriverName:     "sqlite",
+			DataSourceName: dataSourceName,
+		},
+	)
+	if err != nil {
+		t.Fatalf("failed to create the service: %v", err)
+	}
+	tokenValidator, err := NewTokenValidator(service, nil)
+	if err != nil {
+		t.Fatalf("failed to create the token validator: %v", err)
+	}
+	if err = tokenValidator.Connect(ctx); err != nil {
+		t.Fatalf("Connect() error = %v", err)
+	}
+	t.Cleanup(
+		func() {
+			_ = tokenValidator.Disconnect()
+		},
+	)
+
+	// Connecting again leaves the migrated table unchanged
+	if err = tokenValidator.Connect(ctx); err != nil {
+		t.Fatalf("Connect() again error = %v", err)
+	}
+
+	// The access tokens of existing refresh tokens are kept, the orphaned ones are dropped
+	for id, want := range map[string]bool{"access": true, "orphan-access": false} {
+		isValid, err := tokenValidator.IsTokenValid(ctx, gojwttoken.AccessToken, id)
+		if err != nil {
+			t.Fatalf("IsTokenValid() error = %v", err)
+		}
+		if isValid != want {
+			t.Errorf("IsTokenValid(%q) = %v, want %v", id, isValid, want)
+		}
+	}
+
+	// Deleting the refresh token cascades to its access tokens
+	if err = tokenValidator.transaction(
+		ctx, func(tx *sql.Tx) error {
+			_, err := tx.ExecContext(ctx, DeleteRefreshTokenQuery, "refresh")
+			return err
+		}, nil,
+	); err != nil {
+		t.Fatalf("failed to delete the refresh token: %v", err)
+	}
+	isValid, err := tokenValidator.IsTokenValid(ctx, gojwttoken.AccessToken, "access")
+	if err != nil {
+		t.Fatalf("IsTokenValid() error = %v", err)
+	}
+	if isValid {
+		t.Error("IsTokenValid() = true for the access token of a deleted refresh token, want false")
+	}
+}
+
+func TestTokenValidator_LookupParentRefreshToken(t *testing.T) {
+	ctx := context.Background()
+	tokenValidator := newTestTokenValidator(t)
+
+	now := time.Now()
+	if err := tokenValidator.AddRefreshToken(ctx, "refresh", now.Add(time.Hour)); err != nil {
+		t.Fatalf("AddRefreshToken() error = %v", err)
+	}
+	if err := tokenValidator.AddAccessToken(ctx, "access", "refresh", now.Add(time.Minute)); err != nil {
+		t.Fatalf("AddAccessToken() error = %v", err)
+	}
+
+	tests := []struct {
+		name      string
+		id        string
+		wantID    string
+		wantFound bool
+	}{
+		{name: "access token", id: "access", wantID: "refresh", wantFound: true},
+		{name: "missing access token", id: "missing"},
+	}
+	for _, test := range tests {
+		t.Run(
+			test.name, func(t *testing.T) {
+				parentRefreshTokenID, found, err := tokenValidator.LookupParentRefreshToken(ctx, test.id)
+				if err != nil {
+					t.Fatalf("LookupParentRefreshToken() error = %v", err)
+				}
+				if parentRefreshTokenID != test.wantID || found != test.wantFound {
+					t.Errorf(
+						"LookupParentRefreshToken() = %q, %v, want %q, %v",
+						parentRefreshTokenID,
+						found,
+						test.wantID,
+						test.wantFound,
+					)
+				}
+			},
+		)
+	}
+}
@@ -2,6 +2,8 @@ package claims
 
 import (
 	"context"
+	"errors"
+	"time"
 
 	"github.com/golang-jwt/jwt/v5"
 	gojwt "github.com/ralvarezdev/go-jwt"
@@ -13,6 +15,19 @@ type (
 	DefaultClaimsValidator struct {
 		tokenValidator TokenValidator
 	}
+
+	// RefreshTokenRecord is a refresh token to be added to a token validator
+	RefreshTokenRecord struct {
+		ID        string
+		ExpiresAt time.Time
+	}
+
+	// AccessTokenRecord is an access token to be added to a token validator
+	AccessTokenRecord struct {
+		ID                   string
+		ParentRefreshTokenID string
+		ExpiresAt            time.Time
+	}
 )
 
 // NewDefaultClaimsValidator creates a new default claims validator
@@ -72,9 +87,12 @@ func (d DefaultClaimsValidator) ValidateClaims(
 		return false, ErrInvalidIDClaim
 	}
 
-	// Check if the token is valid
+	// Check if the token is valid. A degraded decision is accepted, since the token validator is the one that opted in
 	isValid, err := d.tokenValidator.IsTokenValid(ctx, token, jtiStr)
 	if err != nil {
+		if isValid && errors.Is(err, ErrDegradedDecision) {
+			return true, nil
+		}
 		return false, err
 	}
 	return isValid, nil
@@ -0,0 +1,25 @@
+package service
+
+import (
+	"time"
+)
+
+const (
+	// DefaultAccessTokenTTL is the default lifetime of the access tokens
+	DefaultAccessTokenTTL = 15 * time.Minute
+
+	// DefaultRefreshTokenTTL is the default lifetime of the refresh tokens
+	DefaultRefreshTokenTTL = 7 * 24 * time.Hour
+
+	// TokenIDLength is the number of random bytes of the token IDs
+	TokenIDLength = 16
+
+	// BearerTokenType is the token type of the issued access tokens
+	BearerTokenType = "Bearer"
+
+	// AccessTokenTypeHint is the RFC 7009 token type hint for access tokens
+	AccessTokenTypeHint = "access_token"
+
+	// RefreshTokenTypeHint is the RFC 7009 token type hint for refresh tokens
+	RefreshTokenTypeHint = "refresh_token"
+)
@@ -0,0 +1,14 @@
+package service
+
+import (
+	"errors"
+)
+
+var (
+	ErrNilService               = errors.New("token service cannot be nil")
+	ErrEmptySubject             = errors.New("subject cannot be empty")
+	ErrInvalidGrant             = errors.New("invalid grant")
+	ErrReservedClaim            = errors.New("reserved claim")
+	ErrUnsupportedTokenTypeHint = errors.New("unsupported token type hint")
+	ErrRefreshTokenAlreadyUsed  = errors.New("refresh token already used")
+)
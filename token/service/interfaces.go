@@ -0,0 +1,13 @@
+package service
+
+import (
+	"context"
+)
+
+type (
+	// SessionLister lists the sessions of a subject. The token validators do not index the tokens by subject, so the
+	// application provides it from its own session store
+	SessionLister interface {
+		ListSessions(ctx context.Context, subject string) ([]Session, error)
+	}
+)
@@ -0,0 +1,652 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	gocache "github.com/ralvarezdev/go-cache"
+	gojwt "github.com/ralvarezdev/go-jwt"
+	gojwttoken "github.com/ralvarezdev/go-jwt/token"
+	gojwttokenclaims "github.com/ralvarezdev/go-jwt/token/claims"
+	gojwtissuer "github.com/ralvarezdev/go-jwt/token/issuer"
+	gojwtvalidator "github.com/ralvarezdev/go-jwt/token/validator"
+)
+
+var (
+	// reservedClaims are the claims set by the token service, which cannot be supplied by the application
+	reservedClaims = map[string]struct{}{
+		gojwt.IDClaim:             {},
+		gojwt.SubjectClaim:        {},
+		gojwt.IsRefreshTokenClaim: {},
+		"exp":                     {},
+		"iat":                     {},
+		"nbf":                     {},
+		"iss":                     {},
+		"aud":                     {},
+	}
+)
+
+type (
+	// Service issues, refreshes, revokes and introspects tokens on top of an issuer, a validator and a token validator,
+	// independently of the transport
+	Service struct {
+		issuer          gojwtissuer.Issuer
+		validator       gojwtvalidator.Validator
+		tokenValidator  gojwttokenclaims.TokenValidator
+		accessTokenTTL  time.Duration
+		refreshTokenTTL time.Duration
+		issuerClaim     string
+		audience        []string
+		now             func() time.Time
+		refreshMutex    sync.Mutex
+		logger          *slog.Logger
+	}
+)
+
+// NewService creates a new token service
+//
+// Parameters:
+//
+//   - issuer: The token issuer
+//   - validator: The token validator used to parse and validate the tokens
+//   - tokenValidator: The token validator used to store and revoke the tokens
+//   - options: The options (optional, can be nil)
+//   - logger: The logger (optional, can be nil)
+//
+// Returns:
+//
+//   - *Service: The token service
+//   - error: An error if the issuer, the validator or the token validator is nil
+func NewService(
+	issuer gojwtissuer.Issuer,
+	validator gojwtvalidator.Validator,
+	tokenValidator gojwttokenclaims.TokenValidator,
+	options *Options,
+	logger *slog.Logger,
+) (*Service, error) {
+	// Check if the issuer, the validator or the token validator is nil
+	if issuer == nil {
+		return nil, gojwtissuer.ErrNilIssuer
+	}
+	if validator == nil {
+		return nil, gojwtvalidator.ErrNilValidator
+	}
+	if tokenValidator == nil {
+		return nil, gojwttokenclaims.ErrNilTokenValidator
+	}
+
+	// Set the options
+	if options == nil {
+		options = &Options{}
+	}
+	accessTokenTTL := options.AccessTokenTTL
+	if accessTokenTTL <= 0 {
+		accessTokenTTL = DefaultAccessTokenTTL
+	}
+	refreshTokenTTL := options.RefreshTokenTTL
+	if refreshTokenTTL <= 0 {
+		refreshTokenTTL = DefaultRefreshTokenTTL
+	}
+
+	if logger != nil {
+		logger = logger.With(slog.String("component", "token_service"))
+	}
+
+	return &Service{
+		issuer:          issuer,
+		validator:       validator,
+		tokenValidator:  tokenValidator,
+		accessTokenTTL:  accessTokenTTL,
+		refreshTokenTTL: refreshTokenTTL,
+		issuerClaim:     options.Issuer,
+		audience:        append([]string(nil), options.Audience...),
+		now:             time.Now,
+		logger:          logger,
+	}, nil
+}
+
+// Validator returns the validator used to parse and validate the tokens
+//
+// Returns:
+//
+//   - gojwtvalidator.Validator: The validator
+func (s *Service) Validator() gojwtvalidator.Validator {
+	if s == nil {
+		return nil
+	}
+	return s.validator
+}
+
+// TokenValidator returns the token validator used to store and revoke the tokens
+//
+// Returns:
+//
+//   - gojwttokenclaims.TokenValidator: The token validator
+func (s *Service) TokenValidator() gojwttokenclaims.TokenValidator {
+	if s == nil {
+		return nil
+	}
+	return s.tokenValidator
+}
+
+// newClaims creates the claims of a new token
+//
+// Parameters:
+//
+//   - id: The token ID
+//   - subject: The subject
+//   - isRefreshToken: Whether the token is a refresh token
+//   - issuedAt: The issue time
+//   - expiresAt: The expiration time
+//   - extraClaims: The application claims
+//
+// Returns:
+//
+//   - jwt.MapClaims: The token claims
+func (s *Service) newClaims(
+	id string,
+	subject string,
+	isRefreshToken bool,
+	issuedAt time.Time,
+	expiresAt time.Time,
+	extraClaims map[string]any,
+) jwt.MapClaims {
+	claims := make(jwt.MapClaims, len(extraClaims)+len(reservedClaims))
+	for key, value := range extraClaims {
+		claims[key] = value
+	}
+	claims[gojwt.IDClaim] = id
+	claims[gojwt.SubjectClaim] = subject
+	claims[gojwt.IsRefreshTokenClaim] = isRefreshToken
+	claims["iat"] = issuedAt.Unix()
+	claims["exp"] = expiresAt.Unix()
+	if s.issuerClaim != "" {
+		claims["iss"] = s.issuerClaim
+	}
+	if len(s.audience) > 0 {
+		claims["aud"] = s.audience
+	}
+	return claims
+}
+
+// IssueTokens issues a new pair of access and refresh tokens for the given subject, which must already be
+// authenticated by the application
+//
+// Parameters:
+//
+//   - ctx: The context
+//   - subject: The authenticated subject
+//   - extraClaims: The application claims added to both tokens, such as the scopes (optional, can be nil)
+//
+// Returns:
+//
+//   - *TokenPair: The issued tokens
+//   - error: An error if the subject is empty, if an application claim is reserved, or if the tokens could not be
+//     issued or stored
+func (s *Service) IssueTokens(
+	ctx context.Context,
+	subject string,
+	extraClaims map[string]any,
+) (*TokenPair, error) {
+	if s == nil {
+		return nil, ErrNilService
+	}
+
+	// Check if the subject is empty
+	if subject == "" {
+		return nil, ErrEmptySubject
+	}
+
+	// Check if any application claim is reserved
+	for key := range extraClaims {
+		if _, ok := reservedClaims[key]; ok {
+			return nil, fmt.Errorf("%w: %s", ErrReservedClaim, key)
+		}
+	}
+
+	// Generate the token IDs
+	refreshTokenID, err := GenerateTokenID()
+	if err != nil {
+		return nil, err
+	}
+	accessTokenID, err := GenerateTokenID()
+	if err != nil {
+		return nil, err
+	}
+
+	// Issue the tokens
+	issuedAt := s.now()
+	refreshTokenExpiresAt := issuedAt.Add(s.refreshTokenTTL)
+	accessTokenExpiresAt := issuedAt.Add(s.accessTokenTTL)
+	refreshToken, err := s.issuer.IssueToken(
+		s.newClaims(
+			refreshTokenID,
+			subject,
+			true,
+			issuedAt,
+			refreshTokenExpiresAt,
+			extraClaims,
+		),
+	)
+	if err != nil {
+		return nil, err
+	}
+	accessToken, err := s.issuer.IssueToken(
+		s.newClaims(
+			accessTokenID,
+			subject,
+			false,
+			issuedAt,
+			accessTokenExpiresAt,
+			extraClaims,
+		),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	// Store the tokens, the refresh token first since the access token references it
+	if err = s.tokenValidator.AddRefreshToken(
+		ctx,
+		refreshTokenID,
+		refreshTokenExpiresAt,
+	); err != nil {
+		return nil, err
+	}
+	if err = s.tokenValidator.AddAccessToken(
+		ctx,
+		accessTokenID,
+		refreshTokenID,
+		accessTokenExpiresAt,
+	); err != nil {
+		return nil, err
+	}
+
+	if s.logger != nil {
+		s.logger.Debug(
+			"Tokens issued",
+			slog.String("subject", subject),
+			slog.String("refresh_token_id", refreshTokenID),
+			slog.String("access_token_id", accessTokenID),
+		)
+	}
+	return &TokenPair{
+		AccessToken:           accessToken,
+		AccessTokenExpiresAt:  accessTokenExpiresAt,
+		RefreshToken:          refreshToken,
+		RefreshTokenExpiresAt: refreshTokenExpiresAt,
+		TokenType:             BearerTokenType,
+		ExpiresIn:             int64(s.accessTokenTTL / time.Second),
+	}, nil
+}
+
+// Refresh exchanges a refresh token for a new pair of tokens. The refresh token is rotated, so it is revoked together
+// with its access tokens, and the application claims are carried over to the new tokens. A refresh token can only be
+// exchanged once, even by concurrent requests
+//
+// Parameters:
+//
+//   - ctx: The context
+//   - rawRefreshToken: The raw refresh token
+//
+// Returns:
+//
+//   - *TokenPair: The issued tokens
+//   - error: ErrInvalidGrant if the refresh token is not valid, or an error if the tokens could not be issued or
+//     stored
+func (s *Service) Refresh(
+	ctx context.Context,
+	rawRefreshToken string,
+) (*TokenPair, error) {
+	if s == nil {
+		return nil, ErrNilService
+	}
+
+	// Validate the refresh token
+	claims, err := s.validator.ValidateClaims(
+		ctx,
+		rawRefreshToken,
+		gojwttoken.RefreshToken,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidGrant, err)
+	}
+	if err = gojwt.CheckClaimsTokenType(
+		claims,
+		gojwttoken.RefreshToken,
+	); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidGrant, err)
+	}
+
+	// Get the refresh token ID and subject
+	id, ok := claims[gojwt.IDClaim].(string)
+	if !ok || id == "" {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidGrant, gojwt.ErrMissingTokenClaimsID)
+	}
+	subject, err := claims.GetSubject()
+	if err != nil || subject == "" {
+		return nil, fmt.Errorf(
+			"%w: %w",
+			ErrInvalidGrant,
+			gojwt.ErrMissingTokenClaimsSubject,
+		)
+	}
+
+	// Revoke the refresh token and its access tokens, only one of the concurrent requests can consume it
+	revoked, err := s.revokeRefreshTokenIfValid(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !revoked {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidGrant, ErrRefreshTokenAlreadyUsed)
+	}
+
+	// Carry over the application claims
+	extraClaims := make(map[string]any, len(claims))
+	for key, value := range claims {
+		if _, reserved := reservedClaims[key]; !reserved {
+			extraClaims[key] = value
+		}
+	}
+	return s.IssueTokens(ctx, subject, extraClaims)
+}
+
+// revokeRefreshTokenIfValid revokes a refresh token only if it is still valid. Token validators that cannot revoke
+// conditionally are checked and revoked while holding a lock, which only guards against the concurrent requests of
+// this process
+//
+// Parameters:
+//
+//   - ctx: The context
+//   - id: The refresh token ID
+//
+// Returns:
+//
+//   - bool: Whether the refresh token was valid and is now revoked
+//   - error: An error if the refresh token could not be checked or revoked
+func (s *Service) revokeRefreshTokenIfValid(ctx context.Context, id string) (bool, error) {
+	// Check if the token validator can revoke conditionally
+	if revoker, ok := s.tokenValidator.(gojwttokenclaims.ConditionalTokenRevoker); ok {
+		revoked, err := revoker.RevokeTokenIfValid(ctx, gojwttoken.RefreshToken, id)
+		if !errors.Is(err, gojwttokenclaims.ErrConditionalRevokeNotSupported) {
+			return revoked, err
+		}
+	}
+
+	s.refreshMutex.Lock()
+	defer s.refreshMutex.Unlock()
+
+	// Check if the refresh token is still valid
+	isValid, err := s.tokenValidator.IsTokenValid(ctx, gojwttoken.RefreshToken, id)
+	if err != nil || !isValid {
+		if errors.Is(err, gocache.ErrItemNotFound) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, s.tokenValidator.RevokeToken(ctx, gojwttoken.RefreshToken, id)
+}
+
+// Revoke revokes a token as described in RFC 7009, without checking who owns it. Tokens that cannot be parsed, have
+// expired or are unknown are ignored, since there is nothing left to revoke. Endpoints exposed to the token owners
+// must use RevokeOwned instead
+//
+// Parameters:
+//
+//   - ctx: The context
+//   - rawToken: The raw token
+//   - tokenTypeHint: The RFC 7009 token type hint (optional, can be empty)
+//
+// Returns:
+//
+//   - error: An error if the token type hint is not supported or if the token could not be revoked
+func (s *Service) Revoke(
+	ctx context.Context,
+	rawToken string,
+	tokenTypeHint string,
+) error {
+	if s == nil {
+		return ErrNilService
+	}
+	return s.revoke(ctx, "", rawToken, tokenTypeHint)
+}
+
+// RevokeOwned revokes a token as described in RFC 7009 on behalf of the authenticated subject. Tokens that belong to
+// another subject are ignored as if they were invalid, so the caller cannot revoke them nor learn whether they exist
+//
+// Parameters:
+//
+//   - ctx: The context
+//   - subject: The authenticated subject
+//   - rawToken: The raw token
+//   - tokenTypeHint: The RFC 7009 token type hint (optional, can be empty)
+//
+// Returns:
+//
+//   - error: ErrEmptySubject if the subject is empty, or an error if the token type hint is not supported or if the
+//     token could not be revoked
+func (s *Service) RevokeOwned(
+	ctx context.Context,
+	subject string,
+	rawToken string,
+	tokenTypeHint string,
+) error {
+	if s == nil {
+		return ErrNilService
+	}
+
+	// Check if the subject is empty
+	if subject == "" {
+		return ErrEmptySubject
+	}
+	return s.revoke(ctx, subject, rawToken, tokenTypeHint)
+}
+
+// revoke revokes a token, ignoring the tokens that cannot be parsed or that belong to another subject
+//
+// Parameters:
+//
+//   - ctx: The context
+//   - subject: The subject that must own the token (optional, the owner is not checked if empty)
+//   - rawToken: The raw token
+//   - tokenTypeHint: The RFC 7009 token type hint (optional, can be empty)
+//
+// Returns:
+//
+//   - error: An error if the token type hint is not supported or if the token could not be revoked
+func (s *Service) revoke(
+	ctx context.Context,
+	subject string,
+	rawToken string,
+	tokenTypeHint string,
+) error {
+	// Check the token type hint
+	tokens, err := candidateTokens(tokenTypeHint)
+	if err != nil {
+		return err
+	}
+
+	// Parse the token, its signature is still verified
+	claims, err := s.validator.GetClaims(rawToken)
+	if err != nil {
+		if s.logger != nil {
+			s.logger.Debug(
+				"Ignoring revocation of an invalid token",
+				slog.String("error", err.Error()),
+			)
+		}
+		return nil
+	}
+	id, ok := claims[gojwt.IDClaim].(string)
+	if !ok || id == "" {
+		return nil
+	}
+
+	// Check if the token belongs to the subject
+	if subject != "" {
+		if owner, subjectErr := claims.GetSubject(); subjectErr != nil || owner != subject {
+			if s.logger != nil {
+				s.logger.Warn(
+					"Ignoring revocation of a token owned by another subject",
+					slog.String("subject", subject),
+					slog.String("id", id),
+				)
+			}
+			return nil
+		}
+	}
+
+	// Revoke the token, whose type is known from its claims if present
+	token := tokenFromClaims(claims, tokens[0])
+	if err = s.tokenValidator.RevokeToken(ctx, token, id); err != nil && !errors.Is(
+		err,
+		gocache.ErrItemNotFound,
+	) {
+		return err
+	}
+	return nil
+}
+
+// Introspect introspects a token as described in RFC 7662. Tokens that are not valid are reported as inactive
+//
+// Parameters:
+//
+//   - ctx: The context
+//   - rawToken: The raw token
+//   - tokenTypeHint: The RFC 7662 token type hint (optional, can be empty)
+//
+// Returns:
+//
+//   - *Introspection: The introspection response
+//   - error: An error if the token type hint is not supported
+func (s *Service) Introspect(
+	ctx context.Context,
+	rawToken string,
+	tokenTypeHint string,
+) (*Introspection, error) {
+	if s == nil {
+		return nil, ErrNilService
+	}
+
+	// Check the token type hint
+	tokens, err := candidateTokens(tokenTypeHint)
+	if err != nil {
+		return nil, err
+	}
+
+	// Check the token type from its claims if present, otherwise try the hinted type first
+	if claims, parseErr := s.validator.GetClaims(rawToken); parseErr == nil {
+		if _, ok := claims[gojwt.IsRefreshTokenClaim].(bool); ok {
+			tokens = []gojwttoken.Token{tokenFromClaims(claims, tokens[0])}
+		}
+	}
+
+	for _, token := range tokens {
+		claims, validateErr := s.validator.ValidateClaims(ctx, rawToken, token)
+		if validateErr != nil {
+			continue
+		}
+		return newIntrospection(claims, token), nil
+	}
+	return &Introspection{Active: false}, nil
+}
+
+// candidateTokens returns the token types to try for the given token type hint, the hinted type first
+//
+// Parameters:
+//
+//   - tokenTypeHint: The token type hint (optional, can be empty)
+//
+// Returns:
+//
+//   - []gojwttoken.Token: The token types
+//   - error: ErrUnsupportedTokenTypeHint if the token type hint is not supported
+func candidateTokens(tokenTypeHint string) ([]gojwttoken.Token, error) {
+	switch tokenTypeHint {
+	case "", AccessTokenTypeHint:
+		return []gojwttoken.Token{
+			gojwttoken.AccessToken,
+			gojwttoken.RefreshToken,
+		}, nil
+	case RefreshTokenTypeHint:
+		return []gojwttoken.Token{
+			gojwttoken.RefreshToken,
+			gojwttoken.AccessToken,
+		}, nil
+	default:
+		return nil, fmt.Errorf(
+			"%w: %s",
+			ErrUnsupportedTokenTypeHint,
+			tokenTypeHint,
+		)
+	}
+}
+
+// tokenFromClaims returns the token type from the refresh token claim, or the fallback if the claim is missing
+//
+// Parameters:
+//
+//   - claims: The token claims
+//   - fallback: The fallback token type
+//
+// Returns:
+//
+//   - gojwttoken.Token: The token type
+func tokenFromClaims(claims jwt.MapClaims, fallback gojwttoken.Token) gojwttoken.Token {
+	isRefreshToken, ok := claims[gojwt.IsRefreshTokenClaim].(bool)
+	if !ok {
+		return fallback
+	}
+	if isRefreshToken {
+		return gojwttoken.RefreshToken
+	}
+	return gojwttoken.AccessToken
+}
+
+// newIntrospection creates the introspection response of an active token
+//
+// Parameters:
+//
+//   - claims: The token claims
+//   - token: The token type
+//
+// Returns:
+//
+//   - *Introspection: The introspection response
+func newIntrospection(claims jwt.MapClaims, token gojwttoken.Token) *Introspection {
+	introspection := &Introspection{
+		Active:    true,
+		TokenType: token.String(),
+	}
+	if scopes := gojwt.GetClaimsScopes(claims); len(scopes) > 0 {
+		introspection.Scope = strings.Join(scopes, " ")
+	}
+	if exp, err := claims.GetExpirationTime(); err == nil && exp != nil {
+		introspection.ExpiresAt = exp.Unix()
+	}
+	if iat, err := claims.GetIssuedAt(); err == nil && iat != nil {
+		introspection.IssuedAt = iat.Unix()
+	}
+	if nbf, err := claims.GetNotBefore(); err == nil && nbf != nil {
+		introspection.NotBefore = nbf.Unix()
+	}
+	if sub, err := claims.GetSubject(); err == nil {
+		introspection.Subject = sub
+	}
+	if aud, err := claims.GetAudience(); err == nil {
+		introspection.Audience = aud
+	}
+	if iss, err := claims.GetIssuer(); err == nil {
+		introspection.Issuer = iss
+	}
+	if jti, ok := claims[gojwt.IDClaim].(string); ok {
+		introspection.ID = jti
+	}
+	return introspection
+}
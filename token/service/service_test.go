@@ -0,0 +1,180 @@
+package service
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"sync"
+	"testing"
+
+	goflagmode "github.com/ralvarezdev/go-flags/mode"
+	gojwttoken "github.com/ralvarezdev/go-jwt/token"
+	gojwttokenclaims "github.com/ralvarezdev/go-jwt/token/claims"
+	gojwttokenclaimscache "github.com/ralvarezdev/go-jwt/token/claims/cache"
+	gojwtissuer "github.com/ralvarezdev/go-jwt/token/issuer"
+	gojwtvalidator "github.com/ralvarezdev/go-jwt/token/validator"
+)
+
+type (
+	// unconditionalTokenValidator hides the conditional revocation of the wrapped token validator
+	unconditionalTokenValidator struct {
+		gojwttokenclaims.TokenValidator
+	}
+)
+
+// newTestService creates a token service backed by the given token validator, with a new ED25519 key pair
+//
+// Parameters:
+//
+//   - t: The test
+//   - tokenValidator: The token validator
+//
+// Returns:
+//
+//   - *Service: The token service
+func newTestService(
+	t *testing.T,
+	tokenValidator gojwttokenclaims.TokenValidator,
+) *Service {
+	t.Helper()
+
+	// Generate the key pair
+	publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate the key pair: %v", err)
+	}
+	privateKeyDER, err := x509.MarshalPKCS8PrivateKey(privateKey)
+	if err != nil {
+		t.Fatalf("failed to marshal the private key: %v", err)
+	}
+	publicKeyDER, err := x509.MarshalPKIXPublicKey(publicKey)
+	if err != nil {
+		t.Fatalf("failed to marshal the public key: %v", err)
+	}
+
+	issuer, err := gojwtissuer.NewEd25519Issuer(
+		pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: privateKeyDER}),
+	)
+	if err != nil {
+		t.Fatalf("failed to create the issuer: %v", err)
+	}
+	claimsValidator, err := gojwttokenclaims.NewDefaultClaimsValidator(tokenValidator)
+	if err != nil {
+		t.Fatalf("failed to create the claims validator: %v", err)
+	}
+	validator, err := gojwtvalidator.NewEd25519Validator(
+		pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicKeyDER}),
+		claimsValidator,
+		goflagmode.NewFlag(goflagmode.Prod, goflagmode.AllowedModes),
+	)
+	if err != nil {
+		t.Fatalf("failed to create the validator: %v", err)
+	}
+
+	service, err := NewService(issuer, validator, tokenValidator, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to create the service: %v", err)
+	}
+	return service
+}
+
+func TestService_Refresh_Concurrent(t *testing.T) {
+	tests := []struct {
+		name           string
+		tokenValidator func() gojwttokenclaims.TokenValidator
+	}{
+		{
+			name: "conditional revocation",
+			tokenValidator: func() gojwttokenclaims.TokenValidator {
+				return gojwttokenclaimscache.NewTokenValidator(nil)
+			},
+		},
+		{
+			name: "locked revocation",
+			tokenValidator: func() gojwttokenclaims.TokenValidator {
+				return unconditionalTokenValidator{gojwttokenclaimscache.NewTokenValidator(nil)}
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(
+			test.name, func(t *testing.T) {
+				ctx := context.Background()
+				service := newTestService(t, test.tokenValidator())
+
+				pair, err := service.IssueTokens(ctx, "subject", nil)
+				if err != nil {
+					t.Fatalf("IssueTokens() error = %v", err)
+				}
+
+				// Exchange the same refresh token concurrently
+				const requests = 16
+				var (
+					wg        sync.WaitGroup
+					mutex     sync.Mutex
+					succeeded int
+				)
+				for range requests {
+					wg.Add(1)
+					go func() {
+						defer wg.Done()
+
+						_, refreshErr := service.Refresh(ctx, pair.RefreshToken)
+						if refreshErr != nil && !errors.Is(refreshErr, ErrInvalidGrant) {
+							t.Errorf("Refresh() error = %v, want ErrInvalidGrant", refreshErr)
+							return
+						}
+
+						mutex.Lock()
+						defer mutex.Unlock()
+						if refreshErr == nil {
+							succeeded++
+						}
+					}()
+				}
+				wg.Wait()
+
+				if succeeded != 1 {
+					t.Errorf("Refresh() succeeded %d times, want 1", succeeded)
+				}
+			},
+		)
+	}
+}
+
+func TestService_RevokeOwned(t *testing.T) {
+	tests := []struct {
+		name      string
+		subject   string
+		wantValid bool
+	}{
+		{name: "owner", subject: "owner", wantValid: false},
+		{name: "another subject", subject: "intruder", wantValid: true},
+	}
+	for _, test := range tests {
+		t.Run(
+			test.name, func(t *testing.T) {
+				ctx := context.Background()
+				tokenValidator := gojwttokenclaimscache.NewTokenValidator(nil)
+				service := newTestService(t, tokenValidator)
+
+				pair, err := service.IssueTokens(ctx, "owner", nil)
+				if err != nil {
+					t.Fatalf("IssueTokens() error = %v", err)
+				}
+
+				if err = service.RevokeOwned(ctx, test.subject, pair.AccessToken, ""); err != nil {
+					t.Fatalf("RevokeOwned() error = %v", err)
+				}
+
+				_, err = service.Validator().ValidateClaims(ctx, pair.AccessToken, gojwttoken.AccessToken)
+				if isValid := err == nil; isValid != test.wantValid {
+					t.Errorf("access token valid = %v, want %v", isValid, test.wantValid)
+				}
+			},
+		)
+	}
+}
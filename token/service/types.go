@@ -0,0 +1,54 @@
+package service
+
+import (
+	"time"
+)
+
+type (
+	// Options are the options for the token service
+	Options struct {
+		// AccessTokenTTL is the lifetime of the access tokens (optional, DefaultAccessTokenTTL is used if not positive)
+		AccessTokenTTL time.Duration
+
+		// RefreshTokenTTL is the lifetime of the refresh tokens (optional, DefaultRefreshTokenTTL is used if not
+		// positive)
+		RefreshTokenTTL time.Duration
+
+		// Issuer is the issuer claim of the issued tokens (optional, omitted if empty)
+		Issuer string
+
+		// Audience is the audience claim of the issued tokens (optional, omitted if empty)
+		Audience []string
+	}
+
+	// TokenPair is a pair of issued access and refresh tokens
+	TokenPair struct {
+		AccessToken           string    `json:"access_token"`
+		AccessTokenExpiresAt  time.Time `json:"-"`
+		RefreshToken          string    `json:"refresh_token"`
+		RefreshTokenExpiresAt time.Time `json:"-"`
+		TokenType             string    `json:"token_type"`
+		ExpiresIn             int64     `json:"expires_in"`
+	}
+
+	// Session is an active session of a subject, identified by its refresh token ID
+	Session struct {
+		ID        string    `json:"id"`
+		IssuedAt  time.Time `json:"issued_at"`
+		ExpiresAt time.Time `json:"expires_at"`
+	}
+
+	// Introspection is the RFC 7662 introspection response of a token
+	Introspection struct {
+		Active    bool     `json:"active"`
+		Scope     string   `json:"scope,omitempty"`
+		TokenType string   `json:"token_type,omitempty"`
+		ExpiresAt int64    `json:"exp,omitempty"`
+		IssuedAt  int64    `json:"iat,omitempty"`
+		NotBefore int64    `json:"nbf,omitempty"`
+		Subject   string   `json:"sub,omitempty"`
+		Audience  []string `json:"aud,omitempty"`
+		Issuer    string   `json:"iss,omitempty"`
+		ID        string   `json:"jti,omitempty"`
+	}
+)
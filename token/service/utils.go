@@ -0,0 +1,20 @@
+package service
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+)
+
+// GenerateTokenID generates a random token ID
+//
+// Returns:
+//
+//   - string: The token ID
+//   - error: An error if the random bytes could not be read
+func GenerateTokenID() (string, error) {
+	buffer := make([]byte, TokenIDLength)
+	if _, err := rand.Read(buffer); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buffer), nil
+}
@@ -0,0 +1,10 @@
+package source
+
+import (
+	"time"
+)
+
+const (
+	// DefaultRefreshLeeway is the time before the token expiration at which the token is refreshed
+	DefaultRefreshLeeway = 30 * time.Second
+)
@@ -0,0 +1,13 @@
+package source
+
+import (
+	"errors"
+)
+
+var (
+	ErrNilSource           = errors.New("source cannot be nil")
+	ErrNilFetchFn          = errors.New("fetch function cannot be nil")
+	ErrNilClaimsFn         = errors.New("claims function cannot be nil")
+	ErrNilExchangeFn       = errors.New("exchange function cannot be nil")
+	ErrRefreshNotSupported = errors.New("refresh not supported")
+)
@@ -0,0 +1,71 @@
+package source
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	gojwt "github.com/ralvarezdev/go-jwt"
+)
+
+type (
+	// ExchangeFn exchanges a refresh token for a new access token. If the refresh tokens are rotated, the new refresh
+	// token is returned too, otherwise it is empty
+	ExchangeFn func(ctx context.Context, refreshToken string) (
+		accessToken string,
+		newRefreshToken string,
+		err error,
+	)
+)
+
+// NewRefreshTokenExchangeSource creates a refreshing source that obtains its access tokens by exchanging a refresh
+// token, keeping the rotated refresh tokens
+//
+// Parameters:
+//
+//   - refreshToken: The initial refresh token
+//   - exchangeFn: The function that exchanges the refresh token
+//   - leeway: The time before the token expiration at which the token is refreshed (optional, DefaultRefreshLeeway is
+//     used if not positive)
+//   - logger: The logger (optional, can be nil)
+//
+// Returns:
+//
+//   - *RefreshingSource: The refreshing source
+//   - error: An error if the refresh token is empty or if the exchange function is nil
+func NewRefreshTokenExchangeSource(
+	refreshToken string,
+	exchangeFn ExchangeFn,
+	leeway time.Duration,
+	logger *slog.Logger,
+) (*RefreshingSource, error) {
+	// Check if the refresh token is empty or the exchange function is nil
+	if refreshToken == "" {
+		return nil, gojwt.ErrEmptyToken
+	}
+	if exchangeFn == nil {
+		return nil, ErrNilExchangeFn
+	}
+
+	var mutex sync.Mutex
+	return NewRefreshingSource(
+		func(ctx context.Context) (string, error) {
+			mutex.Lock()
+			defer mutex.Unlock()
+
+			accessToken, newRefreshToken, err := exchangeFn(ctx, refreshToken)
+			if err != nil {
+				return "", err
+			}
+
+			// Keep the rotated refresh token
+			if newRefreshToken != "" {
+				refreshToken = newRefreshToken
+			}
+			return accessToken, nil
+		},
+		leeway,
+		logger,
+	)
+}
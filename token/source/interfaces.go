@@ -0,0 +1,13 @@
+package source
+
+import (
+	"context"
+)
+
+type (
+	// Source provides the raw tokens attached to the outgoing requests
+	Source interface {
+		Token(ctx context.Context) (string, error)
+		Refresh(ctx context.Context) (string, error)
+	}
+)
@@ -0,0 +1,56 @@
+package source
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	gojwtissuer "github.com/ralvarezdev/go-jwt/token/issuer"
+)
+
+type (
+	// ClaimsFn returns the claims of a new self-issued token
+	ClaimsFn func(ctx context.Context) (jwt.Claims, error)
+)
+
+// NewIssuerSource creates a refreshing source that issues its own tokens, such as for service-to-service calls
+//
+// Parameters:
+//
+//   - issuer: The token issuer
+//   - claimsFn: The function that returns the claims of each new token, which should include its expiration time
+//   - leeway: The time before the token expiration at which the token is refreshed (optional, DefaultRefreshLeeway is
+//     used if not positive)
+//   - logger: The logger (optional, can be nil)
+//
+// Returns:
+//
+//   - *RefreshingSource: The refreshing source
+//   - error: An error if the issuer or the claims function is nil
+func NewIssuerSource(
+	issuer gojwtissuer.Issuer,
+	claimsFn ClaimsFn,
+	leeway time.Duration,
+	logger *slog.Logger,
+) (*RefreshingSource, error) {
+	// Check if the issuer or the claims function is nil
+	if issuer == nil {
+		return nil, gojwtissuer.ErrNilIssuer
+	}
+	if claimsFn == nil {
+		return nil, ErrNilClaimsFn
+	}
+
+	return NewRefreshingSource(
+		func(ctx context.Context) (string, error) {
+			claims, err := claimsFn(ctx)
+			if err != nil {
+				return "", err
+			}
+			return issuer.IssueToken(claims)
+		},
+		leeway,
+		logger,
+	)
+}
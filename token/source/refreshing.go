@@ -0,0 +1,186 @@
+package source
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	gojwt "github.com/ralvarezdev/go-jwt"
+	"golang.org/x/sync/singleflight"
+)
+
+type (
+	// FetchFn fetches a new raw token
+	FetchFn func(ctx context.Context) (string, error)
+
+	// RefreshingSource is a source that caches the fetched token and fetches a new one before it expires. Concurrent
+	// fetches are coalesced into a single call to the fetch function
+	RefreshingSource struct {
+		fetchFn   FetchFn
+		leeway    time.Duration
+		parser    *jwt.Parser
+		rawToken  string
+		expiresAt time.Time
+		now       func() time.Time
+		group     singleflight.Group
+		logger    *slog.Logger
+		mutex     sync.RWMutex
+	}
+)
+
+// NewRefreshingSource creates a new refreshing source
+//
+// Parameters:
+//
+//   - fetchFn: The function that fetches a new raw token
+//   - leeway: The time before the token expiration at which the token is refreshed (optional, DefaultRefreshLeeway is
+//     used if not positive)
+//   - logger: The logger (optional, can be nil)
+//
+// Returns:
+//
+//   - *RefreshingSource: The refreshing source
+//   - error: An error if the fetch function is nil
+func NewRefreshingSource(
+	fetchFn FetchFn,
+	leeway time.Duration,
+	logger *slog.Logger,
+) (*RefreshingSource, error) {
+	// Check if the fetch function is nil
+	if fetchFn == nil {
+		return nil, ErrNilFetchFn
+	}
+
+	if leeway <= 0 {
+		leeway = DefaultRefreshLeeway
+	}
+
+	if logger != nil {
+		logger = logger.With(slog.String("component", "refreshing_token_source"))
+	}
+
+	return &RefreshingSource{
+		fetchFn: fetchFn,
+		leeway:  leeway,
+		parser:  jwt.NewParser(),
+		now:     time.Now,
+		logger:  logger,
+	}, nil
+}
+
+// Token returns the cached token, fetching a new one if there is none or if it expires within the leeway
+//
+// Parameters:
+//
+//   - ctx: The context
+//
+// Returns:
+//
+//   - string: The raw token
+//   - error: An error if the source is nil or if the token could not be fetched
+func (r *RefreshingSource) Token(ctx context.Context) (string, error) {
+	if r == nil {
+		return "", ErrNilSource
+	}
+
+	// Check if the cached token is still fresh
+	r.mutex.RLock()
+	rawToken := r.rawToken
+	isFresh := rawToken != "" && (r.expiresAt.IsZero() || r.now().Add(r.leeway).Before(r.expiresAt))
+	r.mutex.RUnlock()
+	if isFresh {
+		return rawToken, nil
+	}
+	return r.fetch(ctx)
+}
+
+// Refresh fetches a new token, discarding the cached one
+//
+// Parameters:
+//
+//   - ctx: The context
+//
+// Returns:
+//
+//   - string: The raw token
+//   - error: An error if the source is nil or if the token could not be fetched
+func (r *RefreshingSource) Refresh(ctx context.Context) (string, error) {
+	if r == nil {
+		return "", ErrNilSource
+	}
+
+	return r.fetch(ctx)
+}
+
+// fetch fetches a new token and caches it with its expiration time, sharing the result with the concurrent callers
+//
+// Parameters:
+//
+//   - ctx: The context
+//
+// Returns:
+//
+//   - string: The raw token
+//   - error: An error if the token could not be fetched
+func (r *RefreshingSource) fetch(ctx context.Context) (string, error) {
+	// The fetch is not canceled when the caller that started it goes away, since other callers may be waiting for it,
+	// but each caller stops waiting when its own context is done
+	resultCh := r.group.DoChan(
+		"fetch", func() (any, error) {
+			return r.doFetch(context.WithoutCancel(ctx))
+		},
+	)
+	select {
+	case <-ctx.Done():
+		return "", ctx.Err()
+	case result := <-resultCh:
+		if result.Err != nil {
+			return "", result.Err
+		}
+		return result.Val.(string), nil
+	}
+}
+
+// doFetch fetches a new token and caches it with its expiration time
+//
+// Parameters:
+//
+//   - ctx: The context
+//
+// Returns:
+//
+//   - string: The raw token
+//   - error: An error if the token could not be fetched
+func (r *RefreshingSource) doFetch(ctx context.Context) (string, error) {
+	// Fetch the new token
+	rawToken, err := r.fetchFn(ctx)
+	if err != nil {
+		if r.logger != nil {
+			r.logger.Error(
+				"Failed to fetch token",
+				slog.String("error", err.Error()),
+			)
+		}
+		return "", err
+	}
+	if rawToken == "" {
+		return "", gojwt.ErrEmptyToken
+	}
+
+	// Read the expiration time, the signature is verified by the receiver of the token
+	var expiresAt time.Time
+	claims := jwt.MapClaims{}
+	if _, _, err = r.parser.ParseUnverified(rawToken, claims); err == nil {
+		if exp, expErr := claims.GetExpirationTime(); expErr == nil && exp != nil {
+			expiresAt = exp.Time
+		}
+	}
+
+	r.mutex.Lock()
+	r.rawToken = rawToken
+	r.expiresAt = expiresAt
+	r.mutex.Unlock()
+	return rawToken, nil
+}
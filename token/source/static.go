@@ -0,0 +1,69 @@
+package source
+
+import (
+	"context"
+
+	gojwt "github.com/ralvarezdev/go-jwt"
+)
+
+type (
+	// StaticSource is a source that always provides the same token
+	StaticSource struct {
+		rawToken string
+	}
+)
+
+// NewStaticSource creates a new static source
+//
+// Parameters:
+//
+//   - rawToken: The raw token
+//
+// Returns:
+//
+//   - *StaticSource: The static source
+//   - error: An error if the raw token is empty
+func NewStaticSource(rawToken string) (*StaticSource, error) {
+	// Check if the raw token is empty
+	if rawToken == "" {
+		return nil, gojwt.ErrEmptyToken
+	}
+
+	return &StaticSource{
+		rawToken: rawToken,
+	}, nil
+}
+
+// Token returns the static token
+//
+// Parameters:
+//
+//   - ctx: The context
+//
+// Returns:
+//
+//   - string: The raw token
+//   - error: An error if the source is nil
+func (s *StaticSource) Token(ctx context.Context) (string, error) {
+	if s == nil {
+		return "", ErrNilSource
+	}
+	return s.rawToken, nil
+}
+
+// Refresh is not supported by the static source
+//
+// Parameters:
+//
+//   - ctx: The context
+//
+// Returns:
+//
+//   - string: An empty string
+//   - error: ErrRefreshNotSupported, or an error if the source is nil
+func (s *StaticSource) Refresh(ctx context.Context) (string, error) {
+	if s == nil {
+		return "", ErrNilSource
+	}
+	return "", ErrRefreshNotSupported
+}
@@ -0,0 +1,315 @@
+package tokensync
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+type (
+	// BatchingOptions are the options for the BatchingPublisher
+	BatchingOptions struct {
+		// MaxSize is the number of issued token pairs and revoked token IDs that triggers a flush (optional,
+		// DefaultBatchMaxSize is used if not positive)
+		MaxSize int
+
+		// FlushInterval is the maximum time a message is held before being flushed (optional,
+		// DefaultBatchFlushInterval is used if not positive)
+		FlushInterval time.Duration
+	}
+
+	// BatchingPublisher is a Publisher that coalesces the tokens messages into a single message, flushed to the
+	// wrapped publisher once it reaches the maximum size, once the flush interval elapses since its first message or
+	// when the publisher is closed. Coalescing is safe since the token IDs are unique and the consumers apply the
+	// revocations of a message over its issued tokens
+	BatchingPublisher struct {
+		publisher     Publisher
+		maxSize       int
+		flushInterval time.Duration
+		pending       *TokensMessage
+		pendingSize   int
+		timer         *time.Timer
+		closed        bool
+		logger        *slog.Logger
+		mutex         sync.Mutex
+		flushMutex    sync.Mutex
+	}
+)
+
+// NewBatchingPublisher creates a new BatchingPublisher
+//
+// Parameters:
+//
+//   - publisher: the wrapped publisher
+//   - options: the options (optional, can be nil)
+//   - logger: the logger (optional, can be nil)
+//
+// Returns:
+//
+//   - *BatchingPublisher: the BatchingPublisher instance
+//   - error: an error if the publisher is nil
+func NewBatchingPublisher(
+	publisher Publisher,
+	options *BatchingOptions,
+	logger *slog.Logger,
+) (*BatchingPublisher, error) {
+	// Check if the publisher is nil
+	if publisher == nil {
+		return nil, ErrNilPublisher
+	}
+
+	// Set the options
+	if options == nil {
+		options = &BatchingOptions{}
+	}
+
+	maxSize := options.MaxSize
+	if maxSize <= 0 {
+		maxSize = DefaultBatchMaxSize
+	}
+
+	flushInterval := options.FlushInterval
+	if flushInterval <= 0 {
+		flushInterval = DefaultBatchFlushInterval
+	}
+
+	if logger != nil {
+		logger = logger.With(
+			slog.String("component", "jwt_tokensync_batching_publisher"),
+		)
+	}
+
+	return &BatchingPublisher{
+		publisher:     publisher,
+		maxSize:       maxSize,
+		flushInterval: flushInterval,
+		logger:        logger,
+	}, nil
+}
+
+// Open opens the wrapped publisher
+//
+// Returns:
+//
+//   - error: an error if the wrapped publisher could not be opened
+func (b *BatchingPublisher) Open() error {
+	// Check if the publisher is nil
+	if b == nil {
+		return ErrNilPublisher
+	}
+	return b.publisher.Open()
+}
+
+// Close flushes the pending message and closes the wrapped publisher. The messages published afterward are rejected
+//
+// Returns:
+//
+//   - error: an error if the pending message could not be flushed or the wrapped publisher could not be closed
+func (b *BatchingPublisher) Close() error {
+	// Check if the publisher is nil
+	if b == nil {
+		return ErrNilPublisher
+	}
+
+	// Reject the new messages
+	b.mutex.Lock()
+	b.closed = true
+	b.mutex.Unlock()
+
+	flushErr := b.Flush(context.Background())
+	return errors.Join(flushErr, b.publisher.Close())
+}
+
+// PublishTokensMessage adds a tokens message to the pending message
+//
+// Parameters:
+//
+//   - msg: the tokens message to publish
+//
+// Returns:
+//
+//   - error: an error if the message could not be added
+func (b *BatchingPublisher) PublishTokensMessage(msg *TokensMessage) error {
+	return b.PublishTokensMessageWithCtx(context.Background(), msg)
+}
+
+// PublishTokensMessageWithCtx adds a tokens message to the pending message, which is flushed by the caller once it
+// reaches the maximum size, or in the background once the flush interval elapses. The flush errors are logged, and
+// the pending message, which already holds the added message, is flushed again on the next interval, so the caller
+// must not publish the message again
+//
+// Parameters:
+//
+//   - ctx: the context
+//   - msg: the tokens message to publish
+//
+// Returns:
+//
+//   - error: an error if the message could not be added
+func (b *BatchingPublisher) PublishTokensMessageWithCtx(
+	ctx context.Context,
+	msg *TokensMessage,
+) error {
+	// Check if the publisher is nil
+	if b == nil {
+		return ErrNilPublisher
+	}
+
+	// Check if the message is nil
+	if msg == nil {
+		return ErrNilMessage
+	}
+
+	b.mutex.Lock()
+
+	// Check if the publisher is closed
+	if b.closed {
+		b.mutex.Unlock()
+		return ErrClosedPublisher
+	}
+
+	// Add the message to the pending message
+	b.merge(msg)
+	isFull := b.pendingSize >= b.maxSize
+	if !isFull {
+		b.schedule()
+	}
+	b.mutex.Unlock()
+
+	// Flush the pending message once full, which is kept pending if it could not be published
+	if isFull {
+		_ = b.Flush(ctx)
+	}
+	return nil
+}
+
+// PublishTokensMessageEnvelope flushes the pending message and publishes a tokens message envelope created elsewhere
+// with the wrapped publisher, since its message ID must be kept
+//
+// Parameters:
+//
+//   - ctx: the context
+//   - envelope: the tokens message envelope to publish
+//
+// Returns:
+//
+//   - error: an error if the pending message could not be flushed or the envelope could not be published
+func (b *BatchingPublisher) PublishTokensMessageEnvelope(
+	ctx context.Context,
+	envelope *TokensMessageEnvelope,
+) error {
+	// Check if the publisher is nil
+	if b == nil {
+		return ErrNilPublisher
+	}
+
+	if err := b.Flush(ctx); err != nil {
+		return err
+	}
+	return b.publisher.PublishTokensMessageEnvelope(ctx, envelope)
+}
+
+// Flush publishes the pending message with the wrapped publisher. If it could not be published, it is kept ahead of
+// the messages added meanwhile and flushed again on the next interval
+//
+// Parameters:
+//
+//   - ctx: the context
+//
+// Returns:
+//
+//   - error: an error if the pending message could not be published
+func (b *BatchingPublisher) Flush(ctx context.Context) error {
+	// Check if the publisher is nil
+	if b == nil {
+		return ErrNilPublisher
+	}
+
+	// Lock the flush mutex, so the pending messages are published in order
+	b.flushMutex.Lock()
+	defer b.flushMutex.Unlock()
+
+	// Take the pending message
+	b.mutex.Lock()
+	msg, size := b.pending, b.pendingSize
+	b.pending, b.pendingSize = nil, 0
+	if b.timer != nil {
+		b.timer.Stop()
+		b.timer = nil
+	}
+	b.mutex.Unlock()
+
+	// Check if there is a pending message
+	if msg == nil {
+		return nil
+	}
+
+	if err := b.publisher.PublishTokensMessageWithCtx(ctx, msg); err != nil {
+		if b.logger != nil {
+			b.logger.Error(
+				"Failed to flush the pending message",
+				slog.Int("size", size),
+				slog.String("error", err.Error()),
+			)
+		}
+
+		// Put the message back ahead of the messages added meanwhile
+		b.mutex.Lock()
+		added := b.pending
+		b.pending, b.pendingSize = msg, size
+		if added != nil {
+			b.merge(added)
+		}
+		if !b.closed {
+			b.schedule()
+		}
+		b.mutex.Unlock()
+		return err
+	}
+
+	if b.logger != nil {
+		b.logger.Debug("Pending message flushed", slog.Int("size", size))
+	}
+	return nil
+}
+
+// merge appends a tokens message to the pending message, the mutex must be held by the caller
+//
+// Parameters:
+//
+//   - msg: the tokens message
+func (b *BatchingPublisher) merge(msg *TokensMessage) {
+	if b.pending == nil {
+		b.pending = &TokensMessage{}
+	}
+	b.pending.IssuedTokenPairs = append(
+		b.pending.IssuedTokenPairs,
+		msg.IssuedTokenPairs...,
+	)
+	b.pending.RevokedRefreshTokensID = append(
+		b.pending.RevokedRefreshTokensID,
+		msg.RevokedRefreshTokensID...,
+	)
+	b.pending.RevokedAccessTokensID = append(
+		b.pending.RevokedAccessTokensID,
+		msg.RevokedAccessTokensID...,
+	)
+	b.pendingSize += len(msg.IssuedTokenPairs) +
+		len(msg.RevokedRefreshTokensID) +
+		len(msg.RevokedAccessTokensID)
+}
+
+// schedule starts the timer that flushes the pending message, if not already started, the mutex must be held by the
+// caller
+func (b *BatchingPublisher) schedule() {
+	if b.timer != nil || b.pending == nil {
+		return
+	}
+	b.timer = time.AfterFunc(
+		b.flushInterval, func() {
+			_ = b.Flush(context.Background())
+		},
+	)
+}
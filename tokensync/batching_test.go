@@ -0,0 +1,234 @@
+package tokensync_test
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	gojwttokensync "github.com/ralvarezdev/go-jwt/tokensync"
+	gojwttokensyncmemory "github.com/ralvarezdev/go-jwt/tokensync/memory"
+)
+
+// errFlakyPublish is the error returned by the flakyPublisher for the failing publishes
+var errFlakyPublish = errors.New("flaky publish")
+
+type (
+	// flakyPublisher is a publisher that fails the given number of publishes before delegating to the wrapped one
+	flakyPublisher struct {
+		gojwttokensync.Publisher
+		failures atomic.Int32
+	}
+)
+
+// PublishTokensMessageWithCtx fails while there are failures left, and publishes the message otherwise
+//
+// Parameters:
+//
+//   - ctx: The context
+//   - msg: The tokens message
+//
+// Returns:
+//
+//   - error: errFlakyPublish while there are failures left, or the error of the wrapped publisher
+func (f *flakyPublisher) PublishTokensMessageWithCtx(
+	ctx context.Context,
+	msg *gojwttokensync.TokensMessage,
+) error {
+	if f.failures.Add(-1) >= 0 {
+		return errFlakyPublish
+	}
+	return f.Publisher.PublishTokensMessageWithCtx(ctx, msg)
+}
+
+// newTestBatchingPublisher creates a batching publisher over a memory broker, and subscribes to the broker
+//
+// Parameters:
+//
+//   - t: The test
+//   - options: The batching options
+//   - failures: The number of publishes of the wrapped publisher that fail
+//
+// Returns:
+//
+//   - *gojwttokensync.BatchingPublisher: The batching publisher
+//   - gojwttokensync.TokensMessagesConsumer: The subscription
+func newTestBatchingPublisher(
+	t *testing.T,
+	options *gojwttokensync.BatchingOptions,
+	failures int32,
+) (*gojwttokensync.BatchingPublisher, gojwttokensync.TokensMessagesConsumer) {
+	t.Helper()
+
+	broker := gojwttokensyncmemory.NewBroker(nil, nil)
+	subscription, err := broker.NewConsumer().CreateTokensMessagesConsumer(context.Background())
+	if err != nil {
+		t.Fatalf("CreateTokensMessagesConsumer() error = %v", err)
+	}
+
+	publisher := &flakyPublisher{Publisher: broker.NewPublisher("test")}
+	publisher.failures.Store(failures)
+	batchingPublisher, err := gojwttokensync.NewBatchingPublisher(publisher, options, nil)
+	if err != nil {
+		t.Fatalf("NewBatchingPublisher() error = %v", err)
+	}
+	return batchingPublisher, subscription
+}
+
+// receiveRevokedAccessTokensID receives a message from the given subscription and returns its revoked access token IDs
+//
+// Parameters:
+//
+//   - t: The test
+//   - subscription: The subscription
+//   - timeout: The time to wait for the message
+//
+// Returns:
+//
+//   - []string: The revoked access token IDs of the message, or nil if no message was received
+func receiveRevokedAccessTokensID(
+	t *testing.T,
+	subscription gojwttokensync.TokensMessagesConsumer,
+	timeout time.Duration,
+) []string {
+	t.Helper()
+
+	select {
+	case delivery := <-subscription.GetChannel():
+		return delivery.GetEnvelope().Message.RevokedAccessTokensID
+	case <-time.After(timeout):
+		return nil
+	}
+}
+
+// equalIDs reports whether the given IDs are equal
+//
+// Parameters:
+//
+//   - got: The received IDs
+//   - want: The expected IDs
+//
+// Returns:
+//
+//   - bool: Whether the IDs are equal
+func equalIDs(got, want []string) bool {
+	if len(got) != len(want) {
+		return false
+	}
+	for i := range got {
+		if got[i] != want[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// publishRevokedAccessTokens publishes a tokens message revoking the given access tokens
+//
+// Parameters:
+//
+//   - t: The test
+//   - publisher: The batching publisher
+//   - ids: The IDs of the revoked access tokens
+func publishRevokedAccessTokens(
+	t *testing.T,
+	publisher *gojwttokensync.BatchingPublisher,
+	ids ...string,
+) {
+	t.Helper()
+
+	if err := publisher.PublishTokensMessageWithCtx(
+		context.Background(),
+		&gojwttokensync.TokensMessage{RevokedAccessTokensID: ids},
+	); err != nil {
+		t.Fatalf("PublishTokensMessageWithCtx() error = %v", err)
+	}
+}
+
+func TestBatchingPublisher_FlushOnMaxSize(t *testing.T) {
+	publisher, subscription := newTestBatchingPublisher(
+		t,
+		&gojwttokensync.BatchingOptions{MaxSize: 3, FlushInterval: time.Hour},
+		0,
+	)
+
+	// The pending message is held until it reaches the maximum size
+	publishRevokedAccessTokens(t, publisher, "access-0", "access-1")
+	if got := receiveRevokedAccessTokensID(t, subscription, 50*time.Millisecond); got != nil {
+		t.Fatalf("received %v before reaching the maximum size, want nothing", got)
+	}
+	publishRevokedAccessTokens(t, publisher, "access-2")
+
+	want := []string{"access-0", "access-1", "access-2"}
+	if got := receiveRevokedAccessTokensID(t, subscription, 5*time.Second); !equalIDs(got, want) {
+		t.Errorf("received %v, want %v", got, want)
+	}
+}
+
+func TestBatchingPublisher_FlushOnInterval(t *testing.T) {
+	publisher, subscription := newTestBatchingPublisher(
+		t,
+		&gojwttokensync.BatchingOptions{MaxSize: 100, FlushInterval: 20 * time.Millisecond},
+		0,
+	)
+
+	publishRevokedAccessTokens(t, publisher, "access-0")
+	publishRevokedAccessTokens(t, publisher, "access-1")
+
+	want := []string{"access-0", "access-1"}
+	if got := receiveRevokedAccessTokensID(t, subscription, 5*time.Second); !equalIDs(got, want) {
+		t.Errorf("received %v, want %v", got, want)
+	}
+}
+
+func TestBatchingPublisher_FlushOnClose(t *testing.T) {
+	publisher, subscription := newTestBatchingPublisher(
+		t,
+		&gojwttokensync.BatchingOptions{MaxSize: 100, FlushInterval: time.Hour},
+		0,
+	)
+
+	publishRevokedAccessTokens(t, publisher, "access-0")
+	if err := publisher.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	want := []string{"access-0"}
+	if got := receiveRevokedAccessTokensID(t, subscription, 5*time.Second); !equalIDs(got, want) {
+		t.Errorf("received %v, want %v", got, want)
+	}
+
+	// The messages published after closing are rejected
+	if err := publisher.PublishTokensMessageWithCtx(
+		context.Background(),
+		&gojwttokensync.TokensMessage{RevokedAccessTokensID: []string{"access-1"}},
+	); !errors.Is(err, gojwttokensync.ErrClosedPublisher) {
+		t.Errorf("PublishTokensMessageWithCtx() error = %v, want %v", err, gojwttokensync.ErrClosedPublisher)
+	}
+}
+
+func TestBatchingPublisher_RequeueOnFailure(t *testing.T) {
+	publisher, subscription := newTestBatchingPublisher(
+		t,
+		&gojwttokensync.BatchingOptions{MaxSize: 1, FlushInterval: time.Hour},
+		1,
+	)
+
+	// The failed flush keeps the message pending without failing the caller, so it is not published twice
+	publishRevokedAccessTokens(t, publisher, "access-0")
+	if got := receiveRevokedAccessTokensID(t, subscription, 50*time.Millisecond); got != nil {
+		t.Fatalf("received %v after a failed flush, want nothing", got)
+	}
+
+	// The pending message is flushed ahead of the messages added meanwhile
+	publishRevokedAccessTokens(t, publisher, "access-1")
+
+	want := []string{"access-0", "access-1"}
+	if got := receiveRevokedAccessTokensID(t, subscription, 5*time.Second); !equalIDs(got, want) {
+		t.Errorf("received %v, want %v", got, want)
+	}
+	if got := receiveRevokedAccessTokensID(t, subscription, 50*time.Millisecond); got != nil {
+		t.Errorf("received %v after the flush, want nothing", got)
+	}
+}
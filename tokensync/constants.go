@@ -0,0 +1,59 @@
+package tokensync
+
+import (
+	"time"
+)
+
+const (
+	// TokensMessageVersion is the major.minor version of the tokens message format produced by this package. A minor
+	// version only adds optional fields, so the consumers accept every minor version of their major version
+	TokensMessageVersion = "1.0"
+
+	// TokensMessageMajorVersion is the major version of the tokens message format accepted by the consumers
+	TokensMessageMajorVersion = 1
+
+	// TokensMessageType is the type of the published tokens messages
+	TokensMessageType = "tokens_message"
+
+	// JSONContentType is the content type of the JSON-encoded tokens messages
+	JSONContentType = "application/json"
+
+	// ProtobufContentType is the content type of the protobuf-encoded tokens messages
+	ProtobufContentType = "application/x-protobuf"
+
+	// MessageIDLength is the length in bytes of the generated message IDs
+	MessageIDLength = 16
+)
+
+var (
+	// DefaultRevocationRetention is the default time a revoked token ID is remembered, which must outlive the tokens,
+	// so it matches the default refresh token lifetime of the token service
+	DefaultRevocationRetention = 7 * 24 * time.Hour
+
+	// RevocationPurgeInterval is the minimum interval between the purges of the expired revoked token IDs
+	RevocationPurgeInterval = time.Minute
+
+	// DefaultDeduplicationCacheSize is the default number of applied message IDs remembered to discard duplicates
+	DefaultDeduplicationCacheSize = 10000
+
+	// DefaultResubscribeInitialBackoff is the default wait before the first re-subscription of the service, doubled on
+	// each failed subscription
+	DefaultResubscribeInitialBackoff = 500 * time.Millisecond
+
+	// DefaultResubscribeMaxBackoff is the default maximum wait between the re-subscriptions of the service
+	DefaultResubscribeMaxBackoff = 30 * time.Second
+
+	// DefaultDeliveriesBufferSize is the default buffer size of the deliveries channel of the transports
+	DefaultDeliveriesBufferSize = 100
+
+	// DefaultMaxRedeliveries is the default number of times a tokens message that could not be applied is redelivered
+	// by the transports before being dead-lettered
+	DefaultMaxRedeliveries = 5
+
+	// DefaultBatchMaxSize is the default number of issued token pairs and revoked token IDs accumulated by the batching
+	// publisher before flushing them
+	DefaultBatchMaxSize = 500
+
+	// DefaultBatchFlushInterval is the default maximum time the batching publisher holds a message before flushing it
+	DefaultBatchFlushInterval = 100 * time.Millisecond
+)
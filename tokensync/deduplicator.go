@@ -0,0 +1,76 @@
+package tokensync
+
+import (
+	"sync"
+)
+
+type (
+	// MemoryDeduplicator is the in-memory implementation of the Deduplicator interface, which remembers a bounded
+	// number of the most recently applied message IDs
+	MemoryDeduplicator struct {
+		ids   map[string]struct{}
+		order []string
+		next  int
+		mutex sync.Mutex
+	}
+)
+
+// NewMemoryDeduplicator creates a new MemoryDeduplicator
+//
+// Parameters:
+//
+//   - size: the number of message IDs remembered (optional, DefaultDeduplicationCacheSize is used if not positive)
+//
+// Returns:
+//
+//   - *MemoryDeduplicator: the MemoryDeduplicator instance
+func NewMemoryDeduplicator(size int) *MemoryDeduplicator {
+	if size <= 0 {
+		size = DefaultDeduplicationCacheSize
+	}
+
+	return &MemoryDeduplicator{
+		ids:   make(map[string]struct{}, size),
+		order: make([]string, size),
+	}
+}
+
+// IsDuplicate checks if a message ID was already applied
+//
+// Parameters:
+//
+//   - messageID: the message ID
+//
+// Returns:
+//
+//   - bool: true if the message ID was already applied
+func (m *MemoryDeduplicator) IsDuplicate(messageID string) bool {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	_, ok := m.ids[messageID]
+	return ok
+}
+
+// MarkApplied remembers an applied message ID, forgetting the oldest one when the cache is full
+//
+// Parameters:
+//
+//   - messageID: the message ID
+func (m *MemoryDeduplicator) MarkApplied(messageID string) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	// Check if the message ID is already remembered
+	if _, ok := m.ids[messageID]; ok {
+		return
+	}
+
+	// Forget the oldest message ID
+	if oldest := m.order[m.next]; oldest != "" {
+		delete(m.ids, oldest)
+	}
+	m.order[m.next] = messageID
+	m.next = (m.next + 1) % len(m.order)
+	m.ids[messageID] = struct{}{}
+}
@@ -0,0 +1,268 @@
+package tokensync
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"mime"
+	"strconv"
+	"strings"
+	"time"
+
+	gojwttokensynctokensmessagepb "github.com/ralvarezdev/go-jwt/tokensync/tokensmessagepb"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// GenerateMessageID generates a random message ID
+//
+// Returns:
+//
+//   - string: the hex-encoded message ID
+//   - error: an error if the random bytes could not be read
+func GenerateMessageID() (string, error) {
+	id := make([]byte, MessageIDLength)
+	if _, err := rand.Read(id); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(id), nil
+}
+
+// NewTokensMessageEnvelope wraps a tokens message in an envelope of the current version with a new message ID
+//
+// Parameters:
+//
+//   - msg: the tokens message
+//   - producer: the name of the service that creates the message
+//
+// Returns:
+//
+//   - *TokensMessageEnvelope: the envelope
+//   - error: an error if the message is nil or the message ID could not be generated
+func NewTokensMessageEnvelope(
+	msg *TokensMessage,
+	producer string,
+) (*TokensMessageEnvelope, error) {
+	// Check if the message is nil
+	if msg == nil {
+		return nil, ErrNilMessage
+	}
+
+	// Generate the message ID
+	messageID, err := GenerateMessageID()
+	if err != nil {
+		return nil, err
+	}
+
+	return &TokensMessageEnvelope{
+		Version:   TokensMessageVersion,
+		MessageID: messageID,
+		IssuedAt:  time.Now().UTC(),
+		Producer:  producer,
+		Message:   msg,
+	}, nil
+}
+
+// CheckTokensMessageVersion checks if the major version of a tokens message is supported
+//
+// Parameters:
+//
+//   - version: the major.minor version
+//
+// Returns:
+//
+//   - error: ErrUnsupportedVersion if the version is malformed or of another major version
+func CheckTokensMessageVersion(version string) error {
+	major, _, _ := strings.Cut(version, ".")
+	if parsedMajor, err := strconv.Atoi(major); err != nil || parsedMajor != TokensMessageMajorVersion {
+		return fmt.Errorf("%w: %q", ErrUnsupportedVersion, version)
+	}
+	return nil
+}
+
+// MarshalTokensMessageEnvelope encodes an envelope with the given content type
+//
+// Parameters:
+//
+//   - envelope: the envelope
+//   - contentType: the content type, either JSONContentType or ProtobufContentType
+//
+// Returns:
+//
+//   - []byte: the encoded envelope
+//   - error: an error if the envelope is nil, the content type is unsupported or the envelope could not be encoded
+func MarshalTokensMessageEnvelope(
+	envelope *TokensMessageEnvelope,
+	contentType string,
+) ([]byte, error) {
+	// Check if the envelope is nil
+	if envelope == nil || envelope.Message == nil {
+		return nil, ErrNilMessage
+	}
+
+	switch mediaType(contentType) {
+	case JSONContentType:
+		return json.Marshal(envelope)
+	case ProtobufContentType:
+		return proto.Marshal(envelopeToProto(envelope))
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrUnsupportedContentType, contentType)
+	}
+}
+
+// UnmarshalTokensMessageEnvelope decodes an envelope with the given content type and checks its version. JSON bodies
+// of the unversioned format, published before the envelope was introduced, are returned in an envelope without a
+// version nor a message ID
+//
+// Parameters:
+//
+//   - body: the encoded envelope
+//   - contentType: the content type (optional, JSONContentType is used if empty)
+//
+// Returns:
+//
+//   - *TokensMessageEnvelope: the envelope
+//   - error: an error if the content type or the major version is unsupported, the message ID is missing or the
+//     envelope could not be decoded
+func UnmarshalTokensMessageEnvelope(
+	body []byte,
+	contentType string,
+) (*TokensMessageEnvelope, error) {
+	var envelope *TokensMessageEnvelope
+	switch mediaType(contentType) {
+	case "", JSONContentType:
+		envelope = &TokensMessageEnvelope{}
+		if err := json.Unmarshal(body, envelope); err != nil {
+			return nil, err
+		}
+
+		// Decode the unversioned format
+		if envelope.Version == "" && envelope.Message == nil {
+			var msg TokensMessage
+			if err := json.Unmarshal(body, &msg); err != nil {
+				return nil, err
+			}
+			return &TokensMessageEnvelope{Message: &msg}, nil
+		}
+	case ProtobufContentType:
+		var pbEnvelope gojwttokensynctokensmessagepb.TokensMessageEnvelope
+		if err := proto.Unmarshal(body, &pbEnvelope); err != nil {
+			return nil, err
+		}
+		envelope = envelopeFromProto(&pbEnvelope)
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrUnsupportedContentType, contentType)
+	}
+
+	// Check the version and the message ID
+	if err := CheckTokensMessageVersion(envelope.Version); err != nil {
+		return nil, err
+	}
+	if envelope.MessageID == "" {
+		return nil, ErrMissingMessageID
+	}
+	if envelope.Message == nil {
+		envelope.Message = &TokensMessage{}
+	}
+	return envelope, nil
+}
+
+// mediaType returns the media type of a content type without its parameters
+//
+// Parameters:
+//
+//   - contentType: the content type
+//
+// Returns:
+//
+//   - string: the media type
+func mediaType(contentType string) string {
+	parsed, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return contentType
+	}
+	return parsed
+}
+
+// envelopeToProto converts an envelope to its protobuf message
+//
+// Parameters:
+//
+//   - envelope: the envelope
+//
+// Returns:
+//
+//   - *gojwttokensynctokensmessagepb.TokensMessageEnvelope: the protobuf message
+func envelopeToProto(
+	envelope *TokensMessageEnvelope,
+) *gojwttokensynctokensmessagepb.TokensMessageEnvelope {
+	msg := envelope.Message
+	issuedTokenPairs := make(
+		[]*gojwttokensynctokensmessagepb.TokenPair,
+		len(msg.IssuedTokenPairs),
+	)
+	for i, pair := range msg.IssuedTokenPairs {
+		issuedTokenPairs[i] = &gojwttokensynctokensmessagepb.TokenPair{
+			RefreshTokenId:        pair.RefreshTokenID,
+			RefreshTokenExpiresAt: timestamppb.New(pair.RefreshTokenExpiresAt),
+			AccessTokenId:         pair.AccessTokenID,
+			AccessTokenExpiresAt:  timestamppb.New(pair.AccessTokenExpiresAt),
+		}
+	}
+
+	return &gojwttokensynctokensmessagepb.TokensMessageEnvelope{
+		Version:   envelope.Version,
+		MessageId: envelope.MessageID,
+		IssuedAt:  timestamppb.New(envelope.IssuedAt),
+		Producer:  envelope.Producer,
+		Message: &gojwttokensynctokensmessagepb.TokensMessage{
+			IssuedTokenPairs:       issuedTokenPairs,
+			RevokedRefreshTokensId: msg.RevokedRefreshTokensID,
+			RevokedAccessTokensId:  msg.RevokedAccessTokensID,
+		},
+	}
+}
+
+// envelopeFromProto converts a protobuf message to an envelope
+//
+// Parameters:
+//
+//   - pbEnvelope: the protobuf message
+//
+// Returns:
+//
+//   - *TokensMessageEnvelope: the envelope
+func envelopeFromProto(
+	pbEnvelope *gojwttokensynctokensmessagepb.TokensMessageEnvelope,
+) *TokensMessageEnvelope {
+	envelope := &TokensMessageEnvelope{
+		Version:   pbEnvelope.GetVersion(),
+		MessageID: pbEnvelope.GetMessageId(),
+		IssuedAt:  pbEnvelope.GetIssuedAt().AsTime(),
+		Producer:  pbEnvelope.GetProducer(),
+	}
+	pbMsg := pbEnvelope.GetMessage()
+	if pbMsg == nil {
+		return envelope
+	}
+
+	msg := &TokensMessage{
+		IssuedTokenPairs: make(
+			[]TokenPair,
+			len(pbMsg.GetIssuedTokenPairs()),
+		),
+		RevokedRefreshTokensID: pbMsg.GetRevokedRefreshTokensId(),
+		RevokedAccessTokensID:  pbMsg.GetRevokedAccessTokensId(),
+	}
+	for i, pair := range pbMsg.GetIssuedTokenPairs() {
+		msg.IssuedTokenPairs[i] = TokenPair{
+			RefreshTokenID:        pair.GetRefreshTokenId(),
+			RefreshTokenExpiresAt: pair.GetRefreshTokenExpiresAt().AsTime(),
+			AccessTokenID:         pair.GetAccessTokenId(),
+			AccessTokenExpiresAt:  pair.GetAccessTokenExpiresAt().AsTime(),
+		}
+	}
+	envelope.Message = msg
+	return envelope
+}
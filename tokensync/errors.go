@@ -0,0 +1,18 @@
+package tokensync
+
+import (
+	"errors"
+)
+
+var (
+	ErrNilPublisher           = errors.New("nil tokens messages publisher")
+	ErrNilConsumer            = errors.New("nil tokens messages consumer")
+	ErrNilMessage             = errors.New("nil tokens message")
+	ErrClosedPublisher        = errors.New("tokens messages publisher closed")
+	ErrClosedConsumer         = errors.New("tokens messages consumer closed")
+	ErrUnsupportedContentType = errors.New("unsupported tokens message content type")
+	ErrUnsupportedVersion     = errors.New("unsupported tokens message version")
+	ErrMissingMessageID       = errors.New("missing tokens message id")
+	ErrNilOutbox              = errors.New("nil tokens messages outbox")
+	ErrNilOutboxTransaction   = errors.New("nil tokens messages outbox transaction")
+)
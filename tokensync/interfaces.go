@@ -0,0 +1,128 @@
+package tokensync
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	gojwttoken "github.com/ralvarezdev/go-jwt/token"
+	gojwttokenclaims "github.com/ralvarezdev/go-jwt/token/claims"
+)
+
+type (
+	// Publisher is the interface for the transports that publish the tokens messages
+	Publisher interface {
+		Open() error
+		Close() error
+		PublishTokensMessage(msg *TokensMessage) error
+		PublishTokensMessageWithCtx(
+			ctx context.Context,
+			msg *TokensMessage,
+		) error
+		PublishTokensMessageEnvelope(
+			ctx context.Context,
+			envelope *TokensMessageEnvelope,
+		) error
+	}
+
+	// Delivery is the interface for a tokens message delivered by a transport, which must be acknowledged once applied
+	// or rejected to be redelivered
+	Delivery interface {
+		GetEnvelope() *TokensMessageEnvelope
+		Ack() error
+		Nack(cause error) error
+	}
+
+	// TokensMessagesConsumer is the interface for a subscription of a transport, which delivers the tokens messages
+	// to its channel while ConsumeTokensMessages runs
+	TokensMessagesConsumer interface {
+		GetChannel() <-chan Delivery
+		ConsumeTokensMessages(ctx context.Context) error
+	}
+
+	// Consumer is the interface for the transports that consume the tokens messages
+	Consumer interface {
+		Open() error
+		Close() error
+		CreateTokensMessagesConsumer(ctx context.Context) (
+			TokensMessagesConsumer,
+			error,
+		)
+	}
+
+	// Deduplicator is the interface for the store of the IDs of the applied tokens messages, used to discard the
+	// duplicated deliveries
+	Deduplicator interface {
+		IsDuplicate(messageID string) bool
+		MarkApplied(messageID string)
+	}
+
+	// RevocationStore is the interface for the store of the revoked token IDs, which keeps the revocations sticky so
+	// a redelivered or reordered issue of a revoked token does not restore it
+	RevocationStore interface {
+		MarkRevoked(
+			ctx context.Context,
+			token gojwttoken.Token,
+			ids []string,
+		) error
+		IsRevoked(
+			ctx context.Context,
+			token gojwttoken.Token,
+			id string,
+		) (bool, error)
+	}
+
+	// Service is the interface for the service that applies the consumed tokens messages to a token validator
+	Service interface {
+		gojwttokenclaims.TokenValidator
+		Start(ctx context.Context) error
+	}
+
+	// EventSource is the interface for the authoritative source of the tokens messages, used by the consumers to catch
+	// up with the messages they missed while offline. The messages are ordered by their issue time and message ID, and
+	// paged with the issue time and message ID of the last received message, so the messages that share an issue time
+	// are never skipped
+	EventSource interface {
+		GetTokensMessagesSince(
+			ctx context.Context,
+			since time.Time,
+			afterMessageID string,
+			limit int,
+		) ([]*TokensMessageEnvelope, bool, error)
+	}
+
+	// EventLog is the interface for the log of the published tokens messages kept by the authoritative service
+	EventLog interface {
+		EventSource
+		AppendTokensMessage(
+			ctx context.Context,
+			envelope *TokensMessageEnvelope,
+		) error
+	}
+
+	// Outbox is the interface for the local store that holds the tokens messages until the transport confirms them.
+	// The messages are stored in their envelope, so they keep their message ID when published again
+	Outbox interface {
+		Add(
+			ctx context.Context,
+			envelope *TokensMessageEnvelope,
+		) (int64, error)
+		Remove(ctx context.Context, id int64) error
+		List(ctx context.Context, limit int) ([]OutboxEntry, error)
+	}
+
+	// TransactionalOutbox is the interface for the outbox stored in the database of the issuing service, so the tokens
+	// messages are added in the same transaction as the token changes they describe. The published messages are
+	// marked as sent instead of being removed, List only lists the unsent ones, and DeleteSentBefore purges the sent
+	// ones
+	TransactionalOutbox interface {
+		Outbox
+		AddWithTx(
+			ctx context.Context,
+			tx *sql.Tx,
+			envelope *TokensMessageEnvelope,
+		) (int64, error)
+		MarkSent(ctx context.Context, id int64) error
+		DeleteSentBefore(ctx context.Context, before time.Time) error
+	}
+)
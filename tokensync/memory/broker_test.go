@@ -0,0 +1,183 @@
+package memory_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	gojwttokensync "github.com/ralvarezdev/go-jwt/tokensync"
+	gojwttokensyncmemory "github.com/ralvarezdev/go-jwt/tokensync/memory"
+)
+
+// receive receives a delivery from the given subscription
+//
+// Parameters:
+//
+//   - t: The test
+//   - subscription: The subscription
+//
+// Returns:
+//
+//   - gojwttokensync.Delivery: The delivery
+func receive(
+	t *testing.T,
+	subscription gojwttokensync.TokensMessagesConsumer,
+) gojwttokensync.Delivery {
+	t.Helper()
+
+	select {
+	case delivery := <-subscription.GetChannel():
+		return delivery
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for a delivery")
+		return nil
+	}
+}
+
+// expectNoDelivery checks that the given subscription does not receive a delivery
+//
+// Parameters:
+//
+//   - t: The test
+//   - subscription: The subscription
+func expectNoDelivery(
+	t *testing.T,
+	subscription gojwttokensync.TokensMessagesConsumer,
+) {
+	t.Helper()
+
+	select {
+	case delivery := <-subscription.GetChannel():
+		t.Fatalf("unexpected delivery of message %q", delivery.GetEnvelope().MessageID)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestBroker_Publish(t *testing.T) {
+	ctx := context.Background()
+	broker := gojwttokensyncmemory.NewBroker(nil, nil)
+	publisher := broker.NewPublisher("test")
+
+	// Subscribe two consumers, so both receive the message
+	first, err := broker.NewConsumer().CreateTokensMessagesConsumer(ctx)
+	if err != nil {
+		t.Fatalf("CreateTokensMessagesConsumer() error = %v", err)
+	}
+	second, err := broker.NewConsumer().CreateTokensMessagesConsumer(ctx)
+	if err != nil {
+		t.Fatalf("CreateTokensMessagesConsumer() error = %v", err)
+	}
+
+	msg := &gojwttokensync.TokensMessage{RevokedAccessTokensID: []string{"access"}}
+	if err = publisher.PublishTokensMessageWithCtx(ctx, msg); err != nil {
+		t.Fatalf("PublishTokensMessageWithCtx() error = %v", err)
+	}
+	for _, subscription := range []gojwttokensync.TokensMessagesConsumer{first, second} {
+		envelope := receive(t, subscription).GetEnvelope()
+		if envelope.Producer != "test" || envelope.MessageID == "" {
+			t.Errorf("envelope = %+v, want a message ID and the test producer", envelope)
+		}
+		if len(envelope.Message.RevokedAccessTokensID) != 1 {
+			t.Errorf("message = %+v, want the published message", envelope.Message)
+		}
+	}
+
+	// A subscription made after the publish does not receive the message
+	late, err := broker.NewConsumer().CreateTokensMessagesConsumer(ctx)
+	if err != nil {
+		t.Fatalf("CreateTokensMessagesConsumer() error = %v", err)
+	}
+	expectNoDelivery(t, late)
+
+	// A closed publisher cannot publish
+	if err = publisher.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	if err = publisher.PublishTokensMessageWithCtx(ctx, msg); !errors.Is(
+		err,
+		gojwttokensync.ErrClosedPublisher,
+	) {
+		t.Errorf("PublishTokensMessageWithCtx() error = %v, want %v", err, gojwttokensync.ErrClosedPublisher)
+	}
+}
+
+func TestConsumer_CreateTokensMessagesConsumer(t *testing.T) {
+	ctx := context.Background()
+	broker := gojwttokensyncmemory.NewBroker(nil, nil)
+	consumer := broker.NewConsumer()
+
+	previous, err := consumer.CreateTokensMessagesConsumer(ctx)
+	if err != nil {
+		t.Fatalf("CreateTokensMessagesConsumer() error = %v", err)
+	}
+	if _, err = consumer.CreateTokensMessagesConsumer(ctx); err != nil {
+		t.Fatalf("CreateTokensMessagesConsumer() error = %v", err)
+	}
+
+	// The previous subscription is closed by the new one
+	if err = previous.ConsumeTokensMessages(ctx); !errors.Is(
+		err,
+		gojwttokensync.ErrClosedConsumer,
+	) {
+		t.Errorf("ConsumeTokensMessages() error = %v, want %v", err, gojwttokensync.ErrClosedConsumer)
+	}
+}
+
+func TestDelivery_Nack(t *testing.T) {
+	tests := []struct {
+		name             string
+		maxRedeliveries  int
+		wantRedeliveries int
+	}{
+		{
+			name:             "default",
+			wantRedeliveries: gojwttokensync.DefaultMaxRedeliveries,
+		},
+		{
+			name:             "custom",
+			maxRedeliveries:  2,
+			wantRedeliveries: 2,
+		},
+		{
+			name:            "never redelivered",
+			maxRedeliveries: -1,
+		},
+	}
+	for _, test := range tests {
+		t.Run(
+			test.name, func(t *testing.T) {
+				ctx := context.Background()
+				broker := gojwttokensyncmemory.NewBroker(
+					&gojwttokensyncmemory.BrokerOptions{MaxRedeliveries: test.maxRedeliveries},
+					nil,
+				)
+				subscription, err := broker.NewConsumer().CreateTokensMessagesConsumer(ctx)
+				if err != nil {
+					t.Fatalf("CreateTokensMessagesConsumer() error = %v", err)
+				}
+				msg := &gojwttokensync.TokensMessage{RevokedAccessTokensID: []string{"access"}}
+				if err = broker.NewPublisher("test").PublishTokensMessageWithCtx(ctx, msg); err != nil {
+					t.Fatalf("PublishTokensMessageWithCtx() error = %v", err)
+				}
+
+				// Reject the first delivery and every redelivery
+				cause := errors.New("apply failed")
+				if err = receive(t, subscription).Nack(cause); err != nil {
+					t.Fatalf("Nack() error = %v", err)
+				}
+				for range test.wantRedeliveries {
+					if err = receive(t, subscription).Nack(cause); err != nil {
+						t.Fatalf("Nack() error = %v", err)
+					}
+				}
+				expectNoDelivery(t, subscription)
+
+				// The message is dead-lettered once the redeliveries are exhausted
+				if deadLetters := broker.DeadLetters(); len(deadLetters) != 1 {
+					t.Errorf("DeadLetters() = %d messages, want 1", len(deadLetters))
+				}
+			},
+		)
+	}
+}
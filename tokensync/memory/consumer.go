@@ -0,0 +1,199 @@
+package memory
+
+import (
+	"context"
+
+	gojwttokensync "github.com/ralvarezdev/go-jwt/tokensync"
+)
+
+// Open opens the consumer, which is a no-op
+//
+// Returns:
+//
+//   - error: an error if the consumer is nil
+func (c *Consumer) Open() error {
+	// Check if the consumer is nil
+	if c == nil {
+		return gojwttokensync.ErrNilConsumer
+	}
+	return nil
+}
+
+// Close closes the current subscription of the consumer
+//
+// Returns:
+//
+//   - error: an error if the consumer is nil
+func (c *Consumer) Close() error {
+	// Check if the consumer is nil
+	if c == nil {
+		return gojwttokensync.ErrNilConsumer
+	}
+
+	c.mutex.Lock()
+	subscription := c.subscription
+	c.subscription = nil
+	c.mutex.Unlock()
+
+	if subscription != nil {
+		subscription.close()
+	}
+	return nil
+}
+
+// CreateTokensMessagesConsumer subscribes to the broker, closing the previous subscription of the consumer. Only the
+// messages published after the subscription are delivered
+//
+// Parameters:
+//
+//   - ctx: the context
+//
+// Returns:
+//
+//   - gojwttokensync.TokensMessagesConsumer: the subscription
+//   - error: an error if the consumer is nil
+func (c *Consumer) CreateTokensMessagesConsumer(ctx context.Context) (
+	gojwttokensync.TokensMessagesConsumer,
+	error,
+) {
+	// Check if the consumer is nil
+	if c == nil {
+		return nil, gojwttokensync.ErrNilConsumer
+	}
+
+	subscription := c.broker.subscribe()
+
+	c.mutex.Lock()
+	previous := c.subscription
+	c.subscription = subscription
+	c.mutex.Unlock()
+
+	if previous != nil {
+		previous.close()
+	}
+	return subscription, nil
+}
+
+// GetChannel returns the tokens messages deliveries channel
+//
+// Returns:
+//
+//   - <-chan gojwttokensync.Delivery: the tokens messages deliveries channel
+func (t *TokensMessagesConsumer) GetChannel() <-chan gojwttokensync.Delivery {
+	return t.deliveriesCh
+}
+
+// ConsumeTokensMessages waits until the subscription is closed, since the broker sends the messages directly to the
+// deliveries channel
+//
+// Parameters:
+//
+//   - ctx: the context
+//
+// Returns:
+//
+//   - error: the context error, or gojwttokensync.ErrClosedConsumer once the subscription is closed
+func (t *TokensMessagesConsumer) ConsumeTokensMessages(ctx context.Context) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.done:
+		return gojwttokensync.ErrClosedConsumer
+	}
+}
+
+// deliver sends a delivery to the deliveries channel
+//
+// Parameters:
+//
+//   - ctx: the context
+//   - delivery: the delivery
+//
+// Returns:
+//
+//   - error: the context error if the context is done before the delivery is sent
+func (t *TokensMessagesConsumer) deliver(
+	ctx context.Context,
+	delivery *Delivery,
+) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.done:
+		// The messages of a closed subscription are discarded
+		return nil
+	case t.deliveriesCh <- delivery:
+		return nil
+	}
+}
+
+// close closes the subscription
+func (t *TokensMessagesConsumer) close() {
+	t.closeOnce.Do(
+		func() {
+			t.broker.unsubscribe(t)
+			close(t.done)
+		},
+	)
+}
+
+// GetEnvelope returns the envelope of the delivered tokens message
+//
+// Returns:
+//
+//   - *gojwttokensync.TokensMessageEnvelope: the envelope
+func (d *Delivery) GetEnvelope() *gojwttokensync.TokensMessageEnvelope {
+	// Check if the delivery is nil
+	if d == nil {
+		return nil
+	}
+	return d.envelope
+}
+
+// Ack acknowledges the delivery, which is a no-op since the broker does not keep the delivered messages
+//
+// Returns:
+//
+//   - error: an error if the delivery is nil
+func (d *Delivery) Ack() error {
+	// Check if the delivery is nil
+	if d == nil {
+		return gojwttokensync.ErrNilMessage
+	}
+	return nil
+}
+
+// Nack rejects the delivery of a tokens message that could not be applied. The message is redelivered to the same
+// subscription until the maximum number of redeliveries is reached, and then dead-lettered
+//
+// Parameters:
+//
+//   - cause: the error that prevented the message from being applied
+//
+// Returns:
+//
+//   - error: an error if the delivery is nil
+func (d *Delivery) Nack(cause error) error {
+	// Check if the delivery is nil
+	if d == nil {
+		return gojwttokensync.ErrNilMessage
+	}
+
+	// Check if the maximum number of redeliveries is reached
+	broker := d.subscription.broker
+	if d.redeliveries >= broker.maxRedeliveries {
+		broker.deadLetter(d.envelope, cause)
+		return nil
+	}
+
+	// Redeliver the message without blocking the caller, which may be the reader of the deliveries channel
+	redelivery := &Delivery{
+		envelope:     d.envelope,
+		subscription: d.subscription,
+		redeliveries: d.redeliveries + 1,
+	}
+	go func() {
+		_ = d.subscription.deliver(context.Background(), redelivery)
+	}()
+	return nil
+}
@@ -0,0 +1,113 @@
+package memory
+
+import (
+	"context"
+
+	gojwttokensync "github.com/ralvarezdev/go-jwt/tokensync"
+)
+
+// Open opens the publisher, which is a no-op
+//
+// Returns:
+//
+//   - error: an error if the publisher is nil
+func (p *Publisher) Open() error {
+	// Check if the publisher is nil
+	if p == nil {
+		return gojwttokensync.ErrNilPublisher
+	}
+	return nil
+}
+
+// Close closes the publisher, so the messages published afterward are rejected
+//
+// Returns:
+//
+//   - error: an error if the publisher is nil
+func (p *Publisher) Close() error {
+	// Check if the publisher is nil
+	if p == nil {
+		return gojwttokensync.ErrNilPublisher
+	}
+
+	p.mutex.Lock()
+	p.closed = true
+	p.mutex.Unlock()
+	return nil
+}
+
+// PublishTokensMessage publishes a tokens message to the broker
+//
+// Parameters:
+//
+//   - msg: the tokens message to publish
+//
+// Returns:
+//
+//   - error: an error if the message could not be published
+func (p *Publisher) PublishTokensMessage(msg *gojwttokensync.TokensMessage) error {
+	return p.PublishTokensMessageWithCtx(context.Background(), msg)
+}
+
+// PublishTokensMessageWithCtx publishes a tokens message in a new envelope to the broker
+//
+// Parameters:
+//
+//   - ctx: the context
+//   - msg: the tokens message to publish
+//
+// Returns:
+//
+//   - error: an error if the message could not be published
+func (p *Publisher) PublishTokensMessageWithCtx(
+	ctx context.Context,
+	msg *gojwttokensync.TokensMessage,
+) error {
+	// Check if the publisher is nil
+	if p == nil {
+		return gojwttokensync.ErrNilPublisher
+	}
+
+	// Wrap the message in its envelope
+	envelope, err := gojwttokensync.NewTokensMessageEnvelope(msg, p.producer)
+	if err != nil {
+		return err
+	}
+	return p.PublishTokensMessageEnvelope(ctx, envelope)
+}
+
+// PublishTokensMessageEnvelope publishes a tokens message envelope to the broker, which returns once every
+// subscription has received it
+//
+// Parameters:
+//
+//   - ctx: the context
+//   - envelope: the tokens message envelope to publish
+//
+// Returns:
+//
+//   - error: an error if the publisher is closed, or the context is done before the message is delivered
+func (p *Publisher) PublishTokensMessageEnvelope(
+	ctx context.Context,
+	envelope *gojwttokensync.TokensMessageEnvelope,
+) error {
+	// Check if the publisher is nil
+	if p == nil {
+		return gojwttokensync.ErrNilPublisher
+	}
+
+	// Check if the envelope is nil
+	if envelope == nil || envelope.Message == nil {
+		return gojwttokensync.ErrNilMessage
+	}
+
+	// Check if the publisher is closed
+	p.mutex.Lock()
+	closed := p.closed
+	p.mutex.Unlock()
+	if closed {
+		return gojwttokensync.ErrClosedPublisher
+	}
+
+	return p.broker.publish(ctx, envelope)
+}
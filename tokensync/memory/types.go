@@ -0,0 +1,225 @@
+package memory
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+
+	gojwttokensync "github.com/ralvarezdev/go-jwt/tokensync"
+)
+
+type (
+	// BrokerOptions are the options for the Broker
+	BrokerOptions struct {
+		// BufferSize is the buffer size of the deliveries channel of each subscription (optional,
+		// gojwttokensync.DefaultDeliveriesBufferSize is used if not positive)
+		BufferSize int
+
+		// MaxRedeliveries is the number of times a rejected message is redelivered before being dead-lettered
+		// (optional, gojwttokensync.DefaultMaxRedeliveries is used if zero, and it is never redelivered if negative)
+		MaxRedeliveries int
+	}
+
+	// Broker is an in-process transport of the tokens messages, which delivers every published message to every
+	// subscription made at the time of the publish, such as the consumers of the replicas of a service in a test
+	Broker struct {
+		subscriptions   map[*TokensMessagesConsumer]struct{}
+		deadLetters     []*gojwttokensync.TokensMessageEnvelope
+		bufferSize      int
+		maxRedeliveries int
+		logger          *slog.Logger
+		mutex           sync.Mutex
+	}
+
+	// Publisher is the in-process implementation of the Publisher interface
+	Publisher struct {
+		broker   *Broker
+		producer string
+		closed   bool
+		mutex    sync.Mutex
+	}
+
+	// Consumer is the in-process implementation of the Consumer interface
+	Consumer struct {
+		broker       *Broker
+		subscription *TokensMessagesConsumer
+		mutex        sync.Mutex
+	}
+
+	// TokensMessagesConsumer is the in-process implementation of the TokensMessagesConsumer interface
+	TokensMessagesConsumer struct {
+		broker       *Broker
+		deliveriesCh chan gojwttokensync.Delivery
+		done         chan struct{}
+		closeOnce    sync.Once
+	}
+
+	// Delivery is a tokens message delivered by the broker to a subscription
+	Delivery struct {
+		envelope     *gojwttokensync.TokensMessageEnvelope
+		subscription *TokensMessagesConsumer
+		redeliveries int
+	}
+)
+
+// NewBroker creates a new Broker
+//
+// Parameters:
+//
+//   - options: the options (optional, can be nil)
+//   - logger: the logger (optional, can be nil)
+//
+// Returns:
+//
+//   - *Broker: the Broker instance
+func NewBroker(options *BrokerOptions, logger *slog.Logger) *Broker {
+	// Set the options
+	if options == nil {
+		options = &BrokerOptions{}
+	}
+
+	bufferSize := options.BufferSize
+	if bufferSize <= 0 {
+		bufferSize = gojwttokensync.DefaultDeliveriesBufferSize
+	}
+
+	maxRedeliveries := gojwttokensync.GetMaxRedeliveries(options.MaxRedeliveries)
+
+	if logger != nil {
+		logger = logger.With(
+			slog.String("component", "jwt_tokensync_memory_broker"),
+		)
+	}
+
+	return &Broker{
+		subscriptions:   make(map[*TokensMessagesConsumer]struct{}),
+		bufferSize:      bufferSize,
+		maxRedeliveries: maxRedeliveries,
+		logger:          logger,
+	}
+}
+
+// NewPublisher creates a new Publisher of the broker
+//
+// Parameters:
+//
+//   - producer: the name of the service that publishes the messages, set in their envelope (optional)
+//
+// Returns:
+//
+//   - *Publisher: the Publisher instance
+func (b *Broker) NewPublisher(producer string) *Publisher {
+	return &Publisher{
+		broker:   b,
+		producer: producer,
+	}
+}
+
+// NewConsumer creates a new Consumer of the broker
+//
+// Returns:
+//
+//   - *Consumer: the Consumer instance
+func (b *Broker) NewConsumer() *Consumer {
+	return &Consumer{broker: b}
+}
+
+// DeadLetters returns the messages dead-lettered after being rejected too many times
+//
+// Returns:
+//
+//   - []*gojwttokensync.TokensMessageEnvelope: the dead-lettered messages
+func (b *Broker) DeadLetters() []*gojwttokensync.TokensMessageEnvelope {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	return append([]*gojwttokensync.TokensMessageEnvelope(nil), b.deadLetters...)
+}
+
+// publish delivers a tokens message envelope to every subscription, waiting for their buffer to have room
+//
+// Parameters:
+//
+//   - ctx: the context
+//   - envelope: the tokens message envelope
+//
+// Returns:
+//
+//   - error: the context error if the context is done before the message is delivered
+func (b *Broker) publish(
+	ctx context.Context,
+	envelope *gojwttokensync.TokensMessageEnvelope,
+) error {
+	// Get the current subscriptions
+	b.mutex.Lock()
+	subscriptions := make([]*TokensMessagesConsumer, 0, len(b.subscriptions))
+	for subscription := range b.subscriptions {
+		subscriptions = append(subscriptions, subscription)
+	}
+	b.mutex.Unlock()
+
+	for _, subscription := range subscriptions {
+		if err := subscription.deliver(
+			ctx,
+			&Delivery{
+				envelope:     envelope,
+				subscription: subscription,
+			},
+		); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// subscribe creates a new subscription
+//
+// Returns:
+//
+//   - *TokensMessagesConsumer: the subscription
+func (b *Broker) subscribe() *TokensMessagesConsumer {
+	subscription := &TokensMessagesConsumer{
+		broker:       b,
+		deliveriesCh: make(chan gojwttokensync.Delivery, b.bufferSize),
+		done:         make(chan struct{}),
+	}
+
+	b.mutex.Lock()
+	b.subscriptions[subscription] = struct{}{}
+	b.mutex.Unlock()
+	return subscription
+}
+
+// unsubscribe removes a subscription
+//
+// Parameters:
+//
+//   - subscription: the subscription
+func (b *Broker) unsubscribe(subscription *TokensMessagesConsumer) {
+	b.mutex.Lock()
+	delete(b.subscriptions, subscription)
+	b.mutex.Unlock()
+}
+
+// deadLetter stores a message rejected too many times
+//
+// Parameters:
+//
+//   - envelope: the tokens message envelope
+//   - cause: the error that prevented the message from being applied
+func (b *Broker) deadLetter(
+	envelope *gojwttokensync.TokensMessageEnvelope,
+	cause error,
+) {
+	b.mutex.Lock()
+	b.deadLetters = append(b.deadLetters, envelope)
+	b.mutex.Unlock()
+
+	if b.logger != nil {
+		b.logger.Warn(
+			"Message dead-lettered",
+			slog.String("message_id", envelope.MessageID),
+			slog.Any("cause", cause),
+		)
+	}
+}
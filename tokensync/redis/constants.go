@@ -0,0 +1,38 @@
+package redis
+
+import (
+	"time"
+)
+
+const (
+	// BodyField is the stream entry field that holds the encoded tokens message envelope
+	BodyField = "body"
+
+	// ContentTypeField is the stream entry field that holds the content type of the body
+	ContentTypeField = "content_type"
+
+	// DeadLetterReasonField is the dead-letter stream entry field that holds the reason why the message was
+	// dead-lettered
+	DeadLetterReasonField = "dead_letter_reason"
+
+	// DeadLetterStreamSuffix is the suffix appended to the stream name to get its dead-letter stream name
+	DeadLetterStreamSuffix = ":dlq"
+
+	// NewMessagesStartID is the ID from which a new consumer group reads only the messages added after its creation
+	NewMessagesStartID = "$"
+
+	// busyGroupErrorPrefix is the prefix of the error returned by Redis when the consumer group already exists
+	busyGroupErrorPrefix = "BUSYGROUP"
+)
+
+var (
+	// DefaultBlockTimeout is the default time a read waits for new messages
+	DefaultBlockTimeout = 5 * time.Second
+
+	// DefaultReadCount is the default maximum number of messages read at once
+	DefaultReadCount int64 = 10
+
+	// DefaultClaimMinIdle is the default time a delivered message stays unacknowledged before being claimed again,
+	// such as after a rejection or the crash of its consumer
+	DefaultClaimMinIdle = time.Minute
+)
@@ -0,0 +1,531 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"time"
+
+	gojwttokensync "github.com/ralvarezdev/go-jwt/tokensync"
+	"github.com/redis/go-redis/v9"
+)
+
+type (
+	// ConsumerOptions are the options for the Consumer
+	ConsumerOptions struct {
+		// BufferSize is the buffer size of the deliveries channel (optional,
+		// gojwttokensync.DefaultDeliveriesBufferSize is used if not positive)
+		BufferSize int
+
+		// ReadCount is the maximum number of messages read at once (optional, DefaultReadCount is used if not
+		// positive)
+		ReadCount int64
+
+		// BlockTimeout is the time a read waits for new messages (optional, DefaultBlockTimeout is used if not
+		// positive)
+		BlockTimeout time.Duration
+
+		// ClaimMinIdle is the time a delivered message stays unacknowledged before being claimed again (optional,
+		// DefaultClaimMinIdle is used if not positive)
+		ClaimMinIdle time.Duration
+
+		// MaxRedeliveries is the number of times a message is claimed again before being dead-lettered (optional,
+		// gojwttokensync.DefaultMaxRedeliveries is used if zero, and it is never claimed again if negative)
+		MaxRedeliveries int
+
+		// StartID is the ID from which a new consumer group reads the stream (optional, NewMessagesStartID is used if
+		// empty)
+		StartID string
+
+		// Deduplicator discards the deliveries of already applied messages (optional, a
+		// gojwttokensync.MemoryDeduplicator is used if nil)
+		Deduplicator gojwttokensync.Deduplicator
+	}
+
+	// Consumer is the Redis Streams implementation of the Consumer interface. The consumers of the same group share
+	// the messages, like the replicas of a work queue, while every group reads every message, so each replica that
+	// must hold every token should use its own group
+	Consumer struct {
+		redisClient     *redis.Client
+		stream          string
+		group           string
+		consumerName    string
+		bufferSize      int
+		readCount       int64
+		blockTimeout    time.Duration
+		claimMinIdle    time.Duration
+		maxRedeliveries int
+		startID         string
+		deduplicator    gojwttokensync.Deduplicator
+		logger          *slog.Logger
+	}
+
+	// TokensMessagesConsumer is the Redis Streams implementation of the TokensMessagesConsumer interface
+	TokensMessagesConsumer struct {
+		consumer     *Consumer
+		deliveriesCh chan gojwttokensync.Delivery
+		lastClaimAt  time.Time
+	}
+
+	// Delivery is a tokens message read from a stream by a consumer group, which stays pending until acknowledged
+	Delivery struct {
+		envelope *gojwttokensync.TokensMessageEnvelope
+		id       string
+		consumer *Consumer
+	}
+)
+
+// NewConsumer creates a new Consumer
+//
+// Parameters:
+//
+//   - redisClient: the Redis client
+//   - stream: the name of the stream
+//   - group: the name of the consumer group
+//   - consumerName: the name of the consumer within its group, which must be stable across restarts
+//   - options: the options (optional, can be nil)
+//   - logger: the logger (optional, can be nil)
+//
+// Returns:
+//
+//   - *Consumer: the Consumer instance
+//   - error: an error if the Redis client is nil, or the stream, group or consumer name is empty
+func NewConsumer(
+	redisClient *redis.Client,
+	stream, group, consumerName string,
+	options *ConsumerOptions,
+	logger *slog.Logger,
+) (*Consumer, error) {
+	// Check if the Redis client is nil
+	if redisClient == nil {
+		return nil, gojwttokensync.ErrNilConsumer
+	}
+
+	// Check if the names are empty
+	if stream == "" {
+		return nil, ErrEmptyStreamName
+	}
+	if group == "" {
+		return nil, ErrEmptyGroupName
+	}
+	if consumerName == "" {
+		return nil, ErrEmptyConsumerName
+	}
+
+	// Set the options
+	if options == nil {
+		options = &ConsumerOptions{}
+	}
+
+	bufferSize := options.BufferSize
+	if bufferSize <= 0 {
+		bufferSize = gojwttokensync.DefaultDeliveriesBufferSize
+	}
+
+	readCount := options.ReadCount
+	if readCount <= 0 {
+		readCount = DefaultReadCount
+	}
+
+	blockTimeout := options.BlockTimeout
+	if blockTimeout <= 0 {
+		blockTimeout = DefaultBlockTimeout
+	}
+
+	claimMinIdle := options.ClaimMinIdle
+	if claimMinIdle <= 0 {
+		claimMinIdle = DefaultClaimMinIdle
+	}
+
+	maxRedeliveries := gojwttokensync.GetMaxRedeliveries(options.MaxRedeliveries)
+
+	startID := options.StartID
+	if startID == "" {
+		startID = NewMessagesStartID
+	}
+
+	deduplicator := options.Deduplicator
+	if deduplicator == nil {
+		deduplicator = gojwttokensync.NewMemoryDeduplicator(
+			gojwttokensync.DefaultDeduplicationCacheSize,
+		)
+	}
+
+	if logger != nil {
+		logger = logger.With(
+			slog.String("component", "jwt_tokensync_redis_consumer"),
+		)
+	}
+
+	return &Consumer{
+		redisClient:     redisClient,
+		stream:          stream,
+		group:           group,
+		consumerName:    consumerName,
+		bufferSize:      bufferSize,
+		readCount:       readCount,
+		blockTimeout:    blockTimeout,
+		claimMinIdle:    claimMinIdle,
+		maxRedeliveries: maxRedeliveries,
+		startID:         startID,
+		deduplicator:    deduplicator,
+		logger:          logger,
+	}, nil
+}
+
+// createGroup creates the consumer group and the stream, if they do not exist
+//
+// Parameters:
+//
+//   - ctx: the context
+//
+// Returns:
+//
+//   - error: an error if the consumer group could not be created
+func (c *Consumer) createGroup(ctx context.Context) error {
+	err := c.redisClient.XGroupCreateMkStream(
+		ctx,
+		c.stream,
+		c.group,
+		c.startID,
+	).Err()
+	if err != nil && !strings.HasPrefix(err.Error(), busyGroupErrorPrefix) {
+		if c.logger != nil {
+			c.logger.Error(
+				"Failed to create the consumer group",
+				slog.String("group", c.group),
+				slog.String("error", err.Error()),
+			)
+		}
+		return err
+	}
+	return nil
+}
+
+// Open creates the consumer group and the stream, if they do not exist
+//
+// Returns:
+//
+//   - error: an error if the consumer group could not be created
+func (c *Consumer) Open() error {
+	// Check if the consumer is nil
+	if c == nil {
+		return gojwttokensync.ErrNilConsumer
+	}
+	return c.createGroup(context.Background())
+}
+
+// Close closes the consumer, which is a no-op since the Redis client is managed by the caller and the reads stop with
+// their context
+//
+// Returns:
+//
+//   - error: an error if the consumer is nil
+func (c *Consumer) Close() error {
+	// Check if the consumer is nil
+	if c == nil {
+		return gojwttokensync.ErrNilConsumer
+	}
+	return nil
+}
+
+// CreateTokensMessagesConsumer creates the consumer group, if it does not exist, and a subscription to it
+//
+// Parameters:
+//
+//   - ctx: the context
+//
+// Returns:
+//
+//   - gojwttokensync.TokensMessagesConsumer: the subscription
+//   - error: an error if the consumer group could not be created
+func (c *Consumer) CreateTokensMessagesConsumer(ctx context.Context) (
+	gojwttokensync.TokensMessagesConsumer,
+	error,
+) {
+	// Check if the consumer is nil
+	if c == nil {
+		return nil, gojwttokensync.ErrNilConsumer
+	}
+
+	if err := c.createGroup(ctx); err != nil {
+		return nil, err
+	}
+	return &TokensMessagesConsumer{
+		consumer:     c,
+		deliveriesCh: make(chan gojwttokensync.Delivery, c.bufferSize),
+	}, nil
+}
+
+// GetChannel returns the tokens messages deliveries channel
+//
+// Returns:
+//
+//   - <-chan gojwttokensync.Delivery: the tokens messages deliveries channel
+func (t *TokensMessagesConsumer) GetChannel() <-chan gojwttokensync.Delivery {
+	return t.deliveriesCh
+}
+
+// ConsumeTokensMessages reads the new messages of the consumer group and sends them to the deliveries channel. The
+// messages left unacknowledged for the minimum idle time, such as the rejected ones or the ones of a crashed consumer,
+// are claimed again, until they reach the maximum number of redeliveries and are dead-lettered
+//
+// Parameters:
+//
+//   - ctx: the context
+//
+// Returns:
+//
+//   - error: the context error, or an error if the stream could not be read
+func (t *TokensMessagesConsumer) ConsumeTokensMessages(ctx context.Context) error {
+	c := t.consumer
+	for {
+		// Claim the idle pending messages
+		if time.Since(t.lastClaimAt) >= c.claimMinIdle {
+			if err := t.claim(ctx); err != nil {
+				return err
+			}
+			t.lastClaimAt = time.Now()
+		}
+
+		// Read the new messages
+		streams, err := c.redisClient.XReadGroup(
+			ctx, &redis.XReadGroupArgs{
+				Group:    c.group,
+				Consumer: c.consumerName,
+				Streams:  []string{c.stream, ">"},
+				Count:    c.readCount,
+				Block:    c.blockTimeout,
+			},
+		).Result()
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if errors.Is(err, redis.Nil) {
+			continue
+		}
+		if err != nil {
+			if c.logger != nil {
+				c.logger.Error(
+					"Failed to read the stream",
+					slog.String("error", err.Error()),
+				)
+			}
+			return err
+		}
+
+		for _, stream := range streams {
+			for _, msg := range stream.Messages {
+				if err = t.handle(ctx, msg); err != nil {
+					return err
+				}
+			}
+		}
+	}
+}
+
+// claim claims the messages of the consumer group left unacknowledged for the minimum idle time, and dead-letters
+// the ones that reached the maximum number of redeliveries
+//
+// Parameters:
+//
+//   - ctx: the context
+//
+// Returns:
+//
+//   - error: an error if the messages could not be claimed or dead-lettered
+func (t *TokensMessagesConsumer) claim(ctx context.Context) error {
+	c := t.consumer
+	pending, err := c.redisClient.XPendingExt(
+		ctx, &redis.XPendingExtArgs{
+			Stream: c.stream,
+			Group:  c.group,
+			Idle:   c.claimMinIdle,
+			Start:  "-",
+			End:    "+",
+			Count:  c.readCount,
+		},
+	).Result()
+	if err != nil {
+		return err
+	}
+
+	var ids []string
+	for _, entry := range pending {
+		// The retry count includes the first delivery
+		if entry.RetryCount <= int64(c.maxRedeliveries) {
+			ids = append(ids, entry.ID)
+			continue
+		}
+
+		// Get the message to dead-letter it, which may have been trimmed from the stream
+		msgs, err := c.redisClient.XRangeN(
+			ctx,
+			c.stream,
+			entry.ID,
+			entry.ID,
+			1,
+		).Result()
+		if err != nil {
+			return err
+		}
+		if len(msgs) == 0 {
+			if err = c.redisClient.XAck(
+				ctx,
+				c.stream,
+				c.group,
+				entry.ID,
+			).Err(); err != nil {
+				return err
+			}
+			continue
+		}
+		if err = c.deadLetter(
+			ctx,
+			msgs[0],
+			"maximum number of redeliveries reached",
+		); err != nil {
+			return err
+		}
+	}
+
+	// Check if there are messages to claim
+	if len(ids) == 0 {
+		return nil
+	}
+
+	msgs, err := c.redisClient.XClaim(
+		ctx, &redis.XClaimArgs{
+			Stream:   c.stream,
+			Group:    c.group,
+			Consumer: c.consumerName,
+			MinIdle:  c.claimMinIdle,
+			Messages: ids,
+		},
+	).Result()
+	if err != nil {
+		return err
+	}
+	for _, msg := range msgs {
+		if err = t.handle(ctx, msg); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// handle decodes a stream entry and sends it to the deliveries channel. The entries that cannot be decoded are
+// dead-lettered, and the duplicates of the applied messages are acknowledged without being sent
+//
+// Parameters:
+//
+//   - ctx: the context
+//   - msg: the stream entry
+//
+// Returns:
+//
+//   - error: the context error, or an error if the entry could not be dead-lettered or acknowledged
+func (t *TokensMessagesConsumer) handle(
+	ctx context.Context,
+	msg redis.XMessage,
+) error {
+	c := t.consumer
+
+	// Decode the envelope
+	body := getStringField(msg.Values, BodyField)
+	if body == "" {
+		return c.deadLetter(ctx, msg, ErrMissingBody.Error())
+	}
+	envelope, err := gojwttokensync.UnmarshalTokensMessageEnvelope(
+		[]byte(body),
+		getStringField(msg.Values, ContentTypeField),
+	)
+	if err != nil {
+		if c.logger != nil {
+			c.logger.Warn(
+				"Failed to decode message, dead-lettering it",
+				slog.String("id", msg.ID),
+				slog.String("error", err.Error()),
+			)
+		}
+		return c.deadLetter(ctx, msg, err.Error())
+	}
+
+	// Discard the duplicates of the applied messages
+	if envelope.MessageID != "" && c.deduplicator.IsDuplicate(envelope.MessageID) {
+		if c.logger != nil {
+			c.logger.Debug(
+				"Discarding duplicated message",
+				slog.String("message_id", envelope.MessageID),
+			)
+		}
+		return c.redisClient.XAck(ctx, c.stream, c.group, msg.ID).Err()
+	}
+
+	// Send the delivery to the channel
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case t.deliveriesCh <- &Delivery{
+		envelope: envelope,
+		id:       msg.ID,
+		consumer: c,
+	}:
+		return nil
+	}
+}
+
+// deadLetter adds a stream entry to the dead-letter stream and acknowledges it, in a single transaction
+//
+// Parameters:
+//
+//   - ctx: the context
+//   - msg: the stream entry
+//   - reason: the reason why the entry is dead-lettered
+//
+// Returns:
+//
+//   - error: an error if the entry could not be dead-lettered
+func (c *Consumer) deadLetter(
+	ctx context.Context,
+	msg redis.XMessage,
+	reason string,
+) error {
+	values := make(map[string]any, len(msg.Values)+1)
+	for field, value := range msg.Values {
+		values[field] = value
+	}
+	values[DeadLetterReasonField] = reason
+
+	if _, err := c.redisClient.TxPipelined(
+		ctx, func(pipe redis.Pipeliner) error {
+			pipe.XAdd(
+				ctx, &redis.XAddArgs{
+					Stream: DeadLetterStreamName(c.stream),
+					Values: values,
+				},
+			)
+			pipe.XAck(ctx, c.stream, c.group, msg.ID)
+			return nil
+		},
+	); err != nil {
+		if c.logger != nil {
+			c.logger.Error(
+				"Failed to dead-letter message",
+				slog.String("id", msg.ID),
+				slog.String("error", err.Error()),
+			)
+		}
+		return err
+	}
+
+	if c.logger != nil {
+		c.logger.Warn(
+			"Message dead-lettered",
+			slog.String("id", msg.ID),
+			slog.String("reason", reason),
+		)
+	}
+	return nil
+}
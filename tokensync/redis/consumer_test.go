@@ -0,0 +1,319 @@
+package redis_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	gojwttokensync "github.com/ralvarezdev/go-jwt/tokensync"
+	gojwttokensyncredis "github.com/ralvarezdev/go-jwt/tokensync/redis"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	// testStream is the name of the stream used by the tests
+	testStream = "tokens"
+
+	// testGroup is the name of the consumer group used by the tests
+	testGroup = "replica"
+)
+
+// startTestConsumer starts a consumer of the test stream on a new Redis server, and returns the publisher of the
+// stream
+//
+// Parameters:
+//
+//   - t: The test
+//   - maxRedeliveries: The maximum number of redeliveries
+//
+// Returns:
+//
+//   - *redis.Client: The Redis client
+//   - *gojwttokensyncredis.Publisher: The publisher
+//   - gojwttokensync.TokensMessagesConsumer: The started subscription
+func startTestConsumer(t *testing.T, maxRedeliveries int) (
+	*redis.Client,
+	*gojwttokensyncredis.Publisher,
+	gojwttokensync.TokensMessagesConsumer,
+) {
+	t.Helper()
+
+	server := miniredis.RunT(t)
+	redisClient := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	t.Cleanup(
+		func() {
+			_ = redisClient.Close()
+		},
+	)
+
+	publisher, err := gojwttokensyncredis.NewPublisher(
+		redisClient,
+		testStream,
+		&gojwttokensyncredis.PublisherOptions{Producer: "test"},
+		nil,
+	)
+	if err != nil {
+		t.Fatalf("NewPublisher() error = %v", err)
+	}
+	consumer, err := gojwttokensyncredis.NewConsumer(
+		redisClient,
+		testStream,
+		testGroup,
+		"consumer",
+		&gojwttokensyncredis.ConsumerOptions{
+			BlockTimeout:    10 * time.Millisecond,
+			ClaimMinIdle:    20 * time.Millisecond,
+			MaxRedeliveries: maxRedeliveries,
+		},
+		nil,
+	)
+	if err != nil {
+		t.Fatalf("NewConsumer() error = %v", err)
+	}
+
+	// Subscribe and consume until the end of the test
+	ctx, cancel := context.WithCancel(context.Background())
+	subscription, err := consumer.CreateTokensMessagesConsumer(ctx)
+	if err != nil {
+		cancel()
+		t.Fatalf("CreateTokensMessagesConsumer() error = %v", err)
+	}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_ = subscription.ConsumeTokensMessages(ctx)
+	}()
+	t.Cleanup(
+		func() {
+			cancel()
+			<-done
+		},
+	)
+	return redisClient, publisher, subscription
+}
+
+// receive receives a delivery from the given subscription
+//
+// Parameters:
+//
+//   - t: The test
+//   - subscription: The subscription
+//
+// Returns:
+//
+//   - gojwttokensync.Delivery: The delivery
+func receive(
+	t *testing.T,
+	subscription gojwttokensync.TokensMessagesConsumer,
+) gojwttokensync.Delivery {
+	t.Helper()
+
+	select {
+	case delivery := <-subscription.GetChannel():
+		return delivery
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for a delivery")
+		return nil
+	}
+}
+
+// expectNoDelivery checks that the given subscription does not receive a delivery
+//
+// Parameters:
+//
+//   - t: The test
+//   - subscription: The subscription
+func expectNoDelivery(
+	t *testing.T,
+	subscription gojwttokensync.TokensMessagesConsumer,
+) {
+	t.Helper()
+
+	select {
+	case delivery := <-subscription.GetChannel():
+		t.Fatalf("unexpected delivery of message %q", delivery.GetEnvelope().MessageID)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+// waitFor waits until the given condition is met
+//
+// Parameters:
+//
+//   - t: The test
+//   - description: The description of the condition
+//   - condition: The condition
+func waitFor(t *testing.T, description string, condition func() bool) {
+	t.Helper()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for !condition() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", description)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// pendingCount returns the number of pending entries of the test consumer group
+//
+// Parameters:
+//
+//   - t: The test
+//   - redisClient: The Redis client
+//
+// Returns:
+//
+//   - int64: The number of pending entries
+func pendingCount(t *testing.T, redisClient *redis.Client) int64 {
+	t.Helper()
+
+	pending, err := redisClient.XPending(context.Background(), testStream, testGroup).Result()
+	if err != nil {
+		t.Fatalf("XPending() error = %v", err)
+	}
+	return pending.Count
+}
+
+// deadLetterCount returns the number of entries of the dead-letter stream of the test stream
+//
+// Parameters:
+//
+//   - t: The test
+//   - redisClient: The Redis client
+//
+// Returns:
+//
+//   - int64: The number of dead-lettered entries
+func deadLetterCount(t *testing.T, redisClient *redis.Client) int64 {
+	t.Helper()
+
+	count, err := redisClient.XLen(
+		context.Background(),
+		gojwttokensyncredis.DeadLetterStreamName(testStream),
+	).Result()
+	if err != nil && !errors.Is(err, redis.Nil) {
+		t.Fatalf("XLen() error = %v", err)
+	}
+	return count
+}
+
+func TestConsumer_Ack(t *testing.T) {
+	ctx := context.Background()
+	redisClient, publisher, subscription := startTestConsumer(t, 0)
+
+	envelope, err := gojwttokensync.NewTokensMessageEnvelope(
+		&gojwttokensync.TokensMessage{RevokedAccessTokensID: []string{"access"}},
+		"test",
+	)
+	if err != nil {
+		t.Fatalf("NewTokensMessageEnvelope() error = %v", err)
+	}
+	if err = publisher.PublishTokensMessageEnvelope(ctx, envelope); err != nil {
+		t.Fatalf("PublishTokensMessageEnvelope() error = %v", err)
+	}
+
+	// The acknowledged entry is no longer pending
+	delivery := receive(t, subscription)
+	if delivery.GetEnvelope().MessageID != envelope.MessageID {
+		t.Fatalf("delivered message %q, want %q", delivery.GetEnvelope().MessageID, envelope.MessageID)
+	}
+	if err = delivery.Ack(); err != nil {
+		t.Fatalf("Ack() error = %v", err)
+	}
+	if count := pendingCount(t, redisClient); count != 0 {
+		t.Errorf("pending entries = %d, want 0", count)
+	}
+
+	// A duplicate of the applied message is acknowledged without being delivered
+	if err = publisher.PublishTokensMessageEnvelope(ctx, envelope); err != nil {
+		t.Fatalf("PublishTokensMessageEnvelope() error = %v", err)
+	}
+	expectNoDelivery(t, subscription)
+	if count := pendingCount(t, redisClient); count != 0 {
+		t.Errorf("pending entries = %d, want 0", count)
+	}
+}
+
+func TestConsumer_Nack(t *testing.T) {
+	tests := []struct {
+		name             string
+		maxRedeliveries  int
+		wantRedeliveries int
+	}{
+		{
+			name:             "default",
+			wantRedeliveries: gojwttokensync.DefaultMaxRedeliveries,
+		},
+		{
+			name:             "custom",
+			maxRedeliveries:  1,
+			wantRedeliveries: 1,
+		},
+		{
+			name:            "never redelivered",
+			maxRedeliveries: -1,
+		},
+	}
+	for _, test := range tests {
+		t.Run(
+			test.name, func(t *testing.T) {
+				redisClient, publisher, subscription := startTestConsumer(t, test.maxRedeliveries)
+				if err := publisher.PublishTokensMessage(
+					&gojwttokensync.TokensMessage{RevokedAccessTokensID: []string{"access"}},
+				); err != nil {
+					t.Fatalf("PublishTokensMessage() error = %v", err)
+				}
+
+				// Reject the first delivery and every redelivery
+				cause := errors.New("apply failed")
+				for range test.wantRedeliveries + 1 {
+					if err := receive(t, subscription).Nack(cause); err != nil {
+						t.Fatalf("Nack() error = %v", err)
+					}
+				}
+
+				// The entry is dead-lettered once the redeliveries are exhausted
+				waitFor(
+					t, "the entry to be dead-lettered", func() bool {
+						return deadLetterCount(t, redisClient) == 1
+					},
+				)
+				expectNoDelivery(t, subscription)
+				if count := pendingCount(t, redisClient); count != 0 {
+					t.Errorf("pending entries = %d, want 0", count)
+				}
+			},
+		)
+	}
+}
+
+func TestConsumer_UndecodableEntry(t *testing.T) {
+	ctx := context.Background()
+	redisClient, _, subscription := startTestConsumer(t, 0)
+
+	if err := redisClient.XAdd(
+		ctx, &redis.XAddArgs{
+			Stream: testStream,
+			Values: map[string]any{
+				gojwttokensyncredis.BodyField:        "not an envelope",
+				gojwttokensyncredis.ContentTypeField: gojwttokensync.JSONContentType,
+			},
+		},
+	).Err(); err != nil {
+		t.Fatalf("XAdd() error = %v", err)
+	}
+
+	// The entry is dead-lettered without being delivered
+	waitFor(
+		t, "the entry to be dead-lettered", func() bool {
+			return deadLetterCount(t, redisClient) == 1
+		},
+	)
+	expectNoDelivery(t, subscription)
+	if count := pendingCount(t, redisClient); count != 0 {
+		t.Errorf("pending entries = %d, want 0", count)
+	}
+}
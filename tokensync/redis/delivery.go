@@ -0,0 +1,71 @@
+package redis
+
+import (
+	"context"
+	"log/slog"
+
+	gojwttokensync "github.com/ralvarezdev/go-jwt/tokensync"
+)
+
+// GetEnvelope returns the envelope of the delivered tokens message
+//
+// Returns:
+//
+//   - *gojwttokensync.TokensMessageEnvelope: the envelope
+func (d *Delivery) GetEnvelope() *gojwttokensync.TokensMessageEnvelope {
+	// Check if the delivery is nil
+	if d == nil {
+		return nil
+	}
+	return d.envelope
+}
+
+// Ack remembers the message ID of the applied tokens message to discard its duplicates, and acknowledges the entry,
+// so it is removed from the pending entries of the consumer group
+//
+// Returns:
+//
+//   - error: an error if the entry could not be acknowledged
+func (d *Delivery) Ack() error {
+	// Check if the delivery is nil
+	if d == nil {
+		return gojwttokensync.ErrNilMessage
+	}
+
+	c := d.consumer
+	if d.envelope.MessageID != "" {
+		c.deduplicator.MarkApplied(d.envelope.MessageID)
+	}
+	return c.redisClient.XAck(
+		context.Background(),
+		c.stream,
+		c.group,
+		d.id,
+	).Err()
+}
+
+// Nack rejects the delivery of a tokens message that could not be applied. The entry is left pending, so it is
+// claimed again once idle for the minimum idle time, until the maximum number of redeliveries is reached
+//
+// Parameters:
+//
+//   - cause: the error that prevented the message from being applied
+//
+// Returns:
+//
+//   - error: an error if the delivery is nil
+func (d *Delivery) Nack(cause error) error {
+	// Check if the delivery is nil
+	if d == nil {
+		return gojwttokensync.ErrNilMessage
+	}
+
+	if d.consumer.logger != nil {
+		d.consumer.logger.Warn(
+			"Message rejected, leaving it pending",
+			slog.String("id", d.id),
+			slog.Any("cause", cause),
+		)
+	}
+	return nil
+}
@@ -0,0 +1,12 @@
+package redis
+
+import (
+	"errors"
+)
+
+var (
+	ErrEmptyStreamName   = errors.New("empty redis stream name")
+	ErrEmptyGroupName    = errors.New("empty redis consumer group name")
+	ErrEmptyConsumerName = errors.New("empty redis consumer name")
+	ErrMissingBody       = errors.New("missing redis stream entry body")
+)
@@ -0,0 +1,221 @@
+package redis
+
+import (
+	"context"
+	"log/slog"
+
+	gojwttokensync "github.com/ralvarezdev/go-jwt/tokensync"
+	"github.com/redis/go-redis/v9"
+)
+
+type (
+	// PublisherOptions are the options for the Publisher
+	PublisherOptions struct {
+		// ContentType is the encoding of the messages, either gojwttokensync.JSONContentType or
+		// gojwttokensync.ProtobufContentType (optional, gojwttokensync.JSONContentType is used if empty)
+		ContentType string
+
+		// Producer is the name of the service that publishes the messages, set in their envelope (optional)
+		Producer string
+
+		// MaxLen is the approximate maximum length of the stream, trimmed on each publish (optional, the stream is
+		// not trimmed if not positive)
+		MaxLen int64
+	}
+
+	// Publisher is the Redis Streams implementation of the Publisher interface. Each message is added as an entry of
+	// the stream, which is read by every consumer group
+	Publisher struct {
+		redisClient *redis.Client
+		stream      string
+		contentType string
+		producer    string
+		maxLen      int64
+		logger      *slog.Logger
+	}
+)
+
+// NewPublisher creates a new Publisher
+//
+// Parameters:
+//
+//   - redisClient: the Redis client
+//   - stream: the name of the stream
+//   - options: the options (optional, can be nil)
+//   - logger: the logger (optional, can be nil)
+//
+// Returns:
+//
+//   - *Publisher: the Publisher instance
+//   - error: an error if the Redis client is nil, the stream name is empty or the content type is unsupported
+func NewPublisher(
+	redisClient *redis.Client,
+	stream string,
+	options *PublisherOptions,
+	logger *slog.Logger,
+) (*Publisher, error) {
+	// Check if the Redis client is nil
+	if redisClient == nil {
+		return nil, gojwttokensync.ErrNilPublisher
+	}
+
+	// Check if the stream name is empty
+	if stream == "" {
+		return nil, ErrEmptyStreamName
+	}
+
+	// Set the options
+	if options == nil {
+		options = &PublisherOptions{}
+	}
+
+	// Check if the content type is supported
+	contentType := options.ContentType
+	switch contentType {
+	case "":
+		contentType = gojwttokensync.JSONContentType
+	case gojwttokensync.JSONContentType, gojwttokensync.ProtobufContentType:
+	default:
+		return nil, gojwttokensync.ErrUnsupportedContentType
+	}
+
+	if logger != nil {
+		logger = logger.With(
+			slog.String("component", "jwt_tokensync_redis_publisher"),
+		)
+	}
+
+	return &Publisher{
+		redisClient: redisClient,
+		stream:      stream,
+		contentType: contentType,
+		producer:    options.Producer,
+		maxLen:      options.MaxLen,
+		logger:      logger,
+	}, nil
+}
+
+// Open opens the publisher, which is a no-op since the Redis client is managed by the caller
+//
+// Returns:
+//
+//   - error: an error if the publisher is nil
+func (p *Publisher) Open() error {
+	// Check if the publisher is nil
+	if p == nil {
+		return gojwttokensync.ErrNilPublisher
+	}
+	return nil
+}
+
+// Close closes the publisher, which is a no-op since the Redis client is managed by the caller
+//
+// Returns:
+//
+//   - error: an error if the publisher is nil
+func (p *Publisher) Close() error {
+	// Check if the publisher is nil
+	if p == nil {
+		return gojwttokensync.ErrNilPublisher
+	}
+	return nil
+}
+
+// PublishTokensMessage publishes a tokens message to the stream
+//
+// Parameters:
+//
+//   - msg: the tokens message to publish
+//
+// Returns:
+//
+//   - error: an error if the message could not be published
+func (p *Publisher) PublishTokensMessage(msg *gojwttokensync.TokensMessage) error {
+	return p.PublishTokensMessageWithCtx(context.Background(), msg)
+}
+
+// PublishTokensMessageWithCtx publishes a tokens message in a new envelope to the stream
+//
+// Parameters:
+//
+//   - ctx: the context
+//   - msg: the tokens message to publish
+//
+// Returns:
+//
+//   - error: an error if the message could not be published
+func (p *Publisher) PublishTokensMessageWithCtx(
+	ctx context.Context,
+	msg *gojwttokensync.TokensMessage,
+) error {
+	// Check if the publisher is nil
+	if p == nil {
+		return gojwttokensync.ErrNilPublisher
+	}
+
+	// Wrap the message in its envelope
+	envelope, err := gojwttokensync.NewTokensMessageEnvelope(msg, p.producer)
+	if err != nil {
+		return err
+	}
+	return p.PublishTokensMessageEnvelope(ctx, envelope)
+}
+
+// PublishTokensMessageEnvelope adds a tokens message envelope to the stream, keeping its message ID
+//
+// Parameters:
+//
+//   - ctx: the context
+//   - envelope: the tokens message envelope to publish
+//
+// Returns:
+//
+//   - error: an error if the envelope could not be encoded or added to the stream
+func (p *Publisher) PublishTokensMessageEnvelope(
+	ctx context.Context,
+	envelope *gojwttokensync.TokensMessageEnvelope,
+) error {
+	// Check if the publisher is nil
+	if p == nil {
+		return gojwttokensync.ErrNilPublisher
+	}
+
+	// Encode the envelope
+	body, err := gojwttokensync.MarshalTokensMessageEnvelope(
+		envelope,
+		p.contentType,
+	)
+	if err != nil {
+		return err
+	}
+
+	// Add the entry to the stream
+	if err = p.redisClient.XAdd(
+		ctx, &redis.XAddArgs{
+			Stream: p.stream,
+			MaxLen: max(p.maxLen, 0),
+			Approx: p.maxLen > 0,
+			Values: map[string]any{
+				BodyField:        body,
+				ContentTypeField: p.contentType,
+			},
+		},
+	).Err(); err != nil {
+		if p.logger != nil {
+			p.logger.Error(
+				"Failed to publish message",
+				slog.String("message_id", envelope.MessageID),
+				slog.String("error", err.Error()),
+			)
+		}
+		return err
+	}
+
+	if p.logger != nil {
+		p.logger.Debug(
+			"Message published",
+			slog.String("message_id", envelope.MessageID),
+		)
+	}
+	return nil
+}
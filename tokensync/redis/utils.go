@@ -0,0 +1,39 @@
+package redis
+
+import (
+	"fmt"
+)
+
+// DeadLetterStreamName returns the name of the dead-letter stream of a stream
+//
+// Parameters:
+//
+//   - stream: the name of the stream
+//
+// Returns:
+//
+//   - string: the name of the dead-letter stream
+func DeadLetterStreamName(stream string) string {
+	return stream + DeadLetterStreamSuffix
+}
+
+// getStringField gets a string field of a stream entry
+//
+// Parameters:
+//
+//   - values: the fields of the stream entry
+//   - field: the name of the field
+//
+// Returns:
+//
+//   - string: the value of the field, or an empty string if missing
+func getStringField(values map[string]any, field string) string {
+	value, ok := values[field]
+	if !ok || value == nil {
+		return ""
+	}
+	if str, ok := value.(string); ok {
+		return str
+	}
+	return fmt.Sprint(value)
+}
@@ -0,0 +1,131 @@
+package tokensync
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	gojwttoken "github.com/ralvarezdev/go-jwt/token"
+)
+
+type (
+	// revocationKey is the key of a revoked token ID
+	revocationKey struct {
+		token gojwttoken.Token
+		id    string
+	}
+
+	// MemoryRevocationStore is the in-memory implementation of the RevocationStore interface, which remembers the
+	// revoked token IDs for a retention period. The revocations are only sticky within the process: they are lost on
+	// restart, so a persistent implementation, such as the SQLite one of the sync/sqlite package, must be used to keep
+	// them sticky across restarts
+	MemoryRevocationStore struct {
+		revoked   map[revocationKey]time.Time
+		retention time.Duration
+		now       func() time.Time
+		lastPurge time.Time
+		mutex     sync.Mutex
+	}
+)
+
+// NewMemoryRevocationStore creates a new MemoryRevocationStore
+//
+// Parameters:
+//
+//   - retention: the time a revoked token ID is remembered (optional, DefaultRevocationRetention is used if not
+//     positive)
+//   - now: the clock (optional, time.Now is used if nil)
+//
+// Returns:
+//
+//   - *MemoryRevocationStore: the MemoryRevocationStore instance
+func NewMemoryRevocationStore(
+	retention time.Duration,
+	now func() time.Time,
+) *MemoryRevocationStore {
+	if retention <= 0 {
+		retention = DefaultRevocationRetention
+	}
+	if now == nil {
+		now = time.Now
+	}
+
+	return &MemoryRevocationStore{
+		revoked:   make(map[revocationKey]time.Time),
+		retention: retention,
+		now:       now,
+		lastPurge: now(),
+	}
+}
+
+// MarkRevoked remembers the revoked token IDs
+//
+// Parameters:
+//
+//   - ctx: the context
+//   - token: the token type
+//   - ids: the revoked token IDs
+//
+// Returns:
+//
+//   - error: always nil
+func (m *MemoryRevocationStore) MarkRevoked(
+	_ context.Context,
+	token gojwttoken.Token,
+	ids []string,
+) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	now := m.now()
+	m.purge(now)
+
+	expiresAt := now.Add(m.retention)
+	for _, id := range ids {
+		m.revoked[revocationKey{token: token, id: id}] = expiresAt
+	}
+	return nil
+}
+
+// IsRevoked checks if a token ID was revoked
+//
+// Parameters:
+//
+//   - ctx: the context
+//   - token: the token type
+//   - id: the token ID
+//
+// Returns:
+//
+//   - bool: true if the token ID was revoked within the retention period
+//   - error: always nil
+func (m *MemoryRevocationStore) IsRevoked(
+	_ context.Context,
+	token gojwttoken.Token,
+	id string,
+) (bool, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	expiresAt, ok := m.revoked[revocationKey{token: token, id: id}]
+	return ok && m.now().Before(expiresAt), nil
+}
+
+// purge forgets the expired revoked token IDs, at most once per RevocationPurgeInterval. The mutex must be held by the
+// caller
+//
+// Parameters:
+//
+//   - now: the current time
+func (m *MemoryRevocationStore) purge(now time.Time) {
+	if now.Sub(m.lastPurge) < RevocationPurgeInterval {
+		return
+	}
+	m.lastPurge = now
+
+	for key, expiresAt := range m.revoked {
+		if !now.Before(expiresAt) {
+			delete(m.revoked, key)
+		}
+	}
+}
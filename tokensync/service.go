@@ -0,0 +1,607 @@
+package tokensync
+
+import (
+	"context"
+	"database/sql"
+	"log/slog"
+	"time"
+
+	gojwtsync "github.com/ralvarezdev/go-jwt/sync"
+	gojwttoken "github.com/ralvarezdev/go-jwt/token"
+	gojwttokenclaims "github.com/ralvarezdev/go-jwt/token/claims"
+	"golang.org/x/sync/errgroup"
+)
+
+type (
+	// ServiceOptions are the options for the DefaultService
+	ServiceOptions struct {
+		// RevocationStore keeps the revocations sticky (optional, a MemoryRevocationStore is used if nil, so the
+		// revocations are only sticky within the process)
+		RevocationStore RevocationStore
+
+		// Now is the clock used to discard the expired issued tokens (optional, time.Now is used if nil)
+		Now func() time.Time
+
+		// CatchUpSource is the authoritative source of the tokens messages missed while offline, requested after each
+		// subscription and before the live messages are applied (optional, the service does not catch up if nil)
+		CatchUpSource EventSource
+
+		// SyncService stores the issue time of the last applied tokens message, from which the service catches up
+		// after a restart (optional, the time is kept in memory if nil, so the service catches up from the
+		// beginning after a restart)
+		SyncService gojwtsync.Service
+
+		// CatchUpOverlap is subtracted from the last sync time when catching up, to request again the messages that
+		// could have been delivered out of order (optional, gojwtsync.DefaultCatchUpOverlap is used if not positive)
+		CatchUpOverlap time.Duration
+
+		// CatchUpLimit is the maximum number of messages requested per catch-up page (optional,
+		// gojwtsync.DefaultCatchUpLimit is used if not positive)
+		CatchUpLimit int
+
+		// SyncInterval is the minimum interval between the updates of the stored last sync time while applying the
+		// live messages (optional, gojwtsync.DefaultSyncInterval is used if not positive)
+		SyncInterval time.Duration
+	}
+
+	// DefaultService is the default implementation of the Service interface. The tokens messages are applied
+	// idempotently and converge to the same state whatever their order: a revoked token is never restored by an issue
+	// delivered after its revocation, and the duplicates and replays are no-ops. When a catch-up source is configured,
+	// the messages missed while offline are applied after each subscription, before switching to the live messages
+	DefaultService struct {
+		gojwttokenclaims.TokenValidator
+		logger          *slog.Logger
+		consumer        Consumer
+		revocationStore RevocationStore
+		now             func() time.Time
+		catchUpSource   EventSource
+		syncService     gojwtsync.Service
+		catchUpOverlap  time.Duration
+		catchUpLimit    int
+		syncInterval    time.Duration
+		lastSyncAt      time.Time
+		lastSyncStoreAt time.Time
+	}
+)
+
+// NewDefaultService creates a new DefaultService
+//
+// Parameters:
+//
+//   - consumer: the tokens messages consumer of a transport
+//   - tokenValidator: the token validator
+//   - options: the options (optional, can be nil)
+//   - logger: the logger (optional, can be nil)
+//
+// Returns:
+//
+//   - *DefaultService: the DefaultService instance
+//   - error: an error if the consumer or the token validator is nil
+func NewDefaultService(
+	consumer Consumer,
+	tokenValidator gojwttokenclaims.TokenValidator,
+	options *ServiceOptions,
+	logger *slog.Logger,
+) (*DefaultService, error) {
+	// Check if the consumer is nil
+	if consumer == nil {
+		return nil, ErrNilConsumer
+	}
+
+	// Check if the token validator is nil
+	if tokenValidator == nil {
+		return nil, gojwttokenclaims.ErrNilClaimsValidator
+	}
+
+	// Set the options
+	if options == nil {
+		options = &ServiceOptions{}
+	}
+
+	now := options.Now
+	if now == nil {
+		now = time.Now
+	}
+
+	revocationStore := options.RevocationStore
+	if revocationStore == nil {
+		revocationStore = NewMemoryRevocationStore(
+			DefaultRevocationRetention,
+			now,
+		)
+	}
+
+	catchUpOverlap := options.CatchUpOverlap
+	if catchUpOverlap <= 0 {
+		catchUpOverlap = gojwtsync.DefaultCatchUpOverlap
+	}
+
+	catchUpLimit := options.CatchUpLimit
+	if catchUpLimit <= 0 {
+		catchUpLimit = gojwtsync.DefaultCatchUpLimit
+	}
+
+	syncInterval := options.SyncInterval
+	if syncInterval <= 0 {
+		syncInterval = gojwtsync.DefaultSyncInterval
+	}
+
+	if logger != nil {
+		logger = logger.With(
+			slog.String("component", "jwt_tokensync_service"),
+		)
+	}
+
+	return &DefaultService{
+		TokenValidator:  tokenValidator,
+		consumer:        consumer,
+		revocationStore: revocationStore,
+		now:             now,
+		catchUpSource:   options.CatchUpSource,
+		syncService:     options.SyncService,
+		catchUpOverlap:  catchUpOverlap,
+		catchUpLimit:    catchUpLimit,
+		syncInterval:    syncInterval,
+		logger:          logger,
+	}, nil
+}
+
+// applyTokensMessage applies a tokens message to the token validator using batch operations. The revocations are
+// remembered before being applied, and the issued tokens that were revoked or have expired are skipped, so the
+// message can be applied in any order relative to the other messages and any number of times
+//
+// Parameters:
+//
+//   - ctx: the context
+//   - msg: the tokens message to apply
+//
+// Returns:
+//
+//   - error: an error if the message could not be applied
+func (d *DefaultService) applyTokensMessage(
+	ctx context.Context,
+	msg *TokensMessage,
+) error {
+	// Remember the revoked token IDs, so they stay revoked
+	if len(msg.RevokedRefreshTokensID) > 0 {
+		if err := d.revocationStore.MarkRevoked(
+			ctx,
+			gojwttoken.RefreshToken,
+			msg.RevokedRefreshTokensID,
+		); err != nil {
+			return err
+		}
+	}
+	if len(msg.RevokedAccessTokensID) > 0 {
+		if err := d.revocationStore.MarkRevoked(
+			ctx,
+			gojwttoken.AccessToken,
+			msg.RevokedAccessTokensID,
+		); err != nil {
+			return err
+		}
+	}
+
+	// Insert the issued refresh and access token IDs that are still valid
+	refreshTokens, accessTokens, err := d.filterIssuedTokenPairs(
+		ctx,
+		msg.IssuedTokenPairs,
+	)
+	if err != nil {
+		return err
+	}
+
+	// The refresh tokens must be inserted before their access tokens
+	if len(refreshTokens) > 0 {
+		if err = d.AddRefreshTokens(ctx, refreshTokens); err != nil {
+			return err
+		}
+	}
+	if len(accessTokens) > 0 {
+		if err = d.AddAccessTokens(ctx, accessTokens); err != nil {
+			return err
+		}
+	}
+
+	// Remove the revoked refresh tokens ID
+	if len(msg.RevokedRefreshTokensID) > 0 {
+		if err = d.RevokeTokens(
+			ctx,
+			gojwttoken.RefreshToken,
+			msg.RevokedRefreshTokensID,
+		); err != nil {
+			return err
+		}
+	}
+
+	// Remove the revoked access tokens ID
+	if len(msg.RevokedAccessTokensID) > 0 {
+		if err = d.RevokeTokens(
+			ctx,
+			gojwttoken.AccessToken,
+			msg.RevokedAccessTokensID,
+		); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// filterIssuedTokenPairs gets the records of the issued tokens that were not revoked and have not expired. The access
+// token of a revoked or expired refresh token is skipped too
+//
+// Parameters:
+//
+//   - ctx: the context
+//   - pairs: the issued token pairs
+//
+// Returns:
+//
+//   - []gojwttokenclaims.RefreshTokenRecord: the refresh token records
+//   - []gojwttokenclaims.AccessTokenRecord: the access token records
+//   - error: an error if the revocations could not be checked
+func (d *DefaultService) filterIssuedTokenPairs(
+	ctx context.Context,
+	pairs []TokenPair,
+) (
+	[]gojwttokenclaims.RefreshTokenRecord,
+	[]gojwttokenclaims.AccessTokenRecord,
+	error,
+) {
+	now := d.now()
+	refreshTokens := make([]gojwttokenclaims.RefreshTokenRecord, 0, len(pairs))
+	accessTokens := make([]gojwttokenclaims.AccessTokenRecord, 0, len(pairs))
+	for _, pair := range pairs {
+		// Check if the refresh token was revoked or has expired
+		if !now.Before(pair.RefreshTokenExpiresAt) {
+			continue
+		}
+		revoked, err := d.revocationStore.IsRevoked(
+			ctx,
+			gojwttoken.RefreshToken,
+			pair.RefreshTokenID,
+		)
+		if err != nil {
+			return nil, nil, err
+		}
+		if revoked {
+			continue
+		}
+		refreshTokens = append(
+			refreshTokens, gojwttokenclaims.RefreshTokenRecord{
+				ID:        pair.RefreshTokenID,
+				ExpiresAt: pair.RefreshTokenExpiresAt,
+			},
+		)
+
+		// Check if the access token was revoked or has expired
+		if !now.Before(pair.AccessTokenExpiresAt) {
+			continue
+		}
+		revoked, err = d.revocationStore.IsRevoked(
+			ctx,
+			gojwttoken.AccessToken,
+			pair.AccessTokenID,
+		)
+		if err != nil {
+			return nil, nil, err
+		}
+		if revoked {
+			continue
+		}
+		accessTokens = append(
+			accessTokens, gojwttokenclaims.AccessTokenRecord{
+				ID:                   pair.AccessTokenID,
+				ParentRefreshTokenID: pair.RefreshTokenID,
+				ExpiresAt:            pair.AccessTokenExpiresAt,
+			},
+		)
+	}
+	return refreshTokens, accessTokens, nil
+}
+
+// getLastSyncAt gets the issue time of the last applied tokens message
+//
+// Parameters:
+//
+//   - ctx: the context
+//
+// Returns:
+//
+//   - time.Time: the last sync time, or the zero time if the service never synced
+//   - error: an error if the last sync time could not be read
+func (d *DefaultService) getLastSyncAt(ctx context.Context) (time.Time, error) {
+	// Check if the last sync time is kept in memory
+	if d.syncService == nil || !d.lastSyncAt.IsZero() {
+		return d.lastSyncAt, nil
+	}
+
+	lastSyncAt, err := d.syncService.GetLastSyncTokensUpdatedAt(ctx)
+	if err != nil {
+		return time.Time{}, err
+	}
+	d.lastSyncAt = lastSyncAt
+	return lastSyncAt, nil
+}
+
+// updateLastSyncAt updates the issue time of the last applied tokens message. The stored time is only updated once
+// per sync interval, unless forced
+//
+// Parameters:
+//
+//   - ctx: the context
+//   - issuedAt: the issue time of the applied tokens message
+//   - force: true to store the time regardless of the sync interval
+//
+// Returns:
+//
+//   - error: an error if the last sync time could not be stored
+func (d *DefaultService) updateLastSyncAt(
+	ctx context.Context,
+	issuedAt time.Time,
+	force bool,
+) error {
+	// Check if the message is newer than the last applied one
+	if !issuedAt.After(d.lastSyncAt) {
+		return nil
+	}
+	d.lastSyncAt = issuedAt
+
+	// Check if the last sync time must be stored
+	if d.syncService == nil {
+		return nil
+	}
+	now := d.now()
+	if !force && now.Sub(d.lastSyncStoreAt) < d.syncInterval {
+		return nil
+	}
+	if err := d.syncService.UpdateLastSyncTokensUpdateAt(
+		ctx,
+		issuedAt,
+	); err != nil {
+		return err
+	}
+	d.lastSyncStoreAt = now
+	return nil
+}
+
+// catchUp applies the tokens messages issued since the last sync time, minus the catch-up overlap, page by page. The
+// overlapping messages that were already applied are no-ops
+//
+// Parameters:
+//
+//   - ctx: the context
+//
+// Returns:
+//
+//   - error: an error if the messages could not be requested or applied
+func (d *DefaultService) catchUp(ctx context.Context) error {
+	// Check if the catch-up source is nil
+	if d.catchUpSource == nil {
+		return nil
+	}
+
+	lastSyncAt, err := d.getLastSyncAt(ctx)
+	if err != nil {
+		return err
+	}
+	var since time.Time
+	if !lastSyncAt.IsZero() {
+		since = lastSyncAt.Add(-d.catchUpOverlap)
+	}
+
+	// The pages are requested after the issue time and message ID of the last applied message
+	var afterMessageID string
+	var applied int
+	for {
+		envelopes, hasMore, err := d.catchUpSource.GetTokensMessagesSince(
+			ctx,
+			since,
+			afterMessageID,
+			d.catchUpLimit,
+		)
+		if err != nil {
+			return err
+		}
+
+		// Apply the messages in issue order
+		for _, envelope := range envelopes {
+			if err = d.applyTokensMessage(ctx, envelope.Message); err != nil {
+				return err
+			}
+			since = envelope.IssuedAt
+			afterMessageID = envelope.MessageID
+		}
+		applied += len(envelopes)
+
+		// Store the last sync time after each page, so a failed catch-up resumes from it
+		if err = d.updateLastSyncAt(ctx, since, true); err != nil {
+			return err
+		}
+
+		// Check if there are more messages, stopping on an empty page that would be requested again
+		if !hasMore || len(envelopes) == 0 {
+			break
+		}
+	}
+
+	if d.logger != nil {
+		d.logger.Info(
+			"Caught up with the missed tokens messages",
+			slog.Int("applied", applied),
+			slog.Time("since", lastSyncAt),
+		)
+	}
+	return nil
+}
+
+// Start starts the service to listen for messages and update the token validator. When the subscription is lost,
+// such as after a broker restart, the service re-subscribes with an exponential backoff until the context is done
+//
+// Parameters:
+//
+//   - ctx: the context for managing cancellation and timeouts
+//
+// Returns:
+//
+//   - error: the context error once the context is done
+func (d *DefaultService) Start(ctx context.Context) error {
+	// Check if the service is nil
+	if d == nil {
+		return sql.ErrConnDone
+	}
+
+	backoff := DefaultResubscribeInitialBackoff
+	for {
+		subscribed, err := d.consume(ctx)
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		// Close the channel, so the next subscription is made on a new one
+		_ = d.consumer.Close()
+
+		// Reset the backoff once a subscription was made
+		if subscribed {
+			backoff = DefaultResubscribeInitialBackoff
+		}
+		if d.logger != nil {
+			d.logger.Warn(
+				"Consumer stopped, re-subscribing",
+				slog.Duration("backoff", backoff),
+				slog.Any("error", err),
+			)
+		}
+
+		// Wait before re-subscribing
+		timer := time.NewTimer(backoff)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+		backoff = min(backoff*2, DefaultResubscribeMaxBackoff)
+	}
+}
+
+// consume subscribes to the queue, catches up with the missed messages and applies the delivered messages until the
+// subscription is lost. The subscription is made before catching up, so the messages published meanwhile are held by
+// the broker instead of being missed
+//
+// Parameters:
+//
+//   - ctx: the context for managing cancellation and timeouts
+//
+// Returns:
+//
+//   - bool: true if the subscription was made
+//   - error: the error that stopped the subscription
+func (d *DefaultService) consume(ctx context.Context) (bool, error) {
+	// Start the consumer
+	tokensMessagesConsumer, err := d.consumer.CreateTokensMessagesConsumer(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	// Catch up with the messages missed while offline
+	if err = d.catchUp(ctx); err != nil {
+		if d.logger != nil {
+			d.logger.Error(
+				"Failed to catch up with the missed tokens messages",
+				slog.String("error", err.Error()),
+			)
+		}
+		return false, err
+	}
+
+	// Create an error group to handle errors from the consumer, which stops both goroutines on the first error
+	eg, ctx := errgroup.WithContext(ctx)
+
+	// Start the consumer in a separate goroutine
+	eg.Go(
+		func() error {
+			return tokensMessagesConsumer.ConsumeTokensMessages(ctx)
+		},
+	)
+
+	// Listen for messages
+	eg.Go(
+		func() error {
+			// Get the deliveries channel
+			deliveriesCh := tokensMessagesConsumer.GetChannel()
+
+			for {
+				select {
+				case <-ctx.Done():
+					if d.logger != nil {
+						d.logger.Info("Service context done, stopping service")
+					}
+					return nil
+				case delivery, ok := <-deliveriesCh:
+					// Check if the channel is closed
+					if !ok {
+						if d.logger != nil {
+							d.logger.Info("Deliveries channel closed, stopping service")
+						}
+						return nil
+					}
+
+					// Check if the delivery is nil
+					if delivery == nil {
+						continue
+					}
+					envelope := delivery.GetEnvelope()
+					if envelope == nil || envelope.Message == nil {
+						if d.logger != nil {
+							d.logger.Warn("Received nil message, skipping")
+						}
+						continue
+					}
+
+					// Apply the message, and acknowledge it only once the token store is updated
+					if err := d.applyTokensMessage(
+						ctx,
+						envelope.Message,
+					); err != nil {
+						if d.logger != nil {
+							d.logger.Error(
+								"Failed to apply message",
+								slog.String("error", err.Error()),
+							)
+						}
+						if err = delivery.Nack(err); err != nil {
+							return err
+						}
+						continue
+					}
+					if err := delivery.Ack(); err != nil {
+						return err
+					}
+
+					// Update the last sync time with the issue time of the message
+					if err := d.updateLastSyncAt(
+						ctx,
+						envelope.IssuedAt,
+						false,
+					); err != nil && d.logger != nil {
+						d.logger.Warn(
+							"Failed to update the last sync time",
+							slog.String("error", err.Error()),
+						)
+					}
+				}
+			}
+		},
+	)
+
+	// Wait for the goroutines to finish and return any errors
+	err = eg.Wait()
+	if err != nil && d.logger != nil {
+		d.logger.Error(
+			"Service encountered an error",
+			slog.String("error", err.Error()),
+		)
+	}
+	return true, err
+}
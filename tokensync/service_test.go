@@ -0,0 +1,247 @@
+package tokensync_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	gojwttoken "github.com/ralvarezdev/go-jwt/token"
+	gojwttokenclaimscache "github.com/ralvarezdev/go-jwt/token/claims/cache"
+	gojwttokensync "github.com/ralvarezdev/go-jwt/tokensync"
+	gojwttokensyncmemory "github.com/ralvarezdev/go-jwt/tokensync/memory"
+)
+
+type (
+	// ackingConsumer wraps a consumer to signal each acknowledged delivery, so the tests know when a message was
+	// applied
+	ackingConsumer struct {
+		gojwttokensync.Consumer
+		subscribed chan struct{}
+		acked      chan string
+	}
+
+	// ackingTokensMessagesConsumer wraps a subscription to signal each acknowledged delivery
+	ackingTokensMessagesConsumer struct {
+		gojwttokensync.TokensMessagesConsumer
+		deliveriesCh chan gojwttokensync.Delivery
+		acked        chan string
+	}
+
+	// ackingDelivery wraps a delivery to signal its acknowledgement
+	ackingDelivery struct {
+		gojwttokensync.Delivery
+		acked chan string
+	}
+
+	// fakeClock is a clock moved by the tests
+	fakeClock struct {
+		now time.Time
+	}
+
+	// serviceStep is a tokens message published to the service, optionally after moving the clock
+	serviceStep struct {
+		advance  time.Duration
+		envelope *gojwttokensync.TokensMessageEnvelope
+	}
+)
+
+// CreateTokensMessagesConsumer subscribes to the wrapped consumer and signals the subscription
+func (a *ackingConsumer) CreateTokensMessagesConsumer(ctx context.Context) (
+	gojwttokensync.TokensMessagesConsumer,
+	error,
+) {
+	tokensMessagesConsumer, err := a.Consumer.CreateTokensMessagesConsumer(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// Forward the deliveries wrapped to signal their acknowledgement
+	deliveriesCh := make(chan gojwttokensync.Delivery)
+	go func() {
+		for delivery := range tokensMessagesConsumer.GetChannel() {
+			select {
+			case deliveriesCh <- &ackingDelivery{Delivery: delivery, acked: a.acked}:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	a.subscribed <- struct{}{}
+	return &ackingTokensMessagesConsumer{
+		TokensMessagesConsumer: tokensMessagesConsumer,
+		deliveriesCh:           deliveriesCh,
+		acked:                  a.acked,
+	}, nil
+}
+
+// GetChannel returns the wrapped deliveries channel
+func (a *ackingTokensMessagesConsumer) GetChannel() <-chan gojwttokensync.Delivery {
+	return a.deliveriesCh
+}
+
+// Ack acknowledges the wrapped delivery and signals it
+func (a *ackingDelivery) Ack() error {
+	if err := a.Delivery.Ack(); err != nil {
+		return err
+	}
+	a.acked <- a.GetEnvelope().MessageID
+	return nil
+}
+
+// Now returns the current time of the clock
+func (f *fakeClock) Now() time.Time {
+	return f.now
+}
+
+// newEnvelope wraps a tokens message in a new envelope
+func newEnvelope(t *testing.T, msg *gojwttokensync.TokensMessage) *gojwttokensync.TokensMessageEnvelope {
+	t.Helper()
+
+	envelope, err := gojwttokensync.NewTokensMessageEnvelope(msg, "test")
+	if err != nil {
+		t.Fatalf("failed to create the envelope: %v", err)
+	}
+	return envelope
+}
+
+func TestDefaultService_ApplyTokensMessages(t *testing.T) {
+	start := time.Now()
+	pair := gojwttokensync.TokenPair{
+		RefreshTokenID:        "refresh",
+		RefreshTokenExpiresAt: start.Add(time.Hour),
+		AccessTokenID:         "access",
+		AccessTokenExpiresAt:  start.Add(time.Minute),
+	}
+	issue := newEnvelope(t, &gojwttokensync.TokensMessage{IssuedTokenPairs: []gojwttokensync.TokenPair{pair}})
+	revokeRefresh := newEnvelope(
+		t,
+		&gojwttokensync.TokensMessage{RevokedRefreshTokensID: []string{pair.RefreshTokenID}},
+	)
+	revokeAccess := newEnvelope(
+		t,
+		&gojwttokensync.TokensMessage{RevokedAccessTokensID: []string{pair.AccessTokenID}},
+	)
+	replayedIssue := newEnvelope(t, issue.Message)
+
+	tests := []struct {
+		name             string
+		steps            []serviceStep
+		wantRefreshValid bool
+		wantAccessValid  bool
+	}{
+		{
+			name:             "issue",
+			steps:            []serviceStep{{envelope: issue}},
+			wantRefreshValid: true,
+			wantAccessValid:  true,
+		},
+		{
+			name:             "duplicate delivery",
+			steps:            []serviceStep{{envelope: issue}, {envelope: issue}},
+			wantRefreshValid: true,
+			wantAccessValid:  true,
+		},
+		{
+			name:             "access token revoked",
+			steps:            []serviceStep{{envelope: issue}, {envelope: revokeAccess}},
+			wantRefreshValid: true,
+		},
+		{
+			name:  "refresh token revoked",
+			steps: []serviceStep{{envelope: issue}, {envelope: revokeRefresh}},
+		},
+		{
+			name:  "duplicate delivery after revoke",
+			steps: []serviceStep{{envelope: issue}, {envelope: revokeRefresh}, {envelope: issue}},
+		},
+		{
+			name:  "replay after revoke",
+			steps: []serviceStep{{envelope: issue}, {envelope: revokeRefresh}, {envelope: replayedIssue}},
+		},
+		{
+			name:  "revoke before issue",
+			steps: []serviceStep{{envelope: revokeRefresh}, {envelope: issue}},
+		},
+		{
+			name:             "access token revoke before issue",
+			steps:            []serviceStep{{envelope: revokeAccess}, {envelope: issue}},
+			wantRefreshValid: true,
+		},
+		{
+			name:             "issue delivered after the access token expiration",
+			steps:            []serviceStep{{advance: 2 * time.Minute, envelope: issue}},
+			wantRefreshValid: true,
+		},
+		{
+			name:  "issue delivered after the refresh token expiration",
+			steps: []serviceStep{{advance: 2 * time.Hour, envelope: issue}},
+		},
+	}
+	for _, test := range tests {
+		t.Run(
+			test.name, func(t *testing.T) {
+				ctx, cancel := context.WithCancel(context.Background())
+				defer cancel()
+
+				// Start the service on the memory transport
+				broker := gojwttokensyncmemory.NewBroker(nil, nil)
+				publisher := broker.NewPublisher("test")
+				consumer := &ackingConsumer{
+					Consumer:   broker.NewConsumer(),
+					subscribed: make(chan struct{}, 1),
+					acked:      make(chan string),
+				}
+				clock := &fakeClock{now: start}
+				tokenValidator := gojwttokenclaimscache.NewTokenValidator(nil)
+				service, err := gojwttokensync.NewDefaultService(
+					consumer,
+					tokenValidator,
+					&gojwttokensync.ServiceOptions{Now: clock.Now},
+					nil,
+				)
+				if err != nil {
+					t.Fatalf("NewDefaultService() error = %v", err)
+				}
+				go func() {
+					_ = service.Start(ctx)
+				}()
+				<-consumer.subscribed
+
+				// Publish the messages one at a time, waiting for each one to be applied
+				for _, step := range test.steps {
+					clock.now = clock.now.Add(step.advance)
+					if err = publisher.PublishTokensMessageEnvelope(ctx, step.envelope); err != nil {
+						t.Fatalf("PublishTokensMessageEnvelope() error = %v", err)
+					}
+					select {
+					case messageID := <-consumer.acked:
+						if messageID != step.envelope.MessageID {
+							t.Fatalf("acked message %q, want %q", messageID, step.envelope.MessageID)
+						}
+					case <-time.After(5 * time.Second):
+						t.Fatal("timed out waiting for the message to be applied")
+					}
+				}
+
+				// Check the tokens
+				for _, check := range []struct {
+					token gojwttoken.Token
+					id    string
+					want  bool
+				}{
+					{token: gojwttoken.RefreshToken, id: pair.RefreshTokenID, want: test.wantRefreshValid},
+					{token: gojwttoken.AccessToken, id: pair.AccessTokenID, want: test.wantAccessValid},
+				} {
+					isValid, _, lookupErr := tokenValidator.LookupToken(ctx, check.token, check.id)
+					if lookupErr != nil {
+						t.Fatalf("LookupToken() error = %v", lookupErr)
+					}
+					if isValid != check.want {
+						t.Errorf("%s token valid = %v, want %v", check.token, isValid, check.want)
+					}
+				}
+			},
+		)
+	}
+}
@@ -0,0 +1,313 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.10
+// 	protoc        (unknown)
+// source: gojwt/sync/v1/tokens_message.proto
+
+package tokensmessagepb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// TokenPair is a pair of issued refresh and access token JTIs
+type TokenPair struct {
+	state                 protoimpl.MessageState `protogen:"open.v1"`
+	RefreshTokenId        string                 `protobuf:"bytes,1,opt,name=refresh_token_id,json=refreshTokenId,proto3" json:"refresh_token_id,omitempty"`
+	RefreshTokenExpiresAt *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=refresh_token_expires_at,json=refreshTokenExpiresAt,proto3" json:"refresh_token_expires_at,omitempty"`
+	AccessTokenId         string                 `protobuf:"bytes,3,opt,name=access_token_id,json=accessTokenId,proto3" json:"access_token_id,omitempty"`
+	AccessTokenExpiresAt  *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=access_token_expires_at,json=accessTokenExpiresAt,proto3" json:"access_token_expires_at,omitempty"`
+	unknownFields         protoimpl.UnknownFields
+	sizeCache             protoimpl.SizeCache
+}
+
+func (x *TokenPair) Reset() {
+	*x = TokenPair{}
+	mi := &file_gojwt_sync_v1_tokens_message_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TokenPair) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TokenPair) ProtoMessage() {}
+
+func (x *TokenPair) ProtoReflect() protoreflect.Message {
+	mi := &file_gojwt_sync_v1_tokens_message_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TokenPair.ProtoReflect.Descriptor instead.
+func (*TokenPair) Descriptor() ([]byte, []int) {
+	return file_gojwt_sync_v1_tokens_message_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *TokenPair) GetRefreshTokenId() string {
+	if x != nil {
+		return x.RefreshTokenId
+	}
+	return ""
+}
+
+func (x *TokenPair) GetRefreshTokenExpiresAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.RefreshTokenExpiresAt
+	}
+	return nil
+}
+
+func (x *TokenPair) GetAccessTokenId() string {
+	if x != nil {
+		return x.AccessTokenId
+	}
+	return ""
+}
+
+func (x *TokenPair) GetAccessTokenExpiresAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.AccessTokenExpiresAt
+	}
+	return nil
+}
+
+// TokensMessage contains the issued and revoked tokens
+type TokensMessage struct {
+	state                  protoimpl.MessageState `protogen:"open.v1"`
+	IssuedTokenPairs       []*TokenPair           `protobuf:"bytes,1,rep,name=issued_token_pairs,json=issuedTokenPairs,proto3" json:"issued_token_pairs,omitempty"`
+	RevokedRefreshTokensId []string               `protobuf:"bytes,2,rep,name=revoked_refresh_tokens_id,json=revokedRefreshTokensId,proto3" json:"revoked_refresh_tokens_id,omitempty"`
+	RevokedAccessTokensId  []string               `protobuf:"bytes,3,rep,name=revoked_access_tokens_id,json=revokedAccessTokensId,proto3" json:"revoked_access_tokens_id,omitempty"`
+	unknownFields          protoimpl.UnknownFields
+	sizeCache              protoimpl.SizeCache
+}
+
+func (x *TokensMessage) Reset() {
+	*x = TokensMessage{}
+	mi := &file_gojwt_sync_v1_tokens_message_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TokensMessage) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TokensMessage) ProtoMessage() {}
+
+func (x *TokensMessage) ProtoReflect() protoreflect.Message {
+	mi := &file_gojwt_sync_v1_tokens_message_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TokensMessage.ProtoReflect.Descriptor instead.
+func (*TokensMessage) Descriptor() ([]byte, []int) {
+	return file_gojwt_sync_v1_tokens_message_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *TokensMessage) GetIssuedTokenPairs() []*TokenPair {
+	if x != nil {
+		return x.IssuedTokenPairs
+	}
+	return nil
+}
+
+func (x *TokensMessage) GetRevokedRefreshTokensId() []string {
+	if x != nil {
+		return x.RevokedRefreshTokensId
+	}
+	return nil
+}
+
+func (x *TokensMessage) GetRevokedAccessTokensId() []string {
+	if x != nil {
+		return x.RevokedAccessTokensId
+	}
+	return nil
+}
+
+// TokensMessageEnvelope is the versioned envelope of a tokens message
+type TokensMessageEnvelope struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// version is the major.minor version of the message format
+	Version string `protobuf:"bytes,1,opt,name=version,proto3" json:"version,omitempty"`
+	// message_id is the unique ID of the message, used by the consumers to discard duplicates
+	MessageId string `protobuf:"bytes,2,opt,name=message_id,json=messageId,proto3" json:"message_id,omitempty"`
+	// issued_at is the time the message was created
+	IssuedAt *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=issued_at,json=issuedAt,proto3" json:"issued_at,omitempty"`
+	// producer is the name of the service that created the message
+	Producer string `protobuf:"bytes,4,opt,name=producer,proto3" json:"producer,omitempty"`
+	// message is the tokens message
+	Message       *TokensMessage `protobuf:"bytes,5,opt,name=message,proto3" json:"message,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TokensMessageEnvelope) Reset() {
+	*x = TokensMessageEnvelope{}
+	mi := &file_gojwt_sync_v1_tokens_message_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TokensMessageEnvelope) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TokensMessageEnvelope) ProtoMessage() {}
+
+func (x *TokensMessageEnvelope) ProtoReflect() protoreflect.Message {
+	mi := &file_gojwt_sync_v1_tokens_message_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TokensMessageEnvelope.ProtoReflect.Descriptor instead.
+func (*TokensMessageEnvelope) Descriptor() ([]byte, []int) {
+	return file_gojwt_sync_v1_tokens_message_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *TokensMessageEnvelope) GetVersion() string {
+	if x != nil {
+		return x.Version
+	}
+	return ""
+}
+
+func (x *TokensMessageEnvelope) GetMessageId() string {
+	if x != nil {
+		return x.MessageId
+	}
+	return ""
+}
+
+func (x *TokensMessageEnvelope) GetIssuedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.IssuedAt
+	}
+	return nil
+}
+
+func (x *TokensMessageEnvelope) GetProducer() string {
+	if x != nil {
+		return x.Producer
+	}
+	return ""
+}
+
+func (x *TokensMessageEnvelope) GetMessage() *TokensMessage {
+	if x != nil {
+		return x.Message
+	}
+	return nil
+}
+
+var File_gojwt_sync_v1_tokens_message_proto protoreflect.FileDescriptor
+
+const file_gojwt_sync_v1_tokens_message_proto_rawDesc = "" +
+	"\n" +
+	"\"gojwt/sync/v1/tokens_message.proto\x12\rgojwt.sync.v1\x1a\x1fgoogle/protobuf/timestamp.proto\"\x85\x02\n" +
+	"\tTokenPair\x12(\n" +
+	"\x10refresh_token_id\x18\x01 \x01(\tR\x0erefreshTokenId\x12S\n" +
+	"\x18refresh_token_expires_at\x18\x02 \x01(\v2\x1a.google.protobuf.TimestampR\x15refreshTokenExpiresAt\x12&\n" +
+	"\x0faccess_token_id\x18\x03 \x01(\tR\raccessTokenId\x12Q\n" +
+	"\x17access_token_expires_at\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\x14accessTokenExpiresAt\"\xcb\x01\n" +
+	"\rTokensMessage\x12F\n" +
+	"\x12issued_token_pairs\x18\x01 \x03(\v2\x18.gojwt.sync.v1.TokenPairR\x10issuedTokenPairs\x129\n" +
+	"\x19revoked_refresh_tokens_id\x18\x02 \x03(\tR\x16revokedRefreshTokensId\x127\n" +
+	"\x18revoked_access_tokens_id\x18\x03 \x03(\tR\x15revokedAccessTokensId\"\xdd\x01\n" +
+	"\x15TokensMessageEnvelope\x12\x18\n" +
+	"\aversion\x18\x01 \x01(\tR\aversion\x12\x1d\n" +
+	"\n" +
+	"message_id\x18\x02 \x01(\tR\tmessageId\x127\n" +
+	"\tissued_at\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\bissuedAt\x12\x1a\n" +
+	"\bproducer\x18\x04 \x01(\tR\bproducer\x126\n" +
+	"\amessage\x18\x05 \x01(\v2\x1c.gojwt.sync.v1.TokensMessageR\amessageBIZGgithub.com/ralvarezdev/go-jwt/tokensync/tokensmessagepb;tokensmessagepbb\x06proto3"
+
+var (
+	file_gojwt_sync_v1_tokens_message_proto_rawDescOnce sync.Once
+	file_gojwt_sync_v1_tokens_message_proto_rawDescData []byte
+)
+
+func file_gojwt_sync_v1_tokens_message_proto_rawDescGZIP() []byte {
+	file_gojwt_sync_v1_tokens_message_proto_rawDescOnce.Do(func() {
+		file_gojwt_sync_v1_tokens_message_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_gojwt_sync_v1_tokens_message_proto_rawDesc), len(file_gojwt_sync_v1_tokens_message_proto_rawDesc)))
+	})
+	return file_gojwt_sync_v1_tokens_message_proto_rawDescData
+}
+
+var file_gojwt_sync_v1_tokens_message_proto_msgTypes = make([]protoimpl.MessageInfo, 3)
+var file_gojwt_sync_v1_tokens_message_proto_goTypes = []any{
+	(*TokenPair)(nil),             // 0: gojwt.sync.v1.TokenPair
+	(*TokensMessage)(nil),         // 1: gojwt.sync.v1.TokensMessage
+	(*TokensMessageEnvelope)(nil), // 2: gojwt.sync.v1.TokensMessageEnvelope
+	(*timestamppb.Timestamp)(nil), // 3: google.protobuf.Timestamp
+}
+var file_gojwt_sync_v1_tokens_message_proto_depIdxs = []int32{
+	3, // 0: gojwt.sync.v1.TokenPair.refresh_token_expires_at:type_name -> google.protobuf.Timestamp
+	3, // 1: gojwt.sync.v1.TokenPair.access_token_expires_at:type_name -> google.protobuf.Timestamp
+	0, // 2: gojwt.sync.v1.TokensMessage.issued_token_pairs:type_name -> gojwt.sync.v1.TokenPair
+	3, // 3: gojwt.sync.v1.TokensMessageEnvelope.issued_at:type_name -> google.protobuf.Timestamp
+	1, // 4: gojwt.sync.v1.TokensMessageEnvelope.message:type_name -> gojwt.sync.v1.TokensMessage
+	5, // [5:5] is the sub-list for method output_type
+	5, // [5:5] is the sub-list for method input_type
+	5, // [5:5] is the sub-list for extension type_name
+	5, // [5:5] is the sub-list for extension extendee
+	0, // [0:5] is the sub-list for field type_name
+}
+
+func init() { file_gojwt_sync_v1_tokens_message_proto_init() }
+func file_gojwt_sync_v1_tokens_message_proto_init() {
+	if File_gojwt_sync_v1_tokens_message_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_gojwt_sync_v1_tokens_message_proto_rawDesc), len(file_gojwt_sync_v1_tokens_message_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   3,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_gojwt_sync_v1_tokens_message_proto_goTypes,
+		DependencyIndexes: file_gojwt_sync_v1_tokens_message_proto_depIdxs,
+		MessageInfos:      file_gojwt_sync_v1_tokens_message_proto_msgTypes,
+	}.Build()
+	File_gojwt_sync_v1_tokens_message_proto = out.File
+	file_gojwt_sync_v1_tokens_message_proto_goTypes = nil
+	file_gojwt_sync_v1_tokens_message_proto_depIdxs = nil
+}
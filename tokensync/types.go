@@ -0,0 +1,38 @@
+package tokensync
+
+import (
+	"time"
+)
+
+type (
+	// TokenPair represents a pair of refresh and access token JTIs
+	TokenPair struct {
+		RefreshTokenID        string    `json:"refresh_token_id"`
+		RefreshTokenExpiresAt time.Time `json:"refresh_token_expires_at"`
+		AccessTokenID         string    `json:"access_token_id"`
+		AccessTokenExpiresAt  time.Time `json:"access_token_expires_at"`
+	}
+
+	// TokensMessage represents a message containing issued and revoked tokens
+	TokensMessage struct {
+		IssuedTokenPairs       []TokenPair `json:"issued_token_pairs"`
+		RevokedRefreshTokensID []string    `json:"revoked_refresh_tokens_id"`
+		RevokedAccessTokensID  []string    `json:"revoked_access_tokens_id"`
+	}
+
+	// TokensMessageEnvelope is the versioned envelope of a tokens message
+	TokensMessageEnvelope struct {
+		Version   string         `json:"version"`
+		MessageID string         `json:"message_id"`
+		IssuedAt  time.Time      `json:"issued_at"`
+		Producer  string         `json:"producer"`
+		Message   *TokensMessage `json:"message"`
+	}
+
+	// OutboxEntry is a tokens message held by an outbox
+	OutboxEntry struct {
+		ID        int64
+		Envelope  *TokensMessageEnvelope
+		CreatedAt time.Time
+	}
+)
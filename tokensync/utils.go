@@ -0,0 +1,23 @@
+package tokensync
+
+// GetMaxRedeliveries returns the number of times a tokens message that could not be applied is redelivered before
+// being dead-lettered, from the configured one of a transport. Zero selects DefaultMaxRedeliveries, and a negative
+// number disables the redeliveries
+//
+// Parameters:
+//
+//   - maxRedeliveries: the configured number of redeliveries
+//
+// Returns:
+//
+//   - int: the number of redeliveries
+func GetMaxRedeliveries(maxRedeliveries int) int {
+	switch {
+	case maxRedeliveries < 0:
+		return 0
+	case maxRedeliveries == 0:
+		return DefaultMaxRedeliveries
+	default:
+		return maxRedeliveries
+	}
+}
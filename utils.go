@@ -0,0 +1,178 @@
+package gojwt
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
+	gojwttoken "github.com/ralvarezdev/go-jwt/token"
+)
+
+// ParseBearerToken parses the raw token from the given authorization header value
+//
+// Parameters:
+//
+//   - authorization: The authorization header value
+//
+// Returns:
+//
+//   - string: The raw token
+//   - error: An error if the authorization header is missing or is not a bearer authorization
+func ParseBearerToken(authorization string) (string, error) {
+	// Check if the authorization header is missing
+	authorization = strings.TrimSpace(authorization)
+	if authorization == "" {
+		return "", ErrMissingAuthorizationHeader
+	}
+
+	// Split the scheme from the token, the scheme is case-insensitive
+	scheme, rawToken, found := strings.Cut(authorization, " ")
+	if !found || !strings.EqualFold(scheme, BearerPrefix) {
+		return "", ErrInvalidAuthorizationHeader
+	}
+
+	// Check if the token is empty or contains more than one value
+	rawToken = strings.TrimSpace(rawToken)
+	if rawToken == "" || strings.ContainsAny(rawToken, " \t") {
+		return "", ErrInvalidAuthorizationHeader
+	}
+	return rawToken, nil
+}
+
+// BearerChallenge builds the RFC 6750 authentication challenge sent in the WWW-Authenticate header
+//
+// Parameters:
+//
+//   - realm: The protection realm (optional, DefaultRealm is used if empty)
+//   - errorCode: The RFC 6750 error code (optional, omitted if empty)
+//   - errorDescription: The human-readable error description (optional, omitted if empty)
+//
+// Returns:
+//
+//   - string: The authentication challenge
+func BearerChallenge(realm, errorCode, errorDescription string) string {
+	if realm == "" {
+		realm = DefaultRealm
+	}
+
+	var builder strings.Builder
+	builder.WriteString(BearerPrefix)
+	builder.WriteString(` realm="`)
+	builder.WriteString(quoteChallengeParam(realm))
+	builder.WriteString(`"`)
+	if errorCode != "" {
+		builder.WriteString(`, error="`)
+		builder.WriteString(quoteChallengeParam(errorCode))
+		builder.WriteString(`"`)
+	}
+	if errorDescription != "" {
+		builder.WriteString(`, error_description="`)
+		builder.WriteString(quoteChallengeParam(errorDescription))
+		builder.WriteString(`"`)
+	}
+	return builder.String()
+}
+
+// InvalidTokenDescription returns the error description sent for an invalid token, without exposing the internal
+// validation errors
+//
+// Parameters:
+//
+//   - err: The validation error
+//
+// Returns:
+//
+//   - string: The error description
+func InvalidTokenDescription(err error) string {
+	switch {
+	case errors.Is(err, jwt.ErrTokenExpired):
+		return "the token has expired"
+	case errors.Is(err, jwt.ErrTokenNotValidYet):
+		return "the token is not valid yet"
+	case errors.Is(err, jwt.ErrTokenMalformed):
+		return "the token is malformed"
+	default:
+		return "the token is invalid"
+	}
+}
+
+// GetClaimsScopes gets the scopes from the scope claim, which is either a space-delimited string or a list of strings
+//
+// Parameters:
+//
+//   - claims: The token claims
+//
+// Returns:
+//
+//   - []string: The scopes, nil if the claim is missing or of an unexpected type
+func GetClaimsScopes(claims jwt.MapClaims) []string {
+	return GetClaimsStrings(claims, ScopeClaim)
+}
+
+// GetClaimsStrings gets the values of a claim that is either a space-delimited string or a list of strings
+//
+// Parameters:
+//
+//   - claims: The token claims
+//   - claim: The claim
+//
+// Returns:
+//
+//   - []string: The claim values, nil if the claim is missing or of an unexpected type
+func GetClaimsStrings(claims jwt.MapClaims, claim string) []string {
+	switch value := claims[claim].(type) {
+	case string:
+		return strings.Fields(value)
+	case []string:
+		return value
+	case []any:
+		values := make([]string, 0, len(value))
+		for _, item := range value {
+			if str, ok := item.(string); ok {
+				values = append(values, str)
+			}
+		}
+		return values
+	default:
+		return nil
+	}
+}
+
+// CheckClaimsTokenType checks if the refresh token claim, when present, matches the given token type
+//
+// Parameters:
+//
+//   - claims: The token claims
+//   - token: The expected token type
+//
+// Returns:
+//
+//   - error: ErrMismatchedTokenType if the claims belong to another token type
+func CheckClaimsTokenType(claims jwt.MapClaims, token gojwttoken.Token) error {
+	isRefreshToken, ok := claims[IsRefreshTokenClaim].(bool)
+	if !ok {
+		return nil
+	}
+	if isRefreshToken != (token == gojwttoken.RefreshToken) {
+		return ErrMismatchedTokenType
+	}
+	return nil
+}
+
+// quoteChallengeParam escapes the characters that cannot appear in a quoted challenge parameter
+//
+// Parameters:
+//
+//   - param: The challenge parameter
+//
+// Returns:
+//
+//   - string: The escaped challenge parameter
+func quoteChallengeParam(param string) string {
+	return strings.NewReplacer(
+		`\`, `\\`,
+		`"`, `\"`,
+		"\r", " ",
+		"\n", " ",
+	).Replace(param)
+}